@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+)
+
+const usage = `用法:
+  policy export [-config 配置文件] [-o 输出文件]
+  policy import [-config 配置文件] [-dry-run] 策略文件
+
+策略文件采用 Casbin CSV 格式：
+  p, 角色编码, 权限编码[, allow|deny]
+  g, 用户名, 角色编码[, 租户编码]
+`
+
+// main 入口，以 Casbin CSV 格式导出或导入角色权限策略
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	configPath := flags.String("config", "config/config.yaml", "配置文件路径")
+	output := flags.String("o", "", "导出文件路径，默认输出到标准输出")
+	dryRun := flags.Bool("dry-run", false, "只输出差异报告，不写入数据库")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flags.Parse(os.Args[2:])
+
+	policyService, err := newPolicyService(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		err = exportPolicy(policyService, *output)
+	case "import":
+		if flags.NArg() != 1 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		err = importPolicy(policyService, flags.Arg(0), *dryRun)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newPolicyService 加载配置并连接数据库，创建策略服务
+func newPolicyService(configPath string) (service.PolicyService, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	if err := model.InitDB(&cfg.Database, cfg.Env); err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %w", err)
+	}
+	db := model.GetDB()
+
+	// 命令行进程不持有权限缓存，运行中服务的缓存条目最迟在TTL后失效
+	return service.NewPolicyService(
+		repository.NewPolicyRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewRoleRepository(db),
+		repository.NewPermissionRepository(db),
+		repository.NewTenantRepository(db),
+		nil,
+		repository.NewSoDRepository(db),
+	), nil
+}
+
+// exportPolicy 导出策略到文件或标准输出
+func exportPolicy(policyService service.PolicyService, output string) error {
+	policy, err := policyService.Export()
+	if err != nil {
+		return fmt.Errorf("导出策略失败: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if _, err := policy.WriteTo(w); err != nil {
+		return fmt.Errorf("写入策略失败: %w", err)
+	}
+	if output != "" {
+		fmt.Printf("已导出 %d 条 p 规则、%d 条 g 规则到 %s\n", len(policy.Grants), len(policy.Assignments), output)
+	}
+	return nil
+}
+
+// importPolicy 从文件导入策略并输出差异报告
+func importPolicy(policyService service.PolicyService, path string, dryRun bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := policyService.Import(file, dryRun)
+	if err == errors.ErrInvalidPolicy {
+		for _, msg := range report.Errors {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("导入策略失败: %w", err)
+	}
+
+	printReport(report)
+	return nil
+}
+
+// printReport 输出差异报告
+func printReport(report *schema.PolicyImportReport) {
+	for _, code := range report.CreatedRoles {
+		fmt.Printf("* 新建角色 %s\n", code)
+	}
+	for _, line := range report.Removed {
+		fmt.Printf("- %s\n", line)
+	}
+	for _, line := range report.Added {
+		fmt.Printf("+ %s\n", line)
+	}
+
+	switch {
+	case report.Applied:
+		fmt.Printf("导入完成：新建角色 %d 个，新增 %d 条，删除 %d 条\n", len(report.CreatedRoles), len(report.Added), len(report.Removed))
+	case report.DryRun:
+		fmt.Println("预览模式，未写入数据库")
+	default:
+		fmt.Println("策略与数据库一致，无需变更")
+	}
+}
@@ -13,7 +13,9 @@ import (
 	_ "github.com/lvyunze/fiber-rbac/docs"
 	"github.com/lvyunze/fiber-rbac/internal/app"
 	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/logger"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/relation"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/repository"
 	"github.com/lvyunze/fiber-rbac/internal/service"
@@ -52,6 +54,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 加载对象类型与关系改写规则
+	relationSchema, err := relation.NewSchema(&cfg.Relations)
+	if err != nil {
+		slog.Error("关系授权配置无效", "error", err)
+		os.Exit(1)
+	}
+
 	// 初始化验证器
 	validator.Init()
 
@@ -60,11 +69,41 @@ func main() {
 	roleRepo := repository.NewRoleRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	policyRepo := repository.NewPolicyRepository(db)
+	sodRepo := repository.NewSoDRepository(db)
+	relationRepo := repository.NewRelationRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	orgUnitRepo := repository.NewOrgUnitRepository(db)
+
+	// 初始化用户有效权限缓存
+	var permissionCache *service.PermissionCache
+	if cfg.Cache.PermissionTTL > 0 {
+		permissionCache = service.NewPermissionCache(time.Duration(cfg.Cache.PermissionTTL)*time.Second, cfg.Cache.PermissionMaxEntries)
+	}
+
+	// 按方法与路径校验权限使用的接口路由表，由权限服务在接口权限变更时重建
+	routeTable := service.NewRouteTable(permissionRepo, 0)
 
 	// 初始化服务层
-	userService := service.NewUserService(userRepo, roleRepo, permissionRepo, refreshTokenRepo, &cfg.JWT)
-	roleService := service.NewRoleService(roleRepo, permissionRepo)
-	permissionService := service.NewPermissionService(permissionRepo)
+	userService := service.NewUserService(userRepo, roleRepo, permissionRepo, tenantRepo, refreshTokenRepo, &cfg.JWT, permissionCache, routeTable, sodRepo)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, tenantRepo, permissionCache)
+	roleTemplateService := service.NewRoleTemplateService(cfg.RoleTemplates, roleService, roleRepo, permissionRepo, permissionCache)
+	permissionService := service.NewPermissionService(permissionRepo, permissionCache, routeTable)
+	tenantService := service.NewTenantService(tenantRepo)
+	policyService := service.NewPolicyService(policyRepo, userRepo, roleRepo, permissionRepo, tenantRepo, permissionCache, sodRepo)
+	forwardAuthService := service.NewForwardAuthService(&cfg.ForwardAuth, userRepo, userService, &cfg.JWT)
+	sodService := service.NewSoDService(sodRepo, roleRepo, userRepo)
+	relationService := service.NewRelationService(relationSchema, relationRepo)
+	groupService := service.NewGroupService(groupRepo, userRepo, roleRepo, tenantRepo, permissionCache, sodRepo)
+	orgUnitService := service.NewOrgUnitService(orgUnitRepo, userRepo, roleRepo)
+
+	// 启动后台任务
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.Jobs.RoleExpirySweepInterval > 0 {
+		service.NewRoleExpirySweeper(userRepo, permissionCache, time.Duration(cfg.Jobs.RoleExpirySweepInterval)*time.Second).Start(jobCtx)
+	}
 
 	// 初始化Fiber应用
 	fiberApp := app.NewFiberApp(cfg)
@@ -73,7 +112,14 @@ func main() {
 	app.RegisterSwaggerRoute(fiberApp, cfg.Env == "dev")
 
 	// 注册路由
-	app.RegisterRoutes(fiberApp, userService, roleService, permissionService, &cfg.JWT)
+	app.RegisterRoutes(fiberApp, userService, roleService, roleTemplateService, permissionService, tenantService, policyService, forwardAuthService, sodService, relationService, groupService, orgUnitService, &cfg.JWT, &cfg.Security)
+
+	// 同步接口权限，路由已不存在的接口权限仅告警，需人工确认后清理
+	if report, err := permissionService.SyncRoutes(apiroute.Collect(fiberApp, app.APIPrefix)); err != nil {
+		slog.Error("同步接口权限失败", "error", err)
+	} else {
+		slog.Info("接口权限同步完成", "created", len(report.Created), "linked", len(report.Linked), "stale", len(report.Stale), "skipped", len(report.Skipped))
+	}
 
 	// 启动服务器（非阻塞）
 	go func() {
@@ -91,6 +137,7 @@ func main() {
 	<-quit
 
 	slog.Info("正在关闭服务器...")
+	stopJobs()
 
 	// 设置关闭超时
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -101,5 +148,11 @@ func main() {
 		slog.Error("服务器关闭失败", "error", err)
 	}
 
+	// 输出权限缓存命中统计
+	if permissionCache != nil {
+		stats := permissionCache.Stats()
+		slog.Info("权限缓存统计", "hits", stats.Hits, "misses", stats.Misses, "evictions", stats.Evictions, "entries", stats.Entries)
+	}
+
 	slog.Info("服务器已关闭")
 }
@@ -16,6 +16,14 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
 	Security SecurityConfig `mapstructure:"security"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+	Jobs     JobsConfig     `mapstructure:"jobs"`
+	// RoleTemplates 角色模板，可据此创建角色并在模板变更后同步到已创建的角色
+	RoleTemplates []RoleTemplateConfig `mapstructure:"role_templates"`
+	// ForwardAuth 反向代理转发认证的规则表
+	ForwardAuth ForwardAuthConfig `mapstructure:"forward_auth"`
+	// Relations 资源实例关系授权的对象类型定义
+	Relations RelationsConfig `mapstructure:"relations"`
 }
 
 // ServerConfig 服务器配置
@@ -55,6 +63,68 @@ type LogConfig struct {
 type SecurityConfig struct {
 	IPWhitelist []string `mapstructure:"ip_whitelist"`
 	EnableWhitelist bool `mapstructure:"enable_whitelist"`
+	EnforceRoutes bool `mapstructure:"enforce_routes"` // 是否按请求方法与路径校验接口权限
+}
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	PermissionTTL        int `mapstructure:"permission_ttl"`         // 用户有效权限缓存时间（秒），0表示不缓存
+	PermissionMaxEntries int `mapstructure:"permission_max_entries"` // 最多缓存的用户数，0表示不限制
+}
+
+// JobsConfig 后台任务配置
+type JobsConfig struct {
+	RoleExpirySweepInterval int `mapstructure:"role_expiry_sweep_interval"` // 过期角色分配清理间隔（秒），0表示不清理
+}
+
+// RoleTemplateConfig 角色模板配置，权限以编码引用
+type RoleTemplateConfig struct {
+	Code        string   `mapstructure:"code"`
+	Name        string   `mapstructure:"name"`
+	Description string   `mapstructure:"description"`
+	Version     int      `mapstructure:"version"`     // 模板版本，修改权限后应递增
+	Permissions []string `mapstructure:"permissions"` // 以允许效果授予的权限编码
+	Deny        []string `mapstructure:"deny"`        // 以拒绝效果授予的权限编码
+}
+
+// ForwardAuthConfig 反向代理转发认证配置
+type ForwardAuthConfig struct {
+	Rules          []ForwardAuthRule `mapstructure:"rules"`           // 按顺序匹配，第一条命中的规则生效
+	AllowUnmatched bool              `mapstructure:"allow_unmatched"` // 未匹配任何规则的请求认证通过即放行，默认拒绝
+}
+
+// ForwardAuthRule 转发认证规则，将原始请求的方法与路径映射为所需权限
+type ForwardAuthRule struct {
+	Method      string   `mapstructure:"method"`      // 请求方法，为空或 * 匹配任意方法
+	Path        string   `mapstructure:"path"`        // 路径模式，:param 匹配一个分段，末尾 * 匹配任意后缀
+	Permissions []string `mapstructure:"permissions"` // 所需权限编码，为空时认证通过即放行
+	Mode        string   `mapstructure:"mode"`        // 判定模式 any 或 all，默认 all
+	Public      bool     `mapstructure:"public"`      // 无需认证即放行
+}
+
+// RelationsConfig 关系元组（object#relation@subject）的对象类型定义
+type RelationsConfig struct {
+	Types    []RelationTypeConfig `mapstructure:"types"`
+	MaxDepth int                  `mapstructure:"max_depth"` // 判定时最多展开的层数，0表示使用默认值
+}
+
+// RelationTypeConfig 一种对象类型及其关系
+type RelationTypeConfig struct {
+	Name      string           `mapstructure:"name"`
+	Relations []RelationConfig `mapstructure:"relations"`
+}
+
+// RelationConfig 一种关系及其改写规则，持有该关系的主体是直接写入的主体与各改写来源的并集
+type RelationConfig struct {
+	Name     string               `mapstructure:"name"`
+	Includes []string             `mapstructure:"includes"` // 同一对象上隐含本关系的其他关系，如 viewer 包含 editor
+	From     []RelationFromConfig `mapstructure:"from"`     // 经由关联对象获得本关系，如文档的 viewer 包含其 parent 文件夹的 viewer
+}
+
+// RelationFromConfig 经由关联对象获得关系（tuple to userset）
+type RelationFromConfig struct {
+	Tupleset string `mapstructure:"tupleset"` // 本对象上指向关联对象的关系，如 parent
+	Relation string `mapstructure:"relation"` // 关联对象上的关系
 }
 
 // DSN 返回数据库连接字符串
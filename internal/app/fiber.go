@@ -45,7 +45,7 @@ func registerMiddlewares(app *fiber.App, cfg *config.Config) {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000,http://localhost:8080",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Tenant-ID",
 		AllowCredentials: true,
 		ExposeHeaders:    "Content-Length, Content-Range",
 	}))
@@ -3,8 +3,14 @@ package app
 import (
 	"github.com/lvyunze/fiber-rbac/config"
 	"github.com/lvyunze/fiber-rbac/internal/handler/auth"
+	"github.com/lvyunze/fiber-rbac/internal/handler/group"
+	"github.com/lvyunze/fiber-rbac/internal/handler/orgunit"
 	"github.com/lvyunze/fiber-rbac/internal/handler/permission"
+	"github.com/lvyunze/fiber-rbac/internal/handler/policy"
+	"github.com/lvyunze/fiber-rbac/internal/handler/relation"
 	"github.com/lvyunze/fiber-rbac/internal/handler/role"
+	"github.com/lvyunze/fiber-rbac/internal/handler/sod"
+	"github.com/lvyunze/fiber-rbac/internal/handler/tenant"
 	"github.com/lvyunze/fiber-rbac/internal/handler/user"
 	"github.com/lvyunze/fiber-rbac/internal/middleware"
 	"github.com/lvyunze/fiber-rbac/internal/service"
@@ -12,49 +18,188 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// APIPrefix 业务接口路由前缀
+const APIPrefix = "/api/v1"
+
+// Services 注册路由所需的服务
+type Services struct {
+	User         service.UserService
+	Role         service.RoleService
+	RoleTemplate service.RoleTemplateService
+	Permission   service.PermissionService
+	Tenant       service.TenantService
+	Policy       service.PolicyService
+	ForwardAuth  service.ForwardAuthService
+	SoD          service.SoDService
+	Relation     service.RelationService
+	Group        service.GroupService
+	OrgUnit      service.OrgUnitService
+}
+
 // RegisterRoutes 注册所有路由
-func RegisterRoutes(app *fiber.App, userService service.UserService, roleService service.RoleService, permissionService service.PermissionService, jwtConfig *config.JWTConfig) {
+func RegisterRoutes(app *fiber.App, userService service.UserService, roleService service.RoleService, roleTemplateService service.RoleTemplateService, permissionService service.PermissionService, tenantService service.TenantService, policyService service.PolicyService, forwardAuthService service.ForwardAuthService, sodService service.SoDService, relationService service.RelationService, groupService service.GroupService, orgUnitService service.OrgUnitService, jwtConfig *config.JWTConfig, securityConfig *config.SecurityConfig) {
+	MountRoutes(app, APIPrefix, &Services{
+		User:         userService,
+		Role:         roleService,
+		RoleTemplate: roleTemplateService,
+		Permission:   permissionService,
+		Tenant:       tenantService,
+		Policy:       policyService,
+		ForwardAuth:  forwardAuthService,
+		SoD:          sodService,
+		Relation:     relationService,
+		Group:        groupService,
+		OrgUnit:      orgUnitService,
+	}, jwtConfig, securityConfig)
+}
+
+// MountRoutes 在指定路由器的 prefix 下注册全部接口，router 可以是宿主应用或其路由分组
+func MountRoutes(router fiber.Router, prefix string, services *Services, jwtConfig *config.JWTConfig, securityConfig *config.SecurityConfig) {
+	userService := services.User
+	roleService := services.Role
+	roleTemplateService := services.RoleTemplate
+	permissionService := services.Permission
+	tenantService := services.Tenant
+	policyService := services.Policy
+	forwardAuthService := services.ForwardAuth
+	sodService := services.SoD
+	relationService := services.Relation
+	groupService := services.Group
+	orgUnitService := services.OrgUnit
+
 	// API 版本前缀
-	api := app.Group("/api/v1")
+	api := router.Group(prefix)
 
 	// 认证相关路由 - 无需认证
 	authGroup := api.Group("/auth")
 	authGroup.Post("/login", auth.NewLoginHandler(userService).Handle)
 	authGroup.Post("/refresh", auth.NewRefreshHandler(userService).Handle)
 
-	// 需要认证的路由组
-	authRequired := api.Use(middleware.Auth(jwtConfig))
+	// 反向代理转发认证 - 自行认证并以HTTP状态码返回结果，接受任意方法，
+	// /forward/* 供 Envoy 以路径前缀方式附加原始路径
+	forwardHandler := auth.NewForwardHandler(forwardAuthService)
+	authGroup.All("/forward", forwardHandler.Handle)
+	authGroup.All("/forward/*", forwardHandler.Handle)
 
-	// 用户个人信息和权限检查
+	// 用户个人信息和权限检查 - 仅需认证，查询的是调用方自身，
+	// 需在下方的分组中间件之前注册，以免开启 enforce_routes 后被接口权限校验拦截
 	authGroup.Post("/profile", middleware.Auth(jwtConfig), auth.NewProfileHandler(userService).Handle)
 	authGroup.Post("/check-permission", middleware.Auth(jwtConfig), auth.NewCheckHandler(userService).Handle)
 	authGroup.Post("/check", middleware.Auth(jwtConfig), auth.NewCheckHandler(userService).Handle)
+	authGroup.Post("/check-batch", middleware.Auth(jwtConfig), auth.NewCheckBatchHandler(userService).Handle)
+	authGroup.Post("/explain", middleware.Auth(jwtConfig), auth.NewExplainHandler(userService).Handle)
+	authGroup.Post("/evaluate", middleware.Auth(jwtConfig), auth.NewEvaluateHandler(userService).Handle)
+	authGroup.Post("/menus", middleware.Auth(jwtConfig), auth.NewMenusHandler(userService).Handle)
+
+	// 需要认证的路由组
+	authRequired := api.Use(middleware.Auth(jwtConfig))
+
+	// 按方法与路径校验接口权限，与各路由上的权限编码校验同时生效
+	if securityConfig.EnforceRoutes {
+		authRequired.Use(middleware.RequireRoute(userService))
+	}
 
 	// 用户管理
 	userGroup := authRequired.Group("/users")
-	userGroup.Post("/list", user.NewListHandler(userService).Handle)
-	userGroup.Post("/create", user.NewCreateHandler(userService).Handle)
-	userGroup.Post("/detail", user.NewDetailHandler(userService).Handle)
-	userGroup.Post("/update", user.NewUpdateHandler(userService).Handle)
-	userGroup.Post("/delete", user.NewDeleteHandler(userService).Handle)
-	userGroup.Post("/assign-roles", user.NewAssignRoleHandler(userService).Handle)
-	userGroup.Post("/list-roles", user.NewListRolesHandler(userService).Handle)
+	userGroup.Post("/list", middleware.RequirePermission(userService, "user:list"), user.NewListHandler(userService).Handle)
+	userGroup.Post("/create", middleware.RequirePermission(userService, "user:create"), user.NewCreateHandler(userService).Handle)
+	userGroup.Post("/detail", middleware.RequirePermission(userService, "user:list"), user.NewDetailHandler(userService).Handle)
+	userGroup.Post("/update", middleware.RequirePermission(userService, "user:update"), user.NewUpdateHandler(userService).Handle)
+	userGroup.Post("/delete", middleware.RequirePermission(userService, "user:delete"), user.NewDeleteHandler(userService).Handle)
+	userGroup.Post("/assign-roles", middleware.RequirePermission(userService, "user:update"), user.NewAssignRoleHandler(userService).Handle)
+	userGroup.Post("/list-roles", middleware.RequirePermission(userService, "user:list"), user.NewListRolesHandler(userService).Handle)
+	userGroup.Post("/assign-permissions", middleware.RequirePermission(userService, "user:update"), user.NewAssignPermissionsHandler(userService).Handle)
+	userGroup.Post("/revoke-permissions", middleware.RequirePermission(userService, "user:update"), user.NewRevokePermissionsHandler(userService).Handle)
+	userGroup.Post("/list-permissions", middleware.RequirePermission(userService, "user:list"), user.NewListPermissionsHandler(userService).Handle)
+	userGroup.Post("/explain-permission", middleware.RequirePermission(userService, "user:list"), user.NewExplainPermissionHandler(userService).Handle)
 
 	// 角色管理
 	roleGroup := authRequired.Group("/roles")
-	roleGroup.Post("/list", role.NewListHandler(roleService).Handle)
-	roleGroup.Post("/create", role.NewCreateHandler(roleService).Handle)
-	roleGroup.Post("/detail", role.NewDetailHandler(roleService).Handle)
-	roleGroup.Post("/update", role.NewUpdateHandler(roleService).Handle)
-	roleGroup.Post("/delete", role.NewDeleteHandler(roleService).Handle)
-	roleGroup.Post("/assign-permissions", role.NewAssignPermissionHandler(roleService).Handle)
-	roleGroup.Post("/list-permissions", role.NewListPermissionsHandler(roleService).Handle)
+	roleGroup.Post("/list", middleware.RequirePermission(userService, "role:list"), role.NewListHandler(roleService).Handle)
+	roleGroup.Post("/create", middleware.RequirePermission(userService, "role:create"), role.NewCreateHandler(roleService).Handle)
+	roleGroup.Post("/detail", middleware.RequirePermission(userService, "role:list"), role.NewDetailHandler(roleService).Handle)
+	roleGroup.Post("/update", middleware.RequirePermission(userService, "role:update"), role.NewUpdateHandler(roleService).Handle)
+	roleGroup.Post("/delete", middleware.RequirePermission(userService, "role:delete"), role.NewDeleteHandler(roleService).Handle)
+	roleGroup.Post("/assign-permissions", middleware.RequirePermission(userService, "role:update"), role.NewAssignPermissionHandler(roleService).Handle)
+	roleGroup.Post("/list-permissions", middleware.RequirePermission(userService, "role:list"), role.NewListPermissionsHandler(roleService).Handle)
+	roleGroup.Post("/ancestors", middleware.RequirePermission(userService, "role:list"), role.NewAncestorsHandler(roleService).Handle)
+	roleGroup.Post("/descendants", middleware.RequirePermission(userService, "role:list"), role.NewDescendantsHandler(roleService).Handle)
+	roleGroup.Post("/effective-permissions", middleware.RequirePermission(userService, "role:list"), role.NewEffectivePermissionsHandler(roleService).Handle)
+	roleGroup.Post("/clone", middleware.RequirePermission(userService, "role:create"), role.NewCloneHandler(roleService).Handle)
+	roleGroup.Post("/templates", middleware.RequirePermission(userService, "role:list"), role.NewTemplatesHandler(roleTemplateService).Handle)
+	roleGroup.Post("/instantiate-template", middleware.RequirePermission(userService, "role:create"), role.NewInstantiateTemplateHandler(roleTemplateService).Handle)
+	roleGroup.Post("/propagate-template", middleware.RequirePermission(userService, "role:update"), role.NewPropagateTemplateHandler(roleTemplateService).Handle)
+	roleGroup.Post("/assign-data-scope", middleware.RequirePermission(userService, "role:update"), role.NewAssignDataScopeHandler(orgUnitService).Handle)
 
 	// 权限管理
 	permissionGroup := authRequired.Group("/permissions")
-	permissionGroup.Post("/list", permission.NewListHandler(permissionService).Handle)
-	permissionGroup.Post("/create", permission.NewCreateHandler(permissionService).Handle)
-	permissionGroup.Post("/detail", permission.NewDetailHandler(permissionService).Handle)
-	permissionGroup.Post("/update", permission.NewUpdateHandler(permissionService).Handle)
-	permissionGroup.Post("/delete", permission.NewDeleteHandler(permissionService).Handle)
+	permissionGroup.Post("/list", middleware.RequirePermission(userService, "permission:list"), permission.NewListHandler(permissionService).Handle)
+	permissionGroup.Post("/create", middleware.RequirePermission(userService, "permission:create"), permission.NewCreateHandler(permissionService).Handle)
+	permissionGroup.Post("/detail", middleware.RequirePermission(userService, "permission:list"), permission.NewDetailHandler(permissionService).Handle)
+	permissionGroup.Post("/update", middleware.RequirePermission(userService, "permission:update"), permission.NewUpdateHandler(permissionService).Handle)
+	permissionGroup.Post("/delete", middleware.RequirePermission(userService, "permission:delete"), permission.NewDeleteHandler(permissionService).Handle)
+	permissionGroup.Post("/tree", middleware.RequirePermission(userService, "permission:list"), permission.NewTreeHandler(permissionService).Handle)
+	permissionGroup.Post("/sync-routes", middleware.RequirePermission(userService, "permission:create"), permission.NewSyncRoutesHandler(permissionService, fullPrefix(router, prefix)).Handle)
+
+	// 租户管理
+	tenantGroup := authRequired.Group("/tenants")
+	tenantGroup.Post("/list", middleware.RequirePermission(userService, "tenant:list"), tenant.NewListHandler(tenantService).Handle)
+	tenantGroup.Post("/create", middleware.RequirePermission(userService, "tenant:create"), tenant.NewCreateHandler(tenantService).Handle)
+	tenantGroup.Post("/detail", middleware.RequirePermission(userService, "tenant:list"), tenant.NewDetailHandler(tenantService).Handle)
+	tenantGroup.Post("/update", middleware.RequirePermission(userService, "tenant:update"), tenant.NewUpdateHandler(tenantService).Handle)
+	tenantGroup.Post("/delete", middleware.RequirePermission(userService, "tenant:delete"), tenant.NewDeleteHandler(tenantService).Handle)
+
+	// 用户组管理
+	groupGroup := authRequired.Group("/groups")
+	groupGroup.Post("/list", middleware.RequirePermission(userService, "group:list"), group.NewListHandler(groupService).Handle)
+	groupGroup.Post("/create", middleware.RequirePermission(userService, "group:create"), group.NewCreateHandler(groupService).Handle)
+	groupGroup.Post("/detail", middleware.RequirePermission(userService, "group:list"), group.NewDetailHandler(groupService).Handle)
+	groupGroup.Post("/update", middleware.RequirePermission(userService, "group:update"), group.NewUpdateHandler(groupService).Handle)
+	groupGroup.Post("/delete", middleware.RequirePermission(userService, "group:delete"), group.NewDeleteHandler(groupService).Handle)
+	groupGroup.Post("/add-members", middleware.RequirePermission(userService, "group:update"), group.NewAddMembersHandler(groupService).Handle)
+	groupGroup.Post("/remove-members", middleware.RequirePermission(userService, "group:update"), group.NewRemoveMembersHandler(groupService).Handle)
+	groupGroup.Post("/list-members", middleware.RequirePermission(userService, "group:list"), group.NewListMembersHandler(groupService).Handle)
+	groupGroup.Post("/assign-roles", middleware.RequirePermission(userService, "group:update"), group.NewAssignRolesHandler(groupService).Handle)
+
+	// 组织单元管理路由
+	orgUnitGroup := authRequired.Group("/org-units")
+	orgUnitGroup.Post("/tree", middleware.RequirePermission(userService, "org:list"), orgunit.NewTreeHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/create", middleware.RequirePermission(userService, "org:create"), orgunit.NewCreateHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/detail", middleware.RequirePermission(userService, "org:list"), orgunit.NewDetailHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/update", middleware.RequirePermission(userService, "org:update"), orgunit.NewUpdateHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/delete", middleware.RequirePermission(userService, "org:delete"), orgunit.NewDeleteHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/add-members", middleware.RequirePermission(userService, "org:update"), orgunit.NewAddMembersHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/remove-members", middleware.RequirePermission(userService, "org:update"), orgunit.NewRemoveMembersHandler(orgUnitService).Handle)
+	orgUnitGroup.Post("/list-members", middleware.RequirePermission(userService, "org:list"), orgunit.NewListMembersHandler(orgUnitService).Handle)
+
+	// 职责分离规则
+	sodGroup := authRequired.Group("/sod-rules")
+	sodGroup.Post("/list", middleware.RequirePermission(userService, "role:list"), sod.NewListHandler(sodService).Handle)
+	sodGroup.Post("/create", middleware.RequirePermission(userService, "role:create"), sod.NewCreateHandler(sodService).Handle)
+	sodGroup.Post("/detail", middleware.RequirePermission(userService, "role:list"), sod.NewDetailHandler(sodService).Handle)
+	sodGroup.Post("/update", middleware.RequirePermission(userService, "role:update"), sod.NewUpdateHandler(sodService).Handle)
+	sodGroup.Post("/delete", middleware.RequirePermission(userService, "role:delete"), sod.NewDeleteHandler(sodService).Handle)
+	sodGroup.Post("/violations", middleware.RequirePermission(userService, "role:list"), sod.NewViolationsHandler(sodService).Handle)
+
+	// 资源实例关系授权
+	relationGroup := authRequired.Group("/relations")
+	relationGroup.Post("/write", middleware.RequirePermission(userService, "relation:write"), relation.NewWriteHandler(relationService).Handle)
+	relationGroup.Post("/delete", middleware.RequirePermission(userService, "relation:write"), relation.NewDeleteHandler(relationService).Handle)
+	relationGroup.Post("/read", middleware.RequirePermission(userService, "relation:read"), relation.NewReadHandler(relationService).Handle)
+	relationGroup.Post("/check", middleware.RequirePermission(userService, "relation:check"), relation.NewCheckHandler(relationService).Handle)
+	relationGroup.Post("/expand", middleware.RequirePermission(userService, "relation:read"), relation.NewExpandHandler(relationService).Handle)
+	relationGroup.Post("/list-objects", middleware.RequirePermission(userService, "relation:check"), relation.NewListObjectsHandler(relationService).Handle)
+
+	// 策略导入导出
+	policyGroup := authRequired.Group("/policies")
+	policyGroup.Post("/export", middleware.RequirePermission(userService, "policy:export"), policy.NewExportHandler(policyService).Handle)
+	policyGroup.Post("/import", middleware.RequirePermission(userService, "policy:import"), policy.NewImportHandler(policyService).Handle)
+}
+
+// fullPrefix 返回 prefix 在宿主应用中的完整路径，用于枚举挂载在分组下的路由
+func fullPrefix(router fiber.Router, prefix string) string {
+	if group, ok := router.(*fiber.Group); ok {
+		return group.Prefix + prefix
+	}
+	return prefix
 }
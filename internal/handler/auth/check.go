@@ -1,12 +1,12 @@
 package auth
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/middleware"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,12 +25,12 @@ func NewCheckHandler(userService service.UserService) *CheckHandler {
 
 // Handle 处理权限检查请求
 // @Summary 检查用户权限
-// @Description 检查当前用户是否具备指定权限
+// @Description 检查当前用户在当前租户内是否具备指定权限，会话只激活部分角色时只计入激活的角色
 // @Tags 认证
 // @Accept json
 // @Produce json
 // @Param data body schema.CheckPermissionRequest true "权限检查请求参数"
-// @Success 200 {object} map[string]bool "检查结果"
+// @Success 200 {object} schema.CheckPermissionResponse "检查结果"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "未授权"
 // @Failure 500 {object} response.Response "服务器内部错误"
@@ -49,16 +49,18 @@ func (h *CheckHandler) Handle(c *fiber.Ctx) error {
 	}
 
 	// 检查用户权限
-	hasPermission, err := h.userService.CheckPermission(userID, req.Permission)
+	result, err := h.userService.CheckPermission(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c), req.Permission)
 	if err != nil {
 		slog.Error("检查权限失败", "userID", userID, "permission", req.Permission, "error", err)
 		return response.ServerError(c, "检查权限失败")
 	}
 
 	// 返回检查结果
-	if hasPermission {
-		return response.Success(c, fiber.Map{"has_permission": true}, "用户具有该权限")
+	if result.HasPermission {
+		return response.Success(c, result, "用户具有该权限")
+	} else if result.DeniedBy != nil {
+		return response.Success(c, result, "用户被角色显式拒绝该权限")
 	} else {
-		return response.Success(c, fiber.Map{"has_permission": false}, "用户不具有该权限")
+		return response.Success(c, result, "用户不具有该权限")
 	}
 }
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckBatchHandler 批量权限检查处理器
+type CheckBatchHandler struct {
+	userService service.UserService
+}
+
+// NewCheckBatchHandler 创建批量权限检查处理器
+func NewCheckBatchHandler(userService service.UserService) *CheckBatchHandler {
+	return &CheckBatchHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理批量权限检查请求
+// @Summary 批量检查用户权限
+// @Description 一次检查当前用户在当前租户内的多个权限，返回每个权限编码的结果，并按 any/all 模式汇总；会话只激活部分角色时只计入激活的角色
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body schema.CheckPermissionsRequest true "批量权限检查请求参数"
+// @Success 200 {object} schema.CheckPermissionsResponse "检查结果"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/check-batch [post]
+func (h *CheckBatchHandler) Handle(c *fiber.Ctx) error {
+	// 从上下文获取用户ID
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "无效的授权令牌")
+	}
+
+	// 解析请求参数
+	req := new(schema.CheckPermissionsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 批量检查用户权限
+	results, err := h.userService.CheckPermissions(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c), req.Permissions)
+	if err != nil {
+		slog.Error("批量检查权限失败", "userID", userID, "count", len(req.Permissions), "error", err)
+		return response.ServerError(c, "检查权限失败")
+	}
+
+	return response.Success(c, &schema.CheckPermissionsResponse{
+		Results: results,
+		Passed:  summarize(results, req.Mode),
+	}, "检查完成")
+}
+
+// summarize 按判定模式汇总批量检查结果，默认要求全部具备
+func summarize(results map[string]bool, mode string) bool {
+	if mode == schema.CheckModeAny {
+		for _, allowed := range results {
+			if allowed {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, allowed := range results {
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EvaluateHandler 携带属性的权限检查处理器
+type EvaluateHandler struct {
+	userService service.UserService
+}
+
+// NewEvaluateHandler 创建携带属性的权限检查处理器
+func NewEvaluateHandler(userService service.UserService) *EvaluateHandler {
+	return &EvaluateHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理携带属性的权限检查请求
+// @Summary 携带属性检查用户权限
+// @Description 使用资源与请求属性检查当前用户在当前租户内是否具备指定权限，带条件的权限授予仅在条件成立时生效；subject 属性由服务端填写；会话只激活部分角色时只计入激活的角色
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body schema.EvaluatePermissionRequest true "权限编码与属性"
+// @Success 200 {object} schema.EvaluatePermissionResponse "检查结果"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/evaluate [post]
+func (h *EvaluateHandler) Handle(c *fiber.Ctx) error {
+	// 从上下文获取用户ID
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "无效的授权令牌")
+	}
+
+	// 解析请求参数
+	req := new(schema.EvaluatePermissionRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.userService.EvaluatePermission(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c), req)
+	if err != nil {
+		if err == errors.ErrUserNotFound {
+			return response.Unauthorized(c, "用户不存在")
+		}
+		slog.Error("携带属性检查权限失败", "userID", userID, "permission", req.Permission, "error", err)
+		return response.ServerError(c, "检查权限失败")
+	}
+
+	if result.HasPermission {
+		return response.Success(c, result, "用户具有该权限")
+	}
+	return response.Success(c, result, "用户不具有该权限")
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExplainHandler 权限判定解释处理器
+type ExplainHandler struct {
+	userService service.UserService
+}
+
+// NewExplainHandler 创建权限判定解释处理器
+func NewExplainHandler(userService service.UserService) *ExplainHandler {
+	return &ExplainHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理权限判定解释请求
+// @Summary 解释当前用户的权限判定
+// @Description 返回当前用户在当前租户内对指定权限的判定结果，以及每条角色分配、继承路径和命中的权限授予；会话未激活的分配状态为 not_activated
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param data body schema.CheckPermissionRequest true "权限检查请求参数"
+// @Success 200 {object} schema.ExplainPermissionResponse "判定结果及推导过程"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/explain [post]
+func (h *ExplainHandler) Handle(c *fiber.Ctx) error {
+	// 从上下文获取用户ID
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "无效的授权令牌")
+	}
+
+	// 解析请求参数
+	req := new(schema.CheckPermissionRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.userService.ExplainPermission(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c), req.Permission)
+	if err != nil {
+		slog.Error("解释权限判定失败", "userID", userID, "permission", req.Permission, "error", err)
+		return response.ServerError(c, "解释权限判定失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// 转发认证使用的请求头
+const (
+	ForwardedMethodHeader = "X-Forwarded-Method" // 原始请求方法
+	ForwardedURIHeader    = "X-Forwarded-Uri"    // 原始请求URI
+	AuthUserIDHeader      = "X-Auth-User-Id"     // 放行时返回的用户ID
+	AuthUsernameHeader    = "X-Auth-Username"    // 放行时返回的用户名
+	AuthTenantIDHeader    = "X-Auth-Tenant-Id"   // 放行时返回的当前租户ID
+	AuthRolesHeader       = "X-Auth-Roles"       // 放行时返回的角色编码，以逗号分隔
+)
+
+// ForwardHandler 反向代理转发认证处理器
+type ForwardHandler struct {
+	forwardAuthService service.ForwardAuthService
+}
+
+// NewForwardHandler 创建转发认证处理器
+func NewForwardHandler(forwardAuthService service.ForwardAuthService) *ForwardHandler {
+	return &ForwardHandler{
+		forwardAuthService: forwardAuthService,
+	}
+}
+
+// Handle 处理转发认证请求
+// 供 nginx auth_request、Traefik ForwardAuth 与 Envoy ext_authz（HTTP模式）调用，
+// 结果以HTTP状态码表示：200放行，401未认证，403无权限
+// @Summary 反向代理转发认证
+// @Description 读取 Authorization、X-Forwarded-Method 与 X-Forwarded-Uri 请求头，按配置的规则表判定原始请求是否放行，放行时通过响应头返回用户ID、用户名与角色
+// @Tags 认证
+// @Produce json
+// @Param Authorization header string false "Bearer 访问令牌"
+// @Param X-Forwarded-Method header string false "原始请求方法，缺省为本请求的方法"
+// @Param X-Forwarded-Uri header string false "原始请求URI，缺省为本请求路径中 /auth/forward 之后的部分"
+// @Success 200 {object} schema.ForwardAuthResult "放行"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/forward [get]
+func (h *ForwardHandler) Handle(c *fiber.Ctx) error {
+	req := &schema.ForwardAuthRequest{
+		Method: c.Get(ForwardedMethodHeader),
+		URI:    c.Get(ForwardedURIHeader),
+	}
+	// Envoy 以路径前缀方式转发时原始方法与路径即本请求的方法与剩余路径
+	if req.Method == "" {
+		req.Method = c.Method()
+	}
+	if req.URI == "" {
+		req.URI = "/" + c.Params("*")
+	}
+
+	if token := c.Get("Authorization"); token != "" {
+		if !strings.HasPrefix(token, "Bearer ") {
+			return unauthorized(c, "无效的认证令牌格式")
+		}
+		req.Token = strings.TrimPrefix(token, "Bearer ")
+	}
+
+	if header := c.Get(middleware.TenantHeader); header != "" {
+		tenantID, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			return forbidden(c, "无效的租户ID")
+		}
+		req.TenantID = tenantID
+	}
+
+	result, err := h.forwardAuthService.Authorize(req)
+	if err != nil {
+		switch err {
+		case errors.ErrInvalidToken, errors.ErrInvalidTokenType, errors.ErrUserNotFound:
+			return unauthorized(c, "无效的认证令牌")
+		case errors.ErrExpiredToken:
+			return unauthorized(c, "认证令牌已过期")
+		case errors.ErrTokenTenantMismatch:
+			return forbidden(c, "租户与认证令牌不一致")
+		case errors.ErrInvalidForwardedURI:
+			return forbidden(c, "无效的原始请求路径")
+		default:
+			slog.Error("转发认证失败", "method", req.Method, "uri", req.URI, "error", err)
+			c.Status(fiber.StatusInternalServerError)
+			return response.ServerError(c, "转发认证失败")
+		}
+	}
+
+	if !result.Allowed {
+		slog.Warn("转发认证拒绝访问", "userID", result.UserID, "tenantID", result.TenantID, "route", req.Method+" "+req.URI, "required", result.Required)
+		return forbidden(c, "")
+	}
+
+	if !result.Public {
+		c.Set(AuthUserIDHeader, strconv.FormatUint(result.UserID, 10))
+		c.Set(AuthUsernameHeader, result.Username)
+		c.Set(AuthTenantIDHeader, strconv.FormatUint(result.TenantID, 10))
+		c.Set(AuthRolesHeader, strings.Join(result.Roles, ","))
+	}
+
+	return response.Success(c, result, "允许访问")
+}
+
+// unauthorized 以401状态码响应，代理据此拒绝原始请求
+func unauthorized(c *fiber.Ctx, msg string) error {
+	c.Status(fiber.StatusUnauthorized)
+	return response.Unauthorized(c, msg)
+}
+
+// forbidden 以403状态码响应，代理据此拒绝原始请求
+func forbidden(c *fiber.Ctx, msg string) error {
+	c.Status(fiber.StatusForbidden)
+	return response.Forbidden(c, msg)
+}
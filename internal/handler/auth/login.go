@@ -1,11 +1,12 @@
 package auth
 
 import (
-	"log/slog"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -24,7 +25,7 @@ func NewLoginHandler(userService service.UserService) *LoginHandler {
 
 // Handle 处理登录请求
 // @Summary 用户登录
-// @Description 用户通过用户名和密码登录，获取访问令牌
+// @Description 用户通过用户名和密码登录，获取访问令牌；可指定 tenant_id 登录某个租户，并通过 active_role_ids 只激活部分已分配的角色
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -32,6 +33,8 @@ func NewLoginHandler(userService service.UserService) *LoginHandler {
 // @Success 200 {object} schema.LoginResponse "登录成功，返回令牌信息"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "用户名或密码错误"
+// @Failure 403 {object} response.Response "无权登录该租户"
+// @Failure 403 {object} response.Response "激活的角色违反职责分离约束（code 1006）"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/auth/login [post]
 func (h *LoginHandler) Handle(c *fiber.Ctx) error {
@@ -44,8 +47,17 @@ func (h *LoginHandler) Handle(c *fiber.Ctx) error {
 	// 调用服务层进行登录
 	res, err := h.userService.Login(req)
 	if err != nil {
-		slog.Error("用户登录失败", "username", req.Username, "error", err)
-		return response.Fail(c, response.CodeUnauthorized, "用户名或密码错误")
+		slog.Error("用户登录失败", "username", req.Username, "tenantID", req.TenantID, "error", err)
+		switch err {
+		case errors.ErrTenantNotFound, errors.ErrTenantForbidden:
+			return response.Fail(c, response.CodeForbidden, "无权登录该租户")
+		case errors.ErrRoleNotActivatable:
+			return response.Fail(c, response.CodeParamError, err.Error())
+		case errors.ErrDynamicSoDViolation:
+			return response.SoDViolation(c, err.Error())
+		default:
+			return response.Fail(c, response.CodeUnauthorized, "用户名或密码错误")
+		}
 	}
 
 	// 返回登录成功响应
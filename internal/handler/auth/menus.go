@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MenusHandler 当前用户菜单处理器
+type MenusHandler struct {
+	userService service.UserService
+}
+
+// NewMenusHandler 创建当前用户菜单处理器
+func NewMenusHandler(userService service.UserService) *MenusHandler {
+	return &MenusHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理获取当前用户菜单请求
+// @Summary 获取当前用户菜单
+// @Description 返回当前用户在当前租户内被允许的模块、菜单与按钮树，用于构建动态导航，会话只激活部分角色时只计入激活的角色
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Success 200 {object} []schema.PermissionTreeNode "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/menus [post]
+func (h *MenusHandler) Handle(c *fiber.Ctx) error {
+	// 从上下文获取用户ID
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		return response.Unauthorized(c, "无效的授权令牌")
+	}
+
+	menus, err := h.userService.GetMenus(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c))
+	if err != nil {
+		slog.Error("获取用户菜单失败", "userID", userID, "error", err)
+		return response.ServerError(c, "获取用户菜单失败")
+	}
+
+	return response.Success(c, menus, "获取成功")
+}
@@ -1,10 +1,13 @@
 package auth
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/middleware"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,14 +26,18 @@ func NewProfileHandler(userService service.UserService) *ProfileHandler {
 
 // Handle 处理获取用户个人信息请求
 // @Summary 获取当前用户信息
-// @Description 获取当前登录用户的详细信息
+// @Description 获取当前登录用户的详细信息、去重后的有效权限编码、菜单树与权限版本摘要，权限只计入会话激活的角色；
+// @Description 请求体的 permission_version 与当前版本一致时只返回 unchanged，If-None-Match 头与当前版本一致时返回 304
 // @Tags 认证
 // @Accept json
 // @Produce json
+// @Param data body schema.ProfileRequest false "客户端已持有的权限版本"
+// @Param If-None-Match header string false "客户端已持有的权限版本"
 // @Success 200 {object} schema.UserResponse "获取成功"
+// @Success 304 "权限版本未变化"
 // @Failure 401 {object} response.Response "未授权"
 // @Failure 500 {object} response.Response "服务器内部错误"
-// @Router /api/v1/auth/profile [get]
+// @Router /api/v1/auth/profile [post]
 func (h *ProfileHandler) Handle(c *fiber.Ctx) error {
 	// 从上下文中获取用户ID
 	userID := middleware.GetUserID(c)
@@ -38,13 +45,41 @@ func (h *ProfileHandler) Handle(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "未授权的访问")
 	}
 
+	// 请求体可为空，兼容不携带权限版本的客户端
+	req := new(schema.ProfileRequest)
+	if len(c.Body()) > 0 {
+		if err := validator.ValidateRequest(c, req); err != nil {
+			return err
+		}
+	}
+	etag := strings.Trim(strings.TrimPrefix(c.Get(fiber.HeaderIfNoneMatch), "W/"), `"`)
+	tenantID := middleware.GetTenantID(c)
+
+	// 客户端携带了权限版本时先只计算版本，未变化则无需组装个人信息
+	if req.PermissionVersion != "" || etag != "" {
+		version, err := h.userService.GetPermissionVersion(userID, tenantID, middleware.GetActiveRoles(c))
+		if err != nil {
+			slog.Error("获取权限版本失败", "userID", userID, "error", err)
+			return response.ServerError(c, "获取用户信息失败")
+		}
+
+		if etag == version {
+			c.Set(fiber.HeaderETag, `"`+version+`"`)
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		if req.PermissionVersion == version {
+			return response.Success(c, schema.ProfileUnchangedResponse{PermissionVersion: version, Unchanged: true}, "权限未变化")
+		}
+	}
+
 	// 调用服务层获取用户信息
-	user, err := h.userService.GetProfile(userID)
+	user, err := h.userService.GetProfile(userID, tenantID, middleware.GetActiveRoles(c))
 	if err != nil {
 		slog.Error("获取用户信息失败", "userID", userID, "error", err)
 		return response.ServerError(c, "获取用户信息失败")
 	}
 
 	// 返回用户信息
+	c.Set(fiber.HeaderETag, `"`+user.PermissionVersion+`"`)
 	return response.Success(c, user, "获取成功")
 }
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"strings"
 	
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
@@ -34,6 +35,7 @@ func NewRefreshHandler(userService service.UserService) *RefreshHandler {
 // @Success 200 {object} schema.LoginResponse "刷新成功，返回新令牌"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "无效的刷新令牌"
+// @Failure 403 {object} response.Response "激活的角色违反职责分离约束（code 1006），需要重新登录"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/auth/refresh [post]
 func (h *RefreshHandler) Handle(c *fiber.Ctx) error {
@@ -71,7 +73,15 @@ func (h *RefreshHandler) Handle(c *fiber.Ctx) error {
 	res, err := h.userService.RefreshToken(token)
 	if err != nil {
 		slog.Error("刷新令牌失败", "error", err)
-		return response.Fail(c, response.CodeUnauthorized, "无效的刷新令牌")
+		switch err {
+		case errors.ErrRoleNotActivatable:
+			// 激活的角色已被撤销，需要重新登录选择角色
+			return response.Fail(c, response.CodeUnauthorized, err.Error())
+		case errors.ErrDynamicSoDViolation:
+			return response.SoDViolation(c, err.Error())
+		default:
+			return response.Fail(c, response.CodeUnauthorized, "无效的刷新令牌")
+		}
 	}
 
 	// 返回刷新成功响应
@@ -0,0 +1,63 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AddMembersHandler 用户组加入成员处理器
+type AddMembersHandler struct {
+	groupService service.GroupService
+}
+
+// NewAddMembersHandler 创建用户组加入成员处理器
+func NewAddMembersHandler(groupService service.GroupService) *AddMembersHandler {
+	return &AddMembersHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理加入用户组成员请求
+// @Summary 加入用户组成员
+// @Description 将用户加入用户组，已是成员的用户保持不变；成员获得分配给该组及其全部上级组的角色
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.GroupMembersRequest true "用户组ID与用户ID列表"
+// @Success 200 {object} nil "加入成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户组或用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/add-members [post]
+func (h *AddMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GroupMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层加入成员
+	err := h.groupService.AddMembers(req)
+	if err != nil {
+		slog.Error("加入用户组成员失败", "groupID", req.GroupID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrGroupNotFound:
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		case errors.ErrUserNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分用户不存在")
+		default:
+			return response.ServerError(c, "加入用户组成员失败")
+		}
+	}
+
+	// 返回加入成功响应
+	return response.Success(c, nil, "成员加入成功")
+}
@@ -0,0 +1,67 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AssignRolesHandler 用户组分配角色处理器
+type AssignRolesHandler struct {
+	groupService service.GroupService
+}
+
+// NewAssignRolesHandler 创建用户组分配角色处理器
+func NewAssignRolesHandler(groupService service.GroupService) *AssignRolesHandler {
+	return &AssignRolesHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理用户组分配角色请求
+// @Summary 分配用户组角色
+// @Description 替换用户组在某个租户内的角色，tenant_id 为0时替换全局角色分配；该组及其全部下级组的成员获得这些角色
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.AssignGroupRolesRequest true "用户组ID与角色ID列表"
+// @Success 200 {object} nil "分配成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户组、角色或租户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/assign-roles [post]
+func (h *AssignRolesHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.AssignGroupRolesRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层分配角色
+	err := h.groupService.AssignRoles(req)
+	if err != nil {
+		slog.Error("用户组分配角色失败", "groupID", req.GroupID, "tenantID", req.TenantID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrGroupNotFound:
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分角色不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "部分角色不属于该租户")
+		default:
+			return response.ServerError(c, "用户组分配角色失败")
+		}
+	}
+
+	// 返回分配成功响应
+	return response.Success(c, nil, "角色分配成功")
+}
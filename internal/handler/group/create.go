@@ -0,0 +1,63 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateHandler 用户组创建处理器
+type CreateHandler struct {
+	groupService service.GroupService
+}
+
+// NewCreateHandler 创建用户组处理器
+func NewCreateHandler(groupService service.GroupService) *CreateHandler {
+	return &CreateHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理创建用户组请求
+// @Summary 创建用户组
+// @Description 创建新的用户组，可通过 parent_ids 嵌套到上级组中，组成员同时获得分配给上级组的角色
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.CreateGroupRequest true "用户组信息"
+// @Success 200 {object} response.Response "创建成功，返回用户组ID"
+// @Failure 400 {object} response.Response "参数错误或用户组已存在"
+// @Failure 404 {object} response.Response "上级组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/create [post]
+func (h *CreateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CreateGroupRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层创建用户组
+	groupID, err := h.groupService.Create(req)
+	if err != nil {
+		slog.Error("创建用户组失败", "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrGroupExists:
+			return response.Fail(c, response.CodeParamError, "用户组编码或名称已存在")
+		case errors.ErrGroupNotFound:
+			return response.Fail(c, response.CodeNotFound, "上级组不存在")
+		default:
+			return response.ServerError(c, "创建用户组失败")
+		}
+	}
+
+	// 返回创建成功响应
+	return response.Success(c, fiber.Map{"id": groupID}, "用户组创建成功")
+}
@@ -0,0 +1,58 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteHandler 用户组删除处理器
+type DeleteHandler struct {
+	groupService service.GroupService
+}
+
+// NewDeleteHandler 创建用户组删除处理器
+func NewDeleteHandler(groupService service.GroupService) *DeleteHandler {
+	return &DeleteHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理删除用户组请求
+// @Summary 删除用户组
+// @Description 删除用户组，同时移除其成员、角色分配与嵌套关系，成员不再经由该组获得角色
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.DeleteGroupRequest true "用户组ID"
+// @Success 200 {object} nil "删除成功"
+// @Failure 404 {object} response.Response "用户组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/delete [post]
+func (h *DeleteHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.DeleteGroupRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层删除用户组
+	err := h.groupService.Delete(req.ID)
+	if err != nil {
+		slog.Error("删除用户组失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrGroupNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		}
+		return response.ServerError(c, "删除用户组失败")
+	}
+
+	// 返回删除成功响应
+	return response.Success(c, nil, "用户组删除成功")
+}
@@ -0,0 +1,58 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DetailHandler 用户组详情处理器
+type DetailHandler struct {
+	groupService service.GroupService
+}
+
+// NewDetailHandler 创建用户组详情处理器
+func NewDetailHandler(groupService service.GroupService) *DetailHandler {
+	return &DetailHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理获取用户组详情请求
+// @Summary 获取用户组详情
+// @Description 根据ID获取用户组信息、上级组、直接分配的角色与直接成员数
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.GetGroupRequest true "用户组ID"
+// @Success 200 {object} schema.GroupResponse "获取成功"
+// @Failure 404 {object} response.Response "用户组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/detail [post]
+func (h *DetailHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GetGroupRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取用户组详情
+	group, err := h.groupService.GetByID(req.ID)
+	if err != nil {
+		slog.Error("获取用户组详情失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrGroupNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		}
+		return response.ServerError(c, "获取用户组详情失败")
+	}
+
+	// 返回用户组详情
+	return response.Success(c, group, "获取成功")
+}
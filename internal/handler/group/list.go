@@ -0,0 +1,62 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListHandler 用户组列表处理器
+type ListHandler struct {
+	groupService service.GroupService
+}
+
+// NewListHandler 创建用户组列表处理器
+func NewListHandler(groupService service.GroupService) *ListHandler {
+	return &ListHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理获取用户组列表请求
+// @Summary 获取用户组列表
+// @Description 分页查询用户组及其上级组，可按编码或名称搜索
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ListGroupRequest true "分页与筛选参数"
+// @Success 200 {object} schema.ListGroupResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/list [post]
+func (h *ListHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListGroupRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	// 调用服务层获取用户组列表
+	result, err := h.groupService.List(req)
+	if err != nil {
+		slog.Error("获取用户组列表失败", "error", err)
+		return response.ServerError(c, "获取用户组列表失败")
+	}
+
+	// 返回用户组列表
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,69 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListMembersHandler 用户组成员列表处理器
+type ListMembersHandler struct {
+	groupService service.GroupService
+}
+
+// NewListMembersHandler 创建用户组成员列表处理器
+func NewListMembersHandler(groupService service.GroupService) *ListMembersHandler {
+	return &ListMembersHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理获取用户组成员列表请求
+// @Summary 获取用户组成员列表
+// @Description 分页查询用户组的直接成员，不含嵌套子组的成员
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ListGroupMembersRequest true "用户组ID与分页参数"
+// @Success 200 {object} schema.ListUserResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/list-members [post]
+func (h *ListMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListGroupMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	// 调用服务层获取成员列表
+	result, err := h.groupService.ListMembers(req)
+	if err != nil {
+		slog.Error("获取用户组成员列表失败", "groupID", req.GroupID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrGroupNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		}
+		return response.ServerError(c, "获取用户组成员列表失败")
+	}
+
+	// 返回成员列表
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,59 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RemoveMembersHandler 用户组移出成员处理器
+type RemoveMembersHandler struct {
+	groupService service.GroupService
+}
+
+// NewRemoveMembersHandler 创建用户组移出成员处理器
+func NewRemoveMembersHandler(groupService service.GroupService) *RemoveMembersHandler {
+	return &RemoveMembersHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理移出用户组成员请求
+// @Summary 移出用户组成员
+// @Description 将用户移出用户组，不是成员的用户忽略
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.GroupMembersRequest true "用户组ID与用户ID列表"
+// @Success 200 {object} nil "移出成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/remove-members [post]
+func (h *RemoveMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GroupMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层移出成员
+	err := h.groupService.RemoveMembers(req)
+	if err != nil {
+		slog.Error("移出用户组成员失败", "groupID", req.GroupID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrGroupNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户组不存在")
+		}
+		return response.ServerError(c, "移出用户组成员失败")
+	}
+
+	// 返回移出成功响应
+	return response.Success(c, nil, "成员移出成功")
+}
@@ -0,0 +1,65 @@
+package group
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateHandler 用户组更新处理器
+type UpdateHandler struct {
+	groupService service.GroupService
+}
+
+// NewUpdateHandler 创建用户组更新处理器
+func NewUpdateHandler(groupService service.GroupService) *UpdateHandler {
+	return &UpdateHandler{
+		groupService: groupService,
+	}
+}
+
+// Handle 处理更新用户组请求
+// @Summary 更新用户组
+// @Description 更新用户组编码、名称与描述；parent_ids 不为空时替换上级组，不能形成循环嵌套
+// @Tags 用户组管理
+// @Accept json
+// @Produce json
+// @Param data body schema.UpdateGroupRequest true "用户组信息"
+// @Success 200 {object} nil "更新成功"
+// @Failure 400 {object} response.Response "参数错误、用户组已存在或形成循环嵌套"
+// @Failure 404 {object} response.Response "用户组或上级组不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/groups/update [post]
+func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.UpdateGroupRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层更新用户组
+	err := h.groupService.Update(req)
+	if err != nil {
+		slog.Error("更新用户组失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrGroupNotFound:
+			return response.Fail(c, response.CodeNotFound, "用户组或上级组不存在")
+		case errors.ErrGroupExists:
+			return response.Fail(c, response.CodeParamError, "用户组编码或名称已存在")
+		case errors.ErrGroupCycle:
+			return response.Fail(c, response.CodeParamError, "上级组不能是该组自身或其下级组")
+		default:
+			return response.ServerError(c, "更新用户组失败")
+		}
+	}
+
+	// 返回更新成功响应
+	return response.Success(c, nil, "用户组更新成功")
+}
@@ -0,0 +1,63 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AddMembersHandler 组织单元加入成员处理器
+type AddMembersHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewAddMembersHandler 创建组织单元加入成员处理器
+func NewAddMembersHandler(orgUnitService service.OrgUnitService) *AddMembersHandler {
+	return &AddMembersHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理加入组织单元成员请求
+// @Summary 加入组织单元成员
+// @Description 将用户加入组织单元，已是成员的用户保持不变；一个用户可以属于多个组织单元
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.OrgUnitMembersRequest true "组织单元ID与用户ID列表"
+// @Success 200 {object} nil "加入成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "组织单元或用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/add-members [post]
+func (h *AddMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.OrgUnitMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层加入成员
+	err := h.orgUnitService.AddMembers(req)
+	if err != nil {
+		slog.Error("加入组织单元成员失败", "orgUnitID", req.OrgUnitID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrOrgUnitNotFound:
+			return response.Fail(c, response.CodeNotFound, "组织单元不存在")
+		case errors.ErrUserNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分用户不存在")
+		default:
+			return response.ServerError(c, "加入组织单元成员失败")
+		}
+	}
+
+	// 返回加入成功响应
+	return response.Success(c, nil, "成员加入成功")
+}
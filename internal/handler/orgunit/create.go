@@ -0,0 +1,63 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateHandler 组织单元创建处理器
+type CreateHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewCreateHandler 创建组织单元处理器
+func NewCreateHandler(orgUnitService service.OrgUnitService) *CreateHandler {
+	return &CreateHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理创建组织单元请求
+// @Summary 创建组织单元
+// @Description 创建新的组织单元，parent_id 为0时作为根节点
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.CreateOrgUnitRequest true "组织单元信息"
+// @Success 200 {object} response.Response "创建成功，返回组织单元ID"
+// @Failure 400 {object} response.Response "参数错误或组织单元已存在"
+// @Failure 404 {object} response.Response "上级单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/create [post]
+func (h *CreateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CreateOrgUnitRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层创建组织单元
+	unitID, err := h.orgUnitService.Create(req)
+	if err != nil {
+		slog.Error("创建组织单元失败", "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrOrgUnitExists:
+			return response.Fail(c, response.CodeParamError, "组织单元编码已存在")
+		case errors.ErrOrgUnitNotFound:
+			return response.Fail(c, response.CodeNotFound, "上级单元不存在")
+		default:
+			return response.ServerError(c, "创建组织单元失败")
+		}
+	}
+
+	// 返回创建成功响应
+	return response.Success(c, fiber.Map{"id": unitID}, "组织单元创建成功")
+}
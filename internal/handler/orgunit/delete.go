@@ -0,0 +1,63 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteHandler 组织单元删除处理器
+type DeleteHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewDeleteHandler 创建组织单元删除处理器
+func NewDeleteHandler(orgUnitService service.OrgUnitService) *DeleteHandler {
+	return &DeleteHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理删除组织单元请求
+// @Summary 删除组织单元
+// @Description 删除没有下级单元的组织单元，同时移除其成员以及角色自定义数据范围中对它的引用
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.DeleteOrgUnitRequest true "组织单元ID"
+// @Success 200 {object} nil "删除成功"
+// @Failure 400 {object} response.Response "组织单元下仍有下级单元"
+// @Failure 404 {object} response.Response "组织单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/delete [post]
+func (h *DeleteHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.DeleteOrgUnitRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层删除组织单元
+	err := h.orgUnitService.Delete(req.ID)
+	if err != nil {
+		slog.Error("删除组织单元失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrOrgUnitNotFound:
+			return response.Fail(c, response.CodeNotFound, "组织单元不存在")
+		case errors.ErrOrgUnitHasChildren:
+			return response.Fail(c, response.CodeParamError, "组织单元下仍有下级单元，无法删除")
+		default:
+			return response.ServerError(c, "删除组织单元失败")
+		}
+	}
+
+	// 返回删除成功响应
+	return response.Success(c, nil, "组织单元删除成功")
+}
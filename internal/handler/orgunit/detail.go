@@ -0,0 +1,59 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DetailHandler 组织单元详情处理器
+type DetailHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewDetailHandler 创建组织单元详情处理器
+func NewDetailHandler(orgUnitService service.OrgUnitService) *DetailHandler {
+	return &DetailHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理获取组织单元详情请求
+// @Summary 获取组织单元详情
+// @Description 获取组织单元信息及其直接成员数
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.GetOrgUnitRequest true "组织单元ID"
+// @Success 200 {object} schema.OrgUnitResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "组织单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/detail [post]
+func (h *DetailHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GetOrgUnitRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取组织单元详情
+	unit, err := h.orgUnitService.GetByID(req.ID)
+	if err != nil {
+		slog.Error("获取组织单元详情失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrOrgUnitNotFound {
+			return response.Fail(c, response.CodeNotFound, "组织单元不存在")
+		}
+		return response.ServerError(c, "获取组织单元详情失败")
+	}
+
+	// 返回组织单元详情
+	return response.Success(c, unit, "获取成功")
+}
@@ -0,0 +1,69 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListMembersHandler 组织单元成员列表处理器
+type ListMembersHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewListMembersHandler 创建组织单元成员列表处理器
+func NewListMembersHandler(orgUnitService service.OrgUnitService) *ListMembersHandler {
+	return &ListMembersHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理获取组织单元成员列表请求
+// @Summary 获取组织单元成员列表
+// @Description 分页查询组织单元的直接成员，不含下级单元的成员
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ListOrgUnitMembersRequest true "组织单元ID与分页参数"
+// @Success 200 {object} schema.ListUserResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "组织单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/list-members [post]
+func (h *ListMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListOrgUnitMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	// 调用服务层获取成员列表
+	result, err := h.orgUnitService.ListMembers(req)
+	if err != nil {
+		slog.Error("获取组织单元成员列表失败", "orgUnitID", req.OrgUnitID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrOrgUnitNotFound {
+			return response.Fail(c, response.CodeNotFound, "组织单元不存在")
+		}
+		return response.ServerError(c, "获取组织单元成员列表失败")
+	}
+
+	// 返回成员列表
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,59 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RemoveMembersHandler 组织单元移出成员处理器
+type RemoveMembersHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewRemoveMembersHandler 创建组织单元移出成员处理器
+func NewRemoveMembersHandler(orgUnitService service.OrgUnitService) *RemoveMembersHandler {
+	return &RemoveMembersHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理移出组织单元成员请求
+// @Summary 移出组织单元成员
+// @Description 将用户移出组织单元，不是成员的用户忽略
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.OrgUnitMembersRequest true "组织单元ID与用户ID列表"
+// @Success 200 {object} nil "移出成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "组织单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/remove-members [post]
+func (h *RemoveMembersHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.OrgUnitMembersRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层移出成员
+	err := h.orgUnitService.RemoveMembers(req)
+	if err != nil {
+		slog.Error("移出组织单元成员失败", "orgUnitID", req.OrgUnitID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrOrgUnitNotFound {
+			return response.Fail(c, response.CodeNotFound, "组织单元不存在")
+		}
+		return response.ServerError(c, "移出组织单元成员失败")
+	}
+
+	// 返回移出成功响应
+	return response.Success(c, nil, "成员移出成功")
+}
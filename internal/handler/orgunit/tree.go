@@ -0,0 +1,41 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TreeHandler 组织单元树处理器
+type TreeHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewTreeHandler 创建组织单元树处理器
+func NewTreeHandler(orgUnitService service.OrgUnitService) *TreeHandler {
+	return &TreeHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理获取组织单元树请求
+// @Summary 获取组织单元树
+// @Description 按上级关系返回全部组织单元组成的树，同级按排序值排列
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} []schema.OrgUnitTreeNode "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/tree [post]
+func (h *TreeHandler) Handle(c *fiber.Ctx) error {
+	tree, err := h.orgUnitService.Tree()
+	if err != nil {
+		slog.Error("获取组织单元树失败", "error", err)
+		return response.ServerError(c, "获取组织单元树失败")
+	}
+
+	return response.Success(c, tree, "获取成功")
+}
@@ -0,0 +1,65 @@
+package orgunit
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateHandler 组织单元更新处理器
+type UpdateHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewUpdateHandler 创建组织单元更新处理器
+func NewUpdateHandler(orgUnitService service.OrgUnitService) *UpdateHandler {
+	return &UpdateHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理更新组织单元请求
+// @Summary 更新组织单元
+// @Description 更新组织单元信息，可移动到其他上级单元下，但不能移动到自身或其下级单元下
+// @Tags 组织单元管理
+// @Accept json
+// @Produce json
+// @Param data body schema.UpdateOrgUnitRequest true "组织单元信息"
+// @Success 200 {object} nil "更新成功"
+// @Failure 400 {object} response.Response "参数错误、组织单元已存在或上级单元无效"
+// @Failure 404 {object} response.Response "组织单元或上级单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/org-units/update [post]
+func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.UpdateOrgUnitRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层更新组织单元
+	err := h.orgUnitService.Update(req)
+	if err != nil {
+		slog.Error("更新组织单元失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrOrgUnitNotFound:
+			return response.Fail(c, response.CodeNotFound, "组织单元或上级单元不存在")
+		case errors.ErrOrgUnitExists:
+			return response.Fail(c, response.CodeParamError, "组织单元编码已存在")
+		case errors.ErrOrgUnitCycle:
+			return response.Fail(c, response.CodeParamError, "上级单元不能是该单元自身或其下级单元")
+		default:
+			return response.ServerError(c, "更新组织单元失败")
+		}
+	}
+
+	// 返回更新成功响应
+	return response.Success(c, nil, "组织单元更新成功")
+}
@@ -1,12 +1,12 @@
 package permission
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,11 +35,17 @@ func (h *CreateHandler) Handle(c *fiber.Ctx) error {
 	permissionID, err := h.permissionService.Create(req)
 	if err != nil {
 		slog.Error("创建权限失败", "error", err)
-		
+
 		// 处理特定错误类型
 		if err == errors.ErrPermissionExists {
 			return response.Fail(c, response.CodeParamError, "权限标识已存在")
 		}
+		if err == errors.ErrInvalidPermissionCode {
+			return response.Fail(c, response.CodeParamError, "权限编码格式错误，应为 resource:action 形式，可使用 * 通配符")
+		}
+		if err == errors.ErrInvalidParent {
+			return response.Fail(c, response.CodeParamError, "父级权限不存在")
+		}
 		return response.ServerError(c, "创建权限失败")
 	}
 
@@ -42,6 +42,8 @@ func (h *DeleteHandler) Handle(c *fiber.Ctx) error {
 			return response.Fail(c, response.CodeNotFound, "权限不存在")
 		case errors.ErrPermissionInUse:
 			return response.Fail(c, response.CodeForbidden, "权限正在使用中，无法删除")
+		case errors.ErrPermissionHasChildren:
+			return response.Fail(c, response.CodeForbidden, "权限下仍有子节点，无法删除")
 		default:
 			return response.ServerError(c, "删除权限失败")
 		}
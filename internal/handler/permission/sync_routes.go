@@ -0,0 +1,46 @@
+package permission
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SyncRoutesHandler 接口路由同步处理器
+type SyncRoutesHandler struct {
+	permissionService service.PermissionService
+	prefix            string
+}
+
+// NewSyncRoutesHandler 创建接口路由同步处理器，prefix 为需要登记的路由前缀
+func NewSyncRoutesHandler(permissionService service.PermissionService, prefix string) *SyncRoutesHandler {
+	return &SyncRoutesHandler{
+		permissionService: permissionService,
+		prefix:            prefix,
+	}
+}
+
+// Handle 处理接口路由同步请求
+// @Summary 同步接口权限
+// @Description 枚举服务当前注册的接口路由，为缺失的路由创建接口权限，并报告路由已不存在的接口权限
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} schema.RouteSyncReport "同步成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/permissions/sync-routes [post]
+func (h *SyncRoutesHandler) Handle(c *fiber.Ctx) error {
+	report, err := h.permissionService.SyncRoutes(apiroute.Collect(c.App(), h.prefix))
+	if err != nil {
+		slog.Error("同步接口权限失败", "error", err)
+		return response.ServerError(c, "同步接口权限失败")
+	}
+
+	return response.Success(c, report, "同步成功")
+}
@@ -0,0 +1,54 @@
+package permission
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TreeHandler 权限树处理器
+type TreeHandler struct {
+	permissionService service.PermissionService
+}
+
+// NewTreeHandler 创建权限树处理器
+func NewTreeHandler(permissionService service.PermissionService) *TreeHandler {
+	return &TreeHandler{
+		permissionService: permissionService,
+	}
+}
+
+// Handle 处理获取权限树请求
+// @Summary 获取权限树
+// @Description 按父级关系返回模块、菜单、按钮与接口组成的权限树，可按节点类型过滤
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Param data body schema.PermissionTreeRequest false "过滤条件"
+// @Success 200 {object} []schema.PermissionTreeNode "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/permissions/tree [post]
+func (h *TreeHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数，过滤条件可省略
+	req := new(schema.PermissionTreeRequest)
+	if len(c.Body()) > 0 {
+		if err := validator.ValidateRequest(c, req); err != nil {
+			return err
+		}
+	}
+
+	tree, err := h.permissionService.Tree(req)
+	if err != nil {
+		slog.Error("获取权限树失败", "error", err)
+		return response.ServerError(c, "获取权限树失败")
+	}
+
+	return response.Success(c, tree, "获取成功")
+}
@@ -1,12 +1,12 @@
 package permission
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,13 +35,17 @@ func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
 	err := h.permissionService.Update(req)
 	if err != nil {
 		slog.Error("更新权限失败", "id", req.ID, "error", err)
-		
+
 		// 处理特定错误类型
 		switch err {
 		case errors.ErrPermissionNotFound:
 			return response.Fail(c, response.CodeNotFound, "权限不存在")
 		case errors.ErrPermissionExists:
 			return response.Fail(c, response.CodeParamError, "权限标识已存在")
+		case errors.ErrInvalidPermissionCode:
+			return response.Fail(c, response.CodeParamError, "权限编码格式错误，应为 resource:action 形式，可使用 * 通配符")
+		case errors.ErrInvalidParent:
+			return response.Fail(c, response.CodeParamError, "父级权限不存在，或不能移动到自身及其子节点下")
 		default:
 			return response.ServerError(c, "更新权限失败")
 		}
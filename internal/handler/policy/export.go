@@ -0,0 +1,47 @@
+package policy
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportHandler 策略导出处理器
+type ExportHandler struct {
+	policyService service.PolicyService
+}
+
+// NewExportHandler 创建策略导出处理器
+func NewExportHandler(policyService service.PolicyService) *ExportHandler {
+	return &ExportHandler{
+		policyService: policyService,
+	}
+}
+
+// Handle 处理策略导出请求
+// @Summary 导出策略
+// @Description 以 Casbin CSV 格式导出全部角色权限授予（p 规则）与用户角色分配（g 规则）
+// @Tags 策略管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} schema.PolicyExportResponse "导出成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/policies/export [post]
+func (h *ExportHandler) Handle(c *fiber.Ctx) error {
+	policy, err := h.policyService.Export()
+	if err != nil {
+		slog.Error("导出策略失败", "error", err)
+		return response.ServerError(c, "导出策略失败")
+	}
+
+	return response.Success(c, &schema.PolicyExportResponse{
+		Content:     policy.String(),
+		Grants:      len(policy.Grants),
+		Assignments: len(policy.Assignments),
+	}, "导出成功")
+}
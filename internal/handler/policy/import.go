@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportHandler 策略导入处理器
+type ImportHandler struct {
+	policyService service.PolicyService
+}
+
+// NewImportHandler 创建策略导入处理器
+func NewImportHandler(policyService service.PolicyService) *ImportHandler {
+	return &ImportHandler{
+		policyService: policyService,
+	}
+}
+
+// Handle 处理策略导入请求
+// @Summary 导入策略
+// @Description 将 Casbin CSV 格式的策略整体同步到角色、角色权限与用户角色分配，dry_run 为 true 时只返回差异报告
+// @Tags 策略管理
+// @Accept json
+// @Produce json
+// @Param data body schema.PolicyImportRequest true "策略导入请求参数"
+// @Success 200 {object} schema.PolicyImportReport "差异报告"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/policies/import [post]
+func (h *ImportHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.PolicyImportRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	report, err := h.policyService.Import(strings.NewReader(req.Content), req.DryRun)
+	if err != nil {
+		if err == errors.ErrInvalidPolicy {
+			return response.ParamError(c, strings.Join(report.Errors, "；"))
+		}
+		slog.Error("导入策略失败", "dryRun", req.DryRun, "error", err)
+		return response.ServerError(c, "导入策略失败")
+	}
+
+	if report.DryRun {
+		return response.Success(c, report, "差异预览完成，未写入数据库")
+	}
+	return response.Success(c, report, "导入成功")
+}
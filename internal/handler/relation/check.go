@@ -0,0 +1,60 @@
+package relation
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckHandler 判定对象关系处理器
+type CheckHandler struct {
+	relationService service.RelationService
+}
+
+// NewCheckHandler 创建判定对象关系处理器
+func NewCheckHandler(relationService service.RelationService) *CheckHandler {
+	return &CheckHandler{
+		relationService: relationService,
+	}
+}
+
+// Handle 处理判定对象关系请求
+// @Summary 判定对象关系
+// @Description 判定主体是否对对象持有指定关系，计入用户集成员关系、同一对象上包含的关系以及经由关联对象（如文档所在文件夹）获得的关系
+// @Tags 关系授权
+// @Accept json
+// @Produce json
+// @Param data body schema.CheckRelationRequest true "判定对象关系请求参数"
+// @Success 200 {object} schema.CheckRelationResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/relations/check [post]
+func (h *CheckHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CheckRelationRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.relationService.Check(req)
+	if err != nil {
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrInvalidRelationTarget, errors.ErrRelationNotDefined:
+			return response.ParamError(c, err.Error())
+		case errors.ErrRelationDepthExceeded:
+			slog.Warn("关系展开层数超过上限", "object", req.Object, "relation", req.Relation, "subject", req.Subject)
+			return response.ParamError(c, err.Error())
+		}
+		slog.Error("判定对象关系失败", "object", req.Object, "relation", req.Relation, "subject", req.Subject, "error", err)
+		return response.ServerError(c, "判定对象关系失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,60 @@
+package relation
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExpandHandler 展开对象关系处理器
+type ExpandHandler struct {
+	relationService service.RelationService
+}
+
+// NewExpandHandler 创建展开对象关系处理器
+func NewExpandHandler(relationService service.RelationService) *ExpandHandler {
+	return &ExpandHandler{
+		relationService: relationService,
+	}
+}
+
+// Handle 处理展开对象关系请求
+// @Summary 展开对象关系
+// @Description 按改写规则展开对象上某个关系的全部持有者，返回树形结构，已在上层展开的节点标记为环
+// @Tags 关系授权
+// @Accept json
+// @Produce json
+// @Param data body schema.ExpandRelationRequest true "展开对象关系请求参数"
+// @Success 200 {object} schema.RelationTree "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/relations/expand [post]
+func (h *ExpandHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ExpandRelationRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.relationService.Expand(req)
+	if err != nil {
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrInvalidRelationTarget, errors.ErrRelationNotDefined:
+			return response.ParamError(c, err.Error())
+		case errors.ErrRelationDepthExceeded:
+			slog.Warn("关系展开层数超过上限", "object", req.Object, "relation", req.Relation)
+			return response.ParamError(c, err.Error())
+		}
+		slog.Error("展开对象关系失败", "object", req.Object, "relation", req.Relation, "error", err)
+		return response.ServerError(c, "展开对象关系失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
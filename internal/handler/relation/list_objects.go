@@ -0,0 +1,60 @@
+package relation
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListObjectsHandler 列出主体可访问的对象处理器
+type ListObjectsHandler struct {
+	relationService service.RelationService
+}
+
+// NewListObjectsHandler 创建列出主体可访问的对象处理器
+func NewListObjectsHandler(relationService service.RelationService) *ListObjectsHandler {
+	return &ListObjectsHandler{
+		relationService: relationService,
+	}
+}
+
+// Handle 处理列出主体可访问的对象请求
+// @Summary 列出主体可访问的对象
+// @Description 列出主体对其持有指定关系的某类对象的全部ID，结果与逐个判定一致
+// @Tags 关系授权
+// @Accept json
+// @Produce json
+// @Param data body schema.ListObjectsRequest true "列出主体可访问的对象请求参数"
+// @Success 200 {object} schema.ListObjectsResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/relations/list-objects [post]
+func (h *ListObjectsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListObjectsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.relationService.ListObjects(req)
+	if err != nil {
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrInvalidRelationTarget, errors.ErrRelationNotDefined:
+			return response.ParamError(c, err.Error())
+		case errors.ErrRelationDepthExceeded:
+			slog.Warn("关系展开层数超过上限", "objectType", req.ObjectType, "relation", req.Relation, "subject", req.Subject)
+			return response.ParamError(c, err.Error())
+		}
+		slog.Error("列出主体可访问的对象失败", "objectType", req.ObjectType, "relation", req.Relation, "subject", req.Subject, "error", err)
+		return response.ServerError(c, "列出主体可访问的对象失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,65 @@
+package relation
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadHandler 关系元组查询处理器
+type ReadHandler struct {
+	relationService service.RelationService
+}
+
+// NewReadHandler 创建关系元组查询处理器
+func NewReadHandler(relationService service.RelationService) *ReadHandler {
+	return &ReadHandler{
+		relationService: relationService,
+	}
+}
+
+// Handle 处理查询关系元组请求
+// @Summary 查询关系元组
+// @Description 分页查询关系元组，可按对象类型、对象ID、关系与主体筛选
+// @Tags 关系授权
+// @Accept json
+// @Produce json
+// @Param data body schema.ReadRelationsRequest true "分页与筛选参数"
+// @Success 200 {object} schema.ReadRelationsResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/relations/read [post]
+func (h *ReadHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ReadRelationsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	result, err := h.relationService.Read(req)
+	if err != nil {
+		if err == errors.ErrInvalidRelationTarget {
+			return response.ParamError(c, "主体格式错误")
+		}
+		slog.Error("查询关系元组失败", "error", err)
+		return response.ServerError(c, "查询关系元组失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,57 @@
+package relation
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WriteHandler 写入关系元组处理器
+type WriteHandler struct {
+	relationService service.RelationService
+}
+
+// NewWriteHandler 创建写入关系元组处理器
+func NewWriteHandler(relationService service.RelationService) *WriteHandler {
+	return &WriteHandler{
+		relationService: relationService,
+	}
+}
+
+// Handle 处理写入关系元组请求
+// @Summary 写入关系元组
+// @Description 写入 对象#关系@主体 形式的关系元组，主体可以是用户或用户集（如 group:eng#member）；已存在的元组会被忽略，任一元组无效时不写入任何元组
+// @Tags 关系授权
+// @Accept json
+// @Produce json
+// @Param data body schema.WriteRelationsRequest true "关系元组列表"
+// @Success 200 {object} schema.WriteRelationsResponse "写入成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/relations/write [post]
+func (h *WriteHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.WriteRelationsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	result, err := h.relationService.Write(req)
+	if err != nil {
+		if err == errors.ErrInvalidRelationTuple {
+			return response.ParamError(c, strings.Join(result.Errors, "；"))
+		}
+		slog.Error("写入关系元组失败", "count", len(req.Tuples), "error", err)
+		return response.ServerError(c, "写入关系元组失败")
+	}
+
+	return response.Success(c, result, "写入成功")
+}
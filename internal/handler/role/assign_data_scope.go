@@ -0,0 +1,65 @@
+package role
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AssignDataScopeHandler 角色数据范围设置处理器
+type AssignDataScopeHandler struct {
+	orgUnitService service.OrgUnitService
+}
+
+// NewAssignDataScopeHandler 创建角色数据范围设置处理器
+func NewAssignDataScopeHandler(orgUnitService service.OrgUnitService) *AssignDataScopeHandler {
+	return &AssignDataScopeHandler{
+		orgUnitService: orgUnitService,
+	}
+}
+
+// Handle 处理设置角色数据范围请求
+// @Summary 设置角色数据范围
+// @Description 设置角色可访问的数据范围：all 全部数据、unit 本人所属单元、unit_and_children 所属单元及下级单元、self 仅本人、custom 指定的组织单元。用户持有多个角色时取各角色范围的并集
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param data body schema.AssignDataScopeRequest true "角色ID、数据范围与自定义组织单元"
+// @Success 200 {object} nil "设置成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "角色或组织单元不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/assign-data-scope [post]
+func (h *AssignDataScopeHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.AssignDataScopeRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层设置数据范围
+	err := h.orgUnitService.AssignRoleDataScope(req)
+	if err != nil {
+		slog.Error("设置角色数据范围失败", "roleID", req.RoleID, "dataScope", req.DataScope, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "角色不存在")
+		case errors.ErrOrgUnitNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分组织单元不存在")
+		case errors.ErrInvalidDataScope:
+			return response.Fail(c, response.CodeParamError, "数据范围只能是 all、unit、unit_and_children、self 或 custom")
+		default:
+			return response.ServerError(c, "设置角色数据范围失败")
+		}
+	}
+
+	// 返回设置成功响应
+	return response.Success(c, nil, "数据范围设置成功")
+}
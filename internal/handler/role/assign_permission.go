@@ -1,12 +1,13 @@
 package role
 
 import (
-	"log/slog"
+	"github.com/lvyunze/fiber-rbac/internal/model"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,7 +26,7 @@ func NewAssignPermissionHandler(roleService service.RoleService) *AssignPermissi
 
 // Handle 处理角色分配权限请求
 // @Summary 角色分配权限
-// @Description 为指定角色分配一个或多个权限
+// @Description 为指定角色分配一个或多个权限，可通过 grants 指定 allow 或 deny 效果，任一角色的拒绝优先于所有允许
 // @Tags 角色权限
 // @Accept json
 // @Produce json
@@ -42,17 +43,28 @@ func (h *AssignPermissionHandler) Handle(c *fiber.Ctx) error {
 		return err
 	}
 
+	// permission_ids 中的权限按允许效果授予
+	grants := make([]schema.PermissionGrant, 0, len(req.PermissionIDs)+len(req.Grants))
+	for _, permissionID := range req.PermissionIDs {
+		grants = append(grants, schema.PermissionGrant{PermissionID: permissionID, Effect: model.EffectAllow})
+	}
+	grants = append(grants, req.Grants...)
+
 	// 调用服务层分配权限
-	err := h.roleService.AssignPermission(req.RoleID, req.PermissionIDs)
+	err := h.roleService.AssignPermission(req.RoleID, grants)
 	if err != nil {
 		slog.Error("角色分配权限失败", "roleID", req.RoleID, "error", err)
-		
+
 		// 处理特定错误类型
 		switch err {
 		case errors.ErrRoleNotFound:
 			return response.Fail(c, response.CodeNotFound, "角色不存在")
 		case errors.ErrPermissionNotFound:
 			return response.Fail(c, response.CodeNotFound, "部分权限不存在")
+		case errors.ErrInvalidEffect:
+			return response.Fail(c, response.CodeParamError, "授予效果只能是 allow 或 deny")
+		case errors.ErrInvalidCondition:
+			return response.Fail(c, response.CodeParamError, "条件表达式无效，请检查语法、属性命名空间与操作数类型")
 		default:
 			return response.ServerError(c, "角色分配权限失败")
 		}
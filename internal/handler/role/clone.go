@@ -0,0 +1,74 @@
+package role
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CloneHandler 角色克隆处理器
+type CloneHandler struct {
+	roleService service.RoleService
+}
+
+// NewCloneHandler 创建角色克隆处理器
+func NewCloneHandler(roleService service.RoleService) *CloneHandler {
+	return &CloneHandler{
+		roleService: roleService,
+	}
+}
+
+// Handle 处理角色克隆请求
+// @Summary 克隆角色
+// @Description 复制源角色的全部权限授予（含授予效果）创建新角色，可通过 grants 新增权限或替换授予效果，通过 remove_permission_ids 排除权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param data body schema.CloneRoleRequest true "源角色ID、新角色信息与覆盖项"
+// @Success 200 {object} nil "克隆成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "角色或权限不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/clone [post]
+func (h *CloneHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CloneRoleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层克隆角色
+	roleID, err := h.roleService.Clone(req)
+	if err != nil {
+		slog.Error("克隆角色失败", "sourceID", req.SourceID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrRoleExists:
+			return response.Fail(c, response.CodeParamError, "角色名已存在")
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "源角色或父角色不存在")
+		case errors.ErrPermissionNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分权限不存在")
+		case errors.ErrInvalidEffect:
+			return response.Fail(c, response.CodeParamError, "授予效果只能是 allow 或 deny")
+		case errors.ErrInvalidCondition:
+			return response.Fail(c, response.CodeParamError, "条件表达式无效，请检查语法、属性命名空间与操作数类型")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "父角色必须是全局角色或属于同一租户")
+		default:
+			return response.ServerError(c, "克隆角色失败")
+		}
+	}
+
+	// 返回克隆成功响应
+	return response.Success(c, fiber.Map{"id": roleID}, "角色克隆成功")
+}
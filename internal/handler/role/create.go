@@ -37,10 +37,18 @@ func (h *CreateHandler) Handle(c *fiber.Ctx) error {
 		slog.Error("创建角色失败", "error", err)
 		
 		// 处理特定错误类型
-		if err == errors.ErrRoleExists {
+		switch err {
+		case errors.ErrRoleExists:
 			return response.Fail(c, response.CodeParamError, "角色名已存在")
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "父角色不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "父角色必须是全局角色或属于同一租户")
+		default:
+			return response.ServerError(c, "创建角色失败")
 		}
-		return response.ServerError(c, "创建角色失败")
 	}
 
 	// 返回创建成功响应
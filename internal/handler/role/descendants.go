@@ -0,0 +1,60 @@
+package role
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DescendantsHandler 后代角色列表处理器
+type DescendantsHandler struct {
+	roleService service.RoleService
+}
+
+// NewDescendantsHandler 创建后代角色列表处理器
+func NewDescendantsHandler(roleService service.RoleService) *DescendantsHandler {
+	return &DescendantsHandler{
+		roleService: roleService,
+	}
+}
+
+// Handle 处理获取后代角色列表请求
+// @Summary 获取后代角色列表
+// @Description 查询直接或间接继承自指定角色的全部子角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param data body schema.RoleHierarchyRequest true "角色ID参数"
+// @Success 200 {object} []schema.RoleSimple "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/descendants [post]
+func (h *DescendantsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.RoleHierarchyRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取后代角色列表
+	roles, err := h.roleService.GetDescendants(req.ID)
+	if err != nil {
+		slog.Error("获取后代角色列表失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrRoleNotFound {
+			return response.Fail(c, response.CodeNotFound, "角色不存在")
+		}
+		return response.ServerError(c, "获取后代角色列表失败")
+	}
+
+	// 返回后代角色列表
+	return response.Success(c, roles, "获取成功")
+}
@@ -0,0 +1,60 @@
+package role
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EffectivePermissionsHandler 角色有效权限处理器
+type EffectivePermissionsHandler struct {
+	roleService service.RoleService
+}
+
+// NewEffectivePermissionsHandler 创建角色有效权限处理器
+func NewEffectivePermissionsHandler(roleService service.RoleService) *EffectivePermissionsHandler {
+	return &EffectivePermissionsHandler{
+		roleService: roleService,
+	}
+}
+
+// Handle 处理获取角色有效权限请求
+// @Summary 获取角色有效权限
+// @Description 查询角色的有效权限，区分直接授予的权限与从祖先角色继承的权限
+// @Tags 角色权限
+// @Accept json
+// @Produce json
+// @Param data body schema.RoleHierarchyRequest true "角色ID参数"
+// @Success 200 {object} schema.RoleEffectivePermissionsResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/effective-permissions [post]
+func (h *EffectivePermissionsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.RoleHierarchyRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取角色有效权限
+	permissions, err := h.roleService.GetEffectivePermissions(req.ID)
+	if err != nil {
+		slog.Error("获取角色有效权限失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrRoleNotFound {
+			return response.Fail(c, response.CodeNotFound, "角色不存在")
+		}
+		return response.ServerError(c, "获取角色有效权限失败")
+	}
+
+	// 返回角色有效权限
+	return response.Success(c, permissions, "获取成功")
+}
@@ -0,0 +1,68 @@
+package role
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InstantiateTemplateHandler 根据角色模板创建角色处理器
+type InstantiateTemplateHandler struct {
+	templateService service.RoleTemplateService
+}
+
+// NewInstantiateTemplateHandler 创建根据角色模板创建角色处理器
+func NewInstantiateTemplateHandler(templateService service.RoleTemplateService) *InstantiateTemplateHandler {
+	return &InstantiateTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// Handle 处理根据角色模板创建角色请求
+// @Summary 根据模板创建角色
+// @Description 按模板当前版本的权限创建角色，角色记录模板编码与版本，模板变更后可通过 propagate-template 同步
+// @Tags 角色模板
+// @Accept json
+// @Produce json
+// @Param data body schema.InstantiateRoleTemplateRequest true "模板编码与角色信息"
+// @Success 200 {object} nil "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "模板、租户或权限不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/instantiate-template [post]
+func (h *InstantiateTemplateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.InstantiateRoleTemplateRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层根据模板创建角色
+	roleID, err := h.templateService.Instantiate(req)
+	if err != nil {
+		slog.Error("根据模板创建角色失败", "template", req.TemplateCode, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrRoleTemplateNotFound:
+			return response.Fail(c, response.CodeNotFound, "角色模板不存在")
+		case errors.ErrRoleExists:
+			return response.Fail(c, response.CodeParamError, "角色名已存在")
+		case errors.ErrPermissionNotFound:
+			return response.Fail(c, response.CodeNotFound, "模板引用的权限不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		default:
+			return response.ServerError(c, "根据模板创建角色失败")
+		}
+	}
+
+	// 返回创建成功响应
+	return response.Success(c, fiber.Map{"id": roleID}, "角色创建成功")
+}
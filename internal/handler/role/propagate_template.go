@@ -0,0 +1,63 @@
+package role
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PropagateTemplateHandler 角色模板同步处理器
+type PropagateTemplateHandler struct {
+	templateService service.RoleTemplateService
+}
+
+// NewPropagateTemplateHandler 创建角色模板同步处理器
+func NewPropagateTemplateHandler(templateService service.RoleTemplateService) *PropagateTemplateHandler {
+	return &PropagateTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// Handle 处理角色模板同步请求
+// @Summary 同步角色模板
+// @Description 将模板当前的权限同步到由其创建的全部角色，返回每个角色新增、移除与变更效果的权限；dry_run 为 true 时只返回报告
+// @Tags 角色模板
+// @Accept json
+// @Produce json
+// @Param data body schema.PropagateRoleTemplateRequest true "模板编码"
+// @Success 200 {object} schema.RoleTemplatePropagationReport "同步成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "模板或权限不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/roles/propagate-template [post]
+func (h *PropagateTemplateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.PropagateRoleTemplateRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层同步模板
+	report, err := h.templateService.Propagate(req)
+	if err != nil {
+		slog.Error("同步角色模板失败", "template", req.TemplateCode, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrRoleTemplateNotFound:
+			return response.Fail(c, response.CodeNotFound, "角色模板不存在")
+		case errors.ErrPermissionNotFound:
+			return response.Fail(c, response.CodeNotFound, "模板引用的权限不存在")
+		default:
+			return response.ServerError(c, "同步角色模板失败")
+		}
+	}
+
+	return response.Success(c, report, "同步成功")
+}
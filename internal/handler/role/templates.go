@@ -0,0 +1,34 @@
+package role
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TemplatesHandler 角色模板列表处理器
+type TemplatesHandler struct {
+	templateService service.RoleTemplateService
+}
+
+// NewTemplatesHandler 创建角色模板列表处理器
+func NewTemplatesHandler(templateService service.RoleTemplateService) *TemplatesHandler {
+	return &TemplatesHandler{
+		templateService: templateService,
+	}
+}
+
+// Handle 处理获取角色模板列表请求
+// @Summary 获取角色模板列表
+// @Description 列出配置文件中定义的角色模板及其版本与权限编码
+// @Tags 角色模板
+// @Accept json
+// @Produce json
+// @Success 200 {array} schema.RoleTemplateResponse "获取成功"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "禁止访问"
+// @Router /api/v1/roles/templates [post]
+func (h *TemplatesHandler) Handle(c *fiber.Ctx) error {
+	return response.Success(c, h.templateService.List(), "获取成功")
+}
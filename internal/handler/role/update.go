@@ -42,6 +42,10 @@ func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
 			return response.Fail(c, response.CodeNotFound, "角色不存在")
 		case errors.ErrRoleExists:
 			return response.Fail(c, response.CodeParamError, "角色名已存在")
+		case errors.ErrRoleCycle:
+			return response.Fail(c, response.CodeParamError, "角色继承关系存在循环")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "父角色必须是全局角色或属于同一租户")
 		default:
 			return response.ServerError(c, "更新角色失败")
 		}
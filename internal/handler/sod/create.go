@@ -0,0 +1,65 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateHandler 职责分离规则创建处理器
+type CreateHandler struct {
+	sodService service.SoDService
+}
+
+// NewCreateHandler 创建职责分离规则处理器
+func NewCreateHandler(sodService service.SoDService) *CreateHandler {
+	return &CreateHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理创建职责分离规则请求
+// @Summary 创建职责分离规则
+// @Description 创建静态或动态职责分离规则：静态规则限制同一用户可同时分配的规则角色数，动态规则限制同一会话可同时激活的规则角色数
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.CreateSoDRuleRequest true "规则信息"
+// @Success 200 {object} response.Response "创建成功，返回规则ID"
+// @Failure 400 {object} response.Response "参数错误或规则已存在"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/create [post]
+func (h *CreateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CreateSoDRuleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层创建规则
+	ruleID, err := h.sodService.Create(req)
+	if err != nil {
+		slog.Error("创建职责分离规则失败", "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrSoDRuleExists:
+			return response.Fail(c, response.CodeParamError, "规则名称已存在")
+		case errors.ErrInvalidSoDRule:
+			return response.Fail(c, response.CodeParamError, err.Error())
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "角色不存在")
+		default:
+			return response.ServerError(c, "创建职责分离规则失败")
+		}
+	}
+
+	// 返回创建成功响应
+	return response.Success(c, fiber.Map{"id": ruleID}, "规则创建成功")
+}
@@ -0,0 +1,58 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteHandler 职责分离规则删除处理器
+type DeleteHandler struct {
+	sodService service.SoDService
+}
+
+// NewDeleteHandler 创建职责分离规则删除处理器
+func NewDeleteHandler(sodService service.SoDService) *DeleteHandler {
+	return &DeleteHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理删除职责分离规则请求
+// @Summary 删除职责分离规则
+// @Description 删除职责分离规则
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.DeleteSoDRuleRequest true "规则ID"
+// @Success 200 {object} nil "删除成功"
+// @Failure 404 {object} response.Response "规则不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/delete [post]
+func (h *DeleteHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.DeleteSoDRuleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层删除规则
+	err := h.sodService.Delete(req.ID)
+	if err != nil {
+		slog.Error("删除职责分离规则失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrSoDRuleNotFound {
+			return response.Fail(c, response.CodeNotFound, "规则不存在")
+		}
+		return response.ServerError(c, "删除职责分离规则失败")
+	}
+
+	// 返回删除成功响应
+	return response.Success(c, nil, "规则删除成功")
+}
@@ -0,0 +1,58 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DetailHandler 职责分离规则详情处理器
+type DetailHandler struct {
+	sodService service.SoDService
+}
+
+// NewDetailHandler 创建职责分离规则详情处理器
+func NewDetailHandler(sodService service.SoDService) *DetailHandler {
+	return &DetailHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理获取职责分离规则详情请求
+// @Summary 获取职责分离规则详情
+// @Description 根据ID获取规则信息及其互斥的角色
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.GetSoDRuleRequest true "规则ID"
+// @Success 200 {object} schema.SoDRuleResponse "获取成功"
+// @Failure 404 {object} response.Response "规则不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/detail [post]
+func (h *DetailHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GetSoDRuleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取规则详情
+	rule, err := h.sodService.GetByID(req.ID)
+	if err != nil {
+		slog.Error("获取职责分离规则详情失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrSoDRuleNotFound {
+			return response.Fail(c, response.CodeNotFound, "规则不存在")
+		}
+		return response.ServerError(c, "获取职责分离规则详情失败")
+	}
+
+	// 返回规则详情
+	return response.Success(c, rule, "获取成功")
+}
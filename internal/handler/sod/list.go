@@ -0,0 +1,62 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListHandler 职责分离规则列表处理器
+type ListHandler struct {
+	sodService service.SoDService
+}
+
+// NewListHandler 创建职责分离规则列表处理器
+func NewListHandler(sodService service.SoDService) *ListHandler {
+	return &ListHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理获取职责分离规则列表请求
+// @Summary 获取职责分离规则列表
+// @Description 分页查询职责分离规则，可按名称关键字与规则类型筛选
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.ListSoDRuleRequest true "分页与筛选参数"
+// @Success 200 {object} schema.ListSoDRuleResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/list [post]
+func (h *ListHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListSoDRuleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	// 调用服务层获取规则列表
+	result, err := h.sodService.List(req)
+	if err != nil {
+		slog.Error("获取职责分离规则列表失败", "error", err)
+		return response.ServerError(c, "获取职责分离规则列表失败")
+	}
+
+	// 返回规则列表
+	return response.Success(c, result, "获取成功")
+}
@@ -0,0 +1,67 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateHandler 职责分离规则更新处理器
+type UpdateHandler struct {
+	sodService service.SoDService
+}
+
+// NewUpdateHandler 创建职责分离规则更新处理器
+func NewUpdateHandler(sodService service.SoDService) *UpdateHandler {
+	return &UpdateHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理更新职责分离规则请求
+// @Summary 更新职责分离规则
+// @Description 更新规则的名称、类型、角色上限与角色，已有的角色分配不受影响
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.UpdateSoDRuleRequest true "规则信息"
+// @Success 200 {object} nil "更新成功"
+// @Failure 400 {object} response.Response "参数错误或规则已存在"
+// @Failure 404 {object} response.Response "规则或角色不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/update [post]
+func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.UpdateSoDRuleRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层更新规则
+	err := h.sodService.Update(req)
+	if err != nil {
+		slog.Error("更新职责分离规则失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrSoDRuleNotFound:
+			return response.Fail(c, response.CodeNotFound, "规则不存在")
+		case errors.ErrSoDRuleExists:
+			return response.Fail(c, response.CodeParamError, "规则名称已存在")
+		case errors.ErrInvalidSoDRule:
+			return response.Fail(c, response.CodeParamError, err.Error())
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "角色不存在")
+		default:
+			return response.ServerError(c, "更新职责分离规则失败")
+		}
+	}
+
+	// 返回更新成功响应
+	return response.Success(c, nil, "规则更新成功")
+}
@@ -0,0 +1,58 @@
+package sod
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ViolationsHandler 违反职责分离规则的用户报告处理器
+type ViolationsHandler struct {
+	sodService service.SoDService
+}
+
+// NewViolationsHandler 创建违反职责分离规则的用户报告处理器
+func NewViolationsHandler(sodService service.SoDService) *ViolationsHandler {
+	return &ViolationsHandler{
+		sodService: sodService,
+	}
+}
+
+// Handle 处理查询违反职责分离规则的用户请求
+// @Summary 查询违反职责分离规则的用户
+// @Description 列出现有角色分配违反指定规则或全部规则的用户，用于新增规则后清理存量分配；角色继承会被计入
+// @Tags 职责分离
+// @Accept json
+// @Produce json
+// @Param data body schema.SoDViolationsRequest true "规则ID，为0时检查全部规则"
+// @Success 200 {object} schema.SoDViolationReport "获取成功"
+// @Failure 404 {object} response.Response "规则不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/sod-rules/violations [post]
+func (h *ViolationsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.SoDViolationsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层检查违反规则的用户
+	report, err := h.sodService.Violations(req)
+	if err != nil {
+		slog.Error("查询违反职责分离规则的用户失败", "ruleID", req.RuleID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrSoDRuleNotFound {
+			return response.Fail(c, response.CodeNotFound, "规则不存在")
+		}
+		return response.ServerError(c, "查询违反职责分离规则的用户失败")
+	}
+
+	// 返回报告
+	return response.Success(c, report, "获取成功")
+}
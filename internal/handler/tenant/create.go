@@ -0,0 +1,58 @@
+package tenant
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateHandler 租户创建处理器
+type CreateHandler struct {
+	tenantService service.TenantService
+}
+
+// NewCreateHandler 创建租户处理器
+func NewCreateHandler(tenantService service.TenantService) *CreateHandler {
+	return &CreateHandler{
+		tenantService: tenantService,
+	}
+}
+
+// Handle 处理创建租户请求
+// @Summary 创建租户
+// @Description 创建新的租户，租户内可创建专属角色并按租户分配用户角色
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.CreateTenantRequest true "租户信息"
+// @Success 200 {object} response.Response "创建成功，返回租户ID"
+// @Failure 400 {object} response.Response "参数错误或租户已存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tenants/create [post]
+func (h *CreateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.CreateTenantRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层创建租户
+	tenantID, err := h.tenantService.Create(req)
+	if err != nil {
+		slog.Error("创建租户失败", "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrTenantExists {
+			return response.Fail(c, response.CodeParamError, "租户编码或名称已存在")
+		}
+		return response.ServerError(c, "创建租户失败")
+	}
+
+	// 返回创建成功响应
+	return response.Success(c, fiber.Map{"id": tenantID}, "租户创建成功")
+}
@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteHandler 租户删除处理器
+type DeleteHandler struct {
+	tenantService service.TenantService
+}
+
+// NewDeleteHandler 创建租户删除处理器
+func NewDeleteHandler(tenantService service.TenantService) *DeleteHandler {
+	return &DeleteHandler{
+		tenantService: tenantService,
+	}
+}
+
+// Handle 处理删除租户请求
+// @Summary 删除租户
+// @Description 删除租户，租户下仍有角色、角色分配或直接授予用户的权限时不允许删除
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.DeleteTenantRequest true "租户ID"
+// @Success 200 {object} nil "删除成功"
+// @Failure 403 {object} response.Response "租户仍在使用中"
+// @Failure 404 {object} response.Response "租户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tenants/delete [post]
+func (h *DeleteHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.DeleteTenantRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层删除租户
+	err := h.tenantService.Delete(req.ID)
+	if err != nil {
+		slog.Error("删除租户失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantInUse:
+			return response.Fail(c, response.CodeForbidden, "租户下仍有角色、角色分配或直接授权，无法删除")
+		default:
+			return response.ServerError(c, "删除租户失败")
+		}
+	}
+
+	// 返回删除成功响应
+	return response.Success(c, nil, "租户删除成功")
+}
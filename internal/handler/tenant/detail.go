@@ -0,0 +1,58 @@
+package tenant
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DetailHandler 租户详情处理器
+type DetailHandler struct {
+	tenantService service.TenantService
+}
+
+// NewDetailHandler 创建租户详情处理器
+func NewDetailHandler(tenantService service.TenantService) *DetailHandler {
+	return &DetailHandler{
+		tenantService: tenantService,
+	}
+}
+
+// Handle 处理获取租户详情请求
+// @Summary 获取租户详情
+// @Description 根据ID获取租户信息
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.GetTenantRequest true "租户ID"
+// @Success 200 {object} schema.TenantResponse "获取成功"
+// @Failure 404 {object} response.Response "租户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tenants/detail [post]
+func (h *DetailHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.GetTenantRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层获取租户详情
+	tenant, err := h.tenantService.GetByID(req.ID)
+	if err != nil {
+		slog.Error("获取租户详情失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		if err == errors.ErrTenantNotFound {
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		}
+		return response.ServerError(c, "获取租户详情失败")
+	}
+
+	// 返回租户详情
+	return response.Success(c, tenant, "获取成功")
+}
@@ -0,0 +1,62 @@
+package tenant
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListHandler 租户列表处理器
+type ListHandler struct {
+	tenantService service.TenantService
+}
+
+// NewListHandler 创建租户列表处理器
+func NewListHandler(tenantService service.TenantService) *ListHandler {
+	return &ListHandler{
+		tenantService: tenantService,
+	}
+}
+
+// Handle 处理获取租户列表请求
+// @Summary 获取租户列表
+// @Description 分页查询所有租户信息
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ListTenantRequest true "分页与筛选参数"
+// @Success 200 {object} schema.ListTenantResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tenants/list [post]
+func (h *ListHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListTenantRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 设置默认分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	} else if req.PageSize > 100 {
+		req.PageSize = 100 // 限制最大每页数量
+	}
+
+	// 调用服务层获取租户列表
+	result, err := h.tenantService.List(req)
+	if err != nil {
+		slog.Error("获取租户列表失败", "error", err)
+		return response.ServerError(c, "获取租户列表失败")
+	}
+
+	// 返回租户列表
+	return response.Success(c, result, "获取成功")
+}
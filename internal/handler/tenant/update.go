@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateHandler 租户更新处理器
+type UpdateHandler struct {
+	tenantService service.TenantService
+}
+
+// NewUpdateHandler 创建租户更新处理器
+func NewUpdateHandler(tenantService service.TenantService) *UpdateHandler {
+	return &UpdateHandler{
+		tenantService: tenantService,
+	}
+}
+
+// Handle 处理更新租户请求
+// @Summary 更新租户
+// @Description 更新租户编码、名称与描述
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.UpdateTenantRequest true "租户信息"
+// @Success 200 {object} nil "更新成功"
+// @Failure 400 {object} response.Response "参数错误或租户已存在"
+// @Failure 404 {object} response.Response "租户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tenants/update [post]
+func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.UpdateTenantRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 调用服务层更新租户
+	err := h.tenantService.Update(req)
+	if err != nil {
+		slog.Error("更新租户失败", "id", req.ID, "error", err)
+
+		// 处理特定错误类型
+		switch err {
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantExists:
+			return response.Fail(c, response.CodeParamError, "租户编码或名称已存在")
+		default:
+			return response.ServerError(c, "更新租户失败")
+		}
+	}
+
+	// 返回更新成功响应
+	return response.Success(c, nil, "租户更新成功")
+}
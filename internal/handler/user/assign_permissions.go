@@ -0,0 +1,68 @@
+package user
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AssignPermissionsHandler 直接授予用户权限处理器
+type AssignPermissionsHandler struct {
+	userService service.UserService
+}
+
+// NewAssignPermissionsHandler 创建直接授予用户权限处理器
+func NewAssignPermissionsHandler(userService service.UserService) *AssignPermissionsHandler {
+	return &AssignPermissionsHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理直接授予用户权限请求
+// @Summary 直接授予用户权限
+// @Description 不经过角色直接为用户授予权限，tenant_id 为0时为全局授予；effect 为 deny 时显式拒绝。已授予的权限只更新效果
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.AssignUserPermissionsRequest true "用户ID与权限ID列表"
+// @Success 200 {object} nil "授予成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户、租户或权限不存在"
+// @Failure 403 {object} response.Response "无权操作其他租户"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/assign-permissions [post]
+func (h *AssignPermissionsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.AssignUserPermissionsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 操作当前租户以外的租户须经由全局分配具备权限
+	if !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
+	if err := h.userService.AssignPermissions(req); err != nil {
+		slog.Error("直接授予用户权限失败", "userID", req.UserID, "tenantID", req.TenantID, "error", err)
+
+		switch err {
+		case errors.ErrUserNotFound:
+			return response.Fail(c, response.CodeNotFound, "用户不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrPermissionNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分权限不存在")
+		default:
+			return response.ServerError(c, "直接授予用户权限失败")
+		}
+	}
+
+	return response.Success(c, nil, "权限授予成功")
+}
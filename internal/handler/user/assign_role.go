@@ -1,12 +1,12 @@
 package user
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,7 +25,7 @@ func NewAssignRoleHandler(userService service.UserService) *AssignRoleHandler {
 
 // Handle 处理用户分配角色请求
 // @Summary 分配用户角色
-// @Description 为指定用户分配一个或多个角色
+// @Description 替换指定用户在某个租户内的角色，tenant_id 为0时替换全局角色分配；可通过 valid_from、valid_until 限定分配的生效区间
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -33,6 +33,7 @@ func NewAssignRoleHandler(userService service.UserService) *AssignRoleHandler {
 // @Success 200 {object} nil "分配成功"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 404 {object} response.Response "用户或角色不存在"
+// @Failure 403 {object} response.Response "角色分配违反职责分离约束（code 1006）；无权操作其他租户"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/users/assign_role [post]
 func (h *AssignRoleHandler) Handle(c *fiber.Ctx) error {
@@ -42,17 +43,30 @@ func (h *AssignRoleHandler) Handle(c *fiber.Ctx) error {
 		return err
 	}
 
+	// 操作当前租户以外的租户须经由全局分配具备权限
+	if !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
 	// 调用服务层分配角色
-	err := h.userService.AssignRole(req.UserID, req.RoleIDs)
+	err := h.userService.AssignRole(req)
 	if err != nil {
-		slog.Error("用户分配角色失败", "userID", req.UserID, "error", err)
-		
+		slog.Error("用户分配角色失败", "userID", req.UserID, "tenantID", req.TenantID, "error", err)
+
 		// 处理特定错误类型
 		switch err {
 		case errors.ErrUserNotFound:
 			return response.Fail(c, response.CodeNotFound, "用户不存在")
 		case errors.ErrRoleNotFound:
 			return response.Fail(c, response.CodeNotFound, "部分角色不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrInvalidRoleValidity:
+			return response.Fail(c, response.CodeParamError, "有效期无效，失效时间须晚于当前时间与生效时间")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "部分角色不属于该租户")
+		case errors.ErrSoDViolation:
+			return response.SoDViolation(c, err.Error())
 		default:
 			return response.ServerError(c, "用户分配角色失败")
 		}
@@ -32,7 +32,9 @@ func NewCreateHandler(userService service.UserService) *CreateHandler {
 // @Param data body schema.CreateUserRequest true "用户创建参数"
 // @Success 200 {object} map[string]interface{} "创建成功，返回用户ID"
 // @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "角色或租户不存在"
 // @Failure 409 {object} response.Response "用户名或邮箱已存在"
+// @Failure 403 {object} response.Response "角色分配违反职责分离约束（code 1006）；无权操作其他租户"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/users/create [post]
 func (h *CreateHandler) Handle(c *fiber.Ctx) error {
@@ -42,6 +44,11 @@ func (h *CreateHandler) Handle(c *fiber.Ctx) error {
 		return err
 	}
 
+	// 分配角色时，操作当前租户以外的租户须经由全局分配具备权限
+	if len(req.RoleIDs) > 0 && !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
 	// 调用服务层创建用户
 	userID, err := h.userService.Create(req)
 	if err != nil {
@@ -53,6 +60,14 @@ func (h *CreateHandler) Handle(c *fiber.Ctx) error {
 			return response.Fail(c, response.CodeParamError, "用户名已存在")
 		case errors.ErrEmailExists:
 			return response.Fail(c, response.CodeParamError, "邮箱已被使用")
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分角色不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "部分角色不属于该租户")
+		case errors.ErrSoDViolation:
+			return response.SoDViolation(c, err.Error())
 		default:
 			return response.ServerError(c, "创建用户失败")
 		}
@@ -25,7 +25,7 @@ func NewDetailHandler(userService service.UserService) *DetailHandler {
 
 // Handle 处理获取用户详情请求
 // @Summary 获取用户详情
-// @Description 根据用户ID获取详细信息
+// @Description 根据用户ID获取详细信息，grants 列出用户在 tenant_id 所指租户内的有效权限授予及来源（direct 直接授予，role 经由角色）
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -33,6 +33,7 @@ func NewDetailHandler(userService service.UserService) *DetailHandler {
 // @Success 200 {object} schema.UserResponse "获取成功"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 404 {object} response.Response "用户不存在"
+// @Failure 403 {object} response.Response "无权操作其他租户"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/users/detail [post]
 func (h *DetailHandler) Handle(c *fiber.Ctx) error {
@@ -42,8 +43,13 @@ func (h *DetailHandler) Handle(c *fiber.Ctx) error {
 		return err
 	}
 
+	// 操作当前租户以外的租户须经由全局分配具备权限
+	if !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
 	// 调用服务层获取用户详情
-	user, err := h.userService.GetByID(req.ID)
+	user, err := h.userService.GetByID(req.ID, req.TenantID)
 	if err != nil {
 		slog.Error("获取用户详情失败", "id", req.ID, "error", err)
 		
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExplainPermissionHandler 用户权限判定解释处理器
+type ExplainPermissionHandler struct {
+	userService service.UserService
+}
+
+// NewExplainPermissionHandler 创建用户权限判定解释处理器
+func NewExplainPermissionHandler(userService service.UserService) *ExplainPermissionHandler {
+	return &ExplainPermissionHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理用户权限判定解释请求
+// @Summary 解释任意用户的权限判定
+// @Description 返回指定用户在指定租户内对某个权限的判定结果，以及每条角色分配、继承路径和命中的权限授予
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ExplainPermissionRequest true "权限判定解释请求参数"
+// @Success 200 {object} schema.ExplainPermissionResponse "判定结果及推导过程"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 403 {object} response.Response "无权操作其他租户"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/explain-permission [post]
+func (h *ExplainPermissionHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ExplainPermissionRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 操作当前租户以外的租户须经由全局分配具备权限
+	if !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
+	result, err := h.userService.ExplainPermission(req.UserID, req.TenantID, nil, req.Permission)
+	if err != nil {
+		slog.Error("解释用户权限判定失败", "userID", req.UserID, "tenantID", req.TenantID, "permission", req.Permission, "error", err)
+
+		if err == errors.ErrUserNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户不存在")
+		}
+		return response.ServerError(c, "解释用户权限判定失败")
+	}
+
+	return response.Success(c, result, "获取成功")
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"log/slog"
 
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
@@ -25,7 +26,7 @@ func NewListHandler(userService service.UserService) *ListHandler {
 
 // Handle 处理获取用户列表请求
 // @Summary 获取用户列表
-// @Description 分页获取用户信息列表，支持关键字搜索
+// @Description 分页获取用户信息列表，支持关键字搜索；只返回当前用户数据范围内的用户
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -51,8 +52,8 @@ func (h *ListHandler) Handle(c *fiber.Ctx) error {
 		req.PageSize = 100 // 限制最大每页数量
 	}
 
-	// 调用服务层获取用户列表
-	result, err := h.userService.List(req)
+	// 调用服务层获取用户列表，按当前用户的数据范围过滤
+	result, err := h.userService.List(req, middleware.GetUserID(c), middleware.GetTenantID(c))
 	if err != nil {
 		slog.Error("获取用户列表失败", "error", err)
 		return response.ServerError(c, "获取用户列表失败")
@@ -0,0 +1,57 @@
+package user
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListPermissionsHandler 用户直接权限列表处理器
+type ListPermissionsHandler struct {
+	userService service.UserService
+}
+
+// NewListPermissionsHandler 创建用户直接权限列表处理器
+func NewListPermissionsHandler(userService service.UserService) *ListPermissionsHandler {
+	return &ListPermissionsHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理获取用户直接权限列表请求
+// @Summary 获取用户直接权限列表
+// @Description 查询直接授予指定用户的全部权限（含各租户内的授予），不包含经由角色获得的权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.ListUserPermissionsRequest true "用户ID参数"
+// @Success 200 {object} []schema.UserPermissionResponse "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/list-permissions [post]
+func (h *ListPermissionsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.ListUserPermissionsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	permissions, err := h.userService.GetDirectPermissions(req.UserID)
+	if err != nil {
+		slog.Error("获取用户直接权限列表失败", "userID", req.UserID, "error", err)
+
+		if err == errors.ErrUserNotFound {
+			return response.Fail(c, response.CodeNotFound, "用户不存在")
+		}
+		return response.ServerError(c, "获取用户直接权限列表失败")
+	}
+
+	return response.Success(c, permissions, "获取成功")
+}
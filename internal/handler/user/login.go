@@ -1,11 +1,12 @@
 package user
 
 import (
-	"log/slog"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
 	"github.com/lvyunze/fiber-rbac/internal/service"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -33,7 +34,10 @@ func (h *LoginHandler) Handle(c *fiber.Ctx) error {
 	// 调用服务层进行登录
 	res, err := h.userService.Login(req)
 	if err != nil {
-		slog.Error("用户登录失败", "username", req.Username, "error", err)
+		slog.Error("用户登录失败", "username", req.Username, "tenantID", req.TenantID, "error", err)
+		if err == errors.ErrTenantNotFound || err == errors.ErrTenantForbidden {
+			return response.Fail(c, response.CodeForbidden, "无权登录该租户")
+		}
 		return response.Fail(c, response.CodeUnauthorized, "用户名或密码错误")
 	}
 
@@ -29,7 +29,7 @@ func (h *ProfileHandler) Handle(c *fiber.Ctx) error {
 	}
 
 	// 调用服务层获取用户信息
-	user, err := h.userService.GetProfile(userID)
+	user, err := h.userService.GetProfile(userID, middleware.GetTenantID(c), middleware.GetActiveRoles(c))
 	if err != nil {
 		return response.Fail(c, response.CodeServerError, "获取用户信息失败")
 	}
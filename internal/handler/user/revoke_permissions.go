@@ -0,0 +1,66 @@
+package user
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/validator"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RevokePermissionsHandler 撤销用户直接权限处理器
+type RevokePermissionsHandler struct {
+	userService service.UserService
+}
+
+// NewRevokePermissionsHandler 创建撤销用户直接权限处理器
+func NewRevokePermissionsHandler(userService service.UserService) *RevokePermissionsHandler {
+	return &RevokePermissionsHandler{
+		userService: userService,
+	}
+}
+
+// Handle 处理撤销用户直接权限请求
+// @Summary 撤销用户直接权限
+// @Description 撤销在指定租户内直接授予用户的权限，经由角色获得的权限不受影响
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param data body schema.RevokeUserPermissionsRequest true "用户ID与权限ID列表"
+// @Success 200 {object} nil "撤销成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户或租户不存在"
+// @Failure 403 {object} response.Response "无权操作其他租户"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/revoke-permissions [post]
+func (h *RevokePermissionsHandler) Handle(c *fiber.Ctx) error {
+	// 解析请求参数
+	req := new(schema.RevokeUserPermissionsRequest)
+	if err := validator.ValidateRequest(c, req); err != nil {
+		return err
+	}
+
+	// 操作当前租户以外的租户须经由全局分配具备权限
+	if !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
+	if err := h.userService.RevokePermissions(req); err != nil {
+		slog.Error("撤销用户直接权限失败", "userID", req.UserID, "tenantID", req.TenantID, "error", err)
+
+		switch err {
+		case errors.ErrUserNotFound:
+			return response.Fail(c, response.CodeNotFound, "用户不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		default:
+			return response.ServerError(c, "撤销用户直接权限失败")
+		}
+	}
+
+	return response.Success(c, nil, "权限撤销成功")
+}
@@ -0,0 +1,27 @@
+package user
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// allowTenant 校验当前用户能否操作请求指定的租户，返回 false 时已写入拒绝响应。
+// 只有经由全局分配获得权限的用户才能操作当前租户以外的租户（包括全局分配）
+func allowTenant(c *fiber.Ctx, provider middleware.PolicyProvider, tenantID uint64) bool {
+	ok, err := middleware.AllowTenant(c, provider, tenantID)
+	if err != nil {
+		slog.Error("校验租户访问权限失败", "userID", middleware.GetUserID(c), "tenantID", tenantID, "error", err)
+		_ = response.ServerError(c, "权限校验失败")
+		return false
+	}
+	if !ok {
+		slog.Warn("跨租户操作权限不足，拒绝访问", "userID", middleware.GetUserID(c), "currentTenantID", middleware.GetTenantID(c), "tenantID", tenantID, "path", c.Path())
+		_ = response.Forbidden(c, "无权操作其他租户")
+		return false
+	}
+	return true
+}
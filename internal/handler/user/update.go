@@ -34,6 +34,7 @@ func NewUpdateHandler(userService service.UserService) *UpdateHandler {
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 404 {object} response.Response "用户不存在"
 // @Failure 409 {object} response.Response "用户名或邮箱已存在"
+// @Failure 403 {object} response.Response "角色分配违反职责分离约束（code 1006）；无权操作其他租户"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/users/update [post]
 func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
@@ -43,6 +44,11 @@ func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
 		return err
 	}
 
+	// 替换角色分配时，操作当前租户以外的租户须经由全局分配具备权限
+	if req.RoleIDs != nil && !allowTenant(c, h.userService, req.TenantID) {
+		return nil
+	}
+
 	// 调用服务层更新用户
 	err := h.userService.Update(req)
 	if err != nil {
@@ -56,6 +62,14 @@ func (h *UpdateHandler) Handle(c *fiber.Ctx) error {
 			return response.Fail(c, response.CodeParamError, "用户名已存在")
 		case errors.ErrEmailExists:
 			return response.Fail(c, response.CodeParamError, "邮箱已被使用")
+		case errors.ErrRoleNotFound:
+			return response.Fail(c, response.CodeNotFound, "部分角色不存在")
+		case errors.ErrTenantNotFound:
+			return response.Fail(c, response.CodeNotFound, "租户不存在")
+		case errors.ErrTenantMismatch:
+			return response.Fail(c, response.CodeParamError, "部分角色不属于该租户")
+		case errors.ErrSoDViolation:
+			return response.SoDViolation(c, err.Error())
 		default:
 			return response.ServerError(c, "更新用户失败")
 		}
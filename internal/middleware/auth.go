@@ -1,14 +1,18 @@
 package middleware
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/config"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"log/slog"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// TenantHeader 指定当前租户的请求头，令牌未携带租户声明时生效
+const TenantHeader = "X-Tenant-ID"
+
 // Auth 认证中间件
 func Auth(jwtConfig *config.JWTConfig) fiber.Handler {
 	tokenService := jwt.NewTokenService(jwtConfig)
@@ -42,9 +46,25 @@ func Auth(jwtConfig *config.JWTConfig) fiber.Handler {
 			return response.Unauthorized(c, "令牌类型错误")
 		}
 
+		// 解析当前租户，令牌中的租户声明优先于请求头
+		tenantID := claims.TenantID
+		if header := c.Get(TenantHeader); header != "" {
+			headerTenantID, err := strconv.ParseUint(header, 10, 64)
+			if err != nil {
+				return response.ParamError(c, "无效的租户ID")
+			}
+			if tenantID != 0 && headerTenantID != tenantID {
+				slog.Warn("请求头租户与令牌租户不一致", "userID", claims.UserID, "tokenTenantID", tenantID, "headerTenantID", headerTenantID)
+				return response.Forbidden(c, "租户与认证令牌不一致")
+			}
+			tenantID = headerTenantID
+		}
+
 		// 将用户信息存储到上下文中
 		c.Locals("userID", claims.UserID)
 		c.Locals("username", claims.Username)
+		c.Locals("tenantID", tenantID)
+		c.Locals("activeRoles", claims.ActiveRoles)
 
 		return c.Next()
 	}
@@ -67,3 +87,21 @@ func GetUsername(c *fiber.Ctx) string {
 	}
 	return username
 }
+
+// GetTenantID 从上下文中获取当前租户ID，0表示未选择租户，仅全局角色分配生效
+func GetTenantID(c *fiber.Ctx) uint64 {
+	tenantID, ok := c.Locals("tenantID").(uint64)
+	if !ok {
+		return 0
+	}
+	return tenantID
+}
+
+// GetActiveRoles 从上下文中获取会话激活的角色ID，为空表示激活全部已分配的角色
+func GetActiveRoles(c *fiber.Ctx) []uint64 {
+	activeRoles, ok := c.Locals("activeRoles").([]uint64)
+	if !ok {
+		return nil
+	}
+	return activeRoles
+}
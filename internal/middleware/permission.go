@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// 当前请求已解析的有效权限在上下文中的键名
+const permissionsKey = "permissions"
+
+// 当前请求已通过的权限要求在上下文中的键名，跨租户操作时需按全局分配重新校验
+const guardsKey = "permissionGuards"
+
+// permissionGuard 一项权限要求，判断权限策略是否满足
+type permissionGuard func(policy *permcode.Policy) bool
+
+// PolicyProvider 提供用户在租户内的权限判定策略，service.UserService 满足该接口
+type PolicyProvider interface {
+	GetPermissionPolicy(userID, tenantID uint64) (*permcode.Policy, error)
+	// GetSessionPolicy 获取会话仅激活部分角色时的权限判定策略
+	GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*permcode.Policy, error)
+}
+
+// RequirePermission 权限校验中间件，要求当前用户具备指定权限
+func RequirePermission(provider PolicyProvider, permission string) fiber.Handler {
+	return RequireAll(provider, permission)
+}
+
+// RequireAny 权限校验中间件，要求当前用户具备任意一个指定权限
+func RequireAny(provider PolicyProvider, permissions ...string) fiber.Handler {
+	return requirePermissions(provider, permissions, false)
+}
+
+// RequireAll 权限校验中间件，要求当前用户具备全部指定权限
+func RequireAll(provider PolicyProvider, permissions ...string) fiber.Handler {
+	return requirePermissions(provider, permissions, true)
+}
+
+// requirePermissions 根据匹配模式校验当前用户的有效权限
+func requirePermissions(provider PolicyProvider, permissions []string, all bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 必须在认证中间件之后使用
+		userID := GetUserID(c)
+		if userID == 0 {
+			return response.Unauthorized(c, "未授权的访问")
+		}
+
+		// 解析当前用户的权限判定策略
+		tenantID := GetTenantID(c)
+		policy, err := resolvePermissions(c, provider, userID, tenantID)
+		if err != nil {
+			if err == errors.ErrUserNotFound {
+				return response.Unauthorized(c, "用户不存在")
+			}
+			slog.Error("获取用户权限失败", "userID", userID, "tenantID", tenantID, "error", err)
+			return response.ServerError(c, "权限校验失败")
+		}
+
+		guard := func(policy *permcode.Policy) bool {
+			return matchPermissions(policy, permissions, all)
+		}
+		if !guard(policy) {
+			slog.Warn("权限不足，拒绝访问", "userID", userID, "tenantID", tenantID, "path", c.Path(), "required", permissions)
+			return response.Forbidden(c, "")
+		}
+		addGuard(c, guard)
+
+		return c.Next()
+	}
+}
+
+// resolvePermissions 获取当前用户在当前租户内的权限判定策略，同一请求内只解析一次
+// 令牌只激活了部分角色时仅计入这些角色的权限
+func resolvePermissions(c *fiber.Ctx, provider PolicyProvider, userID, tenantID uint64) (*permcode.Policy, error) {
+	if policy, ok := c.Locals(permissionsKey).(*permcode.Policy); ok {
+		return policy, nil
+	}
+
+	var policy *permcode.Policy
+	var err error
+	if activeRoles := GetActiveRoles(c); len(activeRoles) > 0 {
+		policy, err = provider.GetSessionPolicy(userID, tenantID, activeRoles)
+	} else {
+		policy, err = provider.GetPermissionPolicy(userID, tenantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Locals(permissionsKey, policy)
+
+	return policy, nil
+}
+
+// addGuard 记录当前请求已通过的权限要求
+func addGuard(c *fiber.Ctx, guard permissionGuard) {
+	guards, _ := c.Locals(guardsKey).([]permissionGuard)
+	c.Locals(guardsKey, append(guards, guard))
+}
+
+// AllowTenant 判断当前用户能否操作指定租户内的数据。目标为当前租户时，权限已由权限中间件校验；
+// 目标为其他租户（包括全局）时，当前用户须仅凭全局分配满足本请求通过的全部权限要求，
+// 租户内分配获得的权限不能用于操作其他租户
+func AllowTenant(c *fiber.Ctx, provider PolicyProvider, tenantID uint64) (bool, error) {
+	if tenantID == GetTenantID(c) {
+		return true, nil
+	}
+
+	guards, _ := c.Locals(guardsKey).([]permissionGuard)
+	if len(guards) == 0 {
+		return false, nil
+	}
+
+	var policy *permcode.Policy
+	var err error
+	userID := GetUserID(c)
+	if activeRoles := GetActiveRoles(c); len(activeRoles) > 0 {
+		policy, err = provider.GetSessionPolicy(userID, model.GlobalTenantID, activeRoles)
+	} else {
+		policy, err = provider.GetPermissionPolicy(userID, model.GlobalTenantID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, guard := range guards {
+		if !guard(policy) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchPermissions 判断权限策略是否满足要求，拒绝规则优先于允许规则
+func matchPermissions(policy *permcode.Policy, required []string, all bool) bool {
+	if all {
+		return policy.AllowsAll(required...)
+	}
+	return policy.AllowsAny(required...)
+}
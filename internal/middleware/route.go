@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutePolicyProvider 提供权限判定策略及与请求匹配的接口权限，service.UserService 满足该接口
+type RoutePolicyProvider interface {
+	PolicyProvider
+	GetRoutePermissions(method, path string) ([]string, error)
+}
+
+// RequireRoute 按请求方法与路径校验权限的中间件，无需为每个路由指定权限编码
+// 请求需匹配至少一条携带方法与路径模式的接口权限（如 POST /api/v1/users/*），
+// 用户被允许其中任意一条且未被拒绝其中任何一条时放行；未匹配任何接口权限的请求一律拒绝
+func RequireRoute(provider RoutePolicyProvider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 必须在认证中间件之后使用
+		userID := GetUserID(c)
+		if userID == 0 {
+			return response.Unauthorized(c, "未授权的访问")
+		}
+
+		method, path := c.Method(), c.Path()
+		codes, err := provider.GetRoutePermissions(method, path)
+		if err != nil {
+			slog.Error("匹配接口权限失败", "method", method, "path", path, "error", err)
+			return response.ServerError(c, "权限校验失败")
+		}
+
+		tenantID := GetTenantID(c)
+		if len(codes) == 0 {
+			slog.Warn("请求未匹配任何接口权限，拒绝访问", "userID", userID, "tenantID", tenantID, "route", method+" "+path)
+			return response.Forbidden(c, "")
+		}
+
+		policy, err := resolvePermissions(c, provider, userID, tenantID)
+		if err != nil {
+			if err == errors.ErrUserNotFound {
+				return response.Unauthorized(c, "用户不存在")
+			}
+			slog.Error("获取用户权限失败", "userID", userID, "tenantID", tenantID, "error", err)
+			return response.ServerError(c, "权限校验失败")
+		}
+
+		guard := func(policy *permcode.Policy) bool {
+			return matchRoute(policy, codes)
+		}
+		if !guard(policy) {
+			slog.Warn("接口权限不足，拒绝访问", "userID", userID, "tenantID", tenantID, "route", method+" "+path, "required", codes)
+			return response.Forbidden(c, "")
+		}
+		addGuard(c, guard)
+
+		return c.Next()
+	}
+}
+
+// matchRoute 判断权限策略是否满足匹配到的接口权限，任一接口权限被拒绝即不满足
+func matchRoute(policy *permcode.Policy, codes []string) bool {
+	for _, code := range codes {
+		if policy.Denies(code) {
+			return false
+		}
+	}
+	return policy.AllowsAny(codes...)
+}
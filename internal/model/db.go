@@ -62,6 +62,11 @@ func InitDB(cfg *config.DatabaseConfig, env string) error {
 	sqlDB.SetConnMaxLifetime(time.Hour)        // 连接最大生命周期
 	sqlDB.SetConnMaxIdleTime(30 * time.Minute) // 空闲连接最大生命周期
 
+	// 注册自定义关联模型
+	if err := SetupJoinTables(db); err != nil {
+		return fmt.Errorf("注册关联模型失败: %w", err)
+	}
+
 	// 自动迁移数据库模式
 	if err := db.AutoMigrate(
 		&User{},
@@ -69,6 +74,8 @@ func InitDB(cfg *config.DatabaseConfig, env string) error {
 		&Permission{},
 		&UserRole{},
 		&RolePermission{},
+		&RoleParent{},
+		&Tenant{},
 	); err != nil {
 		return fmt.Errorf("自动迁移数据库模式失败: %w", err)
 	}
@@ -80,6 +87,29 @@ func InitDB(cfg *config.DatabaseConfig, env string) error {
 	return nil
 }
 
+// SetupJoinTables 注册自定义多对多关联模型，确保关联表包含额外字段
+func SetupJoinTables(db *gorm.DB) error {
+	joinTables := []struct {
+		model     interface{}
+		field     string
+		joinTable interface{}
+	}{
+		{&User{}, "Roles", &UserRole{}},
+		{&Role{}, "Users", &UserRole{}},
+		{&Role{}, "Permissions", &RolePermission{}},
+		{&Permission{}, "Roles", &RolePermission{}},
+		{&Role{}, "Parents", &RoleParent{}},
+		{&Group{}, "Parents", &GroupParent{}},
+	}
+
+	for _, jt := range joinTables {
+		if err := db.SetupJoinTable(jt.model, jt.field, jt.joinTable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetDB 获取数据库连接
 func GetDB() *gorm.DB {
 	return DB
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// Group 用户组模型
+// 组可以嵌套：子组的成员同时是其全部上级组的成员，因此继承分配给上级组的角色
+type Group struct {
+	ID          uint64  `gorm:"primaryKey" json:"id"`
+	Code        string  `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Name        string  `gorm:"size:50;not null;uniqueIndex" json:"name"`
+	Description string  `gorm:"type:text" json:"description"`
+	CreatedAt   int64   `gorm:"not null" json:"created_at"`
+	UpdatedAt   int64   `json:"updated_at"`
+	DeletedAt   *int64  `gorm:"index" json:"deleted_at"`
+	Parents     []Group `gorm:"many2many:group_parents;joinForeignKey:GroupID;joinReferences:ParentID" json:"parents,omitempty"`
+}
+
+// TableName 设置表名，避免与 SQL 关键字 GROUPS 冲突
+func (Group) TableName() string {
+	return "user_groups"
+}
+
+// BeforeCreate 创建前钩子
+func (g *Group) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if g.CreatedAt == 0 {
+		g.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// BeforeUpdate 更新前钩子
+func (g *Group) BeforeUpdate(tx *gorm.DB) error {
+	// 设置更新时间
+	g.UpdatedAt = NowUnix()
+	return nil
+}
+
+// GroupParent 用户组嵌套关系模型，GroupID 为子组，ParentID 为其所属的上级组
+type GroupParent struct {
+	GroupID   uint64 `gorm:"primaryKey;not null" json:"group_id"`
+	ParentID  uint64 `gorm:"primaryKey;not null;index" json:"parent_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (GroupParent) TableName() string {
+	return "group_parents"
+}
+
+// BeforeCreate 创建前钩子
+func (gp *GroupParent) BeforeCreate(tx *gorm.DB) error {
+	if gp.CreatedAt == 0 {
+		gp.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// GroupMember 用户组成员模型
+type GroupMember struct {
+	GroupID   uint64 `gorm:"primaryKey;not null" json:"group_id"`
+	UserID    uint64 `gorm:"primaryKey;not null;index" json:"user_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (GroupMember) TableName() string {
+	return "group_members"
+}
+
+// BeforeCreate 创建前钩子
+func (gm *GroupMember) BeforeCreate(tx *gorm.DB) error {
+	if gm.CreatedAt == 0 {
+		gm.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// GroupRole 用户组角色关联模型，TenantID 为0表示全局分配，在所有租户内生效
+type GroupRole struct {
+	GroupID   uint64 `gorm:"primaryKey" json:"group_id"`
+	RoleID    uint64 `gorm:"primaryKey;index" json:"role_id"`
+	TenantID  uint64 `gorm:"primaryKey;not null;default:0;index" json:"tenant_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (GroupRole) TableName() string {
+	return "group_roles"
+}
+
+// BeforeCreate 创建前钩子
+func (gr *GroupRole) BeforeCreate(tx *gorm.DB) error {
+	if gr.CreatedAt == 0 {
+		gr.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// GroupRoleAssignment 用户组的一条角色分配及组与角色的信息（非数据表模型）
+type GroupRoleAssignment struct {
+	GroupRole
+	GroupCode string `json:"group_code"`
+	GroupName string `json:"group_name"`
+	RoleCode  string `json:"role_code"`
+	RoleName  string `json:"role_name"`
+}
+
+// GroupChange 尚未写入的用户组成员或嵌套关系变更（非数据表模型），用于写入前预览受影响用户的角色分配
+// Members 中的用户将加入该组，Parents 不为nil时替换该组的上级组
+type GroupChange struct {
+	GroupID uint64
+	Members []uint64
+	Parents []uint64
+}
+
+// GroupInheritance 一条用户组嵌套关系及上级组信息（非数据表模型）
+type GroupInheritance struct {
+	GroupID    uint64 `json:"group_id"`
+	ParentID   uint64 `json:"parent_id"`
+	ParentCode string `json:"parent_code"`
+	ParentName string `json:"parent_name"`
+}
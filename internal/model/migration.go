@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
@@ -11,12 +12,32 @@ import (
 func AutoMigrate(db *gorm.DB) error {
 	slog.Info("开始数据库迁移")
 
+	// 注册自定义关联模型
+	if err := SetupJoinTables(db); err != nil {
+		slog.Error("注册关联模型失败", "error", err)
+		return err
+	}
+
 	// 迁移用户、角色和权限表
 	err := db.AutoMigrate(
 		&User{},
 		&Role{},
 		&Permission{},
+		&UserRole{},
+		&RolePermission{},
+		&UserPermission{},
+		&RoleParent{},
+		&Tenant{},
 		&UserRefreshToken{}, // 新增刷新令牌表
+		&SoDRule{},
+		&RelationTuple{},
+		&Group{},
+		&GroupParent{},
+		&GroupMember{},
+		&GroupRole{},
+		&OrgUnit{},
+		&OrgUnitMember{},
+		&RoleDataScopeUnit{},
 	)
 
 	if err != nil {
@@ -24,17 +45,98 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	// 旧版本的 user_roles 主键不含租户，需要重建
+	if err := migrateUserRolePrimaryKey(db); err != nil {
+		slog.Error("迁移用户角色主键失败", "error", err)
+		return err
+	}
+
+	// 旧版本的角色编码与名称全局唯一，改为租户内唯一后需要删除旧索引
+	if err := migrateRoleUniqueIndexes(db); err != nil {
+		slog.Error("迁移角色唯一索引失败", "error", err)
+		return err
+	}
+
 	slog.Info("数据库迁移完成")
 	return nil
 }
 
+// migrateUserRolePrimaryKey 将 user_roles 主键扩展为 (user_id, role_id, tenant_id)，
+// 使同一角色可以分配到多个租户；AutoMigrate 不会修改已有表的主键
+func migrateUserRolePrimaryKey(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	var count int64
+	err := db.Raw(`
+SELECT COUNT(*) FROM information_schema.key_column_usage kcu
+JOIN information_schema.table_constraints tc ON tc.constraint_name = kcu.constraint_name AND tc.table_name = kcu.table_name
+WHERE tc.table_name = 'user_roles' AND tc.constraint_type = 'PRIMARY KEY' AND kcu.column_name = 'tenant_id'`).Scan(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	slog.Info("重建 user_roles 主键以包含租户")
+	return db.Exec(`ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_pkey, ADD PRIMARY KEY (user_id, role_id, tenant_id)`).Error
+}
+
+// migrateRoleUniqueIndexes 删除 roles 表旧的 code、name 全局唯一索引，
+// 由 (tenant_id, code) 与 (tenant_id, name) 复合唯一索引取代；AutoMigrate 不会删除模型中已移除的索引
+func migrateRoleUniqueIndexes(db *gorm.DB) error {
+	for _, name := range []string{"idx_roles_code", "idx_roles_name"} {
+		if !db.Migrator().HasIndex(&Role{}, name) {
+			continue
+		}
+		slog.Info("删除角色旧唯一索引", "index", name)
+		if err := db.Migrator().DropIndex(&Role{}, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultPermissions 默认权限，新增接口权限时追加到这里，已部署的系统在启动时补齐
+var defaultPermissions = []Permission{
+	{Code: "user:list", Name: "用户列表", Description: "查看用户列表"},
+	{Code: "user:create", Name: "创建用户", Description: "创建新用户"},
+	{Code: "user:update", Name: "更新用户", Description: "更新用户信息"},
+	{Code: "user:delete", Name: "删除用户", Description: "删除用户"},
+	{Code: "role:list", Name: "角色列表", Description: "查看角色列表"},
+	{Code: "role:create", Name: "创建角色", Description: "创建新角色"},
+	{Code: "role:update", Name: "更新角色", Description: "更新角色信息"},
+	{Code: "role:delete", Name: "删除角色", Description: "删除角色"},
+	{Code: "permission:list", Name: "权限列表", Description: "查看权限列表"},
+	{Code: "permission:create", Name: "创建权限", Description: "创建新权限"},
+	{Code: "permission:update", Name: "更新权限", Description: "更新权限信息"},
+	{Code: "permission:delete", Name: "删除权限", Description: "删除权限"},
+	{Code: "tenant:list", Name: "租户列表", Description: "查看租户列表"},
+	{Code: "tenant:create", Name: "创建租户", Description: "创建新租户"},
+	{Code: "tenant:update", Name: "更新租户", Description: "更新租户信息"},
+	{Code: "tenant:delete", Name: "删除租户", Description: "删除租户"},
+	{Code: "group:list", Name: "用户组列表", Description: "查看用户组及其成员"},
+	{Code: "group:create", Name: "创建用户组", Description: "创建新用户组"},
+	{Code: "group:update", Name: "更新用户组", Description: "更新用户组信息、成员与角色"},
+	{Code: "group:delete", Name: "删除用户组", Description: "删除用户组"},
+	{Code: "org:list", Name: "组织单元列表", Description: "查看组织单元树及其成员"},
+	{Code: "org:create", Name: "创建组织单元", Description: "创建新组织单元"},
+	{Code: "org:update", Name: "更新组织单元", Description: "更新组织单元信息与成员"},
+	{Code: "org:delete", Name: "删除组织单元", Description: "删除组织单元"},
+	{Code: "policy:export", Name: "导出策略", Description: "导出角色权限策略"},
+	{Code: "policy:import", Name: "导入策略", Description: "导入角色权限策略"},
+	{Code: "relation:read", Name: "查看关系", Description: "查看与展开资源实例关系"},
+	{Code: "relation:write", Name: "写入关系", Description: "写入与删除资源实例关系"},
+	{Code: "relation:check", Name: "判定关系", Description: "判定主体对资源实例的关系并列出可访问的对象"},
+	{Code: "api:*", Name: "全部接口", Description: "按方法与路径校验时访问全部接口"},
+}
+
 // InitDefaultData 初始化默认数据
 func InitDefaultData(db *gorm.DB) error {
 	slog.Info("开始初始化默认数据")
 
-	// 检查是否已存在管理员角色
+	// 检查是否已存在全局管理员角色，租户内同编码的角色不影响初始化
 	var adminRoleCount int64
-	db.Model(&Role{}).Where("code = ?", "admin").Count(&adminRoleCount)
+	db.Model(&Role{}).Where("code = ? AND tenant_id = ?", "admin", GlobalTenantID).Count(&adminRoleCount)
 
 	if adminRoleCount == 0 {
 		// 创建默认角色
@@ -61,22 +163,7 @@ func InitDefaultData(db *gorm.DB) error {
 		}
 
 		// 创建默认权限
-		permissions := []Permission{
-			{Code: "user:list", Name: "用户列表", Description: "查看用户列表"},
-			{Code: "user:create", Name: "创建用户", Description: "创建新用户"},
-			{Code: "user:update", Name: "更新用户", Description: "更新用户信息"},
-			{Code: "user:delete", Name: "删除用户", Description: "删除用户"},
-			{Code: "role:list", Name: "角色列表", Description: "查看角色列表"},
-			{Code: "role:create", Name: "创建角色", Description: "创建新角色"},
-			{Code: "role:update", Name: "更新角色", Description: "更新角色信息"},
-			{Code: "role:delete", Name: "删除角色", Description: "删除角色"},
-			{Code: "permission:list", Name: "权限列表", Description: "查看权限列表"},
-			{Code: "permission:create", Name: "创建权限", Description: "创建新权限"},
-			{Code: "permission:update", Name: "更新权限", Description: "更新权限信息"},
-			{Code: "permission:delete", Name: "删除权限", Description: "删除权限"},
-		}
-
-		for _, p := range permissions {
+		for _, p := range defaultPermissions {
 			if err := db.Create(&p).Error; err != nil {
 				slog.Error("创建权限失败", "code", p.Code, "error", err)
 				return err
@@ -91,7 +178,7 @@ func InitDefaultData(db *gorm.DB) error {
 		}
 
 		// 重新查询管理员角色以获取ID
-		if err := db.First(&adminRole, "code = ?", "admin").Error; err != nil {
+		if err := db.First(&adminRole, "code = ? AND tenant_id = ?", "admin", GlobalTenantID).Error; err != nil {
 			slog.Error("查询管理员角色失败", "error", err)
 			return err
 		}
@@ -110,7 +197,7 @@ func InitDefaultData(db *gorm.DB) error {
 		}
 
 		// 重新查询普通用户角色以获取ID
-		if err := db.First(&userRole, "code = ?", "user").Error; err != nil {
+		if err := db.First(&userRole, "code = ? AND tenant_id = ?", "user", GlobalTenantID).Error; err != nil {
 			slog.Error("查询普通用户角色失败", "error", err)
 			return err
 		}
@@ -152,6 +239,58 @@ func InitDefaultData(db *gorm.DB) error {
 		}
 	}
 
+	// 升级后新增的默认权限不会走上面的首次初始化，需要单独补齐
+	if err := syncDefaultPermissions(db); err != nil {
+		slog.Error("补齐默认权限失败", "error", err)
+		return err
+	}
+
 	slog.Info("默认数据初始化完成")
 	return nil
 }
+
+// syncDefaultPermissions 按编码补齐缺少的默认权限并授予管理员角色。
+// 已存在的权限（包括已删除的）保持不变，因此管理员被收回的权限不会在重启后重新授予
+func syncDefaultPermissions(db *gorm.DB) error {
+	var codes []string
+	if err := db.Model(&Permission{}).Pluck("code", &codes).Error; err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		existing[code] = struct{}{}
+	}
+
+	var missing []Permission
+	for _, p := range defaultPermissions {
+		if _, ok := existing[p.Code]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i := range missing {
+			if err := tx.Create(&missing[i]).Error; err != nil {
+				slog.Error("创建权限失败", "code", missing[i].Code, "error", err)
+				return err
+			}
+			slog.Info("补齐默认权限", "code", missing[i].Code)
+		}
+
+		// 管理员角色已被删除时只创建权限
+		var adminRole Role
+		err := tx.Where("code = ? AND tenant_id = ? AND deleted_at IS NULL", "admin", GlobalTenantID).First(&adminRole).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&adminRole).Association("Permissions").Append(&missing)
+	})
+}
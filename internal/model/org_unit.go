@@ -0,0 +1,111 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// OrgUnit 组织单元模型（公司、部门等），通过 ParentID 组成树，ParentID 为0表示根节点
+type OrgUnit struct {
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	ParentID    uint64 `gorm:"not null;default:0;index" json:"parent_id"`
+	Code        string `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Name        string `gorm:"size:50;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Sort        int    `gorm:"not null;default:0" json:"sort"` // 同级排序，越小越靠前
+	CreatedAt   int64  `gorm:"not null" json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+	DeletedAt   *int64 `gorm:"index" json:"deleted_at"`
+}
+
+// TableName 设置表名
+func (OrgUnit) TableName() string {
+	return "org_units"
+}
+
+// BeforeCreate 创建前钩子
+func (o *OrgUnit) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if o.CreatedAt == 0 {
+		o.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// BeforeUpdate 更新前钩子
+func (o *OrgUnit) BeforeUpdate(tx *gorm.DB) error {
+	// 设置更新时间
+	o.UpdatedAt = NowUnix()
+	return nil
+}
+
+// OrgUnitMember 组织单元成员模型，一个用户可以属于多个组织单元
+type OrgUnitMember struct {
+	OrgUnitID uint64 `gorm:"primaryKey;not null" json:"org_unit_id"`
+	UserID    uint64 `gorm:"primaryKey;not null;index" json:"user_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (OrgUnitMember) TableName() string {
+	return "org_unit_members"
+}
+
+// BeforeCreate 创建前钩子
+func (om *OrgUnitMember) BeforeCreate(tx *gorm.DB) error {
+	if om.CreatedAt == 0 {
+		om.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// 角色的数据范围
+const (
+	// DataScopeAll 全部数据
+	DataScopeAll = "all"
+	// DataScopeUnit 本人所属组织单元的数据
+	DataScopeUnit = "unit"
+	// DataScopeUnitAndChildren 本人所属组织单元及其全部下级单元的数据
+	DataScopeUnitAndChildren = "unit_and_children"
+	// DataScopeSelf 仅本人的数据
+	DataScopeSelf = "self"
+	// DataScopeCustom 角色指定的组织单元的数据，见 RoleDataScopeUnit
+	DataScopeCustom = "custom"
+)
+
+// IsValidDataScope 判断是否为支持的数据范围
+func IsValidDataScope(scope string) bool {
+	switch scope {
+	case DataScopeAll, DataScopeUnit, DataScopeUnitAndChildren, DataScopeSelf, DataScopeCustom:
+		return true
+	}
+	return false
+}
+
+// RoleDataScopeUnit 数据范围为 custom 的角色可访问的组织单元
+type RoleDataScopeUnit struct {
+	RoleID    uint64 `gorm:"primaryKey;not null" json:"role_id"`
+	OrgUnitID uint64 `gorm:"primaryKey;not null;index" json:"org_unit_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (RoleDataScopeUnit) TableName() string {
+	return "role_data_scope_units"
+}
+
+// BeforeCreate 创建前钩子
+func (ru *RoleDataScopeUnit) BeforeCreate(tx *gorm.DB) error {
+	if ru.CreatedAt == 0 {
+		ru.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// DataScope 用户解析后的数据范围（非数据表模型），决定其可以访问哪些用户的数据
+// All 为 true 时不受限制；否则可访问 UnitIDs 中任一组织单元的成员，Self 为 true 时还可访问本人
+type DataScope struct {
+	UserID  uint64   `json:"user_id"`
+	All     bool     `json:"all"`
+	UnitIDs []uint64 `json:"unit_ids"`
+	Self    bool     `json:"self"`
+}
@@ -4,12 +4,30 @@ import (
 	"gorm.io/gorm"
 )
 
-// Permission 权限模型
+// 权限节点类型
+const (
+	// PermissionTypeModule 模块，用于组织菜单
+	PermissionTypeModule = "module"
+	// PermissionTypeMenu 菜单
+	PermissionTypeMenu = "menu"
+	// PermissionTypeButton 页面内的按钮或操作
+	PermissionTypeButton = "button"
+	// PermissionTypeAPI 接口
+	PermissionTypeAPI = "api"
+)
+
+// Permission 权限模型，通过 ParentID 组织为模块、菜单、按钮与接口的树
 type Permission struct {
 	ID          uint64 `gorm:"primaryKey" json:"id"`
 	Code        string `gorm:"size:100;not null;uniqueIndex" json:"code"`
 	Name        string `gorm:"size:100;not null;uniqueIndex" json:"name"`
 	Description string `gorm:"type:text" json:"description"`
+	ParentID    uint64 `gorm:"not null;default:0;index" json:"parent_id"`       // 父级权限，0表示根节点
+	Type        string `gorm:"size:20;not null;default:api" json:"type"`        // 节点类型：module、menu、button、api
+	Sort        int    `gorm:"not null;default:0" json:"sort"`                  // 同级排序，升序
+	Icon        string `gorm:"size:100" json:"icon"`                            // 菜单图标
+	Method      string `gorm:"size:10;index:idx_permissions_api" json:"method"` // 接口方法，仅接口权限使用
+	Route       string `gorm:"size:255;index:idx_permissions_api" json:"route"` // 前端路由或接口路径
 	CreatedAt   int64  `gorm:"not null" json:"created_at"`
 	UpdatedAt   int64  `json:"updated_at"`
 	DeletedAt   *int64 `gorm:"index" json:"deleted_at"`
@@ -27,6 +45,10 @@ func (p *Permission) BeforeCreate(tx *gorm.DB) error {
 	if p.CreatedAt == 0 {
 		p.CreatedAt = NowUnix()
 	}
+	// 未指定类型时视为接口权限
+	if p.Type == "" {
+		p.Type = PermissionTypeAPI
+	}
 	return nil
 }
 
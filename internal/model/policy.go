@@ -0,0 +1,40 @@
+package model
+
+// UserRoleBinding 一条用户角色分配及用户名、角色编码与租户编码（非数据表模型），用于导出策略
+type UserRoleBinding struct {
+	UserID     uint64 `json:"user_id"`
+	Username   string `json:"username"`
+	RoleID     uint64 `json:"role_id"`
+	RoleCode   string `json:"role_code"`
+	TenantID   uint64 `json:"tenant_id"`
+	TenantCode string `json:"tenant_code"` // 全局分配时为空
+}
+
+// PolicyGrant 导入策略时的一条角色权限授予变更，Role 可以是同一事务内新建的角色
+type PolicyGrant struct {
+	Role         *Role
+	PermissionID uint64
+	Effect       string
+}
+
+// PolicyAssignment 导入策略时的一条用户角色分配变更，Role 可以是同一事务内新建的角色
+type PolicyAssignment struct {
+	UserID   uint64
+	Role     *Role
+	TenantID uint64
+}
+
+// PolicyChange 导入策略时需要在同一事务内执行的全部变更（非数据表模型）
+type PolicyChange struct {
+	CreateRoles       []*Role
+	AddGrants         []PolicyGrant
+	RemoveGrants      []PolicyGrant
+	AddAssignments    []PolicyAssignment
+	RemoveAssignments []PolicyAssignment
+}
+
+// Empty 判断是否没有任何变更
+func (c *PolicyChange) Empty() bool {
+	return len(c.CreateRoles) == 0 && len(c.AddGrants) == 0 && len(c.RemoveGrants) == 0 &&
+		len(c.AddAssignments) == 0 && len(c.RemoveAssignments) == 0
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// RelationTuple 关系元组模型 object#relation@subject，表示主体对资源实例持有某种关系
+// SubjectRelation 非空时主体为用户集，即持有 subject_type:subject_id 该关系的全部主体
+type RelationTuple struct {
+	ID              uint64 `gorm:"primaryKey" json:"id"`
+	ObjectType      string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple,priority:1" json:"object_type"`
+	ObjectID        string `gorm:"size:128;not null;uniqueIndex:idx_relation_tuple,priority:2" json:"object_id"`
+	Relation        string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple,priority:3" json:"relation"`
+	SubjectType     string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple,priority:4;index:idx_relation_subject,priority:1" json:"subject_type"`
+	SubjectID       string `gorm:"size:128;not null;uniqueIndex:idx_relation_tuple,priority:5;index:idx_relation_subject,priority:2" json:"subject_id"`
+	SubjectRelation string `gorm:"size:64;not null;default:'';uniqueIndex:idx_relation_tuple,priority:6;index:idx_relation_subject,priority:3" json:"subject_relation"`
+	CreatedAt       int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (RelationTuple) TableName() string {
+	return "relation_tuples"
+}
+
+// BeforeCreate 创建前钩子
+func (t *RelationTuple) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if t.CreatedAt == 0 {
+		t.CreatedAt = NowUnix()
+	}
+	return nil
+}
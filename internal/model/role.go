@@ -6,15 +6,22 @@ import (
 
 // Role 角色模型
 type Role struct {
-	ID          uint64       `gorm:"primaryKey" json:"id"`
-	Code        string       `gorm:"size:50;not null;uniqueIndex" json:"code"`
-	Name        string       `gorm:"size:50;not null;uniqueIndex" json:"name"`
-	Description string       `gorm:"type:text" json:"description"`
-	CreatedAt   int64        `gorm:"not null" json:"created_at"`
-	UpdatedAt   int64        `json:"updated_at"`
-	DeletedAt   *int64       `gorm:"index" json:"deleted_at"`
-	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
-	Users       []User       `gorm:"many2many:user_roles;" json:"users,omitempty"`
+	ID              uint64       `gorm:"primaryKey" json:"id"`
+	Code            string       `gorm:"size:50;not null;uniqueIndex:idx_roles_tenant_code,priority:2" json:"code"`
+	Name            string       `gorm:"size:50;not null;uniqueIndex:idx_roles_tenant_name,priority:2" json:"name"`
+	Description     string       `gorm:"type:text" json:"description"`
+	TenantID        uint64       `gorm:"not null;default:0;index;uniqueIndex:idx_roles_tenant_code,priority:1;uniqueIndex:idx_roles_tenant_name,priority:1" json:"tenant_id"` // 所属租户，0表示全局角色
+	TemplateCode    string       `gorm:"size:50;not null;default:'';index" json:"template_code"`                                                                              // 创建该角色的模板编码，为空表示非模板创建
+	TemplateVersion int          `gorm:"not null;default:0" json:"template_version"`                                                                                          // 最近一次同步的模板版本
+	DataScope       string       `gorm:"size:20;not null;default:all" json:"data_scope"`                                                                                      // 数据范围，取值见 DataScopeAll 等常量
+	CreatedAt       int64        `gorm:"not null" json:"created_at"`
+	UpdatedAt       int64        `json:"updated_at"`
+	DeletedAt       *int64       `gorm:"index" json:"deleted_at"`
+	Permissions     []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
+	// PermissionGrants 角色权限关联记录，用于读取每个权限的授予效果
+	PermissionGrants []RolePermission `gorm:"foreignKey:RoleID" json:"-"`
+	Users            []User           `gorm:"many2many:user_roles;" json:"users,omitempty"`
+	Parents          []Role           `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID" json:"parents,omitempty"`
 }
 
 // TableName 设置表名
@@ -28,6 +35,10 @@ func (r *Role) BeforeCreate(tx *gorm.DB) error {
 	if r.CreatedAt == 0 {
 		r.CreatedAt = NowUnix()
 	}
+	// 未指定数据范围时默认为全部数据
+	if r.DataScope == "" {
+		r.DataScope = DataScopeAll
+	}
 	return nil
 }
 
@@ -38,10 +49,20 @@ func (r *Role) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// 权限授予效果
+const (
+	// EffectAllow 允许
+	EffectAllow = "allow"
+	// EffectDeny 拒绝，任一角色的拒绝优先于所有允许
+	EffectDeny = "deny"
+)
+
 // RolePermission 角色权限关联模型
 type RolePermission struct {
 	RoleID       uint64 `gorm:"primaryKey;not null" json:"role_id"`
 	PermissionID uint64 `gorm:"primaryKey;not null" json:"permission_id"`
+	Effect       string `gorm:"size:10;not null;default:allow" json:"effect"`
+	Condition    string `gorm:"size:512;not null;default:''" json:"condition"` // 条件表达式，为空表示无条件授予
 	CreatedAt    int64  `gorm:"not null" json:"created_at"`
 }
 
@@ -52,6 +73,68 @@ func (RolePermission) TableName() string {
 
 // BeforeCreate 创建前钩子
 func (rp *RolePermission) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if rp.CreatedAt == 0 {
+		rp.CreatedAt = NowUnix()
+	}
+	// 未指定效果时默认为允许
+	if rp.Effect == "" {
+		rp.Effect = EffectAllow
+	}
+	return nil
+}
+
+// PermissionGrant 用户经由某个角色或直接获得的一条权限授予记录（非数据表模型）
+// 直接授予给用户的记录 RoleID 为0，角色编码与名称为空
+type PermissionGrant struct {
+	RoleID         uint64 `json:"role_id"`
+	RoleCode       string `json:"role_code"`
+	RoleName       string `json:"role_name"`
+	PermissionID   uint64 `json:"permission_id"`
+	PermissionCode string `json:"permission_code"`
+	Effect         string `json:"effect"`
+	Condition      string `json:"condition"` // 条件表达式，直接授予用户的权限没有条件
+}
+
+// IsDirect 判断授予记录是否直接授予给用户，而非经由角色获得
+func (g *PermissionGrant) IsDirect() bool {
+	return g.RoleID == 0
+}
+
+// IsConditional 判断授予记录是否附带条件表达式
+func (g *PermissionGrant) IsConditional() bool {
+	return g.Condition != ""
+}
+
+// RoleInheritance 一条角色继承关系及父角色信息（非数据表模型）
+type RoleInheritance struct {
+	RoleID     uint64 `json:"role_id"`
+	ParentID   uint64 `json:"parent_id"`
+	ParentCode string `json:"parent_code"`
+	ParentName string `json:"parent_name"`
+}
+
+// RoleTemplateSync 模板同步需要写入单个角色的变更（非数据表模型）
+type RoleTemplateSync struct {
+	RoleID  uint64
+	Grants  []RolePermission // 为nil时授予记录保持不变
+	Version int
+}
+
+// RoleParent 角色继承关系模型，子角色继承父角色的全部权限
+type RoleParent struct {
+	RoleID    uint64 `gorm:"primaryKey;not null" json:"role_id"`
+	ParentID  uint64 `gorm:"primaryKey;not null;index" json:"parent_id"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (RoleParent) TableName() string {
+	return "role_parents"
+}
+
+// BeforeCreate 创建前钩子
+func (rp *RoleParent) BeforeCreate(tx *gorm.DB) error {
 	// 设置创建时间
 	if rp.CreatedAt == 0 {
 		rp.CreatedAt = NowUnix()
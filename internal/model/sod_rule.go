@@ -0,0 +1,58 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// 职责分离约束类型
+const (
+	// SoDStatic 静态职责分离，用户不能同时被分配规则中超过上限数量的角色
+	SoDStatic = "static"
+	// SoDDynamic 动态职责分离，角色可以同时分配，但同一会话中激活的规则角色不能超过上限
+	SoDDynamic = "dynamic"
+)
+
+// SoDRule 职责分离约束模型
+// 用户持有（静态）或在同一会话中激活（动态）的规则角色数不能超过 MaxRoles，
+// 持有某个角色同时视为持有其继承的全部祖先角色
+type SoDRule struct {
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Type        string `gorm:"size:10;not null;default:static" json:"type"`
+	MaxRoles    int    `gorm:"not null;default:1" json:"max_roles"` // 最多可持有或激活的规则角色数
+	CreatedAt   int64  `gorm:"not null" json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+	DeletedAt   *int64 `gorm:"index" json:"deleted_at"`
+	Roles       []Role `gorm:"many2many:sod_rule_roles;joinForeignKey:RuleID;joinReferences:RoleID" json:"roles,omitempty"`
+}
+
+// TableName 设置表名
+func (SoDRule) TableName() string {
+	return "sod_rules"
+}
+
+// BeforeCreate 创建前钩子
+func (r *SoDRule) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if r.CreatedAt == 0 {
+		r.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// BeforeUpdate 更新前钩子
+func (r *SoDRule) BeforeUpdate(tx *gorm.DB) error {
+	// 设置更新时间
+	r.UpdatedAt = NowUnix()
+	return nil
+}
+
+// RoleIDs 返回规则包含的角色ID
+func (r *SoDRule) RoleIDs() []uint64 {
+	ids := make([]uint64, 0, len(r.Roles))
+	for _, role := range r.Roles {
+		ids = append(ids, role.ID)
+	}
+	return ids
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// GlobalTenantID 全局租户ID，租户ID为0的角色或角色分配在所有租户内生效
+const GlobalTenantID uint64 = 0
+
+// Tenant 租户模型
+type Tenant struct {
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	Code        string `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Name        string `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	CreatedAt   int64  `gorm:"not null" json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+	DeletedAt   *int64 `gorm:"index" json:"deleted_at"`
+}
+
+// TableName 设置表名
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// BeforeCreate 创建前钩子
+func (t *Tenant) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if t.CreatedAt == 0 {
+		t.CreatedAt = NowUnix()
+	}
+	return nil
+}
+
+// BeforeUpdate 更新前钩子
+func (t *Tenant) BeforeUpdate(tx *gorm.DB) error {
+	// 设置更新时间
+	t.UpdatedAt = NowUnix()
+	return nil
+}
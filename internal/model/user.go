@@ -37,11 +37,38 @@ func (u *User) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
-// UserRole 用户角色关联模型
+// UserRole 用户角色关联模型，TenantID 为0表示全局分配，在所有租户内生效
 type UserRole struct {
-	UserID    uint64 `gorm:"primaryKey" json:"user_id"`
-	RoleID    uint64 `gorm:"primaryKey" json:"role_id"`
-	CreatedAt int64  `gorm:"not null;autoCreateTime" json:"created_at"`
+	UserID   uint64 `gorm:"primaryKey" json:"user_id"`
+	RoleID   uint64 `gorm:"primaryKey" json:"role_id"`
+	TenantID uint64 `gorm:"primaryKey;not null;default:0;index" json:"tenant_id"`
+	Validity
+	CreatedAt int64 `gorm:"not null;autoCreateTime" json:"created_at"`
+}
+
+// RoleAssignment 用户的一条角色分配及所分配角色的状态（非数据表模型），用于解释权限判定
+// GroupID 为经由用户组获得该角色时分配角色的组，直接分配为0
+type RoleAssignment struct {
+	UserRole
+	GroupID       uint64 `json:"group_id,omitempty"`
+	RoleCode      string `json:"role_code"`
+	RoleName      string `json:"role_name"`
+	RoleDeletedAt *int64 `json:"role_deleted_at"`
+}
+
+// Validity 角色分配的生效区间（Unix时间戳，秒），为nil的一端不限制
+// 区间为左闭右开：ValidFrom 时刻起生效，ValidUntil 时刻起失效
+type Validity struct {
+	ValidFrom  *int64 `gorm:"index" json:"valid_from,omitempty"`
+	ValidUntil *int64 `gorm:"index" json:"valid_until,omitempty"`
+}
+
+// ActiveAt 判断分配在指定时刻是否生效
+func (v Validity) ActiveAt(now int64) bool {
+	if v.ValidFrom != nil && *v.ValidFrom > now {
+		return false
+	}
+	return v.ValidUntil == nil || *v.ValidUntil > now
 }
 
 // TableName 设置表名
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// UserPermission 直接授予用户的权限，用于不值得单独建角色的个别例外
+// TenantID 为0表示全局授予，在所有租户内生效
+type UserPermission struct {
+	UserID       uint64 `gorm:"primaryKey" json:"user_id"`
+	PermissionID uint64 `gorm:"primaryKey;index" json:"permission_id"`
+	TenantID     uint64 `gorm:"primaryKey;not null;default:0" json:"tenant_id"`
+	Effect       string `gorm:"size:10;not null;default:allow" json:"effect"`
+	CreatedAt    int64  `gorm:"not null" json:"created_at"`
+}
+
+// TableName 设置表名
+func (UserPermission) TableName() string {
+	return "user_permissions"
+}
+
+// BeforeCreate 创建前钩子
+func (up *UserPermission) BeforeCreate(tx *gorm.DB) error {
+	// 设置创建时间
+	if up.CreatedAt == 0 {
+		up.CreatedAt = NowUnix()
+	}
+	// 未指定效果时默认为允许
+	if up.Effect == "" {
+		up.Effect = EffectAllow
+	}
+	return nil
+}
+
+// UserPermissionDetail 一条直接授予及所授予权限的信息（非数据表模型）
+type UserPermissionDetail struct {
+	UserPermission
+	PermissionCode string `json:"permission_code"`
+	PermissionName string `json:"permission_name"`
+}
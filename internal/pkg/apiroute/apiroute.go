@@ -0,0 +1,168 @@
+package apiroute
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Prefix 接口权限编码的首段
+const Prefix = "api"
+
+// AnyMethod 匹配任意请求方法
+const AnyMethod = "*"
+
+// Route 一条接口路由
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// String 返回 "方法 路径" 形式的描述，如 "POST /api/v1/users/list"
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Code 返回路由对应的接口权限编码，路径的每一段作为编码的一个分段，
+// 如 POST /api/v1/users/list 对应 api:post:api:v1:users:list，
+// 因此可以用 api:*:api:v1:users:* 授予某个资源下的全部接口
+func (r Route) Code() string {
+	segments := []string{Prefix, strings.ToLower(r.Method)}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part = sanitize(part); part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return strings.Join(segments, ":")
+}
+
+// Collect 列出 Fiber 应用中注册在指定前缀下的接口路由，按路径与方法排序，
+// 忽略 Use 注册的中间件以及 Fiber 为 GET 自动生成的 HEAD 路由
+func Collect(app *fiber.App, prefix string) []Route {
+	seen := make(map[Route]struct{})
+	var routes []Route
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+
+		r := Route{Method: route.Method, Path: route.Path}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		routes = append(routes, r)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// IsPattern 判断路由是否为匹配模式，即方法为 * 或路径包含参数与通配符
+func (r Route) IsPattern() bool {
+	return r.Method == AnyMethod || strings.ContainsAny(r.Path, ":*")
+}
+
+// Match 判断请求的方法与路径是否符合路由模式
+// 路径按 / 分段比较：以 : 开头的分段匹配任意一个非空分段，
+// * 出现在末尾时匹配剩余的零个或多个分段，出现在中间时匹配任意一个分段；
+// 包含 . 或 .. 分段的路径未经规范化，总是不匹配，外部传入的路径应先经过 CleanPath
+func (r Route) Match(method, path string) bool {
+	return r.Compile().Match(method, path)
+}
+
+// Matcher 预先拆分路径模式的路由，重复匹配时无需每次解析模式
+type Matcher struct {
+	method   string
+	patterns []string
+}
+
+// Compile 将路由模式编译为 Matcher
+func (r Route) Compile() Matcher {
+	return Matcher{method: r.Method, patterns: splitPath(r.Path)}
+}
+
+// Match 判断请求的方法与路径是否符合路由模式，匹配规则见 Route.Match
+func (m Matcher) Match(method, path string) bool {
+	if m.method != AnyMethod && !strings.EqualFold(m.method, method) {
+		return false
+	}
+
+	segments := splitPath(path)
+	for _, segment := range segments {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for i, pattern := range m.patterns {
+		if pattern == "*" && i == len(m.patterns)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if pattern == "*" || strings.HasPrefix(pattern, ":") {
+			continue
+		}
+		if pattern != segments[i] {
+			return false
+		}
+	}
+	return len(m.patterns) == len(segments)
+}
+
+// CleanPath 规范化反向代理转发的原始请求URI以用于规则匹配：去掉查询参数与片段，
+// 解码百分号编码后按 path.Clean 消除 . 与 .. 分段，使 /public/../admin 与 /public/%2e%2e/admin
+// 都按上游实际处理的 /admin 匹配；包含编码的斜杠、反斜杠、无法解码或解码后仍含有编码字符
+// （多重编码）的路径，上游可能作出不同解释，返回 false
+func CleanPath(uri string) (string, bool) {
+	raw := uri
+	if idx := strings.IndexAny(raw, "?#"); idx >= 0 {
+		raw = raw[:idx]
+	}
+
+	lower := strings.ToLower(raw)
+	if strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return "", false
+	}
+
+	decoded, err := url.PathUnescape(raw)
+	if err != nil || strings.ContainsAny(decoded, "%\\\x00") {
+		return "", false
+	}
+
+	cleaned := path.Clean("/" + decoded)
+	for _, segment := range splitPath(cleaned) {
+		if segment == ".." {
+			return "", false
+		}
+	}
+	return cleaned, true
+}
+
+// splitPath 将路径拆分为非空分段，忽略首尾及重复的斜杠
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(ch rune) bool { return ch == '/' })
+}
+
+// sanitize 将路径片段转换为合法的权限编码分段，参数与通配符等字符替换为下划线
+func sanitize(part string) string {
+	return strings.Map(func(ch rune) rune {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+			return ch
+		case ch == '_' || ch == '-' || ch == '.':
+			return ch
+		default:
+			return '_'
+		}
+	}, part)
+}
@@ -0,0 +1,100 @@
+// Package condition 实现附加在权限授予上的条件表达式
+//
+// 表达式语法：
+//
+//	expr      = and { "||" and }
+//	and       = unary { "&&" unary }
+//	unary     = "!" unary | compare
+//	compare   = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" ) operand ]
+//	operand   = attribute | literal | list | "(" expr ")"
+//	attribute = ( "subject" | "resource" | "request" ) "." name { "." name }
+//	literal   = number | string | "true" | "false" | "null" | IPv4 | CIDR | HH:MM
+//	list      = "[" [ operand { "," operand } ] "]"
+//
+// 例如 resource.owner_id == subject.id、request.ip in 10.0.0.0/8、
+// request.time >= 09:00 && request.time < 18:00。表达式只能读取求值时传入的属性，
+// 不能调用函数或修改任何状态，长度与嵌套层数均有上限；缺失的属性视为 null。
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxLength 表达式最大长度，与数据库字段长度保持一致
+	MaxLength = 512
+	// MaxDepth 括号、取反与列表的最大嵌套层数
+	MaxDepth = 32
+)
+
+// 可在表达式中引用的属性命名空间
+const (
+	NamespaceSubject  = "subject"  // 当前用户，由服务端填写
+	NamespaceResource = "resource" // 被访问的资源
+	NamespaceRequest  = "request"  // 请求上下文，如 ip、time
+)
+
+// 定义错误类型
+var (
+	ErrTooLong      = errors.New("条件表达式长度超出限制")
+	ErrSyntax       = errors.New("条件表达式语法错误")
+	ErrTypeMismatch = errors.New("条件表达式的操作数类型不匹配")
+)
+
+// Attributes 求值时可用的属性，键为命名空间，值为该命名空间下的属性，可以嵌套
+type Attributes map[string]map[string]interface{}
+
+// Expr 编译后的条件表达式，创建后只读，并发安全
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile 编译条件表达式，语法错误、引用未知命名空间或操作数类型明显不匹配时返回错误
+func Compile(source string) (*Expr, error) {
+	source = strings.TrimSpace(source)
+	if len(source) > MaxLength {
+		return nil, ErrTooLong
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, syntaxError(tok.pos, "多余的 %q", tok.text)
+	}
+	if err := requireBool(root, 0); err != nil {
+		return nil, err
+	}
+
+	return &Expr{source: source, root: root}, nil
+}
+
+// Validate 校验条件表达式能否编译
+func Validate(source string) error {
+	_, err := Compile(source)
+	return err
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval 使用给定属性求值，结果不是布尔值或比较的操作数类型不匹配时返回错误
+func (e *Expr) Eval(attrs Attributes) (bool, error) {
+	return truth(e.root, attrs)
+}
+
+// syntaxError 生成带位置的语法错误
+func syntaxError(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("%w：位置 %d，%s", ErrSyntax, pos+1, fmt.Sprintf(format, args...))
+}
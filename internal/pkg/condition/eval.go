@@ -0,0 +1,332 @@
+package condition
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// node 语法树节点
+type node interface {
+	kind() kind
+	eval(attrs Attributes) (interface{}, error)
+}
+
+// timeOfDay 一天中的时刻，自零点起的分钟数
+type timeOfDay int
+
+// literalNode 字面量
+type literalNode struct {
+	value     interface{}
+	valueKind kind
+}
+
+func (n *literalNode) kind() kind { return n.valueKind }
+
+func (n *literalNode) eval(Attributes) (interface{}, error) { return n.value, nil }
+
+// attributeNode 属性引用，属性缺失时为 null
+type attributeNode struct {
+	namespace string
+	keys      []string
+}
+
+func (n *attributeNode) kind() kind { return kindAny }
+
+func (n *attributeNode) eval(attrs Attributes) (interface{}, error) {
+	var current interface{} = attrs[n.namespace]
+	for _, key := range n.keys {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = values[key]
+	}
+	return current, nil
+}
+
+// listNode 列表字面量
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) kind() kind { return kindList }
+
+func (n *listNode) eval(attrs Attributes) (interface{}, error) {
+	values := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// notNode 取反
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) kind() kind { return kindBool }
+
+func (n *notNode) eval(attrs Attributes) (interface{}, error) {
+	value, err := truth(n.operand, attrs)
+	return !value, err
+}
+
+// logicalNode 短路求值的与、或运算
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) kind() kind { return kindBool }
+
+func (n *logicalNode) eval(attrs Attributes) (interface{}, error) {
+	left, err := truth(n.left, attrs)
+	if err != nil {
+		return false, err
+	}
+	if left != n.and {
+		return left, nil
+	}
+	return truth(n.right, attrs)
+}
+
+// compareNode 比较运算
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) kind() kind { return kindBool }
+
+func (n *compareNode) eval(attrs Attributes) (interface{}, error) {
+	left, err := n.left.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	right, err := n.right.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	left, right = normalize(left), normalize(right)
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	}
+
+	cmp, ok, err := compare(left, right)
+	if err != nil || !ok {
+		return false, err
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// truth 求值并要求结果为布尔值，属性缺失时为 false
+func truth(n node, attrs Attributes) (bool, error) {
+	value, err := n.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	switch v := normalize(value).(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, ErrTypeMismatch
+}
+
+// normalize 将调用方传入的属性值统一为求值使用的类型
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return items
+	}
+	return value
+}
+
+// equal 判断两个值是否相等，数值、时刻与IP地址可以与其字符串形式比较
+func equal(a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	switch x := a.(type) {
+	case float64:
+		y, ok := toNumber(b)
+		return ok && x == y
+	case timeOfDay:
+		y, ok := toTime(b)
+		return ok && x == y
+	case net.IP:
+		y, ok := toIP(b)
+		return ok && x.Equal(y)
+	case string:
+		switch b.(type) {
+		case float64, timeOfDay, net.IP:
+			return equal(b, a)
+		}
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return false
+}
+
+// compare 比较两个值的大小，任一侧为 null 时 ok 为 false
+func compare(a, b interface{}) (cmp int, ok bool, err error) {
+	if a == nil || b == nil {
+		return 0, false, nil
+	}
+
+	switch {
+	case isKind[float64](a) || isKind[float64](b):
+		x, ok1 := toNumber(a)
+		y, ok2 := toNumber(b)
+		if !ok1 || !ok2 {
+			return 0, false, ErrTypeMismatch
+		}
+		return compareOrdered(x, y), true, nil
+	case isKind[timeOfDay](a) || isKind[timeOfDay](b):
+		x, ok1 := toTime(a)
+		y, ok2 := toTime(b)
+		if !ok1 || !ok2 {
+			return 0, false, ErrTypeMismatch
+		}
+		return compareOrdered(x, y), true, nil
+	}
+
+	x, ok1 := a.(string)
+	y, ok2 := b.(string)
+	if !ok1 || !ok2 {
+		return 0, false, ErrTypeMismatch
+	}
+	return strings.Compare(x, y), true, nil
+}
+
+// contains 判断值是否属于列表或网段，容器为 null 时为 false
+func contains(container, item interface{}) (bool, error) {
+	switch c := container.(type) {
+	case nil:
+		return false, nil
+	case *net.IPNet:
+		ip, ok := toIP(normalize(item))
+		return ok && c.Contains(ip), nil
+	case []interface{}:
+		for _, element := range c {
+			if equal(element, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		// 属性中的网段
+		_, network, err := net.ParseCIDR(c)
+		if err != nil {
+			return false, ErrTypeMismatch
+		}
+		return contains(network, item)
+	}
+	return false, ErrTypeMismatch
+}
+
+func isKind[T any](value interface{}) bool {
+	_, ok := value.(T)
+	return ok
+}
+
+func compareOrdered[T float64 | timeOfDay](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toTime(value interface{}) (timeOfDay, bool) {
+	switch v := value.(type) {
+	case timeOfDay:
+		return v, true
+	case string:
+		return parseTimeOfDay(v)
+	}
+	return 0, false
+}
+
+func toIP(value interface{}) (net.IP, bool) {
+	switch v := value.(type) {
+	case net.IP:
+		return v, true
+	case string:
+		ip := net.ParseIP(v)
+		return ip, ip != nil
+	}
+	return nil, false
+}
+
+// parseTimeOfDay 解析 HH:MM 形式的时刻
+func parseTimeOfDay(text string) (timeOfDay, bool) {
+	hours, minutes, ok := strings.Cut(text, ":")
+	if !ok || len(minutes) != 2 || len(hours) == 0 || len(hours) > 2 {
+		return 0, false
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 23 {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 {
+		return 0, false
+	}
+	return timeOfDay(h*60 + m), true
+}
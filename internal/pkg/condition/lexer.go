@@ -0,0 +1,154 @@
+package condition
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// 词法单元类型
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokLiteral
+	tokOperator
+)
+
+// token 词法单元，字面量已解析为值
+type token struct {
+	typ   tokenType
+	text  string
+	pos   int
+	value interface{}
+	kind  kind
+}
+
+// 双字符与单字符运算符
+var (
+	doubleOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+	singleOperators = "<>!()[],"
+)
+
+// tokenize 将表达式切分为词法单元
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case isLetter(c):
+			start := i
+			for i < len(source) && (isLetter(source[i]) || isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			text := source[start:i]
+			switch text {
+			case "true", "false":
+				tokens = append(tokens, token{typ: tokLiteral, text: text, pos: start, value: text == "true", kind: kindBool})
+			case "null":
+				tokens = append(tokens, token{typ: tokLiteral, text: text, pos: start, kind: kindNull})
+			case "in":
+				tokens = append(tokens, token{typ: tokOperator, text: text, pos: start})
+			default:
+				tokens = append(tokens, token{typ: tokIdent, text: text, pos: start})
+			}
+
+		case isDigit(c) || (c == '-' && i+1 < len(source) && isDigit(source[i+1])):
+			start := i
+			i++
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.' || source[i] == ':' || source[i] == '/') {
+				i++
+			}
+			tok, err := numericLiteral(source[start:i], start)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+
+		case c == '"' || c == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for ; i < len(source) && source[i] != c; i++ {
+				if source[i] == '\\' && i+1 < len(source) {
+					i++
+				}
+				b.WriteByte(source[i])
+			}
+			if i >= len(source) {
+				return nil, syntaxError(start, "字符串缺少结束引号")
+			}
+			i++
+			tokens = append(tokens, token{typ: tokLiteral, text: source[start:i], pos: start, value: b.String(), kind: kindString})
+
+		default:
+			matched := false
+			for _, op := range doubleOperators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{typ: tokOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			if strings.IndexByte(singleOperators, c) >= 0 {
+				tokens = append(tokens, token{typ: tokOperator, text: string(c), pos: i})
+				i++
+				continue
+			}
+			if c == '=' {
+				return nil, syntaxError(i, "比较相等应使用 ==")
+			}
+			return nil, syntaxError(i, "无法识别的字符 %q", c)
+		}
+	}
+
+	return append(tokens, token{typ: tokEOF, pos: len(source)}), nil
+}
+
+// numericLiteral 解析以数字开头的字面量：网段、IPv4 地址、时刻（HH:MM）或数值
+func numericLiteral(text string, pos int) (token, error) {
+	tok := token{typ: tokLiteral, text: text, pos: pos}
+	switch {
+	case strings.Contains(text, "/"):
+		_, network, err := net.ParseCIDR(text)
+		if err != nil {
+			return tok, syntaxError(pos, "无效的网段 %s", text)
+		}
+		tok.value, tok.kind = network, kindNetwork
+	case strings.Count(text, ".") == 3:
+		ip := net.ParseIP(text).To4()
+		if ip == nil {
+			return tok, syntaxError(pos, "无效的IP地址 %s", text)
+		}
+		tok.value, tok.kind = ip, kindIP
+	case strings.Contains(text, ":"):
+		t, ok := parseTimeOfDay(text)
+		if !ok {
+			return tok, syntaxError(pos, "无效的时刻 %s，格式应为 HH:MM", text)
+		}
+		tok.value, tok.kind = t, kindTime
+	default:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return tok, syntaxError(pos, "无效的数值 %s", text)
+		}
+		tok.value, tok.kind = n, kindNumber
+	}
+	return tok, nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,270 @@
+package condition
+
+import (
+	"net"
+	"strings"
+)
+
+// kind 编译期可确定的操作数类型
+type kind int
+
+const (
+	kindAny     kind = iota // 属性，类型在求值时确定
+	kindBool                // 比较、逻辑运算或布尔字面量
+	kindNumber              // 数值
+	kindString              // 字符串
+	kindNull                // null
+	kindIP                  // IPv4 地址
+	kindNetwork             // 网段
+	kindTime                // 一天中的时刻
+	kindList                // 列表
+)
+
+// parser 递归下降语法分析器
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept 下一个词法单元是指定运算符时消费它
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.typ == tokOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// enter 进入一层嵌套，超过上限时返回错误
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return syntaxError(pos, "嵌套层数超过 %d", MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogical(false, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogical(true, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.typ == tokOperator && tok.text == "!" {
+		p.next()
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(operand, tok.pos); err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.typ != tokOperator {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=", "in":
+	default:
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return newCompare(tok, left, right)
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokLiteral:
+		return &literalNode{value: tok.value, valueKind: tok.kind}, nil
+
+	case tokIdent:
+		return newAttribute(tok)
+
+	case tokOperator:
+		switch tok.text {
+		case "(":
+			if err := p.enter(tok.pos); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, syntaxError(p.peek().pos, "缺少 )")
+			}
+			return inner, nil
+
+		case "[":
+			if err := p.enter(tok.pos); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+
+			list := &listNode{}
+			if p.accept("]") {
+				return list, nil
+			}
+			for {
+				item, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+				if p.accept("]") {
+					return list, nil
+				}
+				if !p.accept(",") {
+					return nil, syntaxError(p.peek().pos, "列表元素之间应以逗号分隔")
+				}
+			}
+		}
+	}
+
+	if tok.typ == tokEOF {
+		return nil, syntaxError(tok.pos, "表达式不完整")
+	}
+	return nil, syntaxError(tok.pos, "此处不能出现 %q", tok.text)
+}
+
+// newAttribute 解析 命名空间.属性 形式的属性引用
+func newAttribute(tok token) (node, error) {
+	parts := strings.Split(tok.text, ".")
+	switch parts[0] {
+	case NamespaceSubject, NamespaceResource, NamespaceRequest:
+	default:
+		return nil, syntaxError(tok.pos, "未知的属性命名空间 %s，只能是 subject、resource 或 request", parts[0])
+	}
+	if len(parts) < 2 {
+		return nil, syntaxError(tok.pos, "应引用 %s 下的具体属性，如 %s.id", parts[0], parts[0])
+	}
+	for _, part := range parts[1:] {
+		if part == "" || !isLetter(part[0]) {
+			return nil, syntaxError(tok.pos, "无效的属性名 %s", tok.text)
+		}
+	}
+	return &attributeNode{namespace: parts[0], keys: parts[1:]}, nil
+}
+
+// newLogical 创建逻辑运算节点，两侧须为布尔值
+func newLogical(and bool, left, right node) (node, error) {
+	if err := requireBool(left, 0); err != nil {
+		return nil, err
+	}
+	if err := requireBool(right, 0); err != nil {
+		return nil, err
+	}
+	return &logicalNode{and: and, left: left, right: right}, nil
+}
+
+// newCompare 创建比较节点，并校验编译期可确定的操作数类型
+func newCompare(op token, left, right node) (node, error) {
+	switch op.text {
+	case "in":
+		switch right.kind() {
+		case kindList, kindNetwork, kindAny:
+		case kindString:
+			// 引号中的网段，用于书写 IPv6 网段
+			_, network, err := net.ParseCIDR(right.(*literalNode).value.(string))
+			if err != nil {
+				return nil, syntaxError(op.pos, "in 右侧的字符串须为网段")
+			}
+			right = &literalNode{value: network, valueKind: kindNetwork}
+		default:
+			return nil, syntaxError(op.pos, "in 右侧须为列表、网段或属性")
+		}
+		if left.kind() == kindList || left.kind() == kindNetwork {
+			return nil, syntaxError(op.pos, "in 左侧不能是列表或网段")
+		}
+
+	case "<", "<=", ">", ">=":
+		for _, operand := range []node{left, right} {
+			switch operand.kind() {
+			case kindAny, kindNumber, kindString, kindTime:
+			default:
+				return nil, syntaxError(op.pos, "%s 只能比较数值、字符串或时刻", op.text)
+			}
+		}
+	}
+
+	return &compareNode{op: op.text, left: left, right: right}, nil
+}
+
+// requireBool 校验节点可以作为布尔值使用
+func requireBool(n node, pos int) error {
+	switch n.kind() {
+	case kindBool, kindAny:
+		return nil
+	}
+	return syntaxError(pos, "条件须为布尔值，缺少比较运算")
+}
@@ -5,25 +5,77 @@ import "errors"
 // 定义通用错误类型
 var (
 	// 用户相关错误
-	ErrUserNotFound      = errors.New("用户不存在")
+	ErrUserNotFound       = errors.New("用户不存在")
 	ErrInvalidCredentials = errors.New("用户名或密码错误")
 	ErrUserExists         = errors.New("用户名已存在")
 	ErrEmailExists        = errors.New("邮箱已被使用")
 
 	// 角色相关错误
-	ErrRoleNotFound      = errors.New("角色不存在")
-	ErrRoleExists        = errors.New("角色已存在")
-	ErrRoleInUse         = errors.New("角色正在使用中，无法删除")
+	ErrRoleNotFound = errors.New("角色不存在")
+	ErrRoleExists   = errors.New("角色已存在")
+	ErrRoleInUse    = errors.New("角色正在使用中，无法删除")
+	ErrRoleCycle    = errors.New("角色继承关系存在循环")
+
+	// 角色模板相关错误
+	ErrRoleTemplateNotFound = errors.New("角色模板不存在")
 
 	// 权限相关错误
-	ErrPermissionNotFound = errors.New("权限不存在")
-	ErrPermissionExists   = errors.New("权限已存在")
-	ErrPermissionInUse    = errors.New("权限正在使用中，无法删除")
+	ErrPermissionNotFound    = errors.New("权限不存在")
+	ErrPermissionExists      = errors.New("权限已存在")
+	ErrPermissionInUse       = errors.New("权限正在使用中，无法删除")
+	ErrInvalidPermissionCode = errors.New("权限编码格式错误")
+	ErrInvalidEffect         = errors.New("无效的授予效果")
+	ErrInvalidCondition      = errors.New("条件表达式无效")
+	ErrInvalidParent         = errors.New("父级权限不存在或会形成循环")
+	ErrPermissionHasChildren = errors.New("权限下仍有子节点，无法删除")
+
+	// 用户组相关错误
+	ErrGroupNotFound = errors.New("用户组不存在")
+	ErrGroupExists   = errors.New("用户组已存在")
+	ErrGroupCycle    = errors.New("用户组嵌套关系存在循环")
+
+	// 组织单元与数据范围相关错误
+	ErrOrgUnitNotFound    = errors.New("组织单元不存在")
+	ErrOrgUnitExists      = errors.New("组织单元已存在")
+	ErrOrgUnitCycle       = errors.New("上级组织单元不能是自身或其下级单元")
+	ErrOrgUnitHasChildren = errors.New("组织单元下仍有下级单元，无法删除")
+	ErrInvalidDataScope   = errors.New("无效的数据范围")
+
+	// 角色分配有效期错误
+	ErrInvalidRoleValidity = errors.New("角色分配有效期无效")
+
+	// 租户相关错误
+	ErrTenantNotFound  = errors.New("租户不存在")
+	ErrTenantExists    = errors.New("租户已存在")
+	ErrTenantInUse     = errors.New("租户下仍有角色、角色分配或直接授权，无法删除")
+	ErrTenantMismatch  = errors.New("角色不属于该租户")
+	ErrTenantForbidden = errors.New("用户不属于该租户")
+
+	// 职责分离约束错误
+	ErrSoDRuleNotFound     = errors.New("职责分离规则不存在")
+	ErrSoDRuleExists       = errors.New("职责分离规则已存在")
+	ErrInvalidSoDRule      = errors.New("职责分离规则的角色上限必须小于角色数")
+	ErrSoDViolation        = errors.New("角色分配违反职责分离约束")
+	ErrDynamicSoDViolation = errors.New("同一会话不能同时激活互斥的角色")
+	ErrRoleNotActivatable  = errors.New("只能激活已分配且生效的角色")
+
+	// 策略导入错误
+	ErrInvalidPolicy = errors.New("策略内容无效")
+
+	// 关系授权错误
+	ErrInvalidRelationTuple  = errors.New("关系元组无效")
+	ErrInvalidRelationTarget = errors.New("无效的对象或主体")
+	ErrRelationNotDefined    = errors.New("未定义的对象类型或关系")
+	ErrRelationDepthExceeded = errors.New("关系展开层数超过上限")
 
 	// 令牌相关错误
-	ErrInvalidToken      = errors.New("无效的令牌")
-	ErrExpiredToken      = errors.New("令牌已过期")
-	ErrInvalidTokenType  = errors.New("无效的令牌类型")
+	ErrInvalidToken        = errors.New("无效的令牌")
+	ErrExpiredToken        = errors.New("令牌已过期")
+	ErrInvalidTokenType    = errors.New("无效的令牌类型")
+	ErrTokenTenantMismatch = errors.New("租户与认证令牌不一致")
+
+	// 转发认证错误
+	ErrInvalidForwardedURI = errors.New("无效的原始请求路径")
 
 	// 数据库相关错误
 	ErrDB = errors.New("数据库异常")
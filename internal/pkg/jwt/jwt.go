@@ -20,7 +20,10 @@ var (
 type Claims struct {
 	UserID    uint64 `json:"user_id"`
 	Username  string `json:"username"`
-	TokenType string `json:"token_type"` // access 或 refresh
+	TokenType string `json:"token_type"`          // access 或 refresh
+	TenantID  uint64 `json:"tenant_id,omitempty"` // 登录时选择的租户，0表示未选择
+	// ActiveRoles 会话中激活的角色，为空表示激活全部已分配的角色
+	ActiveRoles []uint64 `json:"active_roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -36,6 +39,16 @@ func NewTokenService(cfg *config.JWTConfig) *TokenService {
 
 // GenerateToken 生成JWT令牌
 func (s *TokenService) GenerateToken(userID uint64, username string, tokenType string) (string, error) {
+	return s.GenerateTenantToken(userID, 0, username, tokenType)
+}
+
+// GenerateTenantToken 生成携带租户声明的JWT令牌
+func (s *TokenService) GenerateTenantToken(userID, tenantID uint64, username string, tokenType string) (string, error) {
+	return s.GenerateSessionToken(userID, tenantID, nil, username, tokenType)
+}
+
+// GenerateSessionToken 生成携带租户与会话激活角色声明的JWT令牌
+func (s *TokenService) GenerateSessionToken(userID, tenantID uint64, activeRoles []uint64, username string, tokenType string) (string, error) {
 	// 确定过期时间
 	var expiry time.Duration
 	if tokenType == "refresh" {
@@ -46,9 +59,11 @@ func (s *TokenService) GenerateToken(userID uint64, username string, tokenType s
 
 	// 创建JWT声明
 	claims := &Claims{
-		UserID:    userID,
-		Username:  username,
-		TokenType: tokenType,
+		UserID:      userID,
+		Username:    username,
+		TokenType:   tokenType,
+		TenantID:    tenantID,
+		ActiveRoles: activeRoles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -100,14 +115,24 @@ func (s *TokenService) ValidateToken(tokenString string) (*Claims, error) {
 
 // GenerateTokenPair 生成访问令牌和刷新令牌对
 func (s *TokenService) GenerateTokenPair(userID uint64, username string) (accessToken string, refreshToken string, err error) {
+	return s.GenerateTenantTokenPair(userID, 0, username)
+}
+
+// GenerateTenantTokenPair 生成携带租户声明的访问令牌和刷新令牌对
+func (s *TokenService) GenerateTenantTokenPair(userID, tenantID uint64, username string) (accessToken string, refreshToken string, err error) {
+	return s.GenerateSessionTokenPair(userID, tenantID, nil, username)
+}
+
+// GenerateSessionTokenPair 生成携带租户与会话激活角色声明的访问令牌和刷新令牌对
+func (s *TokenService) GenerateSessionTokenPair(userID, tenantID uint64, activeRoles []uint64, username string) (accessToken string, refreshToken string, err error) {
 	// 生成访问令牌
-	accessToken, err = s.GenerateToken(userID, username, "access")
+	accessToken, err = s.GenerateSessionToken(userID, tenantID, activeRoles, username, "access")
 	if err != nil {
 		return "", "", err
 	}
 
 	// 生成刷新令牌
-	refreshToken, err = s.GenerateToken(userID, username, "refresh")
+	refreshToken, err = s.GenerateSessionToken(userID, tenantID, activeRoles, username, "refresh")
 	if err != nil {
 		return "", "", err
 	}
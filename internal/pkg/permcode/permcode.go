@@ -0,0 +1,122 @@
+package permcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// 权限编码语法：
+//
+//	code    = segment ":" segment { ":" segment }
+//	segment = "*" | 1*( ALPHA | DIGIT | "_" | "-" | "." )
+//
+// 通配符 "*" 只能独占一个分段：位于中间时匹配恰好一个分段，
+// 位于末尾时匹配剩余的一个或多个分段。例如 "user:*" 匹配 "user:list"
+// 与 "user:profile:update"，"*:list" 只匹配 "user:list" 这类两段编码。
+const (
+	// Separator 分段分隔符
+	Separator = ":"
+	// Wildcard 通配符
+	Wildcard = "*"
+	// MaxLength 权限编码最大长度，与数据库字段长度保持一致
+	MaxLength = 100
+)
+
+// 定义错误类型
+var (
+	ErrEmptyCode      = errors.New("权限编码不能为空")
+	ErrCodeTooLong    = errors.New("权限编码长度超出限制")
+	ErrTooFewSegments = errors.New("权限编码至少包含资源和操作两段，如 user:list")
+	ErrEmptySegment   = errors.New("权限编码包含空分段")
+	ErrInvalidSegment = errors.New("权限编码分段只能包含字母、数字、下划线、中划线、点或单独的通配符")
+)
+
+// Validate 校验权限编码（含通配符模式）是否符合语法
+func Validate(code string) error {
+	if code == "" {
+		return ErrEmptyCode
+	}
+	if len(code) > MaxLength {
+		return ErrCodeTooLong
+	}
+
+	segments := strings.Split(code, Separator)
+	if len(segments) < 2 {
+		return ErrTooFewSegments
+	}
+
+	for _, segment := range segments {
+		if segment == "" {
+			return ErrEmptySegment
+		}
+		if segment == Wildcard {
+			continue
+		}
+		for _, ch := range segment {
+			if !isSegmentChar(ch) {
+				return ErrInvalidSegment
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsPattern 判断权限编码是否包含通配符
+func IsPattern(code string) bool {
+	return strings.Contains(code, Wildcard)
+}
+
+// Match 判断授予的权限模式是否覆盖被检查的权限编码
+func Match(pattern, code string) bool {
+	if pattern == code {
+		return true
+	}
+	if !IsPattern(pattern) {
+		return false
+	}
+	return matchSegments(strings.Split(pattern, Separator), strings.Split(code, Separator))
+}
+
+// MatchAny 判断任一权限模式是否覆盖被检查的权限编码
+func MatchAny(patterns []string, code string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments 按分段匹配
+func matchSegments(pattern, code []string) bool {
+	for i, segment := range pattern {
+		last := i == len(pattern)-1
+		if i >= len(code) {
+			return false
+		}
+		if segment == Wildcard {
+			// 末尾通配符匹配剩余的全部分段
+			if last {
+				return true
+			}
+			continue
+		}
+		if segment != code[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(code)
+}
+
+// isSegmentChar 判断字符是否可用于分段
+func isSegmentChar(ch rune) bool {
+	switch {
+	case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		return true
+	case ch == '_' || ch == '-' || ch == '.':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package permcode
+
+// Policy 由允许与拒绝两组权限模式组成的判定策略，拒绝优先于允许
+type Policy struct {
+	allow *Set
+	deny  *Set
+}
+
+// NewPolicy 根据允许与拒绝的权限编码（可包含通配符模式）创建判定策略
+func NewPolicy(allow, deny []string) *Policy {
+	return &Policy{
+		allow: NewSet(allow),
+		deny:  NewSet(deny),
+	}
+}
+
+// Allows 判断权限编码是否被允许：未被任何拒绝规则覆盖，且被至少一条允许规则覆盖
+func (p *Policy) Allows(code string) bool {
+	return !p.deny.Has(code) && p.allow.Has(code)
+}
+
+// Denies 判断权限编码是否被拒绝规则覆盖
+func (p *Policy) Denies(code string) bool {
+	return p.deny.Has(code)
+}
+
+// AllowsAny 判断是否允许任意一个权限编码，未指定编码时视为满足
+func (p *Policy) AllowsAny(codes ...string) bool {
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if p.Allows(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAll 判断是否允许全部权限编码
+func (p *Policy) AllowsAll(codes ...string) bool {
+	for _, code := range codes {
+		if !p.Allows(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// Allowed 返回授予的允许规则
+func (p *Policy) Allowed() []string {
+	return p.allow.Codes()
+}
+
+// Denied 返回授予的拒绝规则
+func (p *Policy) Denied() []string {
+	return p.deny.Codes()
+}
@@ -0,0 +1,72 @@
+package permcode
+
+import "strings"
+
+// Set 编译后的权限集合，精确编码使用哈希查找，通配符模式按分段匹配
+type Set struct {
+	exact    map[string]struct{}
+	patterns [][]string
+	codes    []string
+}
+
+// NewSet 根据授予的权限编码（可包含通配符模式）创建权限集合
+func NewSet(codes []string) *Set {
+	s := &Set{
+		exact: make(map[string]struct{}, len(codes)),
+		codes: codes,
+	}
+	for _, code := range codes {
+		if IsPattern(code) {
+			s.patterns = append(s.patterns, strings.Split(code, Separator))
+			continue
+		}
+		s.exact[code] = struct{}{}
+	}
+	return s
+}
+
+// Has 判断集合是否覆盖被检查的权限编码
+func (s *Set) Has(code string) bool {
+	if _, ok := s.exact[code]; ok {
+		return true
+	}
+	if len(s.patterns) == 0 {
+		return false
+	}
+
+	segments := strings.Split(code, Separator)
+	for _, pattern := range s.patterns {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny 判断集合是否覆盖任意一个权限编码，未指定编码时视为满足
+func (s *Set) HasAny(codes ...string) bool {
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if s.Has(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll 判断集合是否覆盖全部权限编码
+func (s *Set) HasAll(codes ...string) bool {
+	for _, code := range codes {
+		if !s.Has(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// Codes 返回集合中授予的原始权限编码
+func (s *Set) Codes() []string {
+	return s.codes
+}
@@ -0,0 +1,193 @@
+package policyfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 策略文件采用 Casbin 的 CSV 策略格式，每行一条规则，以 # 开头的行为注释：
+//
+//	p, 角色编码, 权限编码[, 效果]      角色授予权限，效果为 allow（默认）或 deny
+//	g, 用户名, 角色编码[, 租户编码]    为用户分配角色，省略租户时为全局分配
+const (
+	// TypePolicy 权限授予规则
+	TypePolicy = "p"
+	// TypeGrouping 角色分配规则
+	TypeGrouping = "g"
+	// EffectAllow 允许
+	EffectAllow = "allow"
+	// EffectDeny 拒绝
+	EffectDeny = "deny"
+)
+
+// 定义错误类型
+var (
+	ErrUnknownType   = errors.New("未知的规则类型，只支持 p 与 g")
+	ErrFieldCount    = errors.New("规则字段数量错误")
+	ErrEmptyField    = errors.New("规则包含空字段")
+	ErrInvalidEffect = errors.New("无效的授予效果，只支持 allow 与 deny")
+)
+
+// LineError 带行号的解析错误
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Error 实现 error 接口
+func (e *LineError) Error() string {
+	return fmt.Sprintf("第%d行: %v", e.Line, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Grant 一条 p 规则：角色授予权限
+type Grant struct {
+	Role       string
+	Permission string
+	Effect     string
+}
+
+// String 返回规则的 CSV 行，允许效果省略不写
+func (g Grant) String() string {
+	if g.Effect == EffectDeny {
+		return join(TypePolicy, g.Role, g.Permission, g.Effect)
+	}
+	return join(TypePolicy, g.Role, g.Permission)
+}
+
+// Assignment 一条 g 规则：为用户分配角色
+type Assignment struct {
+	User   string
+	Role   string
+	Tenant string // 租户编码，为空表示全局分配
+}
+
+// String 返回规则的 CSV 行
+func (a Assignment) String() string {
+	if a.Tenant != "" {
+		return join(TypeGrouping, a.User, a.Role, a.Tenant)
+	}
+	return join(TypeGrouping, a.User, a.Role)
+}
+
+// Policy 完整的策略内容
+type Policy struct {
+	Grants      []Grant
+	Assignments []Assignment
+}
+
+// Parse 解析 CSV 格式的策略内容，遇到第一个错误即返回 *LineError
+func Parse(r io.Reader) (*Policy, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	policy := &Policy{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return policy, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err := policy.add(record); err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+	}
+}
+
+// add 校验并添加一条规则
+func (p *Policy) add(record []string) error {
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+		if record[i] == "" {
+			return ErrEmptyField
+		}
+	}
+
+	switch record[0] {
+	case TypePolicy:
+		if len(record) != 3 && len(record) != 4 {
+			return ErrFieldCount
+		}
+		grant := Grant{Role: record[1], Permission: record[2], Effect: EffectAllow}
+		if len(record) == 4 {
+			grant.Effect = record[3]
+		}
+		if grant.Effect != EffectAllow && grant.Effect != EffectDeny {
+			return ErrInvalidEffect
+		}
+		p.Grants = append(p.Grants, grant)
+	case TypeGrouping:
+		if len(record) != 3 && len(record) != 4 {
+			return ErrFieldCount
+		}
+		assignment := Assignment{User: record[1], Role: record[2]}
+		if len(record) == 4 {
+			assignment.Tenant = record[3]
+		}
+		p.Assignments = append(p.Assignments, assignment)
+	default:
+		return ErrUnknownType
+	}
+	return nil
+}
+
+// Sort 按规则内容排序，使导出结果稳定
+func (p *Policy) Sort() {
+	sort.Slice(p.Grants, func(i, j int) bool {
+		return p.Grants[i].String() < p.Grants[j].String()
+	})
+	sort.Slice(p.Assignments, func(i, j int) bool {
+		return p.Assignments[i].String() < p.Assignments[j].String()
+	})
+}
+
+// WriteTo 按 CSV 格式输出全部规则，先输出 p 规则再输出 g 规则
+func (p *Policy) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, line := range p.Lines() {
+		n, err := io.WriteString(w, line+"\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String 返回 CSV 格式的策略内容
+func (p *Policy) String() string {
+	var b strings.Builder
+	p.WriteTo(&b)
+	return b.String()
+}
+
+// Lines 返回全部规则的 CSV 行
+func (p *Policy) Lines() []string {
+	lines := make([]string, 0, len(p.Grants)+len(p.Assignments))
+	for _, grant := range p.Grants {
+		lines = append(lines, grant.String())
+	}
+	for _, assignment := range p.Assignments {
+		lines = append(lines, assignment.String())
+	}
+	return lines
+}
+
+// join 以 Casbin 习惯的 ", " 连接字段
+func join(fields ...string) string {
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,155 @@
+package relation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lvyunze/fiber-rbac/config"
+)
+
+// DefaultMaxDepth 判定时默认最多展开的层数
+const DefaultMaxDepth = 25
+
+// Rewrite 一种关系的改写规则
+type Rewrite struct {
+	Includes []string         // 同一对象上隐含本关系的其他关系
+	From     []TupleToUserset // 经由关联对象获得本关系
+}
+
+// TupleToUserset 经由 Tupleset 关系指向的关联对象上的 Relation 关系获得本关系
+type TupleToUserset struct {
+	Tupleset string
+	Relation string
+}
+
+// Inheritance 经由关联对象继承的关系：ObjectType 对象的 Relation 关系包含其 Tupleset 关联对象上的某个关系
+type Inheritance struct {
+	ObjectType string
+	Relation   string
+	Tupleset   string
+}
+
+// Schema 编译后的对象类型定义，创建后只读，并发安全
+type Schema struct {
+	maxDepth   int
+	rewrites   map[string]map[string]*Rewrite // 对象类型 -> 关系 -> 改写规则
+	includedBy map[string]map[string][]string // 对象类型 -> 关系 -> 包含该关系的关系
+	inherited  map[string][]Inheritance       // 关联对象上的关系 -> 经由它继承的关系
+}
+
+// NewSchema 校验并编译对象类型定义：类型与关系名须合法且不重复，
+// includes 与 tupleset 须引用同一类型中已定义的关系，from 引用的关系须在某个类型中定义
+func NewSchema(cfg *config.RelationsConfig) (*Schema, error) {
+	s := &Schema{
+		maxDepth:   cfg.MaxDepth,
+		rewrites:   make(map[string]map[string]*Rewrite),
+		includedBy: make(map[string]map[string][]string),
+		inherited:  make(map[string][]Inheritance),
+	}
+	if s.maxDepth <= 0 {
+		s.maxDepth = DefaultMaxDepth
+	}
+
+	defined := make(map[string]struct{})
+	for _, t := range cfg.Types {
+		if !validName(t.Name) {
+			return nil, fmt.Errorf("无效的对象类型名 %q", t.Name)
+		}
+		if _, ok := s.rewrites[t.Name]; ok {
+			return nil, fmt.Errorf("对象类型 %s 重复定义", t.Name)
+		}
+		relations := make(map[string]*Rewrite, len(t.Relations))
+		for _, r := range t.Relations {
+			if !validName(r.Name) {
+				return nil, fmt.Errorf("对象类型 %s 的关系名 %q 无效", t.Name, r.Name)
+			}
+			if _, ok := relations[r.Name]; ok {
+				return nil, fmt.Errorf("对象类型 %s 的关系 %s 重复定义", t.Name, r.Name)
+			}
+			rewrite := &Rewrite{Includes: append([]string(nil), r.Includes...)}
+			for _, from := range r.From {
+				rewrite.From = append(rewrite.From, TupleToUserset{Tupleset: from.Tupleset, Relation: from.Relation})
+			}
+			relations[r.Name] = rewrite
+			defined[r.Name] = struct{}{}
+		}
+		s.rewrites[t.Name] = relations
+		s.includedBy[t.Name] = make(map[string][]string)
+	}
+
+	// 校验引用并建立反向索引，用于从主体出发查找可访问的对象
+	for objectType, relations := range s.rewrites {
+		for name, rewrite := range relations {
+			for _, included := range rewrite.Includes {
+				if _, ok := relations[included]; !ok {
+					return nil, fmt.Errorf("对象类型 %s 的关系 %s 包含未定义的关系 %s", objectType, name, included)
+				}
+				s.includedBy[objectType][included] = append(s.includedBy[objectType][included], name)
+			}
+			for _, from := range rewrite.From {
+				if _, ok := relations[from.Tupleset]; !ok {
+					return nil, fmt.Errorf("对象类型 %s 的关系 %s 引用了未定义的关联关系 %s", objectType, name, from.Tupleset)
+				}
+				if _, ok := defined[from.Relation]; !ok {
+					return nil, fmt.Errorf("对象类型 %s 的关系 %s 引用了未定义的关系 %s", objectType, name, from.Relation)
+				}
+				s.inherited[from.Relation] = append(s.inherited[from.Relation], Inheritance{
+					ObjectType: objectType,
+					Relation:   name,
+					Tupleset:   from.Tupleset,
+				})
+			}
+		}
+	}
+
+	// 反向索引按名称排序，保证遍历顺序稳定
+	for _, relations := range s.includedBy {
+		for _, names := range relations {
+			sort.Strings(names)
+		}
+	}
+	for _, items := range s.inherited {
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].ObjectType != items[j].ObjectType {
+				return items[i].ObjectType < items[j].ObjectType
+			}
+			return items[i].Relation < items[j].Relation
+		})
+	}
+
+	return s, nil
+}
+
+// MaxDepth 返回判定时最多展开的层数
+func (s *Schema) MaxDepth() int {
+	return s.maxDepth
+}
+
+// Rewrite 返回对象类型上某个关系的改写规则，未定义时 ok 为 false
+func (s *Schema) Rewrite(objectType, relation string) (*Rewrite, bool) {
+	rewrite, ok := s.rewrites[objectType][relation]
+	return rewrite, ok
+}
+
+// IncludedBy 返回同一对象类型上包含指定关系的其他关系
+func (s *Schema) IncludedBy(objectType, relation string) []string {
+	return s.includedBy[objectType][relation]
+}
+
+// InheritedBy 返回经由关联对象上的指定关系继承的关系
+func (s *Schema) InheritedBy(relation string) []Inheritance {
+	return s.inherited[relation]
+}
+
+// Validate 校验元组引用的关系均已定义：对象的关系须已定义，主体为用户集时其关系也须已定义
+func (s *Schema) Validate(t Tuple) error {
+	if _, ok := s.Rewrite(t.Object.Type, t.Relation); !ok {
+		return fmt.Errorf("对象类型 %s 未定义关系 %s", t.Object.Type, t.Relation)
+	}
+	if t.Subject.IsUserset() {
+		if _, ok := s.Rewrite(t.Subject.Type, t.Subject.Relation); !ok {
+			return fmt.Errorf("对象类型 %s 未定义关系 %s", t.Subject.Type, t.Subject.Relation)
+		}
+	}
+	return nil
+}
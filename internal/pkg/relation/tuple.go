@@ -0,0 +1,127 @@
+// Package relation 实现 Zanzibar 风格的关系元组格式与对象类型定义
+//
+// 关系元组写作 object#relation@subject，表示主体对某个资源实例持有某种关系：
+//
+//	document:42#viewer@user:7          用户7可以查看文档42
+//	document:42#viewer@group:eng#member eng组的全部成员可以查看文档42
+//	document:42#parent@folder:3        文档42位于文件夹3中
+package relation
+
+import (
+	"errors"
+	"strings"
+)
+
+// 定义错误类型
+var (
+	ErrInvalidObject  = errors.New("无效的对象，格式应为 类型:ID")
+	ErrInvalidSubject = errors.New("无效的主体，格式应为 类型:ID 或 类型:ID#关系")
+	ErrInvalidTuple   = errors.New("无效的关系元组，格式应为 类型:ID#关系@主体")
+)
+
+// Object 资源实例
+type Object struct {
+	Type string
+	ID   string
+}
+
+// String 返回 类型:ID 形式的对象
+func (o Object) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// Subject 关系的主体，Relation 非空时为用户集，即持有该对象该关系的全部主体
+type Subject struct {
+	Type     string
+	ID       string
+	Relation string
+}
+
+// String 返回 类型:ID 或 类型:ID#关系 形式的主体
+func (s Subject) String() string {
+	if s.Relation == "" {
+		return s.Type + ":" + s.ID
+	}
+	return s.Type + ":" + s.ID + "#" + s.Relation
+}
+
+// IsUserset 判断主体是否为用户集
+func (s Subject) IsUserset() bool {
+	return s.Relation != ""
+}
+
+// Object 返回主体所在的对象
+func (s Subject) Object() Object {
+	return Object{Type: s.Type, ID: s.ID}
+}
+
+// Tuple 关系元组
+type Tuple struct {
+	Object   Object
+	Relation string
+	Subject  Subject
+}
+
+// String 返回 object#relation@subject 形式的元组
+func (t Tuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseObject 解析 类型:ID 形式的对象，ID 中不能包含 #、@ 与空白
+func ParseObject(s string) (Object, error) {
+	objectType, id, ok := strings.Cut(s, ":")
+	if !ok || !validName(objectType) || !validID(id) {
+		return Object{}, ErrInvalidObject
+	}
+	return Object{Type: objectType, ID: id}, nil
+}
+
+// ParseSubject 解析 类型:ID 或 类型:ID#关系 形式的主体
+func ParseSubject(s string) (Subject, error) {
+	object, relation, userset := strings.Cut(s, "#")
+	o, err := ParseObject(object)
+	if err != nil || (userset && !validName(relation)) {
+		return Subject{}, ErrInvalidSubject
+	}
+	return Subject{Type: o.Type, ID: o.ID, Relation: relation}, nil
+}
+
+// ParseTuple 解析 object#relation@subject 形式的关系元组
+func ParseTuple(s string) (Tuple, error) {
+	resource, subject, ok := strings.Cut(s, "@")
+	if !ok {
+		return Tuple{}, ErrInvalidTuple
+	}
+	object, relation, ok := strings.Cut(resource, "#")
+	if !ok || !validName(relation) {
+		return Tuple{}, ErrInvalidTuple
+	}
+
+	o, err := ParseObject(object)
+	if err != nil {
+		return Tuple{}, err
+	}
+	sub, err := ParseSubject(subject)
+	if err != nil {
+		return Tuple{}, err
+	}
+	return Tuple{Object: o, Relation: relation, Subject: sub}, nil
+}
+
+// validName 判断类型或关系名是否合法：小写字母开头，由小写字母、数字与下划线组成
+func validName(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// validID 判断对象ID是否合法
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "#@ \t\r\n")
+}
@@ -12,6 +12,7 @@ const (
 	CodeForbidden    = 1003 // 禁止访问
 	CodeNotFound     = 1004 // 资源不存在
 	CodeServerError  = 1005 // 服务器错误
+	CodeSoDViolation = 1006 // 违反职责分离约束
 )
 
 // Response 统一响应结构
@@ -77,6 +78,14 @@ func NotFound(c *fiber.Ctx, msg string) error {
 	return Fail(c, CodeNotFound, msg)
 }
 
+// SoDViolation 违反职责分离约束
+func SoDViolation(c *fiber.Ctx, msg string) error {
+	if msg == "" {
+		msg = "违反职责分离约束"
+	}
+	return Fail(c, CodeSoDViolation, msg)
+}
+
 // ServerError 服务器错误
 func ServerError(c *fiber.Ctx, msg string) error {
 	if msg == "" {
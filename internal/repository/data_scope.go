@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// WithDataScope 返回按数据范围过滤查询的 GORM scope，用法为 db.Scopes(WithDataScope(scope, "users.id"))
+// userColumn 为记录所属用户的列（用户表本身即 users.id），必须是代码中的常量，不能来自请求参数。
+// scope 为nil或可访问全部数据时不过滤；记录所属用户是 UnitIDs 中任一组织单元的成员，
+// 或 Self 为 true 且记录属于 scope.UserID 时可见；范围为空时不返回任何记录
+func WithDataScope(scope *model.DataScope, userColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if scope == nil || scope.All {
+			return db
+		}
+
+		var conditions []string
+		var args []interface{}
+		if len(scope.UnitIDs) > 0 {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM org_unit_members WHERE org_unit_members.user_id = "+userColumn+" AND org_unit_members.org_unit_id IN ?)")
+			args = append(args, scope.UnitIDs)
+		}
+		if scope.Self {
+			conditions = append(conditions, userColumn+" = ?")
+			args = append(args, scope.UserID)
+		}
+
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GroupRepository 用户组仓储接口
+type GroupRepository interface {
+	Create(group *model.Group) error
+	Update(group *model.Group) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*model.Group, error)
+	GetByCode(code string) (*model.Group, error)
+	GetByName(name string) (*model.Group, error)
+	List(page, pageSize int, keyword string) ([]*model.Group, int64, error)
+	SetParents(groupID uint64, parentIDs []uint64) error
+	GetDescendantIDs(groupID uint64) ([]uint64, error)
+	AddMembers(groupID uint64, userIDs []uint64) error
+	RemoveMembers(groupID uint64, userIDs []uint64) error
+	ListMembers(groupID uint64, page, pageSize int) ([]*model.User, int64, error)
+	CountMembers(groupID uint64) (int64, error)
+	GetAffectedUserIDs(groupID uint64) ([]uint64, error)
+	GetRoleAssignments(groupID uint64) ([]*model.GroupRoleAssignment, error)
+	UpdateRoles(groupID, tenantID uint64, roleIDs []uint64) error
+}
+
+// 用户组后代查询，UNION 去重可保证嵌套关系中存在环时递归仍能终止
+const groupDescendantsSQL = `
+WITH RECURSIVE descendants(id) AS (
+	SELECT group_parents.group_id FROM group_parents WHERE group_parents.parent_id = ?
+	UNION
+	SELECT group_parents.group_id FROM group_parents
+	JOIN descendants ON group_parents.parent_id = descendants.id
+)
+SELECT id FROM descendants`
+
+// groupRepo 用户组仓储实现
+type groupRepo struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository 创建用户组仓储实例
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepo{db: db}
+}
+
+// Create 创建用户组
+func (r *groupRepo) Create(group *model.Group) error {
+	return r.db.Omit("Parents").Create(group).Error
+}
+
+// Update 更新用户组
+func (r *groupRepo) Update(group *model.Group) error {
+	// 只更新非零值字段
+	return r.db.Model(group).Omit("Parents").Updates(group).Error
+}
+
+// Delete 删除用户组（软删除），同时移除其成员、角色分配与嵌套关系
+func (r *groupRepo) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ? OR parent_id = ?", id, id).Delete(&model.GroupParent{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Group{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
+	})
+}
+
+// GetByID 根据ID获取用户组及其未删除的上级组
+func (r *groupRepo) GetByID(id uint64) (*model.Group, error) {
+	return r.getBy("id = ?", id)
+}
+
+// GetByCode 根据编码获取用户组
+func (r *groupRepo) GetByCode(code string) (*model.Group, error) {
+	return r.getBy("code = ?", code)
+}
+
+// GetByName 根据名称获取用户组
+func (r *groupRepo) GetByName(name string) (*model.Group, error) {
+	return r.getBy("name = ?", name)
+}
+
+// getBy 按条件获取未删除的用户组，不存在时返回nil
+func (r *groupRepo) getBy(condition string, value interface{}) (*model.Group, error) {
+	var group model.Group
+	result := r.db.Preload("Parents", "deleted_at IS NULL").Where(condition+" AND deleted_at IS NULL", value).First(&group)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // 用户组不存在返回nil，而不是错误
+		}
+		return nil, result.Error
+	}
+	return &group, nil
+}
+
+// List 获取用户组列表
+func (r *groupRepo) List(page, pageSize int, keyword string) ([]*model.Group, int64, error) {
+	var groups []*model.Group
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	// 构建查询
+	query := r.db.Model(&model.Group{}).Where("deleted_at IS NULL")
+
+	// 添加关键词搜索
+	if keyword != "" {
+		keyword = fmt.Sprintf("%%%s%%", strings.ToLower(keyword))
+		query = query.Where("LOWER(code) LIKE ? OR LOWER(name) LIKE ?", keyword, keyword)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Parents", "deleted_at IS NULL").Offset(offset).Limit(pageSize).Order("id DESC").Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+// SetParents 设置用户组的上级组（覆盖原有嵌套关系）
+func (r *groupRepo) SetParents(groupID uint64, parentIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 删除所有现有上级组
+		if err := tx.Where("group_id = ?", groupID).Delete(&model.GroupParent{}).Error; err != nil {
+			return err
+		}
+
+		// 添加新的上级组
+		for _, parentID := range parentIDs {
+			var count int64
+			if err := tx.Model(&model.Group{}).Where("id = ? AND deleted_at IS NULL", parentID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("上级用户组ID %d 不存在: %w", parentID, gorm.ErrRecordNotFound)
+			}
+
+			if err := tx.Create(&model.GroupParent{GroupID: groupID, ParentID: parentID}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDescendantIDs 获取嵌套在该用户组下的全部后代组ID（含间接嵌套）
+func (r *groupRepo) GetDescendantIDs(groupID uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := r.db.Raw(groupDescendantsSQL, groupID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AddMembers 将用户加入用户组，已是成员的用户保持不变
+func (r *groupRepo) AddMembers(groupID uint64, userIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			var count int64
+			if err := tx.Model(&model.GroupMember{}).Where("group_id = ? AND user_id = ?", groupID, userID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(&model.GroupMember{GroupID: groupID, UserID: userID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMembers 将用户移出用户组
+func (r *groupRepo) RemoveMembers(groupID uint64, userIDs []uint64) error {
+	return r.db.Where("group_id = ? AND user_id IN ?", groupID, userIDs).Delete(&model.GroupMember{}).Error
+}
+
+// ListMembers 分页获取用户组的直接成员，不含嵌套子组的成员，已删除的用户不返回
+func (r *groupRepo) ListMembers(groupID uint64, page, pageSize int) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := r.db.Model(&model.User{}).
+		Joins("JOIN group_members ON group_members.user_id = users.id").
+		Where("group_members.group_id = ? AND users.deleted_at IS NULL", groupID)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("users.id").Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// CountMembers 统计用户组未删除的直接成员数
+func (r *groupRepo) CountMembers(groupID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.GroupMember{}).
+		Joins("JOIN users ON users.id = group_members.user_id AND users.deleted_at IS NULL").
+		Where("group_members.group_id = ?", groupID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetAffectedUserIDs 获取该用户组及其全部后代组的成员ID，
+// 这些用户的有效权限会随该组的角色分配或嵌套关系变化
+func (r *groupRepo) GetAffectedUserIDs(groupID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Raw(`
+WITH RECURSIVE affected_groups(id) AS (
+	SELECT id FROM user_groups WHERE id = ?
+	UNION
+	SELECT group_parents.group_id FROM group_parents
+	JOIN affected_groups ON group_parents.parent_id = affected_groups.id
+)
+SELECT DISTINCT group_members.user_id FROM group_members
+JOIN affected_groups ON affected_groups.id = group_members.group_id
+ORDER BY group_members.user_id`, groupID).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetRoleAssignments 获取用户组在各租户内的角色分配，已删除的角色不返回
+func (r *groupRepo) GetRoleAssignments(groupID uint64) ([]*model.GroupRoleAssignment, error) {
+	var assignments []*model.GroupRoleAssignment
+	err := r.db.Table("group_roles").
+		Select("group_roles.*, user_groups.code AS group_code, user_groups.name AS group_name, roles.code AS role_code, roles.name AS role_name").
+		Joins("JOIN user_groups ON user_groups.id = group_roles.group_id").
+		Joins("JOIN roles ON roles.id = group_roles.role_id AND roles.deleted_at IS NULL").
+		Where("group_roles.group_id = ?", groupID).
+		Order("group_roles.tenant_id, group_roles.role_id").
+		Scan(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// UpdateRoles 替换用户组在指定租户内的角色，其他租户的分配保持不变
+func (r *groupRepo) UpdateRoles(groupID, tenantID uint64, roleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ? AND tenant_id = ?", groupID, tenantID).Delete(&model.GroupRole{}).Error; err != nil {
+			return err
+		}
+
+		seen := make(map[uint64]struct{}, len(roleIDs))
+		for _, roleID := range roleIDs {
+			if _, ok := seen[roleID]; ok {
+				continue
+			}
+			seen[roleID] = struct{}{}
+
+			// 租户角色只能在所属租户内分配
+			var count int64
+			if err := tx.Model(&model.Role{}).Where("id = ? AND deleted_at IS NULL AND tenant_id IN (0, ?)", roleID, tenantID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("角色ID %d 不存在或不属于租户 %d: %w", roleID, tenantID, gorm.ErrRecordNotFound)
+			}
+
+			if err := tx.Create(&model.GroupRole{GroupID: groupID, RoleID: roleID, TenantID: tenantID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
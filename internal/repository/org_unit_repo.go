@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"errors"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// OrgUnitRepository 组织单元仓储接口
+type OrgUnitRepository interface {
+	Create(unit *model.OrgUnit) error
+	Update(unit *model.OrgUnit) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*model.OrgUnit, error)
+	GetByCode(code string) (*model.OrgUnit, error)
+	ListAll() ([]*model.OrgUnit, error)
+	CountChildren(id uint64) (int64, error)
+	GetSubtreeIDs(id uint64) ([]uint64, error)
+	AddMembers(unitID uint64, userIDs []uint64) error
+	RemoveMembers(unitID uint64, userIDs []uint64) error
+	ListMembers(unitID uint64, page, pageSize int) ([]*model.User, int64, error)
+	CountMembers(unitID uint64) (int64, error)
+}
+
+// 组织单元子树查询，结果包含起始单元自身及其全部未删除的下级单元；
+// 参数为起始单元ID列表，UNION 去重可保证数据异常形成环时递归仍能终止
+const orgUnitSubtreeSQL = `
+WITH RECURSIVE subtree(id) AS (
+	SELECT org_units.id FROM org_units WHERE org_units.id IN ? AND org_units.deleted_at IS NULL
+	UNION
+	SELECT org_units.id FROM org_units
+	JOIN subtree ON org_units.parent_id = subtree.id
+	WHERE org_units.deleted_at IS NULL
+)
+SELECT id FROM subtree ORDER BY id`
+
+// orgUnitRepo 组织单元仓储实现
+type orgUnitRepo struct {
+	db *gorm.DB
+}
+
+// NewOrgUnitRepository 创建组织单元仓储实例
+func NewOrgUnitRepository(db *gorm.DB) OrgUnitRepository {
+	return &orgUnitRepo{db: db}
+}
+
+// Create 创建组织单元
+func (r *orgUnitRepo) Create(unit *model.OrgUnit) error {
+	return r.db.Create(unit).Error
+}
+
+// Update 更新组织单元，上级单元与排序允许更新为零值
+func (r *orgUnitRepo) Update(unit *model.OrgUnit) error {
+	return r.db.Model(unit).Select("parent_id", "code", "name", "description", "sort", "updated_at").Updates(unit).Error
+}
+
+// Delete 删除组织单元（软删除），同时移除其成员与角色数据范围中的引用
+func (r *orgUnitRepo) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("org_unit_id = ?", id).Delete(&model.OrgUnitMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("org_unit_id = ?", id).Delete(&model.RoleDataScopeUnit{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.OrgUnit{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
+	})
+}
+
+// GetByID 根据ID获取组织单元
+func (r *orgUnitRepo) GetByID(id uint64) (*model.OrgUnit, error) {
+	return r.getBy("id = ?", id)
+}
+
+// GetByCode 根据编码获取组织单元
+func (r *orgUnitRepo) GetByCode(code string) (*model.OrgUnit, error) {
+	return r.getBy("code = ?", code)
+}
+
+// getBy 按条件获取未删除的组织单元，不存在时返回nil
+func (r *orgUnitRepo) getBy(condition string, value interface{}) (*model.OrgUnit, error) {
+	var unit model.OrgUnit
+	result := r.db.Where(condition+" AND deleted_at IS NULL", value).First(&unit)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // 组织单元不存在返回nil，而不是错误
+		}
+		return nil, result.Error
+	}
+	return &unit, nil
+}
+
+// ListAll 获取全部未删除的组织单元，按排序值与ID排序
+func (r *orgUnitRepo) ListAll() ([]*model.OrgUnit, error) {
+	var units []*model.OrgUnit
+	if err := r.db.Where("deleted_at IS NULL").Order("sort, id").Find(&units).Error; err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// CountChildren 统计未删除的直接下级单元数
+func (r *orgUnitRepo) CountChildren(id uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OrgUnit{}).Where("parent_id = ? AND deleted_at IS NULL", id).Count(&count).Error
+	return count, err
+}
+
+// GetSubtreeIDs 获取组织单元自身及其全部下级单元的ID（含间接下级）
+func (r *orgUnitRepo) GetSubtreeIDs(id uint64) ([]uint64, error) {
+	var ids []uint64
+	if err := r.db.Raw(orgUnitSubtreeSQL, []uint64{id}).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AddMembers 将用户加入组织单元，已是成员的用户保持不变
+func (r *orgUnitRepo) AddMembers(unitID uint64, userIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			var count int64
+			if err := tx.Model(&model.OrgUnitMember{}).Where("org_unit_id = ? AND user_id = ?", unitID, userID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(&model.OrgUnitMember{OrgUnitID: unitID, UserID: userID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMembers 将用户移出组织单元
+func (r *orgUnitRepo) RemoveMembers(unitID uint64, userIDs []uint64) error {
+	return r.db.Where("org_unit_id = ? AND user_id IN ?", unitID, userIDs).Delete(&model.OrgUnitMember{}).Error
+}
+
+// ListMembers 分页获取组织单元的直接成员，不含下级单元的成员，已删除的用户不返回
+func (r *orgUnitRepo) ListMembers(unitID uint64, page, pageSize int) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := r.db.Model(&model.User{}).
+		Joins("JOIN org_unit_members ON org_unit_members.user_id = users.id").
+		Where("org_unit_members.org_unit_id = ? AND users.deleted_at IS NULL", unitID)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("users.id").Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// CountMembers 统计组织单元未删除的直接成员数
+func (r *orgUnitRepo) CountMembers(unitID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OrgUnitMember{}).
+		Joins("JOIN users ON users.id = org_unit_members.user_id AND users.deleted_at IS NULL").
+		Where("org_unit_members.org_unit_id = ?", unitID).
+		Count(&count).Error
+	return count, err
+}
@@ -19,6 +19,9 @@ type PermissionRepository interface {
 	GetByCode(code string) (*model.Permission, error)
 	List(page, pageSize int, keyword string) ([]*model.Permission, int64, error)
 	GetByNames(names []string) ([]*model.Permission, error)
+	ListAll() ([]*model.Permission, error)
+	CountChildren(id uint64) (int64, error)
+	ListRoutes() ([]*model.Permission, error)
 }
 
 // permissionRepo 权限仓储实现
@@ -38,8 +41,10 @@ func (r *permissionRepo) Create(permission *model.Permission) error {
 
 // Update 更新权限
 func (r *permissionRepo) Update(permission *model.Permission) error {
-	// 只更新非零值字段
-	return r.db.Model(permission).Updates(permission).Error
+	// 树结构字段允许更新为零值（如移动到根节点），因此显式指定更新的列
+	return r.db.Model(permission).
+		Select("code", "name", "description", "parent_id", "type", "sort", "icon", "method", "route", "updated_at").
+		Updates(permission).Error
 }
 
 // Delete 删除权限（软删除）
@@ -51,6 +56,11 @@ func (r *permissionRepo) Delete(id uint64) error {
 			return err
 		}
 
+		// 删除直接授予用户的权限
+		if err := tx.Where("permission_id = ?", id).Delete(&model.UserPermission{}).Error; err != nil {
+			return err
+		}
+
 		// 软删除权限
 		return tx.Model(&model.Permission{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
 	})
@@ -144,3 +154,28 @@ func (r *permissionRepo) GetByNames(names []string) ([]*model.Permission, error)
 
 	return permissions, nil
 }
+
+// ListAll 获取全部权限，按同级排序与ID升序，用于组装权限树
+func (r *permissionRepo) ListAll() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	if err := r.db.Where("deleted_at IS NULL").Order("sort, id").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// CountChildren 统计权限的直接子节点数量
+func (r *permissionRepo) CountChildren(id uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Permission{}).Where("parent_id = ? AND deleted_at IS NULL", id).Count(&count).Error
+	return count, err
+}
+
+// ListRoutes 获取全部绑定了接口方法与路径的权限
+func (r *permissionRepo) ListRoutes() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	if err := r.db.Where("method <> '' AND deleted_at IS NULL").Order("route, method").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PolicyRepository 策略仓储接口，用于整体导出与导入角色权限模型
+type PolicyRepository interface {
+	GetGrants() ([]*model.PermissionGrant, error)
+	GetBindings() ([]*model.UserRoleBinding, error)
+	Apply(change *model.PolicyChange) error
+}
+
+// policyRepo 策略仓储实现
+type policyRepo struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository 创建策略仓储实例
+func NewPolicyRepository(db *gorm.DB) PolicyRepository {
+	return &policyRepo{db: db}
+}
+
+// GetGrants 获取全部角色直接授予的权限（不含继承），已软删除的角色和权限不参与导出
+func (r *policyRepo) GetGrants() ([]*model.PermissionGrant, error) {
+	var grants []*model.PermissionGrant
+	err := r.db.Table("role_permissions").
+		Select("roles.id AS role_id, roles.code AS role_code, roles.name AS role_name, " +
+			"permissions.id AS permission_id, permissions.code AS permission_code, role_permissions.effect, role_permissions.condition").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Order("roles.code, permissions.code").
+		Scan(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// GetBindings 获取全部用户角色分配，已软删除的用户、角色和租户不参与导出
+func (r *policyRepo) GetBindings() ([]*model.UserRoleBinding, error) {
+	var bindings []*model.UserRoleBinding
+	err := r.db.Table("user_roles").
+		Select("users.id AS user_id, users.username, roles.id AS role_id, roles.code AS role_code, " +
+			"user_roles.tenant_id, COALESCE(tenants.code, '') AS tenant_code").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL").
+		Joins("LEFT JOIN tenants ON tenants.id = user_roles.tenant_id").
+		Where("user_roles.tenant_id = 0 OR tenants.deleted_at IS NULL").
+		Order("users.username, roles.code, user_roles.tenant_id").
+		Scan(&bindings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// Apply 在一个事务内执行导入变更：先创建角色，再删除多余的授予与分配，最后写入新增项
+func (r *policyRepo) Apply(change *model.PolicyChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, role := range change.CreateRoles {
+			if err := tx.Create(role).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, grant := range change.RemoveGrants {
+			if err := tx.Where("role_id = ? AND permission_id = ?", grant.Role.ID, grant.PermissionID).
+				Delete(&model.RolePermission{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, assignment := range change.RemoveAssignments {
+			if err := tx.Where("user_id = ? AND role_id = ? AND tenant_id = ?", assignment.UserID, assignment.Role.ID, assignment.TenantID).
+				Delete(&model.UserRole{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, grant := range change.AddGrants {
+			rolePermission := &model.RolePermission{RoleID: grant.Role.ID, PermissionID: grant.PermissionID, Effect: grant.Effect}
+			if err := tx.Create(rolePermission).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, assignment := range change.AddAssignments {
+			userRole := &model.UserRole{UserID: assignment.UserID, RoleID: assignment.Role.ID, TenantID: assignment.TenantID}
+			if err := tx.Create(userRole).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RelationRepository 关系元组仓储接口
+type RelationRepository interface {
+	Write(tuples []*model.RelationTuple) (int, error)
+	Delete(tuples []*model.RelationTuple) (int, error)
+	List(filter *model.RelationTuple, page, pageSize int) ([]*model.RelationTuple, int64, error)
+	GetSubjects(objectType, objectID, relation string) ([]*model.RelationTuple, error)
+	GetBySubject(subjectType, subjectID, subjectRelation string) ([]*model.RelationTuple, error)
+}
+
+// relationRepo 关系元组仓储实现
+type relationRepo struct {
+	db *gorm.DB
+}
+
+// NewRelationRepository 创建关系元组仓储实例
+func NewRelationRepository(db *gorm.DB) RelationRepository {
+	return &relationRepo{db: db}
+}
+
+// Write 在一个事务内写入关系元组，已存在的元组忽略，返回新写入的数量
+func (r *relationRepo) Write(tuples []*model.RelationTuple) (int, error) {
+	written := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, tuple := range tuples {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(tuple)
+			if result.Error != nil {
+				return result.Error
+			}
+			written += int(result.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// Delete 在一个事务内删除关系元组，不存在的元组忽略，返回删除的数量
+func (r *relationRepo) Delete(tuples []*model.RelationTuple) (int, error) {
+	deleted := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, tuple := range tuples {
+			result := tx.Where(
+				"object_type = ? AND object_id = ? AND relation = ? AND subject_type = ? AND subject_id = ? AND subject_relation = ?",
+				tuple.ObjectType, tuple.ObjectID, tuple.Relation, tuple.SubjectType, tuple.SubjectID, tuple.SubjectRelation,
+			).Delete(&model.RelationTuple{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += int(result.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// List 按条件分页查询关系元组，filter 中的零值字段不作为条件
+func (r *relationRepo) List(filter *model.RelationTuple, page, pageSize int) ([]*model.RelationTuple, int64, error) {
+	var tuples []*model.RelationTuple
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := r.db.Model(&model.RelationTuple{}).Where(filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("id").Find(&tuples).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tuples, total, nil
+}
+
+// GetSubjects 获取对象上某个关系直接写入的全部元组，包括主体为用户集的元组
+func (r *relationRepo) GetSubjects(objectType, objectID, relation string) ([]*model.RelationTuple, error) {
+	var tuples []*model.RelationTuple
+	err := r.db.Where("object_type = ? AND object_id = ? AND relation = ?", objectType, objectID, relation).
+		Order("id").Find(&tuples).Error
+	if err != nil {
+		return nil, err
+	}
+	return tuples, nil
+}
+
+// GetBySubject 获取主体直接出现的全部元组，subjectRelation 为空时只匹配非用户集主体
+func (r *relationRepo) GetBySubject(subjectType, subjectID, subjectRelation string) ([]*model.RelationTuple, error) {
+	var tuples []*model.RelationTuple
+	err := r.db.Where("subject_type = ? AND subject_id = ? AND subject_relation = ?", subjectType, subjectID, subjectRelation).
+		Order("id").Find(&tuples).Error
+	if err != nil {
+		return nil, err
+	}
+	return tuples, nil
+}
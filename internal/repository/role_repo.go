@@ -16,16 +16,46 @@ type RoleRepository interface {
 	Update(role *model.Role) error
 	Delete(id uint64) error
 	GetByID(id uint64) (*model.Role, error)
-	GetByName(name string) (*model.Role, error)
-	GetByCode(code string) (*model.Role, error)
-	List(page, pageSize int, keyword string) ([]*model.Role, int64, error)
+	GetByName(name string, tenantID uint64) (*model.Role, error)
+	GetByCode(code string, tenantID uint64) (*model.Role, error)
+	ListByCode(code string) ([]*model.Role, error)
+	List(page, pageSize int, keyword string, tenantID uint64) ([]*model.Role, int64, error)
 	AddPermissions(roleID uint64, permissionIDs []uint64) error
 	RemovePermissions(roleID uint64, permissionIDs []uint64) error
 	UpdatePermissions(roleID uint64, permissionIDs []uint64) error
+	SetPermissionGrants(roleID uint64, grants []model.RolePermission) error
+	CreateWithGrants(role *model.Role, parentIDs []uint64, grants []model.RolePermission) error
+	SyncTemplate(syncs []*model.RoleTemplateSync) error
 	GetUsersByRoleID(roleID uint64) ([]*model.User, error)
 	GetRoleWithPermissions(roleID uint64) (*model.Role, error)
+	SetParents(roleID uint64, parentIDs []uint64) error
+	GetAncestors(roleID uint64) ([]*model.Role, error)
+	GetDescendants(roleID uint64) ([]*model.Role, error)
+	ListByTemplate(templateCode string) ([]*model.Role, error)
+	SetDataScope(roleID uint64, dataScope string, unitIDs []uint64) error
+	GetDataScopeUnits(roleID uint64) ([]*model.OrgUnit, error)
 }
 
+// 角色祖先查询，UNION 去重可保证继承关系中存在环时递归仍能终止
+const roleAncestorsSQL = `
+WITH RECURSIVE ancestors(id) AS (
+	SELECT role_parents.parent_id FROM role_parents WHERE role_parents.role_id = ?
+	UNION
+	SELECT role_parents.parent_id FROM role_parents
+	JOIN ancestors ON role_parents.role_id = ancestors.id
+)
+SELECT id FROM ancestors`
+
+// 角色后代查询
+const roleDescendantsSQL = `
+WITH RECURSIVE descendants(id) AS (
+	SELECT role_parents.role_id FROM role_parents WHERE role_parents.parent_id = ?
+	UNION
+	SELECT role_parents.role_id FROM role_parents
+	JOIN descendants ON role_parents.parent_id = descendants.id
+)
+SELECT id FROM descendants`
+
 // roleRepo 角色仓储实现
 type roleRepo struct {
 	db *gorm.DB
@@ -61,6 +91,21 @@ func (r *roleRepo) Delete(id uint64) error {
 			return err
 		}
 
+		// 删除用户组与角色的关联
+		if err := tx.Where("role_id = ?", id).Delete(&model.GroupRole{}).Error; err != nil {
+			return err
+		}
+
+		// 删除角色的继承关系
+		if err := tx.Where("role_id = ? OR parent_id = ?", id, id).Delete(&model.RoleParent{}).Error; err != nil {
+			return err
+		}
+
+		// 删除角色自定义数据范围的组织单元
+		if err := tx.Where("role_id = ?", id).Delete(&model.RoleDataScopeUnit{}).Error; err != nil {
+			return err
+		}
+
 		// 软删除角色
 		return tx.Model(&model.Role{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
 	})
@@ -69,7 +114,7 @@ func (r *roleRepo) Delete(id uint64) error {
 // GetByID 根据ID获取角色
 func (r *roleRepo) GetByID(id uint64) (*model.Role, error) {
 	var role model.Role
-	result := r.db.Preload("Permissions").Where("id = ? AND deleted_at IS NULL", id).First(&role)
+	result := r.db.Preload("Permissions").Preload("PermissionGrants").Preload("Parents", "deleted_at IS NULL").Where("id = ? AND deleted_at IS NULL", id).First(&role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil // 角色不存在返回nil，而不是错误
@@ -79,10 +124,10 @@ func (r *roleRepo) GetByID(id uint64) (*model.Role, error) {
 	return &role, nil
 }
 
-// GetByCode 根据编码获取角色
-func (r *roleRepo) GetByCode(code string) (*model.Role, error) {
+// GetByCode 根据编码获取指定租户下的角色，编码只在租户内唯一
+func (r *roleRepo) GetByCode(code string, tenantID uint64) (*model.Role, error) {
 	var role model.Role
-	result := r.db.Preload("Permissions").Where("code = ? AND deleted_at IS NULL", code).First(&role)
+	result := r.db.Preload("Permissions").Where("code = ? AND tenant_id = ? AND deleted_at IS NULL", code, tenantID).First(&role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -92,10 +137,19 @@ func (r *roleRepo) GetByCode(code string) (*model.Role, error) {
 	return &role, nil
 }
 
+// ListByCode 获取全部租户中使用该编码的未删除角色，全局角色排在最前
+func (r *roleRepo) ListByCode(code string) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.Where("code = ? AND deleted_at IS NULL", code).Order("tenant_id, id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
 // GetRoleWithPermissions 获取角色及其权限详情
 func (r *roleRepo) GetRoleWithPermissions(roleID uint64) (*model.Role, error) {
 	var role model.Role
-	result := r.db.Preload("Permissions").Where("id = ? AND deleted_at IS NULL", roleID).First(&role)
+	result := r.db.Preload("Permissions").Preload("PermissionGrants").Where("id = ? AND deleted_at IS NULL", roleID).First(&role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -125,10 +179,10 @@ func (r *roleRepo) GetUsersByRoleID(roleID uint64) ([]*model.User, error) {
 	return users, nil
 }
 
-// GetByName 根据名称获取角色
-func (r *roleRepo) GetByName(name string) (*model.Role, error) {
+// GetByName 根据名称获取指定租户下的角色，名称只在租户内唯一
+func (r *roleRepo) GetByName(name string, tenantID uint64) (*model.Role, error) {
 	var role model.Role
-	result := r.db.Preload("Permissions").Where("name = ? AND deleted_at IS NULL", name).First(&role)
+	result := r.db.Preload("Permissions").Where("name = ? AND tenant_id = ? AND deleted_at IS NULL", name, tenantID).First(&role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -138,8 +192,8 @@ func (r *roleRepo) GetByName(name string) (*model.Role, error) {
 	return &role, nil
 }
 
-// List 获取角色列表
-func (r *roleRepo) List(page, pageSize int, keyword string) ([]*model.Role, int64, error) {
+// List 获取角色列表，tenantID 大于0时只返回该租户的角色及全局角色
+func (r *roleRepo) List(page, pageSize int, keyword string, tenantID uint64) ([]*model.Role, int64, error) {
 	var roles []*model.Role
 	var total int64
 
@@ -160,6 +214,11 @@ func (r *roleRepo) List(page, pageSize int, keyword string) ([]*model.Role, int6
 		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", keyword, keyword)
 	}
 
+	// 按租户过滤
+	if tenantID > 0 {
+		query = query.Where("tenant_id IN (0, ?)", tenantID)
+	}
+
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -167,7 +226,7 @@ func (r *roleRepo) List(page, pageSize int, keyword string) ([]*model.Role, int6
 
 	// 获取分页数据
 	offset := (page - 1) * pageSize
-	if err := query.Preload("Permissions").Offset(offset).Limit(pageSize).Order("id DESC").Find(&roles).Error; err != nil {
+	if err := query.Preload("Permissions").Preload("PermissionGrants").Offset(offset).Limit(pageSize).Order("id DESC").Find(&roles).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -178,38 +237,44 @@ func (r *roleRepo) List(page, pageSize int, keyword string) ([]*model.Role, int6
 func (r *roleRepo) AddPermissions(roleID uint64, permissionIDs []uint64) error {
 	// 开启事务
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// 检查角色是否存在
-		var role model.Role
-		if err := tx.Where("id = ? AND deleted_at IS NULL", roleID).First(&role).Error; err != nil {
-			return err
+		return addPermissions(tx, roleID, permissionIDs)
+	})
+}
+
+// addPermissions 在给定事务内为角色添加允许的权限，已存在的关联保持不变
+func addPermissions(tx *gorm.DB, roleID uint64, permissionIDs []uint64) error {
+	// 检查角色是否存在
+	var role model.Role
+	if err := tx.Where("id = ? AND deleted_at IS NULL", roleID).First(&role).Error; err != nil {
+		return err
+	}
+
+	// 添加权限关联
+	for _, permissionID := range permissionIDs {
+		// 检查权限是否存在
+		var permission model.Permission
+		if err := tx.Where("id = ? AND deleted_at IS NULL", permissionID).First(&permission).Error; err != nil {
+			return fmt.Errorf("权限ID %d 不存在: %w", permissionID, err)
 		}
 
-		// 添加权限关联
-		for _, permissionID := range permissionIDs {
-			// 检查权限是否存在
-			var permission model.Permission
-			if err := tx.Where("id = ? AND deleted_at IS NULL", permissionID).First(&permission).Error; err != nil {
-				return fmt.Errorf("权限ID %d 不存在: %w", permissionID, err)
+		// 检查关联是否已存在
+		var count int64
+		tx.Model(&model.RolePermission{}).Where("role_id = ? AND permission_id = ?", roleID, permissionID).Count(&count)
+		if count == 0 {
+			// 创建关联
+			rolePermission := model.RolePermission{
+				RoleID:       roleID,
+				PermissionID: permissionID,
+				Effect:       model.EffectAllow,
+				CreatedAt:    time.Now().Unix(), // 添加创建时间
 			}
-
-			// 检查关联是否已存在
-			var count int64
-			tx.Model(&model.RolePermission{}).Where("role_id = ? AND permission_id = ?", roleID, permissionID).Count(&count)
-			if count == 0 {
-				// 创建关联
-				rolePermission := model.RolePermission{
-					RoleID:       roleID,
-					PermissionID: permissionID,
-					CreatedAt:    time.Now().Unix(), // 添加创建时间
-				}
-				if err := tx.Create(&rolePermission).Error; err != nil {
-					return err
-				}
+			if err := tx.Create(&rolePermission).Error; err != nil {
+				return err
 			}
 		}
+	}
 
-		return nil
-	})
+	return nil
 }
 
 // RemovePermissions 移除角色的权限
@@ -217,20 +282,220 @@ func (r *roleRepo) RemovePermissions(roleID uint64, permissionIDs []uint64) erro
 	return r.db.Where("role_id = ? AND permission_id IN ?", roleID, permissionIDs).Delete(&model.RolePermission{}).Error
 }
 
-// UpdatePermissions 更新角色的权限
+// UpdatePermissions 更新角色允许的权限，拒绝规则保持不变
 func (r *roleRepo) UpdatePermissions(roleID uint64, permissionIDs []uint64) error {
 	// 开启事务
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// 删除所有现有权限
-		if err := tx.Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+		// 删除所有现有的允许权限
+		if err := tx.Where("role_id = ? AND effect = ?", roleID, model.EffectAllow).Delete(&model.RolePermission{}).Error; err != nil {
 			return err
 		}
 
 		// 添加新权限
 		if len(permissionIDs) > 0 {
-			return r.AddPermissions(roleID, permissionIDs)
+			return addPermissions(tx, roleID, permissionIDs)
+		}
+
+		return nil
+	})
+}
+
+// SetPermissionGrants 设置角色的权限授予记录（覆盖原有的允许与拒绝规则）
+func (r *roleRepo) SetPermissionGrants(roleID uint64, grants []model.RolePermission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setPermissionGrants(tx, roleID, grants)
+	})
+}
+
+// setPermissionGrants 在给定事务内覆盖角色的权限授予记录
+func setPermissionGrants(tx *gorm.DB, roleID uint64, grants []model.RolePermission) error {
+	// 检查角色是否存在
+	var role model.Role
+	if err := tx.Where("id = ? AND deleted_at IS NULL", roleID).First(&role).Error; err != nil {
+		return err
+	}
+
+	// 删除所有现有授予记录
+	if err := tx.Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+
+	for _, grant := range grants {
+		// 检查权限是否存在
+		var count int64
+		if err := tx.Model(&model.Permission{}).Where("id = ? AND deleted_at IS NULL", grant.PermissionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("权限ID %d 不存在: %w", grant.PermissionID, gorm.ErrRecordNotFound)
+		}
+
+		rolePermission := model.RolePermission{
+			RoleID:       roleID,
+			PermissionID: grant.PermissionID,
+			Effect:       grant.Effect,
+			Condition:    grant.Condition,
+		}
+		if err := tx.Create(&rolePermission).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetParents 设置角色的父角色（覆盖原有继承关系）
+func (r *roleRepo) SetParents(roleID uint64, parentIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setParents(tx, roleID, parentIDs)
+	})
+}
+
+// setParents 在给定事务内覆盖角色的父角色
+func setParents(tx *gorm.DB, roleID uint64, parentIDs []uint64) error {
+	// 删除所有现有父角色
+	if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleParent{}).Error; err != nil {
+		return err
+	}
+
+	// 添加新的父角色
+	for _, parentID := range parentIDs {
+		var count int64
+		if err := tx.Model(&model.Role{}).Where("id = ? AND deleted_at IS NULL", parentID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("父角色ID %d 不存在: %w", parentID, gorm.ErrRecordNotFound)
+		}
+
+		if err := tx.Create(&model.RoleParent{RoleID: roleID, ParentID: parentID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateWithGrants 在一个事务内创建角色并写入其父角色与权限授予记录，任一步失败时不留下角色
+func (r *roleRepo) CreateWithGrants(role *model.Role, parentIDs []uint64, grants []model.RolePermission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
 		}
 
+		if len(parentIDs) > 0 {
+			if err := setParents(tx, role.ID, parentIDs); err != nil {
+				return err
+			}
+		}
+
+		return setPermissionGrants(tx, role.ID, grants)
+	})
+}
+
+// SyncTemplate 在一个事务内写入模板同步的全部变更，任一角色写入失败时全部回滚
+func (r *roleRepo) SyncTemplate(syncs []*model.RoleTemplateSync) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, sync := range syncs {
+			if sync.Grants != nil {
+				if err := setPermissionGrants(tx, sync.RoleID, sync.Grants); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&model.Role{}).Where("id = ?", sync.RoleID).Update("template_version", sync.Version).Error; err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
+
+// GetAncestors 获取角色的全部祖先角色（含间接继承）
+func (r *roleRepo) GetAncestors(roleID uint64) ([]*model.Role, error) {
+	return r.findRolesBySQL(roleAncestorsSQL, roleID)
+}
+
+// GetDescendants 获取继承自该角色的全部后代角色（含间接继承）
+func (r *roleRepo) GetDescendants(roleID uint64) ([]*model.Role, error) {
+	return r.findRolesBySQL(roleDescendantsSQL, roleID)
+}
+
+// ListByTemplate 获取由指定模板创建的全部未删除角色及其权限授予
+func (r *roleRepo) ListByTemplate(templateCode string) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.Preload("Permissions").Preload("PermissionGrants").Where("template_code = ? AND deleted_at IS NULL", templateCode).Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// SetDataScope 设置角色的数据范围，unitIDs 仅在数据范围为 custom 时保存，其余范围会清空已有的组织单元
+func (r *roleRepo) SetDataScope(roleID uint64, dataScope string, unitIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Role{}).Where("id = ?", roleID).Update("data_scope", dataScope).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleDataScopeUnit{}).Error; err != nil {
+			return err
+		}
+
+		if dataScope != model.DataScopeCustom {
+			return nil
+		}
+
+		seen := make(map[uint64]struct{}, len(unitIDs))
+		for _, unitID := range unitIDs {
+			if _, ok := seen[unitID]; ok {
+				continue
+			}
+			seen[unitID] = struct{}{}
+
+			var count int64
+			if err := tx.Model(&model.OrgUnit{}).Where("id = ? AND deleted_at IS NULL", unitID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("组织单元ID %d 不存在: %w", unitID, gorm.ErrRecordNotFound)
+			}
+
+			if err := tx.Create(&model.RoleDataScopeUnit{RoleID: roleID, OrgUnitID: unitID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDataScopeUnits 获取角色自定义数据范围内未删除的组织单元
+func (r *roleRepo) GetDataScopeUnits(roleID uint64) ([]*model.OrgUnit, error) {
+	var units []*model.OrgUnit
+	err := r.db.Model(&model.OrgUnit{}).
+		Joins("JOIN role_data_scope_units ON role_data_scope_units.org_unit_id = org_units.id").
+		Where("role_data_scope_units.role_id = ? AND org_units.deleted_at IS NULL", roleID).
+		Order("org_units.id").
+		Find(&units).Error
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// findRolesBySQL 根据递归查询得到的角色ID加载未删除的角色及其权限
+func (r *roleRepo) findRolesBySQL(sql string, roleID uint64) ([]*model.Role, error) {
+	var ids []uint64
+	if err := r.db.Raw(sql, roleID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make([]*model.Role, 0, len(ids))
+	if len(ids) == 0 {
+		return roles, nil
+	}
+
+	if err := r.db.Preload("Permissions").Preload("PermissionGrants").Where("id IN ? AND deleted_at IS NULL", ids).Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SoDRepository 职责分离规则仓储接口
+type SoDRepository interface {
+	Create(rule *model.SoDRule, roleIDs []uint64) error
+	Update(rule *model.SoDRule, roleIDs []uint64) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*model.SoDRule, error)
+	GetByName(name string) (*model.SoDRule, error)
+	List(page, pageSize int, keyword, ruleType string) ([]*model.SoDRule, int64, error)
+	ListByType(ruleType string) ([]*model.SoDRule, error)
+	GetUserAssignments(userID uint64) ([]*model.RoleAssignment, error)
+	GetRoleAssignments(roleIDs []uint64) ([]*model.RoleAssignment, error)
+	PreviewAssignments(userIDs []uint64, change *model.GroupChange) ([]*model.RoleAssignment, error)
+}
+
+// sodRepo 职责分离规则仓储实现
+type sodRepo struct {
+	db *gorm.DB
+}
+
+// NewSoDRepository 创建职责分离规则仓储实例
+func NewSoDRepository(db *gorm.DB) SoDRepository {
+	return &sodRepo{db: db}
+}
+
+// Create 创建规则及其角色关联
+func (r *sodRepo) Create(rule *model.SoDRule, roleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(rule).Error; err != nil {
+			return err
+		}
+		return replaceSoDRoles(tx, rule, roleIDs)
+	})
+}
+
+// Update 更新规则并替换其角色
+func (r *sodRepo) Update(rule *model.SoDRule, roleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 描述允许清空，因此显式指定更新的字段
+		if err := tx.Model(rule).Select("name", "description", "type", "max_roles", "updated_at").Updates(rule).Error; err != nil {
+			return err
+		}
+		return replaceSoDRoles(tx, rule, roleIDs)
+	})
+}
+
+// replaceSoDRoles 替换规则的角色关联
+func replaceSoDRoles(tx *gorm.DB, rule *model.SoDRule, roleIDs []uint64) error {
+	roles := make([]model.Role, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		roles = append(roles, model.Role{ID: id})
+	}
+	return tx.Model(rule).Association("Roles").Replace(roles)
+}
+
+// Delete 删除规则（软删除），同时移除角色关联
+func (r *sodRepo) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM sod_rule_roles WHERE rule_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.SoDRule{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
+	})
+}
+
+// GetByID 根据ID获取规则及其未删除的角色
+func (r *sodRepo) GetByID(id uint64) (*model.SoDRule, error) {
+	return r.getBy("id = ?", id)
+}
+
+// GetByName 根据名称获取规则
+func (r *sodRepo) GetByName(name string) (*model.SoDRule, error) {
+	return r.getBy("name = ?", name)
+}
+
+// getBy 按条件获取未删除的规则，不存在时返回nil
+func (r *sodRepo) getBy(condition string, value interface{}) (*model.SoDRule, error) {
+	var rule model.SoDRule
+	result := r.db.Preload("Roles", "deleted_at IS NULL").Where(condition+" AND deleted_at IS NULL", value).First(&rule)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // 规则不存在返回nil，而不是错误
+		}
+		return nil, result.Error
+	}
+	return &rule, nil
+}
+
+// List 获取规则列表
+func (r *sodRepo) List(page, pageSize int, keyword, ruleType string) ([]*model.SoDRule, int64, error) {
+	var rules []*model.SoDRule
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	// 构建查询
+	query := r.db.Model(&model.SoDRule{}).Where("deleted_at IS NULL")
+
+	// 添加关键词搜索
+	if keyword != "" {
+		keyword = fmt.Sprintf("%%%s%%", strings.ToLower(keyword))
+		query = query.Where("LOWER(name) LIKE ?", keyword)
+	}
+
+	if ruleType != "" {
+		query = query.Where("type = ?", ruleType)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Roles", "deleted_at IS NULL").Offset(offset).Limit(pageSize).Order("id DESC").Find(&rules).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rules, total, nil
+}
+
+// ListByType 获取指定类型的全部未删除规则及其角色，用于校验角色分配与激活
+func (r *sodRepo) ListByType(ruleType string) ([]*model.SoDRule, error) {
+	var rules []*model.SoDRule
+	if err := r.db.Preload("Roles", "deleted_at IS NULL").Where("type = ? AND deleted_at IS NULL", ruleType).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetUserAssignments 获取用户在所有租户内未过期且角色未删除的角色分配，包括尚未生效的分配
+// 以及经由直接或间接所属的用户组获得的角色
+func (r *sodRepo) GetUserAssignments(userID uint64) ([]*model.RoleAssignment, error) {
+	return r.findAssignments([]uint64{userID}, nil, nil)
+}
+
+// GetRoleAssignments 获取指定角色未过期的全部分配（含经由用户组的分配），用于查找违反规则的用户
+func (r *sodRepo) GetRoleAssignments(roleIDs []uint64) ([]*model.RoleAssignment, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+	return r.findAssignments(nil, roleIDs, nil)
+}
+
+// PreviewAssignments 获取指定用户在用户组变更写入后的全部角色分配，change 为nil时按当前状态查询
+func (r *sodRepo) PreviewAssignments(userIDs []uint64, change *model.GroupChange) ([]*model.RoleAssignment, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.findAssignments(userIDs, nil, change)
+}
+
+// 角色分配递归查询，先求出每个用户直接或经由嵌套组间接所属的全部用户组，
+// 再合并直接分配（GroupID 为0）与分配给这些组的角色；
+// group_memberships 与 group_edges 为成员与嵌套关系，预览用户组变更时在其中替换
+const sodAssignmentsSQL = `
+WITH RECURSIVE group_memberships(user_id, group_id) AS (
+	%s
+),
+group_edges(group_id, parent_id) AS (
+	%s
+),
+member_groups(user_id, group_id) AS (
+	SELECT group_memberships.user_id, group_memberships.group_id FROM group_memberships
+	JOIN user_groups ON user_groups.id = group_memberships.group_id AND user_groups.deleted_at IS NULL
+	UNION
+	SELECT member_groups.user_id, group_edges.parent_id FROM group_edges
+	JOIN member_groups ON group_edges.group_id = member_groups.group_id
+	JOIN user_groups ON user_groups.id = group_edges.parent_id AND user_groups.deleted_at IS NULL
+),
+assignments(user_id, role_id, tenant_id, group_id) AS (
+	SELECT user_roles.user_id, user_roles.role_id, user_roles.tenant_id, 0 FROM user_roles
+	WHERE user_roles.valid_until IS NULL OR user_roles.valid_until > @now
+	UNION
+	SELECT member_groups.user_id, group_roles.role_id, group_roles.tenant_id, group_roles.group_id FROM group_roles
+	JOIN member_groups ON member_groups.group_id = group_roles.group_id
+)
+SELECT assignments.*, roles.code AS role_code, roles.name AS role_name FROM assignments
+JOIN roles ON roles.id = assignments.role_id AND roles.deleted_at IS NULL
+JOIN users ON users.id = assignments.user_id AND users.deleted_at IS NULL
+WHERE %s
+ORDER BY assignments.user_id, assignments.tenant_id, assignments.role_id, assignments.group_id`
+
+// findAssignments 查询未过期且角色未删除的角色分配，userIDs 与 roleIDs 至少指定一个，为nil时不按其过滤
+func (r *sodRepo) findAssignments(userIDs, roleIDs []uint64, change *model.GroupChange) ([]*model.RoleAssignment, error) {
+	args := map[string]interface{}{"now": time.Now().Unix()}
+	memberships := "SELECT group_members.user_id, group_members.group_id FROM group_members"
+	edges := "SELECT group_parents.group_id, group_parents.parent_id FROM group_parents"
+	var conditions []string
+
+	if userIDs != nil {
+		args["users"] = userIDs
+		memberships += " WHERE group_members.user_id IN @users"
+		conditions = append(conditions, "assignments.user_id IN @users")
+	}
+	if roleIDs != nil {
+		args["roles"] = roleIDs
+		conditions = append(conditions, "assignments.role_id IN @roles")
+	}
+
+	if change != nil {
+		args["group"] = change.GroupID
+		if len(change.Members) > 0 {
+			args["members"] = change.Members
+			memberships += `
+	UNION
+	SELECT users.id, user_groups.id FROM users, user_groups WHERE users.id IN @members AND user_groups.id = @group`
+		}
+		if change.Parents != nil {
+			edges += " WHERE group_parents.group_id <> @group"
+			if len(change.Parents) > 0 {
+				args["parents"] = change.Parents
+				edges += `
+	UNION
+	SELECT child.id, parent.id FROM user_groups AS child, user_groups AS parent WHERE child.id = @group AND parent.id IN @parents`
+			}
+		}
+	}
+
+	var assignments []*model.RoleAssignment
+	query := fmt.Sprintf(sodAssignmentsSQL, memberships, edges, strings.Join(conditions, " AND "))
+	if err := r.db.Raw(query, args).Scan(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TenantRepository 租户仓储接口
+type TenantRepository interface {
+	Create(tenant *model.Tenant) error
+	Update(tenant *model.Tenant) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*model.Tenant, error)
+	GetByCode(code string) (*model.Tenant, error)
+	GetByName(name string) (*model.Tenant, error)
+	List(page, pageSize int, keyword string) ([]*model.Tenant, int64, error)
+	CountUsage(id uint64) (int64, error)
+}
+
+// tenantRepo 租户仓储实现
+type tenantRepo struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository 创建租户仓储实例
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepo{db: db}
+}
+
+// Create 创建租户
+func (r *tenantRepo) Create(tenant *model.Tenant) error {
+	return r.db.Create(tenant).Error
+}
+
+// Update 更新租户
+func (r *tenantRepo) Update(tenant *model.Tenant) error {
+	// 只更新非零值字段
+	return r.db.Model(tenant).Updates(tenant).Error
+}
+
+// Delete 删除租户（软删除）
+func (r *tenantRepo) Delete(id uint64) error {
+	return r.db.Model(&model.Tenant{}).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error
+}
+
+// GetByID 根据ID获取租户
+func (r *tenantRepo) GetByID(id uint64) (*model.Tenant, error) {
+	return r.getBy("id = ?", id)
+}
+
+// GetByCode 根据编码获取租户
+func (r *tenantRepo) GetByCode(code string) (*model.Tenant, error) {
+	return r.getBy("code = ?", code)
+}
+
+// GetByName 根据名称获取租户
+func (r *tenantRepo) GetByName(name string) (*model.Tenant, error) {
+	return r.getBy("name = ?", name)
+}
+
+// getBy 按条件获取未删除的租户，不存在时返回nil
+func (r *tenantRepo) getBy(condition string, value interface{}) (*model.Tenant, error) {
+	var tenant model.Tenant
+	result := r.db.Where(condition+" AND deleted_at IS NULL", value).First(&tenant)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // 租户不存在返回nil，而不是错误
+		}
+		return nil, result.Error
+	}
+	return &tenant, nil
+}
+
+// List 获取租户列表
+func (r *tenantRepo) List(page, pageSize int, keyword string) ([]*model.Tenant, int64, error) {
+	var tenants []*model.Tenant
+	var total int64
+
+	// 默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	// 构建查询
+	query := r.db.Model(&model.Tenant{}).Where("deleted_at IS NULL")
+
+	// 添加关键词搜索
+	if keyword != "" {
+		keyword = fmt.Sprintf("%%%s%%", strings.ToLower(keyword))
+		query = query.Where("LOWER(code) LIKE ? OR LOWER(name) LIKE ?", keyword, keyword)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取分页数据
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("id DESC").Find(&tenants).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tenants, total, nil
+}
+
+// CountUsage 统计租户下未删除的角色数、用户与用户组的角色分配数及直接授予用户的权限数之和
+func (r *tenantRepo) CountUsage(id uint64) (int64, error) {
+	var roles, assignments, groupAssignments, permissions int64
+	if err := r.db.Model(&model.Role{}).Where("tenant_id = ? AND deleted_at IS NULL", id).Count(&roles).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.UserRole{}).Where("tenant_id = ?", id).Count(&assignments).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.GroupRole{}).Where("tenant_id = ?", id).Count(&groupAssignments).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.UserPermission{}).Where("tenant_id = ?", id).Count(&permissions).Error; err != nil {
+		return 0, err
+	}
+	return roles + assignments + groupAssignments + permissions, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/lvyunze/fiber-rbac/internal/model"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
@@ -17,11 +18,80 @@ type UserRepository interface {
 	GetByID(id uint64) (*model.User, error)
 	GetByUsername(username string) (*model.User, error)
 	GetByEmail(email string) (*model.User, error)
-	List(page, pageSize int, keyword string) ([]*model.User, int64, error)
-	AddRoles(userID uint64, roleIDs []uint64) error
-	RemoveRoles(userID uint64, roleIDs []uint64) error
-	UpdateRoles(userID uint64, roleIDs []uint64) error
+	List(page, pageSize int, keyword string, tenantID uint64, scope *model.DataScope) ([]*model.User, int64, error)
+	AddRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error
+	RemoveRoles(userID, tenantID uint64, roleIDs []uint64) error
+	UpdateRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error
+	DeleteExpiredRoles(now int64) ([]model.UserRole, error)
 	GetUserWithRoles(userID uint64) (*model.User, error)
+	HasTenantAccess(userID, tenantID uint64) (bool, error)
+	GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error)
+	GetEffectiveRoles(userID, tenantID uint64) ([]*model.Role, error)
+	GetEffectivePermissionGrants(userID, tenantID uint64) ([]*model.PermissionGrant, error)
+	GetNextValidityChange(userID, tenantID uint64) (int64, error)
+	GetRoleAssignments(userID, tenantID uint64) ([]*model.RoleAssignment, error)
+	GetEffectiveRoleParents(userID, tenantID uint64) ([]*model.RoleInheritance, error)
+	AddPermissions(userID, tenantID uint64, permissionIDs []uint64, effect string) error
+	RemovePermissions(userID, tenantID uint64, permissionIDs []uint64) error
+	GetDirectPermissions(userID uint64) ([]*model.UserPermissionDetail, error)
+	GetGroups(userID uint64) ([]*model.Group, error)
+	GetEffectiveGroupParents(userID uint64) ([]*model.GroupInheritance, error)
+	GetGroupRoleAssignments(userID uint64) ([]*model.GroupRoleAssignment, error)
+	GetDataScope(userID, tenantID uint64) (*model.DataScope, error)
+}
+
+// 用户有效角色递归查询，先求出用户直接或经由嵌套组间接所属的全部用户组，
+// 再求出直接分配给用户、分配给这些组的角色及其全部祖先角色，
+// UNION 去重可保证组嵌套或角色继承关系中存在环时递归仍能终止。
+// 仅计入全局分配及该租户内的分配，直接分配还须在当前时刻生效，参数见 effectiveRolesArgs
+const effectiveRolesCTE = `
+WITH RECURSIVE member_groups(group_id) AS (
+	SELECT group_members.group_id FROM group_members
+	JOIN users ON users.id = group_members.user_id AND users.deleted_at IS NULL
+	JOIN user_groups ON user_groups.id = group_members.group_id AND user_groups.deleted_at IS NULL
+	WHERE group_members.user_id = @user
+	UNION
+	SELECT group_parents.parent_id FROM group_parents
+	JOIN member_groups ON group_parents.group_id = member_groups.group_id
+	JOIN user_groups ON user_groups.id = group_parents.parent_id AND user_groups.deleted_at IS NULL
+),
+effective_roles(role_id) AS (
+	SELECT user_roles.role_id FROM user_roles
+	JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL
+	JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL
+	WHERE user_roles.user_id = @user AND user_roles.tenant_id IN (0, @tenant) AND roles.tenant_id IN (0, @tenant)
+	AND (user_roles.valid_from IS NULL OR user_roles.valid_from <= @now)
+	AND (user_roles.valid_until IS NULL OR user_roles.valid_until > @now)
+	UNION
+	SELECT group_roles.role_id FROM group_roles
+	JOIN member_groups ON member_groups.group_id = group_roles.group_id
+	JOIN roles ON roles.id = group_roles.role_id AND roles.deleted_at IS NULL
+	WHERE group_roles.tenant_id IN (0, @tenant) AND roles.tenant_id IN (0, @tenant)
+	UNION
+	SELECT role_parents.parent_id FROM role_parents
+	JOIN effective_roles ON role_parents.role_id = effective_roles.role_id
+	JOIN roles ON roles.id = role_parents.parent_id AND roles.deleted_at IS NULL
+)`
+
+// 用户所属用户组的递归查询，包含用户直接所属的组及经由嵌套关系间接所属的全部上级组
+const memberGroupsCTE = `
+WITH RECURSIVE member_groups(group_id) AS (
+	SELECT group_members.group_id FROM group_members
+	JOIN user_groups ON user_groups.id = group_members.group_id AND user_groups.deleted_at IS NULL
+	WHERE group_members.user_id = ?
+	UNION
+	SELECT group_parents.parent_id FROM group_parents
+	JOIN member_groups ON group_parents.group_id = member_groups.group_id
+	JOIN user_groups ON user_groups.id = group_parents.parent_id AND user_groups.deleted_at IS NULL
+)`
+
+// effectiveRolesArgs 构造 effectiveRolesCTE 的命名参数
+func effectiveRolesArgs(userID, tenantID uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"user":   userID,
+		"tenant": tenantID,
+		"now":    model.NowUnix(),
+	}
 }
 
 // userRepo 用户仓储实现
@@ -103,8 +173,261 @@ func (r *userRepo) GetUserWithRoles(userID uint64) (*model.User, error) {
 	return &user, nil
 }
 
-// List 获取用户列表
-func (r *userRepo) List(page, pageSize int, keyword string) ([]*model.User, int64, error) {
+// HasTenantAccess 判断用户能否进入指定租户
+// 用户在该租户内有生效的角色分配、所属的用户组在该租户内有角色分配，
+// 或拥有跨租户生效的全局角色分配时可进入
+func (r *userRepo) HasTenantAccess(userID, tenantID uint64) (bool, error) {
+	var count int64
+	now := model.NowUnix()
+	err := r.db.Model(&model.UserRole{}).
+		Where("user_id = ? AND tenant_id IN (0, ?)", userID, tenantID).
+		Where("(valid_from IS NULL OR valid_from <= ?) AND (valid_until IS NULL OR valid_until > ?)", now, now).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = r.db.Raw(memberGroupsCTE+`
+SELECT COUNT(*) FROM group_roles
+JOIN member_groups ON member_groups.group_id = group_roles.group_id
+WHERE group_roles.tenant_id IN (0, ?)`, userID, tenantID).Scan(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetEffectiveRoleIDs 获取用户在指定租户内的有效角色ID（含全局角色及继承的祖先角色）
+func (r *userRepo) GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error) {
+	var roleIDs []uint64
+	if err := r.db.Raw(effectiveRolesCTE+`
+SELECT role_id FROM effective_roles ORDER BY role_id`, effectiveRolesArgs(userID, tenantID)).Scan(&roleIDs).Error; err != nil {
+		return nil, err
+	}
+	return roleIDs, nil
+}
+
+// GetEffectiveRoles 获取用户在指定租户内的有效角色，与 GetEffectiveRoleIDs 使用同一递归查询
+func (r *userRepo) GetEffectiveRoles(userID, tenantID uint64) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Raw(effectiveRolesCTE+`
+SELECT roles.* FROM roles
+JOIN effective_roles ON effective_roles.role_id = roles.id
+ORDER BY roles.id`, effectiveRolesArgs(userID, tenantID)).Scan(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetEffectivePermissionGrants 通过一次递归联表查询获取用户在指定租户内的全部权限授予记录
+// 包含全局分配与从父角色继承的授予（含拒绝）以及直接授予用户的权限（RoleID 为0），
+// 已软删除的用户、角色和权限均不参与计算
+func (r *userRepo) GetEffectivePermissionGrants(userID, tenantID uint64) ([]*model.PermissionGrant, error) {
+	var grants []*model.PermissionGrant
+	err := r.db.Raw(effectiveRolesCTE+`
+SELECT roles.id AS role_id, roles.code AS role_code, roles.name AS role_name,
+	permissions.id AS permission_id, permissions.code AS permission_code, role_permissions.effect, role_permissions.condition
+FROM effective_roles
+JOIN roles ON roles.id = effective_roles.role_id
+JOIN role_permissions ON role_permissions.role_id = effective_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL
+UNION ALL
+SELECT 0 AS role_id, '' AS role_code, '' AS role_name,
+	permissions.id AS permission_id, permissions.code AS permission_code, user_permissions.effect, '' AS condition
+FROM user_permissions
+JOIN users ON users.id = user_permissions.user_id AND users.deleted_at IS NULL
+JOIN permissions ON permissions.id = user_permissions.permission_id AND permissions.deleted_at IS NULL
+WHERE user_permissions.user_id = @user AND user_permissions.tenant_id IN (0, @tenant)
+ORDER BY permission_code, role_id`, effectiveRolesArgs(userID, tenantID)).Scan(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// GetNextValidityChange 获取用户在指定租户内下一次有角色分配生效或失效的时间，
+// 不存在时返回0；缓存有效权限时用于在该时刻之前让缓存过期
+func (r *userRepo) GetNextValidityChange(userID, tenantID uint64) (int64, error) {
+	var next *int64
+	err := r.db.Raw(`
+SELECT MIN(t) FROM (
+	SELECT valid_from AS t FROM user_roles WHERE user_id = @user AND tenant_id IN (0, @tenant) AND valid_from > @now
+	UNION ALL
+	SELECT valid_until AS t FROM user_roles WHERE user_id = @user AND tenant_id IN (0, @tenant) AND valid_until > @now
+) AS changes`, effectiveRolesArgs(userID, tenantID)).Scan(&next).Error
+	if err != nil || next == nil {
+		return 0, err
+	}
+	return *next, nil
+}
+
+// GetRoleAssignments 获取用户在指定租户内可能生效的全部角色分配（含全局分配），
+// 包括未生效、已过期以及所分配角色已删除的记录，用于解释权限判定
+func (r *userRepo) GetRoleAssignments(userID, tenantID uint64) ([]*model.RoleAssignment, error) {
+	var assignments []*model.RoleAssignment
+	err := r.db.Table("user_roles").
+		Select("user_roles.*, roles.code AS role_code, roles.name AS role_name, roles.deleted_at AS role_deleted_at").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND user_roles.tenant_id IN (0, ?)", userID, tenantID).
+		Order("user_roles.tenant_id, user_roles.role_id").
+		Scan(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// GetEffectiveRoleParents 获取用户有效角色之间的继承关系，与有效权限使用同一递归查询，
+// 已软删除的父角色不会出现在结果中
+func (r *userRepo) GetEffectiveRoleParents(userID, tenantID uint64) ([]*model.RoleInheritance, error) {
+	var parents []*model.RoleInheritance
+	err := r.db.Raw(effectiveRolesCTE+`
+SELECT DISTINCT role_parents.role_id, role_parents.parent_id, roles.code AS parent_code, roles.name AS parent_name
+FROM role_parents
+JOIN effective_roles ON effective_roles.role_id = role_parents.role_id
+JOIN roles ON roles.id = role_parents.parent_id AND roles.deleted_at IS NULL
+ORDER BY role_parents.role_id, role_parents.parent_id`, effectiveRolesArgs(userID, tenantID)).Scan(&parents).Error
+	if err != nil {
+		return nil, err
+	}
+	return parents, nil
+}
+
+// GetGroups 获取用户直接所属的未删除用户组
+func (r *userRepo) GetGroups(userID uint64) ([]*model.Group, error) {
+	var groups []*model.Group
+	err := r.db.Joins("JOIN group_members ON group_members.group_id = user_groups.id").
+		Where("group_members.user_id = ? AND user_groups.deleted_at IS NULL", userID).
+		Order("user_groups.id").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetEffectiveGroupParents 获取用户所属用户组之间的嵌套关系，与有效角色使用同样的递归方式，
+// 已删除的上级组不会出现在结果中
+func (r *userRepo) GetEffectiveGroupParents(userID uint64) ([]*model.GroupInheritance, error) {
+	var parents []*model.GroupInheritance
+	err := r.db.Raw(memberGroupsCTE+`
+SELECT DISTINCT group_parents.group_id, group_parents.parent_id, user_groups.code AS parent_code, user_groups.name AS parent_name
+FROM group_parents
+JOIN member_groups ON member_groups.group_id = group_parents.group_id
+JOIN user_groups ON user_groups.id = group_parents.parent_id AND user_groups.deleted_at IS NULL
+ORDER BY group_parents.group_id, group_parents.parent_id`, userID).Scan(&parents).Error
+	if err != nil {
+		return nil, err
+	}
+	return parents, nil
+}
+
+// GetGroupRoleAssignments 获取用户直接或间接所属的用户组在各租户内的角色分配，已删除的角色不返回
+func (r *userRepo) GetGroupRoleAssignments(userID uint64) ([]*model.GroupRoleAssignment, error) {
+	var assignments []*model.GroupRoleAssignment
+	err := r.db.Raw(memberGroupsCTE+`
+SELECT group_roles.*, user_groups.code AS group_code, user_groups.name AS group_name, roles.code AS role_code, roles.name AS role_name
+FROM group_roles
+JOIN member_groups ON member_groups.group_id = group_roles.group_id
+JOIN user_groups ON user_groups.id = group_roles.group_id
+JOIN roles ON roles.id = group_roles.role_id AND roles.deleted_at IS NULL
+ORDER BY group_roles.tenant_id, group_roles.role_id, group_roles.group_id`, userID).Scan(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// GetDataScope 解析用户在指定租户内的数据范围，合并全部有效角色（含继承的角色与经由用户组获得的角色）：
+// 任一角色可访问全部数据时不受限制，否则取各角色可访问范围的并集；没有有效角色时只能访问本人的数据
+func (r *userRepo) GetDataScope(userID, tenantID uint64) (*model.DataScope, error) {
+	var roles []struct {
+		ID        uint64
+		DataScope string
+	}
+	err := r.db.Raw(effectiveRolesCTE+`
+SELECT roles.id, roles.data_scope FROM roles
+JOIN effective_roles ON effective_roles.role_id = roles.id
+ORDER BY roles.id`, effectiveRolesArgs(userID, tenantID)).Scan(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &model.DataScope{UserID: userID, UnitIDs: []uint64{}}
+	if len(roles) == 0 {
+		scope.Self = true
+		return scope, nil
+	}
+
+	var ownUnits, withChildren bool
+	var customRoleIDs []uint64
+	for _, role := range roles {
+		switch role.DataScope {
+		case model.DataScopeAll:
+			return &model.DataScope{UserID: userID, All: true}, nil
+		case model.DataScopeUnit:
+			ownUnits = true
+		case model.DataScopeUnitAndChildren:
+			withChildren = true
+		case model.DataScopeSelf:
+			scope.Self = true
+		case model.DataScopeCustom:
+			customRoleIDs = append(customRoleIDs, role.ID)
+		}
+	}
+
+	var unitIDs []uint64
+	if ownUnits || withChildren {
+		var memberUnitIDs []uint64
+		err := r.db.Model(&model.OrgUnitMember{}).
+			Joins("JOIN org_units ON org_units.id = org_unit_members.org_unit_id AND org_units.deleted_at IS NULL").
+			Where("org_unit_members.user_id = ?", userID).
+			Pluck("org_unit_members.org_unit_id", &memberUnitIDs).Error
+		if err != nil {
+			return nil, err
+		}
+
+		unitIDs = append(unitIDs, memberUnitIDs...)
+
+		// 包含所属单元的全部下级单元
+		if withChildren && len(memberUnitIDs) > 0 {
+			var subtreeIDs []uint64
+			if err := r.db.Raw(orgUnitSubtreeSQL, memberUnitIDs).Scan(&subtreeIDs).Error; err != nil {
+				return nil, err
+			}
+			unitIDs = append(unitIDs, subtreeIDs...)
+		}
+	}
+
+	if len(customRoleIDs) > 0 {
+		var customUnitIDs []uint64
+		err := r.db.Model(&model.RoleDataScopeUnit{}).
+			Joins("JOIN org_units ON org_units.id = role_data_scope_units.org_unit_id AND org_units.deleted_at IS NULL").
+			Where("role_data_scope_units.role_id IN ?", customRoleIDs).
+			Pluck("role_data_scope_units.org_unit_id", &customUnitIDs).Error
+		if err != nil {
+			return nil, err
+		}
+		unitIDs = append(unitIDs, customUnitIDs...)
+	}
+
+	// 去重并排序，便于比较与构造查询
+	seen := make(map[uint64]struct{}, len(unitIDs))
+	for _, unitID := range unitIDs {
+		if _, ok := seen[unitID]; ok {
+			continue
+		}
+		seen[unitID] = struct{}{}
+		scope.UnitIDs = append(scope.UnitIDs, unitID)
+	}
+	sort.Slice(scope.UnitIDs, func(i, j int) bool { return scope.UnitIDs[i] < scope.UnitIDs[j] })
+	return scope, nil
+}
+
+// List 获取用户列表，tenantID 大于0时只返回在该租户内有角色分配的用户，
+// scope 不为nil时只返回数据范围内的用户
+func (r *userRepo) List(page, pageSize int, keyword string, tenantID uint64, scope *model.DataScope) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
@@ -125,6 +448,14 @@ func (r *userRepo) List(page, pageSize int, keyword string) ([]*model.User, int6
 		query = query.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", keyword, keyword)
 	}
 
+	// 按租户过滤
+	if tenantID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM user_roles WHERE user_roles.user_id = users.id AND user_roles.tenant_id = ?)", tenantID)
+	}
+
+	// 按数据范围过滤
+	query = query.Scopes(WithDataScope(scope, "users.id"))
+
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -139,9 +470,62 @@ func (r *userRepo) List(page, pageSize int, keyword string) ([]*model.User, int6
 	return users, total, nil
 }
 
-// AddRoles 为用户添加角色
-func (r *userRepo) AddRoles(userID uint64, roleIDs []uint64) error {
+// AddRoles 在指定租户内为用户添加角色，tenantID 为0表示全局分配
+func (r *userRepo) AddRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error {
 	// 开启事务
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return addRoles(tx, userID, tenantID, roleIDs, validity)
+	})
+}
+
+// addRoles 在给定事务内为用户添加角色，已存在的关联只更新生效区间
+func addRoles(tx *gorm.DB, userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error {
+	// 检查用户是否存在
+	var user model.User
+	if err := tx.Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error; err != nil {
+		return err
+	}
+
+	// 添加角色关联
+	for _, roleID := range roleIDs {
+		// 检查角色是否存在，租户角色只能在所属租户内分配
+		var role model.Role
+		if err := tx.Where("id = ? AND deleted_at IS NULL AND tenant_id IN (0, ?)", roleID, tenantID).First(&role).Error; err != nil {
+			return fmt.Errorf("角色ID %d 不存在或不属于租户 %d: %w", roleID, tenantID, err)
+		}
+
+		// 检查关联是否已存在
+		var count int64
+		tx.Model(&model.UserRole{}).Where("user_id = ? AND role_id = ? AND tenant_id = ?", userID, roleID, tenantID).Count(&count)
+		if count > 0 {
+			// 显式更新两端，nil 表示取消限制
+			err := tx.Model(&model.UserRole{}).Where("user_id = ? AND role_id = ? AND tenant_id = ?", userID, roleID, tenantID).Updates(map[string]interface{}{
+				"valid_from":  validity.ValidFrom,
+				"valid_until": validity.ValidUntil,
+			}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 创建关联
+		userRole := model.UserRole{
+			UserID:   userID,
+			RoleID:   roleID,
+			TenantID: tenantID,
+			Validity: validity,
+		}
+		if err := tx.Create(&userRole).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddPermissions 在指定租户内直接为用户授予权限，已存在的授予只更新效果
+func (r *userRepo) AddPermissions(userID, tenantID uint64, permissionIDs []uint64, effect string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// 检查用户是否存在
 		var user model.User
@@ -149,26 +533,32 @@ func (r *userRepo) AddRoles(userID uint64, roleIDs []uint64) error {
 			return err
 		}
 
-		// 添加角色关联
-		for _, roleID := range roleIDs {
-			// 检查角色是否存在
-			var role model.Role
-			if err := tx.Where("id = ? AND deleted_at IS NULL", roleID).First(&role).Error; err != nil {
-				return fmt.Errorf("角色ID %d 不存在: %w", roleID, err)
+		for _, permissionID := range permissionIDs {
+			// 检查权限是否存在
+			var permission model.Permission
+			if err := tx.Where("id = ? AND deleted_at IS NULL", permissionID).First(&permission).Error; err != nil {
+				return fmt.Errorf("权限ID %d 不存在: %w", permissionID, err)
 			}
 
-			// 检查关联是否已存在
+			// 检查授予是否已存在
 			var count int64
-			tx.Model(&model.UserRole{}).Where("user_id = ? AND role_id = ?", userID, roleID).Count(&count)
-			if count == 0 {
-				// 创建关联
-				userRole := model.UserRole{
-					UserID: userID,
-					RoleID: roleID,
-				}
-				if err := tx.Create(&userRole).Error; err != nil {
+			tx.Model(&model.UserPermission{}).Where("user_id = ? AND permission_id = ? AND tenant_id = ?", userID, permissionID, tenantID).Count(&count)
+			if count > 0 {
+				err := tx.Model(&model.UserPermission{}).Where("user_id = ? AND permission_id = ? AND tenant_id = ?", userID, permissionID, tenantID).Update("effect", effect).Error
+				if err != nil {
 					return err
 				}
+				continue
+			}
+
+			userPermission := model.UserPermission{
+				UserID:       userID,
+				PermissionID: permissionID,
+				TenantID:     tenantID,
+				Effect:       effect,
+			}
+			if err := tx.Create(&userPermission).Error; err != nil {
+				return err
 			}
 		}
 
@@ -176,25 +566,63 @@ func (r *userRepo) AddRoles(userID uint64, roleIDs []uint64) error {
 	})
 }
 
-// RemoveRoles 移除用户的角色
-func (r *userRepo) RemoveRoles(userID uint64, roleIDs []uint64) error {
-	return r.db.Where("user_id = ? AND role_id IN ?", userID, roleIDs).Delete(&model.UserRole{}).Error
+// RemovePermissions 撤销在指定租户内直接授予用户的权限
+func (r *userRepo) RemovePermissions(userID, tenantID uint64, permissionIDs []uint64) error {
+	return r.db.Where("user_id = ? AND tenant_id = ? AND permission_id IN ?", userID, tenantID, permissionIDs).Delete(&model.UserPermission{}).Error
 }
 
-// UpdateRoles 更新用户的角色
-func (r *userRepo) UpdateRoles(userID uint64, roleIDs []uint64) error {
+// GetDirectPermissions 获取直接授予用户的全部权限（含各租户内的授予），已删除的权限不返回
+func (r *userRepo) GetDirectPermissions(userID uint64) ([]*model.UserPermissionDetail, error) {
+	var permissions []*model.UserPermissionDetail
+	err := r.db.Table("user_permissions").
+		Select("user_permissions.*, permissions.code AS permission_code, permissions.name AS permission_name").
+		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Where("user_permissions.user_id = ?", userID).
+		Order("user_permissions.tenant_id, permissions.code").
+		Scan(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// RemoveRoles 移除用户在指定租户内的角色
+func (r *userRepo) RemoveRoles(userID, tenantID uint64, roleIDs []uint64) error {
+	return r.db.Where("user_id = ? AND tenant_id = ? AND role_id IN ?", userID, tenantID, roleIDs).Delete(&model.UserRole{}).Error
+}
+
+// UpdateRoles 更新用户在指定租户内的角色，其他租户的分配保持不变
+func (r *userRepo) UpdateRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error {
 	// 开启事务
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// 删除所有现有角色
-		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+		// 删除该租户内的现有角色
+		if err := tx.Where("user_id = ? AND tenant_id = ?", userID, tenantID).Delete(&model.UserRole{}).Error; err != nil {
 			return err
 		}
 
 		// 添加新角色
 		if len(roleIDs) > 0 {
-			return r.AddRoles(userID, roleIDs)
+			return addRoles(tx, userID, tenantID, roleIDs, validity)
 		}
 
 		return nil
 	})
 }
+
+// DeleteExpiredRoles 物理删除在指定时刻已失效的角色分配，返回被删除的记录
+func (r *userRepo) DeleteExpiredRoles(now int64) ([]model.UserRole, error) {
+	var expired []model.UserRole
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("valid_until IS NOT NULL AND valid_until <= ?", now).Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		return tx.Where("valid_until IS NOT NULL AND valid_until <= ?", now).Delete(&model.UserRole{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
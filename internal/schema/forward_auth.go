@@ -0,0 +1,20 @@
+package schema
+
+// ForwardAuthRequest 反向代理转发认证请求，由处理器从代理传入的请求头中解析
+type ForwardAuthRequest struct {
+	Token    string // 访问令牌，不含 Bearer 前缀
+	TenantID uint64 // 请求头指定的租户，0表示未指定
+	Method   string // 原始请求方法
+	URI      string // 原始请求URI，可包含查询参数
+}
+
+// ForwardAuthResult 转发认证结果
+type ForwardAuthResult struct {
+	Allowed  bool     `json:"allowed"`
+	Public   bool     `json:"public,omitempty"` // 命中无需认证的规则
+	UserID   uint64   `json:"user_id,omitempty"`
+	Username string   `json:"username,omitempty"`
+	TenantID uint64   `json:"tenant_id,omitempty"`
+	Roles    []string `json:"roles,omitempty"`    // 当前租户内生效的直接分配角色编码
+	Required []string `json:"required,omitempty"` // 命中规则所需的权限
+}
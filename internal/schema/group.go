@@ -0,0 +1,97 @@
+package schema
+
+// CreateGroupRequest 创建用户组请求
+type CreateGroupRequest struct {
+	Code        string   `json:"code" validate:"required,min=2,max=50"`
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"omitempty"`
+	ParentIDs   []uint64 `json:"parent_ids" validate:"omitempty"` // 上级组ID列表，组成员同时是上级组的成员
+}
+
+// UpdateGroupRequest 更新用户组请求
+type UpdateGroupRequest struct {
+	ID          uint64   `json:"id" validate:"required"`
+	Code        string   `json:"code" validate:"required,min=2,max=50"`
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"omitempty"`
+	ParentIDs   []uint64 `json:"parent_ids" validate:"omitempty"` // 为nil时不修改嵌套关系，空数组表示清空
+}
+
+// DeleteGroupRequest 删除用户组请求
+type DeleteGroupRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// GetGroupRequest 获取用户组详情请求
+type GetGroupRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// ListGroupRequest 获取用户组列表请求
+type ListGroupRequest struct {
+	Page     int    `json:"page" validate:"omitempty,min=1"`
+	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+	Keyword  string `json:"keyword" validate:"omitempty"`
+}
+
+// GroupMembersRequest 加入或移出用户组成员请求
+type GroupMembersRequest struct {
+	GroupID uint64   `json:"group_id" validate:"required"`
+	UserIDs []uint64 `json:"user_ids" validate:"required,min=1,max=1000,dive,required"`
+}
+
+// ListGroupMembersRequest 分页获取用户组直接成员请求
+type ListGroupMembersRequest struct {
+	GroupID  uint64 `json:"group_id" validate:"required"`
+	Page     int    `json:"page" validate:"omitempty,min=1"`
+	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// AssignGroupRolesRequest 替换用户组在指定租户内的角色请求
+type AssignGroupRolesRequest struct {
+	GroupID  uint64   `json:"group_id" validate:"required"`
+	RoleIDs  []uint64 `json:"role_ids" validate:"required"`
+	TenantID uint64   `json:"tenant_id" validate:"omitempty"` // 仅替换该租户内的角色分配，0表示全局分配
+}
+
+// GroupSimple 简化的用户组信息
+type GroupSimple struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// GroupRoleResponse 用户组的一条角色分配
+type GroupRoleResponse struct {
+	Role     RoleSimple `json:"role"`
+	TenantID uint64     `json:"tenant_id"` // 0表示全局分配
+}
+
+// GroupResponse 用户组信息响应
+type GroupResponse struct {
+	ID          uint64              `json:"id"`
+	Code        string              `json:"code"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Parents     []GroupSimple       `json:"parents"`
+	Roles       []GroupRoleResponse `json:"roles,omitempty"`        // 直接分配给该组的角色，仅详情接口返回
+	MemberCount int64               `json:"member_count,omitempty"` // 直接成员数，仅详情接口返回
+	CreatedAt   int64               `json:"created_at"`
+}
+
+// ListGroupResponse 用户组列表响应，包含分页信息
+type ListGroupResponse struct {
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalPages int             `json:"total_pages"`
+	Items      []GroupResponse `json:"items"`
+}
+
+// UserGroupRole 用户经由用户组获得的一条角色
+type UserGroupRole struct {
+	Role     RoleSimple    `json:"role"`
+	TenantID uint64        `json:"tenant_id"` // 0表示全局分配
+	Group    GroupSimple   `json:"group"`     // 角色所分配到的用户组
+	Path     []GroupSimple `json:"path"`      // 从用户直接所属的组沿嵌套关系到 group 的路径，首尾均包含
+}
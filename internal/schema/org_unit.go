@@ -0,0 +1,68 @@
+package schema
+
+// CreateOrgUnitRequest 创建组织单元请求
+type CreateOrgUnitRequest struct {
+	ParentID    uint64 `json:"parent_id" validate:"omitempty"` // 上级单元ID，0表示根节点
+	Code        string `json:"code" validate:"required,min=2,max=50"`
+	Name        string `json:"name" validate:"required,min=2,max=50"`
+	Description string `json:"description" validate:"omitempty"`
+	Sort        int    `json:"sort" validate:"omitempty"`
+}
+
+// UpdateOrgUnitRequest 更新组织单元请求，上级单元与排序按请求值整体替换
+type UpdateOrgUnitRequest struct {
+	ID          uint64 `json:"id" validate:"required"`
+	ParentID    uint64 `json:"parent_id" validate:"omitempty"` // 上级单元ID，0表示移动为根节点
+	Code        string `json:"code" validate:"required,min=2,max=50"`
+	Name        string `json:"name" validate:"required,min=2,max=50"`
+	Description string `json:"description" validate:"omitempty"`
+	Sort        int    `json:"sort" validate:"omitempty"`
+}
+
+// DeleteOrgUnitRequest 删除组织单元请求
+type DeleteOrgUnitRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// GetOrgUnitRequest 获取组织单元详情请求
+type GetOrgUnitRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// OrgUnitMembersRequest 加入或移出组织单元成员请求
+type OrgUnitMembersRequest struct {
+	OrgUnitID uint64   `json:"org_unit_id" validate:"required"`
+	UserIDs   []uint64 `json:"user_ids" validate:"required,min=1,max=1000,dive,required"`
+}
+
+// ListOrgUnitMembersRequest 分页获取组织单元直接成员请求
+type ListOrgUnitMembersRequest struct {
+	OrgUnitID uint64 `json:"org_unit_id" validate:"required"`
+	Page      int    `json:"page" validate:"omitempty,min=1"`
+	PageSize  int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+// OrgUnitSimple 简化的组织单元信息
+type OrgUnitSimple struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// OrgUnitResponse 组织单元信息响应
+type OrgUnitResponse struct {
+	ID          uint64 `json:"id"`
+	ParentID    uint64 `json:"parent_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Sort        int    `json:"sort"`
+	MemberCount int64  `json:"member_count,omitempty"` // 直接成员数，仅详情接口返回
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// OrgUnitTreeNode 组织单元树节点
+type OrgUnitTreeNode struct {
+	OrgUnitResponse
+	Children []*OrgUnitTreeNode `json:"children,omitempty"`
+}
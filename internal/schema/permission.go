@@ -5,14 +5,26 @@ type CreatePermissionRequest struct {
 	Code        string `json:"code" validate:"required,min=3,max=50"`
 	Name        string `json:"name" validate:"required,min=3,max=100"`
 	Description string `json:"description" validate:"required"`
+	ParentID    uint64 `json:"parent_id"`                                              // 父级权限，0表示根节点
+	Type        string `json:"type" validate:"omitempty,oneof=module menu button api"` // 节点类型，默认 api
+	Sort        int    `json:"sort"`                                                   // 同级排序，升序
+	Icon        string `json:"icon" validate:"omitempty,max=100"`
+	Method      string `json:"method" validate:"omitempty,oneof=GET POST PUT PATCH DELETE *"` // 接口方法，与 route 一起标识接口权限，* 表示任意方法
+	Route       string `json:"route" validate:"omitempty,max=255"`
 }
 
-// UpdatePermissionRequest 更新权限请求
+// UpdatePermissionRequest 更新权限请求，树结构字段为空时保持原值
 type UpdatePermissionRequest struct {
-	ID          uint64 `json:"id" validate:"required"`
-	Code        string `json:"code" validate:"required,min=3,max=50"`
-	Name        string `json:"name" validate:"required,min=3,max=100"`
-	Description string `json:"description" validate:"required"`
+	ID          uint64  `json:"id" validate:"required"`
+	Code        string  `json:"code" validate:"required,min=3,max=50"`
+	Name        string  `json:"name" validate:"required,min=3,max=100"`
+	Description string  `json:"description" validate:"required"`
+	ParentID    *uint64 `json:"parent_id"`
+	Type        *string `json:"type" validate:"omitempty,oneof=module menu button api"`
+	Sort        *int    `json:"sort"`
+	Icon        *string `json:"icon" validate:"omitempty,max=100"`
+	Method      *string `json:"method" validate:"omitempty,oneof=GET POST PUT PATCH DELETE *"`
+	Route       *string `json:"route" validate:"omitempty,max=255"`
 }
 
 // DeletePermissionRequest 删除权限请求
@@ -38,7 +50,26 @@ type PermissionResponse struct {
 	Code        string `json:"code"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	ParentID    uint64 `json:"parent_id"`
+	Type        string `json:"type"`
+	Sort        int    `json:"sort"`
+	Icon        string `json:"icon,omitempty"`
+	Method      string `json:"method,omitempty"`
+	Route       string `json:"route,omitempty"`
 	CreatedAt   int64  `json:"created_at"`
+	Effect      string `json:"effect,omitempty"`    // 授予效果：allow 或 deny，仅在角色上下文中返回
+	Condition   string `json:"condition,omitempty"` // 授予附带的条件表达式，仅在角色上下文中返回
+}
+
+// PermissionTreeRequest 获取权限树请求
+type PermissionTreeRequest struct {
+	Types []string `json:"types" validate:"omitempty,dive,oneof=module menu button api"` // 只保留指定类型的节点，为空时返回全部
+}
+
+// PermissionTreeNode 权限树节点
+type PermissionTreeNode struct {
+	PermissionResponse
+	Children []*PermissionTreeNode `json:"children,omitempty"`
 }
 
 // ListPermissionResponse 权限列表响应，包含分页信息
@@ -50,3 +81,11 @@ type ListPermissionResponse struct {
 	TotalPages int                  `json:"total_pages"`
 	Items      []PermissionResponse `json:"items"`
 }
+
+// RouteSyncReport 接口路由同步报告，路由以 "方法 路径" 表示
+type RouteSyncReport struct {
+	Created []string `json:"created"` // 新建的接口权限
+	Linked  []string `json:"linked"`  // 已有同编码权限，补充了方法与路径
+	Stale   []string `json:"stale"`   // 路由已不存在的接口权限
+	Skipped []string `json:"skipped"` // 无法创建的路由及原因
+}
@@ -0,0 +1,24 @@
+package schema
+
+// PolicyExportResponse 策略导出响应
+type PolicyExportResponse struct {
+	Content     string `json:"content"`     // Casbin CSV 格式的策略内容
+	Grants      int    `json:"grants"`      // p 规则数量
+	Assignments int    `json:"assignments"` // g 规则数量
+}
+
+// PolicyImportRequest 策略导入请求
+type PolicyImportRequest struct {
+	Content string `json:"content" validate:"required"` // Casbin CSV 格式的策略内容
+	DryRun  bool   `json:"dry_run"`                     // 只返回差异报告，不写入数据库
+}
+
+// PolicyImportReport 策略导入差异报告
+type PolicyImportReport struct {
+	DryRun       bool     `json:"dry_run"`
+	Applied      bool     `json:"applied"`          // 变更是否已写入数据库
+	CreatedRoles []string `json:"created_roles"`    // 新建的角色编码
+	Added        []string `json:"added"`            // 新增的规则
+	Removed      []string `json:"removed"`          // 删除的规则
+	Errors       []string `json:"errors,omitempty"` // 校验错误，存在时不执行任何变更
+}
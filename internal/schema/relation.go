@@ -0,0 +1,91 @@
+package schema
+
+// WriteRelationsRequest 写入或删除关系元组请求，元组形如 document:42#viewer@user:7
+type WriteRelationsRequest struct {
+	Tuples []string `json:"tuples" validate:"required,min=1,max=500,dive,required"`
+}
+
+// WriteRelationsResponse 写入或删除关系元组的结果
+type WriteRelationsResponse struct {
+	Affected int      `json:"affected"`         // 新写入或删除的元组数，已存在或不存在的元组不计入
+	Errors   []string `json:"errors,omitempty"` // 无效的元组，任一元组无效时不写入任何元组
+}
+
+// ReadRelationsRequest 查询关系元组请求，各条件为空时不限制
+type ReadRelationsRequest struct {
+	Page       int    `json:"page" validate:"omitempty,min=1"`
+	PageSize   int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+	ObjectType string `json:"object_type" validate:"omitempty"`
+	ObjectID   string `json:"object_id" validate:"omitempty"`
+	Relation   string `json:"relation" validate:"omitempty"`
+	Subject    string `json:"subject" validate:"omitempty"` // 类型:ID 或 类型:ID#关系
+}
+
+// RelationTupleResponse 关系元组信息
+type RelationTupleResponse struct {
+	ID        uint64 `json:"id"`
+	Tuple     string `json:"tuple"`
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+	Subject   string `json:"subject"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// ReadRelationsResponse 关系元组列表响应，包含分页信息
+type ReadRelationsResponse struct {
+	Total      int64                   `json:"total"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	TotalPages int                     `json:"total_pages"`
+	Items      []RelationTupleResponse `json:"items"`
+}
+
+// CheckRelationRequest 判定主体是否对对象持有某种关系的请求
+type CheckRelationRequest struct {
+	Object   string `json:"object" validate:"required"`   // 类型:ID，如 document:42
+	Relation string `json:"relation" validate:"required"` // 如 viewer
+	Subject  string `json:"subject" validate:"required"`  // 类型:ID 或 类型:ID#关系
+}
+
+// CheckRelationResponse 关系判定结果
+type CheckRelationResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// ExpandRelationRequest 展开对象上某个关系的持有者请求
+type ExpandRelationRequest struct {
+	Object   string `json:"object" validate:"required"`
+	Relation string `json:"relation" validate:"required"`
+}
+
+// 关系展开树中子节点并入父节点的方式
+const (
+	RelationViaUserset  = "userset"  // 直接写入的用户集主体
+	RelationViaIncludes = "includes" // 同一对象上包含本关系的关系
+	RelationViaFrom     = "from"     // 经由关联对象获得的关系
+)
+
+// RelationTree 关系展开树，持有节点关系的主体为 Subjects 与全部子节点的并集
+type RelationTree struct {
+	Object   string          `json:"object"`
+	Relation string          `json:"relation"`
+	Via      string          `json:"via,omitempty"`      // 本节点如何并入父节点：userset、includes 或 from
+	Tupleset string          `json:"tupleset,omitempty"` // via 为 from 时，指向本节点对象的关联关系
+	Subjects []string        `json:"subjects"`           // 直接写入的非用户集主体
+	Children []*RelationTree `json:"children,omitempty"`
+	Cycle    bool            `json:"cycle,omitempty"` // 已在上层节点中展开，不再重复展开
+}
+
+// ListObjectsRequest 列出主体持有某种关系的对象请求
+type ListObjectsRequest struct {
+	ObjectType string `json:"object_type" validate:"required"`
+	Relation   string `json:"relation" validate:"required"`
+	Subject    string `json:"subject" validate:"required"`
+}
+
+// ListObjectsResponse 主体持有关系的对象ID，按字典序排列
+type ListObjectsResponse struct {
+	ObjectType string   `json:"object_type"`
+	Relation   string   `json:"relation"`
+	ObjectIDs  []string `json:"object_ids"`
+}
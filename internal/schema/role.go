@@ -1,20 +1,23 @@
 package schema
 
-// CreateRoleRequest 
+// CreateRoleRequest
 type CreateRoleRequest struct {
-	Code        string   `json:"code" validate:"required,min=2,max=50"`
-	Name        string   `json:"name" validate:"required,min=2,max=50"`
-	Description string   `json:"description" validate:"required"`
+	Code          string   `json:"code" validate:"required,min=2,max=50"`
+	Name          string   `json:"name" validate:"required,min=2,max=50"`
+	Description   string   `json:"description" validate:"required"`
 	PermissionIDs []uint64 `json:"permission_ids" validate:"omitempty"`
+	ParentIDs     []uint64 `json:"parent_ids" validate:"omitempty"` // 父角色ID列表，继承父角色的全部权限
+	TenantID      uint64   `json:"tenant_id" validate:"omitempty"`  // 所属租户，0表示全局角色，可在所有租户内分配
 }
 
-// UpdateRoleRequest 
+// UpdateRoleRequest
 type UpdateRoleRequest struct {
-	ID          uint64   `json:"id" validate:"required"`
-	Code        string   `json:"code" validate:"required,min=2,max=50"`
-	Name        string   `json:"name" validate:"required,min=2,max=50"`
-	Description string   `json:"description" validate:"required"`
+	ID            uint64   `json:"id" validate:"required"`
+	Code          string   `json:"code" validate:"required,min=2,max=50"`
+	Name          string   `json:"name" validate:"required,min=2,max=50"`
+	Description   string   `json:"description" validate:"required"`
 	PermissionIDs []uint64 `json:"permission_ids" validate:"omitempty"`
+	ParentIDs     []uint64 `json:"parent_ids" validate:"omitempty"` // 为nil时不修改继承关系，空数组表示清空
 }
 
 // RoleDeleteRequest 删除角色请求
@@ -22,21 +25,22 @@ type RoleDeleteRequest struct {
 	ID uint64 `json:"id" validate:"required"`
 }
 
-// DeleteRoleRequest 
+// DeleteRoleRequest
 type DeleteRoleRequest struct {
 	ID uint64 `json:"id" validate:"required"`
 }
 
-// GetRoleRequest 
+// GetRoleRequest
 type GetRoleRequest struct {
 	ID uint64 `json:"id" validate:"required"`
 }
 
-// ListRoleRequest 
+// ListRoleRequest
 type ListRoleRequest struct {
 	Page     int    `json:"page" validate:"omitempty,min=1"`
 	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
 	Keyword  string `json:"keyword" validate:"omitempty"`
+	TenantID uint64 `json:"tenant_id" validate:"omitempty"` // 只返回该租户的角色及全局角色
 }
 
 // RoleDetailRequest 获取角色详情请求
@@ -49,21 +53,65 @@ type RoleListPermissionsRequest struct {
 	ID uint64 `json:"id" validate:"required"`
 }
 
-// RoleResponse 
+// RoleHierarchyRequest 获取角色继承关系请求
+type RoleHierarchyRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// CloneRoleRequest 克隆角色请求，复制源角色的全部权限授予后再应用覆盖项
+type CloneRoleRequest struct {
+	SourceID            uint64            `json:"source_id" validate:"required"`
+	Code                string            `json:"code" validate:"required,min=2,max=50"`
+	Name                string            `json:"name" validate:"required,min=2,max=50"`
+	Description         string            `json:"description" validate:"omitempty"`           // 为空时沿用源角色的描述
+	TenantID            *uint64           `json:"tenant_id" validate:"omitempty"`             // 为空时与源角色属于同一租户
+	CopyParents         bool              `json:"copy_parents"`                               // 是否同时复制继承关系
+	Grants              []PermissionGrant `json:"grants" validate:"omitempty,dive"`           // 覆盖项，新增权限或替换已有权限的授予效果
+	RemovePermissionIDs []uint64          `json:"remove_permission_ids" validate:"omitempty"` // 不复制的权限ID
+}
+
+// AssignDataScopeRequest 设置角色数据范围请求
+type AssignDataScopeRequest struct {
+	RoleID     uint64   `json:"role_id" validate:"required"`
+	DataScope  string   `json:"data_scope" validate:"required,oneof=all unit unit_and_children self custom"`
+	OrgUnitIDs []uint64 `json:"org_unit_ids" validate:"omitempty,max=1000"` // 数据范围为 custom 时可访问的组织单元，其余范围忽略
+}
+
+// RoleResponse
 type RoleResponse struct {
-	ID          uint64              `json:"id"`
-	Code        string              `json:"code"`
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	CreatedAt   int64               `json:"created_at"`
-	Permissions []PermissionSimple  `json:"permissions,omitempty"`
+	ID              uint64             `json:"id"`
+	Code            string             `json:"code"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	TenantID        uint64             `json:"tenant_id"`                  // 所属租户，0表示全局角色
+	TemplateCode    string             `json:"template_code,omitempty"`    // 创建该角色的模板编码
+	TemplateVersion int                `json:"template_version,omitempty"` // 最近一次同步的模板版本
+	DataScope       string             `json:"data_scope,omitempty"`       // 数据范围：all、unit、unit_and_children、self 或 custom
+	DataScopeUnits  []OrgUnitSimple    `json:"data_scope_units,omitempty"` // 自定义数据范围的组织单元，仅详情接口返回
+	CreatedAt       int64              `json:"created_at"`
+	Permissions     []PermissionSimple `json:"permissions,omitempty"`
+	Parents         []RoleSimple       `json:"parents,omitempty"`
+}
+
+// InheritedPermission 继承而来的权限
+type InheritedPermission struct {
+	PermissionSimple
+	FromRoles []RoleSimple `json:"from_roles"` // 授予该权限的祖先角色
+}
+
+// RoleEffectivePermissionsResponse 角色有效权限响应，区分直接授予与继承的权限
+type RoleEffectivePermissionsResponse struct {
+	Direct    []PermissionSimple    `json:"direct"`
+	Inherited []InheritedPermission `json:"inherited"`
 }
 
-// PermissionSimple 
+// PermissionSimple
 type PermissionSimple struct {
-	ID   uint64 `json:"id"`
-	Code string `json:"code"`
-	Name string `json:"name"`
+	ID        uint64 `json:"id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Effect    string `json:"effect,omitempty"`    // 授予效果：allow 或 deny，仅在角色上下文中返回
+	Condition string `json:"condition,omitempty"` // 授予附带的条件表达式，仅在角色上下文中返回
 }
 
 // ListRoleResponse 角色列表响应，包含分页信息
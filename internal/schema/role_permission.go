@@ -1,9 +1,17 @@
 package schema
 
+// PermissionGrant 角色权限授予项
+type PermissionGrant struct {
+	PermissionID uint64 `json:"permission_id" validate:"required"`
+	Effect       string `json:"effect" validate:"omitempty,oneof=allow deny"` // 为空时默认为 allow
+	Condition    string `json:"condition" validate:"omitempty,max=512"`       // 条件表达式，为空表示无条件授予，如 resource.owner_id == subject.id
+}
+
 // AssignPermissionRequest 分配权限请求
 type AssignPermissionRequest struct {
-	RoleID       uint64   `json:"role_id" validate:"required"`
-	PermissionIDs []uint64 `json:"permission_ids" validate:"required"`
+	RoleID        uint64            `json:"role_id" validate:"required"`
+	PermissionIDs []uint64          `json:"permission_ids" validate:"required_without=Grants"` // 以允许效果授予的权限ID
+	Grants        []PermissionGrant `json:"grants" validate:"omitempty,dive"`                  // 指定效果的授予项，同一权限同时允许和拒绝时以拒绝为准
 }
 
 // GetRolePermissionsRequest 获取角色权限请求
@@ -15,5 +23,7 @@ type GetRolePermissionsRequest struct {
 type RolePermissionResponse struct {
 	RoleID       uint64 `json:"role_id"`
 	PermissionID uint64 `json:"permission_id"`
+	Effect       string `json:"effect"`
+	Condition    string `json:"condition,omitempty"`
 	CreatedAt    int64  `json:"created_at"`
 }
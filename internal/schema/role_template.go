@@ -0,0 +1,44 @@
+package schema
+
+// InstantiateRoleTemplateRequest 根据角色模板创建角色请求
+type InstantiateRoleTemplateRequest struct {
+	TemplateCode string `json:"template_code" validate:"required"`
+	Code         string `json:"code" validate:"omitempty,min=2,max=50"` // 为空时使用模板编码
+	Name         string `json:"name" validate:"omitempty,min=2,max=50"` // 为空时使用模板名称
+	Description  string `json:"description" validate:"omitempty"`       // 为空时使用模板描述
+	TenantID     uint64 `json:"tenant_id" validate:"omitempty"`
+}
+
+// PropagateRoleTemplateRequest 将角色模板同步到由其创建的角色请求
+type PropagateRoleTemplateRequest struct {
+	TemplateCode string `json:"template_code" validate:"required"`
+	DryRun       bool   `json:"dry_run"` // 只返回变更报告，不写入
+}
+
+// RoleTemplateResponse 角色模板信息
+type RoleTemplateResponse struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Version     int      `json:"version"`
+	Permissions []string `json:"permissions"`
+	Deny        []string `json:"deny"`
+}
+
+// RoleTemplateRoleChange 单个角色在模板同步中的变更
+type RoleTemplateRoleChange struct {
+	Role        RoleSimple         `json:"role"`
+	FromVersion int                `json:"from_version"`
+	Added       []PermissionSimple `json:"added"`   // 新增的授予
+	Removed     []PermissionSimple `json:"removed"` // 移除的授予
+	Changed     []PermissionSimple `json:"changed"` // 授予效果变更，Effect 为新的效果
+}
+
+// RoleTemplatePropagationReport 角色模板同步报告
+type RoleTemplatePropagationReport struct {
+	TemplateCode string                   `json:"template_code"`
+	Version      int                      `json:"version"`
+	DryRun       bool                     `json:"dry_run"`
+	Roles        []RoleTemplateRoleChange `json:"roles"`     // 发生变更的角色
+	Unchanged    []RoleSimple             `json:"unchanged"` // 已与模板一致的角色
+}
@@ -0,0 +1,80 @@
+package schema
+
+// CreateSoDRuleRequest 创建职责分离规则请求
+type CreateSoDRuleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" validate:"omitempty"`
+	Type        string   `json:"type" validate:"required,oneof=static dynamic"` // static 不能同时分配，dynamic 不能在同一会话中同时激活
+	MaxRoles    int      `json:"max_roles" validate:"omitempty,min=1"`          // 最多可持有或激活的规则角色数，默认1即规则角色两两互斥
+	RoleIDs     []uint64 `json:"role_ids" validate:"required,min=2,dive,required"`
+}
+
+// UpdateSoDRuleRequest 更新职责分离规则请求
+type UpdateSoDRuleRequest struct {
+	ID          uint64   `json:"id" validate:"required"`
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" validate:"omitempty"`
+	Type        string   `json:"type" validate:"required,oneof=static dynamic"`
+	MaxRoles    int      `json:"max_roles" validate:"omitempty,min=1"`
+	RoleIDs     []uint64 `json:"role_ids" validate:"required,min=2,dive,required"`
+}
+
+// DeleteSoDRuleRequest 删除职责分离规则请求
+type DeleteSoDRuleRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// GetSoDRuleRequest 获取职责分离规则详情请求
+type GetSoDRuleRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// ListSoDRuleRequest 获取职责分离规则列表请求
+type ListSoDRuleRequest struct {
+	Page     int    `json:"page" validate:"omitempty,min=1"`
+	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+	Keyword  string `json:"keyword" validate:"omitempty"`
+	Type     string `json:"type" validate:"omitempty,oneof=static dynamic"`
+}
+
+// SoDRuleResponse 职责分离规则信息响应
+type SoDRuleResponse struct {
+	ID          uint64       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Type        string       `json:"type"`
+	MaxRoles    int          `json:"max_roles"`
+	Roles       []RoleSimple `json:"roles"`
+	CreatedAt   int64        `json:"created_at"`
+}
+
+// ListSoDRuleResponse 职责分离规则列表响应，包含分页信息
+type ListSoDRuleResponse struct {
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+	Items      []SoDRuleResponse `json:"items"`
+}
+
+// SoDViolationsRequest 查询违反职责分离规则的用户请求
+type SoDViolationsRequest struct {
+	RuleID uint64 `json:"rule_id" validate:"omitempty"` // 为0时检查全部规则
+}
+
+// SoDViolation 一个用户在一个租户范围内违反一条规则
+type SoDViolation struct {
+	RuleID   uint64       `json:"rule_id"`
+	RuleName string       `json:"rule_name"`
+	Type     string       `json:"type"`
+	UserID   uint64       `json:"user_id"`
+	Username string       `json:"username"`
+	TenantID uint64       `json:"tenant_id"` // 违反规则的租户范围，0表示仅由全局分配构成
+	Roles    []RoleSimple `json:"roles"`     // 用户持有的规则角色，包括经由继承持有的角色
+}
+
+// SoDViolationReport 违反职责分离规则的用户报告
+type SoDViolationReport struct {
+	Total      int            `json:"total"`
+	Violations []SoDViolation `json:"violations"`
+}
@@ -0,0 +1,51 @@
+package schema
+
+// CreateTenantRequest 创建租户请求
+type CreateTenantRequest struct {
+	Code        string `json:"code" validate:"required,min=2,max=50"`
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"omitempty"`
+}
+
+// UpdateTenantRequest 更新租户请求
+type UpdateTenantRequest struct {
+	ID          uint64 `json:"id" validate:"required"`
+	Code        string `json:"code" validate:"required,min=2,max=50"`
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"omitempty"`
+}
+
+// DeleteTenantRequest 删除租户请求
+type DeleteTenantRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// GetTenantRequest 获取租户详情请求
+type GetTenantRequest struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// ListTenantRequest 获取租户列表请求
+type ListTenantRequest struct {
+	Page     int    `json:"page" validate:"omitempty,min=1"`
+	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
+	Keyword  string `json:"keyword" validate:"omitempty"`
+}
+
+// TenantResponse 租户信息响应
+type TenantResponse struct {
+	ID          uint64 `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// ListTenantResponse 租户列表响应，包含分页信息
+type ListTenantResponse struct {
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+	Items      []TenantResponse `json:"items"`
+}
@@ -4,6 +4,9 @@ package schema
 type LoginRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=32"`
 	Password string `json:"password" validate:"required,min=6"`
+	TenantID uint64 `json:"tenant_id" validate:"omitempty"` // 登录的租户，令牌内的权限仅在该租户内生效
+	// ActiveRoleIDs 本次会话激活的角色，须为已分配且生效的角色；为空时激活全部角色
+	ActiveRoleIDs []uint64 `json:"active_role_ids" validate:"omitempty"`
 }
 
 // LoginResponse 登录响应
@@ -23,12 +26,132 @@ type CheckPermissionRequest struct {
 	Permission string `json:"permission" validate:"required"`
 }
 
+// CheckPermissionResponse 权限检查结果
+type CheckPermissionResponse struct {
+	HasPermission    bool        `json:"has_permission"`
+	DeniedBy         *RoleSimple `json:"denied_by,omitempty"`         // 产生拒绝的角色
+	DeniedPermission string      `json:"denied_permission,omitempty"` // 命中的拒绝规则
+	DeniedDirectly   bool        `json:"denied_directly,omitempty"`   // 拒绝规则直接授予给用户，此时 denied_by 为空
+}
+
+// EvaluatePermissionRequest 携带属性的权限检查请求，用于判定带条件的权限授予
+// subject 属性（id、username、tenant_id）由服务端根据当前用户填写，不能在请求中指定
+type EvaluatePermissionRequest struct {
+	Permission string                 `json:"permission" validate:"required"`
+	Resource   map[string]interface{} `json:"resource"` // 被访问资源的属性，如 owner_id
+	Request    map[string]interface{} `json:"request"`  // 请求上下文属性，如 ip；time 缺省时为服务端当前时刻 HH:MM
+}
+
+// EvaluatePermissionResponse 携带属性的权限检查结果
+type EvaluatePermissionResponse struct {
+	CheckPermissionResponse
+	Conditions []ConditionResult `json:"conditions"` // 命中被检查权限的带条件授予及其求值结果
+}
+
+// ConditionResult 一条带条件授予的求值结果
+type ConditionResult struct {
+	Role           RoleSimple `json:"role"`
+	PermissionCode string     `json:"permission_code"`
+	Effect         string     `json:"effect"`
+	Condition      string     `json:"condition"`
+	Satisfied      bool       `json:"satisfied"`
+	Error          string     `json:"error,omitempty"` // 求值失败的原因，此时允许不生效、拒绝仍然生效
+}
+
+// 批量权限检查的判定模式
+const (
+	CheckModeAll = "all" // 全部权限均具备时通过
+	CheckModeAny = "any" // 具备任意一个权限时通过
+)
+
+// CheckPermissionsRequest 批量权限检查请求
+type CheckPermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1,max=200,dive,required"`
+	Mode        string   `json:"mode" validate:"omitempty,oneof=any all"` // 判定模式，默认 all
+}
+
+// CheckPermissionsResponse 批量权限检查结果
+type CheckPermissionsResponse struct {
+	Results map[string]bool `json:"results"` // 权限编码 -> 是否具备
+	Passed  bool            `json:"passed"`  // 按判定模式汇总的结果
+}
+
+// ExplainPermissionRequest 管理员查询任意用户权限判定依据的请求
+type ExplainPermissionRequest struct {
+	UserID     uint64 `json:"user_id" validate:"required"`
+	TenantID   uint64 `json:"tenant_id"` // 判定所在租户，0表示只计入全局分配
+	Permission string `json:"permission" validate:"required"`
+}
+
+// 权限解释中角色分配的状态
+const (
+	AssignmentActive       = "active"        // 分配生效中
+	AssignmentPending      = "pending"       // 尚未到生效时间
+	AssignmentExpired      = "expired"       // 已过期
+	AssignmentRoleDeleted  = "role_deleted"  // 所分配的角色已删除
+	AssignmentNotActivated = "not_activated" // 分配生效，但未在当前会话中激活
+)
+
+// 权限解释中角色分配对判定结果的作用
+const (
+	OutcomeGranted    = "granted"    // 提供了允许规则，且未被拒绝
+	OutcomeDenied     = "denied"     // 提供了拒绝规则
+	OutcomeOverridden = "overridden" // 提供了允许规则，但被拒绝规则覆盖
+	OutcomeNoMatch    = "no_match"   // 分配生效，但未授予该权限
+	OutcomeInactive   = "inactive"   // 分配不生效，原因见状态
+)
+
+// ExplainPermissionResponse 权限判定结果及推导过程
+type ExplainPermissionResponse struct {
+	CheckPermissionResponse
+	UserID      uint64              `json:"user_id"`
+	TenantID    uint64              `json:"tenant_id"`
+	Permission  string              `json:"permission"`
+	Assignments []ExplainAssignment `json:"assignments"` // 全部候选角色分配
+	// DirectGrants 直接授予用户且命中被检查权限的授予，path 为空
+	DirectGrants []ExplainGrant `json:"direct_grants"`
+}
+
+// ExplainAssignment 一条候选角色分配对判定结果的作用
+type ExplainAssignment struct {
+	Role       RoleSimple     `json:"role"`
+	TenantID   uint64         `json:"tenant_id"` // 0表示全局分配
+	ValidFrom  *int64         `json:"valid_from,omitempty"`
+	ValidUntil *int64         `json:"valid_until,omitempty"`
+	Status     string         `json:"status"`
+	Outcome    string         `json:"outcome"`
+	Grants     []ExplainGrant `json:"grants,omitempty"` // 经由该分配命中的权限授予
+	// Groups 经由用户组获得的分配，为从用户直接所属的组到角色所分配的组的路径；直接分配时为空
+	Groups []GroupSimple `json:"groups,omitempty"`
+}
+
+// ExplainGrant 经由某条角色分配命中的一条权限授予
+type ExplainGrant struct {
+	Path           []RoleSimple `json:"path"` // 从所分配的角色沿继承关系到授予该权限的角色
+	PermissionID   uint64       `json:"permission_id"`
+	PermissionCode string       `json:"permission_code"`
+	Effect         string       `json:"effect"`
+	Condition      string       `json:"condition,omitempty"` // 带条件的允许在解释中不计入，拒绝总是计入
+}
+
+// ProfileRequest 获取个人信息请求，请求体可为空
+type ProfileRequest struct {
+	PermissionVersion string `json:"permission_version"` // 客户端已持有的权限版本，与当前版本一致时不返回个人信息
+}
+
+// ProfileUnchangedResponse 权限版本未变化时的个人信息响应
+type ProfileUnchangedResponse struct {
+	PermissionVersion string `json:"permission_version"`
+	Unchanged         bool   `json:"unchanged"`
+}
+
 // CreateUserRequest 创建用户请求
 type CreateUserRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=32"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Username string   `json:"username" validate:"required,min=3,max=32"`
+	Email    string   `json:"email" validate:"required,email"`
+	Password string   `json:"password" validate:"required,min=6"`
 	RoleIDs  []uint64 `json:"role_ids" validate:"omitempty"`
+	TenantID uint64   `json:"tenant_id" validate:"omitempty"` // 角色分配所属租户，0表示全局分配
 }
 
 // UpdateUserRequest 更新用户请求
@@ -38,6 +161,7 @@ type UpdateUserRequest struct {
 	Email    string   `json:"email" validate:"required,email"`
 	Password string   `json:"password" validate:"omitempty,min=6"`
 	RoleIDs  []uint64 `json:"role_ids" validate:"omitempty"`
+	TenantID uint64   `json:"tenant_id" validate:"omitempty"` // 仅替换该租户内的角色分配，0表示全局分配
 }
 
 // DeleteUserRequest 删除用户请求
@@ -52,7 +176,8 @@ type GetUserRequest struct {
 
 // UserDetailRequest 获取用户详情请求
 type UserDetailRequest struct {
-	ID uint64 `json:"id" validate:"required"`
+	ID       uint64 `json:"id" validate:"required"`
+	TenantID uint64 `json:"tenant_id"` // 计算有效权限所在的租户，0表示只计入全局授予
 }
 
 // ListUserRequest 获取用户列表请求
@@ -60,15 +185,31 @@ type ListUserRequest struct {
 	Page     int    `json:"page" validate:"omitempty,min=1"`
 	PageSize int    `json:"page_size" validate:"omitempty,min=1,max=100"`
 	Keyword  string `json:"keyword" validate:"omitempty"`
+	TenantID uint64 `json:"tenant_id" validate:"omitempty"` // 只返回在该租户内有角色分配的用户
 }
 
 // UserResponse 用户信息响应
 type UserResponse struct {
-	ID        uint64        `json:"id"`
-	Username  string        `json:"username"`
-	Email     string        `json:"email"`
-	CreatedAt int64         `json:"created_at"`
-	Roles     []RoleSimple  `json:"roles,omitempty"`
+	ID          uint64       `json:"id"`
+	Username    string       `json:"username"`
+	Email       string       `json:"email"`
+	CreatedAt   int64        `json:"created_at"`
+	Roles       []RoleSimple `json:"roles,omitempty"`
+	Permissions []string     `json:"permissions,omitempty"` // 有效权限编码，仅个人信息接口返回
+	// DeniedPermissions 被显式拒绝的权限编码，优先于 Permissions 中的通配符授权
+	DeniedPermissions []string `json:"denied_permissions,omitempty"`
+	// TenantID 权限计算所在的租户，仅个人信息接口返回
+	TenantID uint64 `json:"tenant_id,omitempty"`
+	// Menus 被允许的模块、菜单与按钮树，仅个人信息接口返回
+	Menus []*PermissionTreeNode `json:"menus,omitempty"`
+	// PermissionVersion 有效权限与菜单的版本摘要，仅个人信息接口返回
+	PermissionVersion string `json:"permission_version,omitempty"`
+	// Grants 有效权限授予及来源（直接授予或经由角色），仅用户详情接口返回
+	Grants []UserPermissionGrant `json:"grants,omitempty"`
+	// Groups 用户直接所属的用户组，仅用户详情接口返回
+	Groups []GroupSimple `json:"groups,omitempty"`
+	// GroupRoles 经由用户组获得的角色及授予该角色的组，仅用户详情接口返回
+	GroupRoles []UserGroupRole `json:"group_roles,omitempty"`
 }
 
 // RoleSimple 简化的角色信息
@@ -0,0 +1,47 @@
+package schema
+
+// AssignUserPermissionsRequest 直接为用户授予权限请求
+type AssignUserPermissionsRequest struct {
+	UserID        uint64   `json:"user_id" validate:"required"`
+	PermissionIDs []uint64 `json:"permission_ids" validate:"required,min=1"`
+	TenantID      uint64   `json:"tenant_id" validate:"omitempty"`               // 授予所在租户，0表示全局授予
+	Effect        string   `json:"effect" validate:"omitempty,oneof=allow deny"` // 授予效果，默认 allow
+}
+
+// RevokeUserPermissionsRequest 撤销直接授予用户的权限请求
+type RevokeUserPermissionsRequest struct {
+	UserID        uint64   `json:"user_id" validate:"required"`
+	PermissionIDs []uint64 `json:"permission_ids" validate:"required,min=1"`
+	TenantID      uint64   `json:"tenant_id" validate:"omitempty"` // 授予所在租户，0表示全局授予
+}
+
+// ListUserPermissionsRequest 获取直接授予用户的权限请求
+type ListUserPermissionsRequest struct {
+	UserID uint64 `json:"user_id" validate:"required"`
+}
+
+// UserPermissionResponse 一条直接授予用户的权限
+type UserPermissionResponse struct {
+	PermissionID   uint64 `json:"permission_id"`
+	PermissionCode string `json:"permission_code"`
+	PermissionName string `json:"permission_name"`
+	TenantID       uint64 `json:"tenant_id"`
+	Effect         string `json:"effect"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// 有效权限的来源
+const (
+	PermissionSourceDirect = "direct" // 直接授予用户
+	PermissionSourceRole   = "role"   // 经由角色获得
+)
+
+// UserPermissionGrant 用户的一条有效权限授予及其来源
+type UserPermissionGrant struct {
+	PermissionID   uint64      `json:"permission_id"`
+	PermissionCode string      `json:"permission_code"`
+	Effect         string      `json:"effect"`
+	Condition      string      `json:"condition,omitempty"` // 角色授予附带的条件表达式
+	Source         string      `json:"source"`              // direct 或 role
+	Role           *RoleSimple `json:"role,omitempty"`      // 经由角色获得时授予该权限的角色
+}
@@ -2,8 +2,13 @@ package schema
 
 // AssignRoleRequest 分配角色请求
 type AssignRoleRequest struct {
-	UserID  uint64   `json:"user_id" validate:"required"`
-	RoleIDs []uint64 `json:"role_ids" validate:"required"`
+	UserID   uint64   `json:"user_id" validate:"required"`
+	RoleIDs  []uint64 `json:"role_ids" validate:"required"`
+	TenantID uint64   `json:"tenant_id" validate:"omitempty"` // 仅替换该租户内的角色分配，0表示全局分配
+	// ValidFrom 分配生效时间（Unix时间戳，秒），0表示立即生效
+	ValidFrom int64 `json:"valid_from" validate:"omitempty,min=0"`
+	// ValidUntil 分配失效时间（Unix时间戳，秒），0表示长期有效，到期后由后台任务清除
+	ValidUntil int64 `json:"valid_until" validate:"omitempty,min=0"`
 }
 
 // GetUserRolesRequest 获取用户角色请求
@@ -13,7 +18,10 @@ type GetUserRolesRequest struct {
 
 // UserRoleResponse 用户角色响应
 type UserRoleResponse struct {
-	UserID    uint64 `json:"user_id"`
-	RoleID    uint64 `json:"role_id"`
-	CreatedAt int64  `json:"created_at"`
+	UserID     uint64 `json:"user_id"`
+	RoleID     uint64 `json:"role_id"`
+	TenantID   uint64 `json:"tenant_id"`
+	ValidFrom  *int64 `json:"valid_from,omitempty"`
+	ValidUntil *int64 `json:"valid_until,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
 }
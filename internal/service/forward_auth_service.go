@@ -0,0 +1,189 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// ForwardAuthService 反向代理转发认证服务接口
+type ForwardAuthService interface {
+	Authorize(req *schema.ForwardAuthRequest) (*schema.ForwardAuthResult, error)
+}
+
+// forwardAuthService 转发认证服务实现，规则表来自配置文件
+type forwardAuthService struct {
+	rules          []forwardAuthRule
+	allowUnmatched bool
+	userRepo       repository.UserRepository
+	userService    UserService
+	tokenService   *jwt.TokenService
+}
+
+// forwardAuthRule 编译后的转发认证规则
+type forwardAuthRule struct {
+	route       apiroute.Route
+	permissions []string
+	any         bool
+	public      bool
+}
+
+// NewForwardAuthService 创建转发认证服务实例
+func NewForwardAuthService(
+	forwardAuthConfig *config.ForwardAuthConfig,
+	userRepo repository.UserRepository,
+	userService UserService, // 用于判定权限，与接口权限校验共用有效权限缓存
+	jwtConfig *config.JWTConfig,
+) ForwardAuthService {
+	rules := make([]forwardAuthRule, 0, len(forwardAuthConfig.Rules))
+	for _, rule := range forwardAuthConfig.Rules {
+		method := strings.ToUpper(rule.Method)
+		if method == "" {
+			method = apiroute.AnyMethod
+		}
+		rules = append(rules, forwardAuthRule{
+			route:       apiroute.Route{Method: method, Path: rule.Path},
+			permissions: rule.Permissions,
+			any:         rule.Mode == schema.CheckModeAny,
+			public:      rule.Public,
+		})
+	}
+
+	return &forwardAuthService{
+		rules:          rules,
+		allowUnmatched: forwardAuthConfig.AllowUnmatched,
+		userRepo:       userRepo,
+		userService:    userService,
+		tokenService:   jwt.NewTokenService(jwtConfig),
+	}
+}
+
+// Authorize 判定原始请求是否放行
+// 令牌无效或用户不存在时返回令牌错误，由调用方响应401；认证通过但权限不足或
+// 未匹配任何规则时返回 Allowed 为 false 的结果
+func (s *forwardAuthService) Authorize(req *schema.ForwardAuthRequest) (*schema.ForwardAuthResult, error) {
+	// 代理传入的URI可能包含查询参数与编码的 . 或 .. 分段，按上游实际访问的规范化路径匹配
+	path, ok := apiroute.CleanPath(req.URI)
+	if !ok {
+		return nil, errors.ErrInvalidForwardedURI
+	}
+	rule := s.match(req.Method, path)
+	if rule != nil && rule.public {
+		return &schema.ForwardAuthResult{Allowed: true, Public: true}, nil
+	}
+
+	// 认证访问令牌
+	if req.Token == "" {
+		return nil, errors.ErrInvalidToken
+	}
+	claims, err := s.tokenService.ValidateToken(req.Token)
+	if err != nil {
+		if err == jwt.ErrExpiredToken {
+			return nil, errors.ErrExpiredToken
+		}
+		return nil, errors.ErrInvalidToken
+	}
+	if claims.TokenType != "access" {
+		return nil, errors.ErrInvalidTokenType
+	}
+
+	// 解析当前租户，令牌中的租户声明优先于请求头
+	tenantID := claims.TenantID
+	if req.TenantID != 0 {
+		if tenantID != 0 && req.TenantID != tenantID {
+			return nil, errors.ErrTokenTenantMismatch
+		}
+		tenantID = req.TenantID
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	result := &schema.ForwardAuthResult{
+		UserID:   user.ID,
+		Username: user.Username,
+		TenantID: tenantID,
+	}
+
+	switch {
+	case rule == nil:
+		result.Allowed = s.allowUnmatched
+	case len(rule.permissions) == 0:
+		result.Allowed = true
+	default:
+		// 令牌只激活了部分角色时仅计入这些角色的权限
+		var policy *permcode.Policy
+		if len(claims.ActiveRoles) > 0 {
+			policy, err = s.userService.GetSessionPolicy(user.ID, tenantID, claims.ActiveRoles)
+		} else {
+			policy, err = s.userService.GetPermissionPolicy(user.ID, tenantID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Required = rule.permissions
+		if rule.any {
+			result.Allowed = policy.AllowsAny(rule.permissions...)
+		} else {
+			result.Allowed = policy.AllowsAll(rule.permissions...)
+		}
+	}
+
+	if !result.Allowed {
+		return result, nil
+	}
+
+	if result.Roles, err = s.activeRoles(user.ID, tenantID, claims.ActiveRoles); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// match 返回与请求匹配的第一条规则，未匹配时为nil
+func (s *forwardAuthService) match(method, path string) *forwardAuthRule {
+	for i := range s.rules {
+		if s.rules[i].route.Match(method, path) {
+			return &s.rules[i]
+		}
+	}
+	return nil
+}
+
+// activeRoles 获取用户在租户内的有效角色编码，包括经由用户组获得与继承的祖先角色，
+// session 非空时只返回会话激活的角色及其祖先角色，与权限判定计入的角色一致
+func (s *forwardAuthService) activeRoles(userID, tenantID uint64, session []uint64) ([]string, error) {
+	roles, err := s.userRepo.GetEffectiveRoles(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active map[uint64]struct{}
+	if len(session) > 0 {
+		inheritances, err := s.userRepo.GetEffectiveRoleParents(userID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		active = sessionRoles(session, inheritances)
+	}
+
+	codes := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if _, ok := active[role.ID]; active != nil && !ok {
+			continue
+		}
+		codes = append(codes, role.Code)
+	}
+	return sortedUnique(codes), nil
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// GroupService 用户组服务接口
+type GroupService interface {
+	Create(req *schema.CreateGroupRequest) (uint64, error)
+	Update(req *schema.UpdateGroupRequest) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*schema.GroupResponse, error)
+	List(req *schema.ListGroupRequest) (*schema.ListGroupResponse, error)
+	AddMembers(req *schema.GroupMembersRequest) error
+	RemoveMembers(req *schema.GroupMembersRequest) error
+	ListMembers(req *schema.ListGroupMembersRequest) (*schema.ListUserResponse, error)
+	AssignRoles(req *schema.AssignGroupRolesRequest) error
+}
+
+// groupService 用户组服务实现
+type groupService struct {
+	groupRepo       repository.GroupRepository
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+	tenantRepo      repository.TenantRepository
+	permissionCache *PermissionCache
+	sod             *sodChecker
+}
+
+// NewGroupService 创建用户组服务实例
+func NewGroupService(
+	groupRepo repository.GroupRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	tenantRepo repository.TenantRepository,
+	permissionCache *PermissionCache, // 可为nil，表示不缓存有效权限
+	sodRepo repository.SoDRepository, // 可为nil，表示不校验职责分离规则
+) GroupService {
+	return &groupService{
+		groupRepo:       groupRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		tenantRepo:      tenantRepo,
+		permissionCache: permissionCache,
+		sod:             newSoDChecker(sodRepo, roleRepo),
+	}
+}
+
+// Create 创建用户组
+func (s *groupService) Create(req *schema.CreateGroupRequest) (uint64, error) {
+	if err := s.checkUnique(0, req.Code, req.Name); err != nil {
+		return 0, err
+	}
+
+	// 新建的组没有后代，只需校验上级组存在
+	if err := s.validateParents(0, req.ParentIDs); err != nil {
+		return 0, err
+	}
+
+	group := &model.Group{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.groupRepo.Create(group); err != nil {
+		return 0, err
+	}
+
+	if len(req.ParentIDs) > 0 {
+		if err := s.groupRepo.SetParents(group.ID, req.ParentIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	return group.ID, nil
+}
+
+// Update 更新用户组，ParentIDs 不为nil时替换嵌套关系
+func (s *groupService) Update(req *schema.UpdateGroupRequest) error {
+	existingGroup, err := s.groupRepo.GetByID(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if existingGroup == nil {
+		return errors.ErrGroupNotFound
+	}
+
+	if err := s.checkUnique(req.ID, req.Code, req.Name); err != nil {
+		return err
+	}
+
+	// 嵌套关系变化影响该组及其后代组全部成员经由上级组获得的角色
+	var affected []uint64
+	if req.ParentIDs != nil {
+		if err := s.validateParents(req.ID, req.ParentIDs); err != nil {
+			return err
+		}
+
+		affected, err = s.groupRepo.GetAffectedUserIDs(req.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.sod.checkGroupChange(affected, &model.GroupChange{GroupID: req.ID, Parents: req.ParentIDs}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.groupRepo.Update(&model.Group{
+		ID:          req.ID,
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	}); err != nil {
+		return err
+	}
+
+	if req.ParentIDs == nil {
+		return nil
+	}
+
+	defer s.permissionCache.InvalidateUsers(affected...)
+
+	return s.groupRepo.SetParents(req.ID, req.ParentIDs)
+}
+
+// Delete 删除用户组，同时移除其成员、角色分配与嵌套关系
+func (s *groupService) Delete(id uint64) error {
+	group, err := s.groupRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if group == nil {
+		return errors.ErrGroupNotFound
+	}
+
+	// 删除后无法再查询到经由该组获得角色的用户，需要先记录
+	affected, err := s.groupRepo.GetAffectedUserIDs(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(affected...)
+	return nil
+}
+
+// GetByID 获取用户组详情，包括上级组、直接分配的角色与直接成员数
+func (s *groupService) GetByID(id uint64) (*schema.GroupResponse, error) {
+	group, err := s.groupRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if group == nil {
+		return nil, errors.ErrGroupNotFound
+	}
+
+	assignments, err := s.groupRepo.GetRoleAssignments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.groupRepo.CountMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToGroupResponse(group)
+	response.MemberCount = count
+	response.Roles = make([]schema.GroupRoleResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		response.Roles = append(response.Roles, schema.GroupRoleResponse{
+			Role:     schema.RoleSimple{ID: assignment.RoleID, Code: assignment.RoleCode, Name: assignment.RoleName},
+			TenantID: assignment.TenantID,
+		})
+	}
+	return response, nil
+}
+
+// List 获取用户组列表，返回完整分页信息
+func (s *groupService) List(req *schema.ListGroupRequest) (*schema.ListGroupResponse, error) {
+	groups, total, err := s.groupRepo.List(req.Page, req.PageSize, req.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.GroupResponse, 0, len(groups))
+	for _, group := range groups {
+		items = append(items, *convertToGroupResponse(group))
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ListGroupResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// AddMembers 将用户加入用户组，已是成员的用户保持不变
+func (s *groupService) AddMembers(req *schema.GroupMembersRequest) error {
+	if err := s.ensureGroupExists(req.GroupID); err != nil {
+		return err
+	}
+
+	// 检查所有用户是否存在
+	for _, userID := range req.UserIDs {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return errors.ErrUserNotFound
+		}
+	}
+
+	// 成员将获得该组及其全部上级组的角色
+	if err := s.sod.checkGroupChange(req.UserIDs, &model.GroupChange{GroupID: req.GroupID, Members: req.UserIDs}); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.AddMembers(req.GroupID, req.UserIDs); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(req.UserIDs...)
+	return nil
+}
+
+// RemoveMembers 将用户移出用户组，不是成员的用户忽略
+func (s *groupService) RemoveMembers(req *schema.GroupMembersRequest) error {
+	if err := s.ensureGroupExists(req.GroupID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMembers(req.GroupID, req.UserIDs); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(req.UserIDs...)
+	return nil
+}
+
+// ListMembers 分页获取用户组的直接成员
+func (s *groupService) ListMembers(req *schema.ListGroupMembersRequest) (*schema.ListUserResponse, error) {
+	if err := s.ensureGroupExists(req.GroupID); err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.groupRepo.ListMembers(req.GroupID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.UserResponse, 0, len(users))
+	for _, user := range users {
+		items = append(items, schema.UserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ListUserResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// AssignRoles 替换用户组在指定租户内的角色，租户ID为0表示全局分配
+// 组及其全部后代组的成员都会获得这些角色
+func (s *groupService) AssignRoles(req *schema.AssignGroupRolesRequest) error {
+	if err := s.ensureGroupExists(req.GroupID); err != nil {
+		return err
+	}
+
+	// 检查租户是否存在
+	if req.TenantID != model.GlobalTenantID {
+		tenant, err := s.tenantRepo.GetByID(req.TenantID)
+		if err != nil {
+			return err
+		}
+		if tenant == nil {
+			return errors.ErrTenantNotFound
+		}
+	}
+
+	// 检查所有角色是否存在，租户角色只能在所属租户内分配
+	for _, roleID := range req.RoleIDs {
+		role, err := s.roleRepo.GetByID(roleID)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			return errors.ErrRoleNotFound
+		}
+		if role.TenantID != model.GlobalTenantID && role.TenantID != req.TenantID {
+			return errors.ErrTenantMismatch
+		}
+	}
+
+	affected, err := s.groupRepo.GetAffectedUserIDs(req.GroupID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sod.checkGroupRoles(affected, req.GroupID, req.TenantID, req.RoleIDs); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.UpdateRoles(req.GroupID, req.TenantID, req.RoleIDs); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(affected...)
+	return nil
+}
+
+// checkUnique 校验编码与名称未被其他用户组使用，groupID为0表示新建用户组
+func (s *groupService) checkUnique(groupID uint64, code, name string) error {
+	group, err := s.groupRepo.GetByCode(code)
+	if err != nil {
+		return err
+	}
+	if group != nil && group.ID != groupID {
+		return errors.ErrGroupExists
+	}
+
+	group, err = s.groupRepo.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if group != nil && group.ID != groupID {
+		return errors.ErrGroupExists
+	}
+	return nil
+}
+
+// validateParents 校验上级组存在且不会形成循环嵌套，groupID为0表示新建用户组
+func (s *groupService) validateParents(groupID uint64, parentIDs []uint64) error {
+	for _, parentID := range parentIDs {
+		if groupID != 0 && parentID == groupID {
+			return errors.ErrGroupCycle
+		}
+		if err := s.ensureGroupExists(parentID); err != nil {
+			return err
+		}
+	}
+
+	// 新建用户组没有后代，不可能形成循环
+	if groupID == 0 || len(parentIDs) == 0 {
+		return nil
+	}
+
+	// 上级组不能是当前组的后代
+	descendants, err := s.groupRepo.GetDescendantIDs(groupID)
+	if err != nil {
+		return err
+	}
+
+	for _, descendantID := range descendants {
+		for _, parentID := range parentIDs {
+			if descendantID == parentID {
+				return errors.ErrGroupCycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensureGroupExists 检查用户组是否存在
+func (s *groupService) ensureGroupExists(groupID uint64) error {
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	if group == nil {
+		return errors.ErrGroupNotFound
+	}
+
+	return nil
+}
+
+// convertToGroupResponse 将用户组模型转换为响应结构
+func convertToGroupResponse(group *model.Group) *schema.GroupResponse {
+	response := &schema.GroupResponse{
+		ID:          group.ID,
+		Code:        group.Code,
+		Name:        group.Name,
+		Description: group.Description,
+		Parents:     make([]schema.GroupSimple, 0, len(group.Parents)),
+		CreatedAt:   group.CreatedAt,
+	}
+	for _, parent := range group.Parents {
+		response.Parents = append(response.Parents, schema.GroupSimple{ID: parent.ID, Code: parent.Code, Name: parent.Name})
+	}
+	return response
+}
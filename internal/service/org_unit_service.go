@@ -0,0 +1,319 @@
+package service
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// OrgUnitService 组织单元与角色数据范围服务接口
+type OrgUnitService interface {
+	Create(req *schema.CreateOrgUnitRequest) (uint64, error)
+	Update(req *schema.UpdateOrgUnitRequest) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*schema.OrgUnitResponse, error)
+	Tree() ([]*schema.OrgUnitTreeNode, error)
+	AddMembers(req *schema.OrgUnitMembersRequest) error
+	RemoveMembers(req *schema.OrgUnitMembersRequest) error
+	ListMembers(req *schema.ListOrgUnitMembersRequest) (*schema.ListUserResponse, error)
+	AssignRoleDataScope(req *schema.AssignDataScopeRequest) error
+}
+
+// orgUnitService 组织单元服务实现
+type orgUnitService struct {
+	orgUnitRepo repository.OrgUnitRepository
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+}
+
+// NewOrgUnitService 创建组织单元服务实例
+func NewOrgUnitService(orgUnitRepo repository.OrgUnitRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository) OrgUnitService {
+	return &orgUnitService{
+		orgUnitRepo: orgUnitRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+	}
+}
+
+// Create 创建组织单元
+func (s *orgUnitService) Create(req *schema.CreateOrgUnitRequest) (uint64, error) {
+	if err := s.checkUnique(0, req.Code); err != nil {
+		return 0, err
+	}
+
+	// 新建的单元没有下级，只需校验上级单元存在
+	if err := s.validateParent(0, req.ParentID); err != nil {
+		return 0, err
+	}
+
+	unit := &model.OrgUnit{
+		ParentID:    req.ParentID,
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Sort:        req.Sort,
+	}
+	if err := s.orgUnitRepo.Create(unit); err != nil {
+		return 0, err
+	}
+
+	return unit.ID, nil
+}
+
+// Update 更新组织单元，可移动到其他上级单元下，但不能移动到自身的下级单元下
+func (s *orgUnitService) Update(req *schema.UpdateOrgUnitRequest) error {
+	if err := s.ensureOrgUnitExists(req.ID); err != nil {
+		return err
+	}
+
+	if err := s.checkUnique(req.ID, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.validateParent(req.ID, req.ParentID); err != nil {
+		return err
+	}
+
+	return s.orgUnitRepo.Update(&model.OrgUnit{
+		ID:          req.ID,
+		ParentID:    req.ParentID,
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Sort:        req.Sort,
+	})
+}
+
+// Delete 删除组织单元，仍有下级单元时不允许删除
+func (s *orgUnitService) Delete(id uint64) error {
+	if err := s.ensureOrgUnitExists(id); err != nil {
+		return err
+	}
+
+	count, err := s.orgUnitRepo.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.ErrOrgUnitHasChildren
+	}
+
+	return s.orgUnitRepo.Delete(id)
+}
+
+// GetByID 获取组织单元详情，包括直接成员数
+func (s *orgUnitService) GetByID(id uint64) (*schema.OrgUnitResponse, error) {
+	unit, err := s.orgUnitRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if unit == nil {
+		return nil, errors.ErrOrgUnitNotFound
+	}
+
+	count, err := s.orgUnitRepo.CountMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toOrgUnitResponse(unit)
+	response.MemberCount = count
+	return response, nil
+}
+
+// Tree 获取完整的组织单元树，同级按排序值排列
+func (s *orgUnitService) Tree() ([]*schema.OrgUnitTreeNode, error) {
+	units, err := s.orgUnitRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint64]struct{}, len(units))
+	for _, unit := range units {
+		ids[unit.ID] = struct{}{}
+	}
+
+	// 上级单元不存在的节点作为根节点
+	var roots []*model.OrgUnit
+	children := make(map[uint64][]*model.OrgUnit)
+	for _, unit := range units {
+		if _, ok := ids[unit.ParentID]; ok && unit.ParentID != 0 {
+			children[unit.ParentID] = append(children[unit.ParentID], unit)
+		} else {
+			roots = append(roots, unit)
+		}
+	}
+
+	var build func(level []*model.OrgUnit) []*schema.OrgUnitTreeNode
+	build = func(level []*model.OrgUnit) []*schema.OrgUnitTreeNode {
+		nodes := make([]*schema.OrgUnitTreeNode, 0, len(level))
+		for _, unit := range level {
+			nodes = append(nodes, &schema.OrgUnitTreeNode{
+				OrgUnitResponse: *toOrgUnitResponse(unit),
+				Children:        build(children[unit.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots), nil
+}
+
+// AddMembers 将用户加入组织单元，已是成员的用户保持不变
+func (s *orgUnitService) AddMembers(req *schema.OrgUnitMembersRequest) error {
+	if err := s.ensureOrgUnitExists(req.OrgUnitID); err != nil {
+		return err
+	}
+
+	// 检查所有用户是否存在
+	for _, userID := range req.UserIDs {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return errors.ErrUserNotFound
+		}
+	}
+
+	return s.orgUnitRepo.AddMembers(req.OrgUnitID, req.UserIDs)
+}
+
+// RemoveMembers 将用户移出组织单元，不是成员的用户忽略
+func (s *orgUnitService) RemoveMembers(req *schema.OrgUnitMembersRequest) error {
+	if err := s.ensureOrgUnitExists(req.OrgUnitID); err != nil {
+		return err
+	}
+
+	return s.orgUnitRepo.RemoveMembers(req.OrgUnitID, req.UserIDs)
+}
+
+// ListMembers 分页获取组织单元的直接成员
+func (s *orgUnitService) ListMembers(req *schema.ListOrgUnitMembersRequest) (*schema.ListUserResponse, error) {
+	if err := s.ensureOrgUnitExists(req.OrgUnitID); err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.orgUnitRepo.ListMembers(req.OrgUnitID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.UserResponse, 0, len(users))
+	for _, user := range users {
+		items = append(items, schema.UserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ListUserResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// AssignRoleDataScope 设置角色的数据范围，custom 范围需指定可访问的组织单元
+func (s *orgUnitService) AssignRoleDataScope(req *schema.AssignDataScopeRequest) error {
+	if !model.IsValidDataScope(req.DataScope) {
+		return errors.ErrInvalidDataScope
+	}
+
+	role, err := s.roleRepo.GetByID(req.RoleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return errors.ErrRoleNotFound
+	}
+
+	var unitIDs []uint64
+	if req.DataScope == model.DataScopeCustom {
+		for _, unitID := range req.OrgUnitIDs {
+			if err := s.ensureOrgUnitExists(unitID); err != nil {
+				return err
+			}
+		}
+		unitIDs = req.OrgUnitIDs
+	}
+
+	return s.roleRepo.SetDataScope(req.RoleID, req.DataScope, unitIDs)
+}
+
+// checkUnique 校验编码未被其他组织单元使用，unitID为0表示新建组织单元
+func (s *orgUnitService) checkUnique(unitID uint64, code string) error {
+	unit, err := s.orgUnitRepo.GetByCode(code)
+	if err != nil {
+		return err
+	}
+	if unit != nil && unit.ID != unitID {
+		return errors.ErrOrgUnitExists
+	}
+	return nil
+}
+
+// validateParent 校验上级单元存在且不是当前单元自身或其下级单元，unitID为0表示新建组织单元
+func (s *orgUnitService) validateParent(unitID, parentID uint64) error {
+	if parentID == 0 {
+		return nil
+	}
+
+	if err := s.ensureOrgUnitExists(parentID); err != nil {
+		return err
+	}
+
+	// 新建组织单元没有下级，不可能形成循环
+	if unitID == 0 {
+		return nil
+	}
+
+	// 子树包含当前单元自身
+	subtree, err := s.orgUnitRepo.GetSubtreeIDs(unitID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range subtree {
+		if id == parentID {
+			return errors.ErrOrgUnitCycle
+		}
+	}
+	return nil
+}
+
+// ensureOrgUnitExists 检查组织单元是否存在
+func (s *orgUnitService) ensureOrgUnitExists(unitID uint64) error {
+	unit, err := s.orgUnitRepo.GetByID(unitID)
+	if err != nil {
+		return err
+	}
+
+	if unit == nil {
+		return errors.ErrOrgUnitNotFound
+	}
+
+	return nil
+}
+
+// toOrgUnitResponse 将组织单元模型转换为响应结构
+func toOrgUnitResponse(unit *model.OrgUnit) *schema.OrgUnitResponse {
+	return &schema.OrgUnitResponse{
+		ID:          unit.ID,
+		ParentID:    unit.ParentID,
+		Code:        unit.Code,
+		Name:        unit.Name,
+		Description: unit.Description,
+		Sort:        unit.Sort,
+		CreatedAt:   unit.CreatedAt,
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+)
+
+// PermissionCacheStats 权限缓存统计信息
+type PermissionCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"` // 因容量或过期被淘汰的条目数
+	Entries   int    `json:"entries"`
+}
+
+// PermissionCache 进程内的用户有效权限缓存
+// 按用户和租户缓存授予记录与编译后的判定策略，超过TTL或容量上限时淘汰，
+// 并按角色、权限建立反向索引，以便在角色授权、继承关系或权限变更时精确失效。
+// 零值不可用，请使用 NewPermissionCache 创建；nil 缓存的所有方法均为空操作。
+type PermissionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	entries      map[permissionCacheKey]*list.Element // 用户与租户 -> LRU链表节点
+	lru          *list.List
+	byUser       map[uint64]map[permissionCacheKey]struct{} // 用户ID -> 缓存键集合
+	byRole       map[uint64]map[permissionCacheKey]struct{} // 角色ID -> 缓存键集合
+	byPermission map[uint64]map[permissionCacheKey]struct{} // 权限ID -> 缓存键集合
+
+	// generation 每次失效时递增，用于丢弃失效前开始加载的结果
+	generation uint64
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// permissionCacheKey 缓存键，同一用户在不同租户内的有效权限分别缓存
+type permissionCacheKey struct {
+	userID   uint64
+	tenantID uint64
+}
+
+// permissionCacheEntry 单个用户在单个租户内的缓存条目
+type permissionCacheEntry struct {
+	key       permissionCacheKey
+	roleIDs   []uint64
+	grants    []*model.PermissionGrant
+	policy    *permcode.Policy
+	expiresAt time.Time
+}
+
+// NewPermissionCache 创建权限缓存，maxEntries 小于等于0时不限制容量
+func NewPermissionCache(ttl time.Duration, maxEntries int) *PermissionCache {
+	return &PermissionCache{
+		ttl:          ttl,
+		maxEntries:   maxEntries,
+		now:          time.Now,
+		entries:      make(map[permissionCacheKey]*list.Element),
+		lru:          list.New(),
+		byUser:       make(map[uint64]map[permissionCacheKey]struct{}),
+		byRole:       make(map[uint64]map[permissionCacheKey]struct{}),
+		byPermission: make(map[uint64]map[permissionCacheKey]struct{}),
+	}
+}
+
+// Generation 返回当前失效代数，加载数据前获取并在写入时传回
+func (c *PermissionCache) Generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// get 获取用户在指定租户内的缓存条目
+func (c *PermissionCache) get(userID, tenantID uint64) (*permissionCacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[permissionCacheKey{userID: userID, tenantID: tenantID}]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := elem.Value.(*permissionCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		c.evictions++
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits++
+	return entry, true
+}
+
+// set 写入用户在指定租户内的缓存条目，若加载期间发生过失效则丢弃
+// notAfter 大于0时条目最迟在该时刻（Unix时间戳，秒）过期，用于角色分配生效或失效的时刻
+func (c *PermissionCache) set(generation uint64, userID, tenantID uint64, roleIDs []uint64, grants []*model.PermissionGrant, notAfter int64) *permissionCacheEntry {
+	allow, deny := splitGrants(grants)
+	entry := &permissionCacheEntry{
+		key:     permissionCacheKey{userID: userID, tenantID: tenantID},
+		roleIDs: roleIDs,
+		grants:  grants,
+		policy:  permcode.NewPolicy(allow, deny),
+	}
+	if c == nil {
+		return entry
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return entry
+	}
+
+	if elem, ok := c.entries[entry.key]; ok {
+		c.removeElement(elem)
+	}
+
+	entry.expiresAt = c.now().Add(c.ttl)
+	if notAfter > 0 {
+		if deadline := time.Unix(notAfter, 0); deadline.Before(entry.expiresAt) {
+			entry.expiresAt = deadline
+		}
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	addIndex(c.byUser, userID, entry.key)
+	for _, roleID := range roleIDs {
+		addIndex(c.byRole, roleID, entry.key)
+	}
+	for _, grant := range grants {
+		addIndex(c.byPermission, grant.PermissionID, entry.key)
+	}
+
+	// 超出容量时淘汰最久未使用的条目
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.removeElement(c.lru.Back())
+		c.evictions++
+	}
+
+	return entry
+}
+
+// InvalidateUsers 使指定用户在所有租户内的缓存失效，用于用户角色变更或用户删除
+func (c *PermissionCache) InvalidateUsers(userIDs ...uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, userID := range userIDs {
+		c.invalidateIndexed(c.byUser[userID])
+	}
+}
+
+// InvalidateRoles 使有效角色包含指定角色的用户缓存失效，
+// 用于角色授权、继承关系变更或角色删除；继承该角色的后代角色用户同样会失效
+func (c *PermissionCache) InvalidateRoles(roleIDs ...uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, roleID := range roleIDs {
+		c.invalidateIndexed(c.byRole[roleID])
+	}
+}
+
+// InvalidatePermissions 使授予了指定权限的用户缓存失效，用于权限更新或删除
+func (c *PermissionCache) InvalidatePermissions(permissionIDs ...uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, permissionID := range permissionIDs {
+		c.invalidateIndexed(c.byPermission[permissionID])
+	}
+}
+
+// Purge 清空全部缓存
+func (c *PermissionCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[permissionCacheKey]*list.Element)
+	c.lru.Init()
+	c.byUser = make(map[uint64]map[permissionCacheKey]struct{})
+	c.byRole = make(map[uint64]map[permissionCacheKey]struct{})
+	c.byPermission = make(map[uint64]map[permissionCacheKey]struct{})
+}
+
+// Stats 返回缓存命中统计
+func (c *PermissionCache) Stats() PermissionCacheStats {
+	if c == nil {
+		return PermissionCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return PermissionCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+	}
+}
+
+// invalidateIndexed 删除索引集合中的全部条目，调用方需持有锁
+func (c *PermissionCache) invalidateIndexed(keys map[permissionCacheKey]struct{}) {
+	// 删除条目会修改索引集合，先复制再遍历
+	indexed := make([]permissionCacheKey, 0, len(keys))
+	for key := range keys {
+		indexed = append(indexed, key)
+	}
+	for _, key := range indexed {
+		if elem, ok := c.entries[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// removeElement 删除缓存条目及其反向索引，调用方需持有锁
+func (c *PermissionCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*permissionCacheEntry)
+	delete(c.entries, entry.key)
+	removeIndex(c.byUser, entry.key.userID, entry.key)
+	for _, roleID := range entry.roleIDs {
+		removeIndex(c.byRole, roleID, entry.key)
+	}
+	for _, grant := range entry.grants {
+		removeIndex(c.byPermission, grant.PermissionID, entry.key)
+	}
+}
+
+// addIndex 向反向索引添加缓存键
+func addIndex(index map[uint64]map[permissionCacheKey]struct{}, id uint64, key permissionCacheKey) {
+	keys, ok := index[id]
+	if !ok {
+		keys = make(map[permissionCacheKey]struct{})
+		index[id] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// removeIndex 从反向索引移除缓存键
+func removeIndex(index map[uint64]map[permissionCacheKey]struct{}, id uint64, key permissionCacheKey) {
+	keys, ok := index[id]
+	if !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(index, id)
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+)
+
+// PermissionSource 解析用户有效权限所需的数据来源，repository.UserRepository 满足该接口
+type PermissionSource interface {
+	GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error)
+	GetNextValidityChange(userID, tenantID uint64) (int64, error)
+	GetEffectivePermissionGrants(userID, tenantID uint64) ([]*model.PermissionGrant, error)
+	GetEffectiveRoleParents(userID, tenantID uint64) ([]*model.RoleInheritance, error)
+}
+
+// RouteSource 接口权限的数据来源，repository.PermissionRepository 满足该接口
+type RouteSource interface {
+	ListRoutes() ([]*model.Permission, error)
+}
+
+// PermissionResolver 解析用户的权限判定策略与请求匹配的接口权限
+// 用户服务与嵌入到其他服务的权限校验共用该解析逻辑及缓存
+type PermissionResolver struct {
+	source PermissionSource
+	routes *RouteTable
+	cache  *PermissionCache
+}
+
+// NewPermissionResolver 创建权限解析器，cache 可为nil，表示不缓存有效权限
+func NewPermissionResolver(source PermissionSource, routes *RouteTable, cache *PermissionCache) *PermissionResolver {
+	return &PermissionResolver{
+		source: source,
+		routes: routes,
+		cache:  cache,
+	}
+}
+
+// GetPermissionPolicy 获取用户在指定租户内的权限判定策略
+func (r *PermissionResolver) GetPermissionPolicy(userID, tenantID uint64) (*permcode.Policy, error) {
+	permissions, err := r.load(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions.policy, nil
+}
+
+// GetSessionPolicy 获取会话仅激活部分角色时的权限判定策略
+// 只计入激活角色及其继承的祖先角色的授予与直接授予用户的权限；activeRoles 为空时激活全部角色
+func (r *PermissionResolver) GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*permcode.Policy, error) {
+	permissions, err := r.loadSession(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions.policy, nil
+}
+
+// GetRoutePermissions 获取方法与路径模式匹配指定请求的接口权限编码
+// 从内存中的接口路由表匹配，接口权限变更后路由表重新加载，无需重启即可生效
+func (r *PermissionResolver) GetRoutePermissions(method, path string) ([]string, error) {
+	return r.routes.Match(method, path)
+}
+
+// load 获取用户在指定租户内的权限授予记录与判定策略，优先读取缓存
+// 租户ID为0时仅计入全局角色分配
+func (r *PermissionResolver) load(userID, tenantID uint64) (*permissionCacheEntry, error) {
+	if entry, ok := r.cache.get(userID, tenantID); ok {
+		return entry, nil
+	}
+
+	// 有效角色ID与下一次分配变化时间仅用于缓存的失效，未启用缓存时无需查询
+	generation := r.cache.Generation()
+	var roleIDs []uint64
+	var notAfter int64
+	if r.cache != nil {
+		ids, err := r.source.GetEffectiveRoleIDs(userID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		roleIDs = ids
+
+		if notAfter, err = r.source.GetNextValidityChange(userID, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	grants, err := r.source.GetEffectivePermissionGrants(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.cache.set(generation, userID, tenantID, roleIDs, grants, notAfter), nil
+}
+
+// loadSession 获取会话中生效的权限授予记录与判定策略，activeRoles 为空时即为全部有效权限。
+// 只激活部分角色时由缓存的有效权限过滤得到，过滤结果本身不缓存
+func (r *PermissionResolver) loadSession(userID, tenantID uint64, activeRoles []uint64) (*permissionCacheEntry, error) {
+	permissions, err := r.load(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(activeRoles) == 0 {
+		return permissions, nil
+	}
+
+	inheritances, err := r.source.GetEffectiveRoleParents(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	active := sessionRoles(activeRoles, inheritances)
+
+	grants := make([]*model.PermissionGrant, 0, len(permissions.grants))
+	for _, grant := range permissions.grants {
+		if _, ok := active[grant.RoleID]; ok || grant.IsDirect() {
+			grants = append(grants, grant)
+		}
+	}
+	allow, deny := splitGrants(grants)
+	return &permissionCacheEntry{grants: grants, policy: permcode.NewPolicy(allow, deny)}, nil
+}
+
+// sessionRoles 返回会话激活的角色及其经由继承关系可达的全部祖先角色
+func sessionRoles(activeRoles []uint64, inheritances []*model.RoleInheritance) map[uint64]struct{} {
+	parents := make(map[uint64][]uint64)
+	for _, inheritance := range inheritances {
+		parents[inheritance.RoleID] = append(parents[inheritance.RoleID], inheritance.ParentID)
+	}
+
+	active := make(map[uint64]struct{})
+	queue := append([]uint64(nil), activeRoles...)
+	for len(queue) > 0 {
+		roleID := queue[0]
+		queue = queue[1:]
+		if _, ok := active[roleID]; ok {
+			continue
+		}
+		active[roleID] = struct{}{}
+		queue = append(queue, parents[roleID]...)
+	}
+	return active
+}
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"fmt"
+
 	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
 	"github.com/lvyunze/fiber-rbac/internal/repository"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"log/slog"
 )
 
 // PermissionService 权限服务接口
@@ -14,22 +19,34 @@ type PermissionService interface {
 	Delete(id uint64) error
 	GetByID(id uint64) (*schema.PermissionResponse, error)
 	List(req *schema.ListPermissionRequest) (*schema.ListPermissionResponse, error)
+	Tree(req *schema.PermissionTreeRequest) ([]*schema.PermissionTreeNode, error)
+	SyncRoutes(routes []apiroute.Route) (*schema.RouteSyncReport, error)
 }
 
 // permissionService 权限服务实现
 type permissionService struct {
-	permissionRepo repository.PermissionRepository
+	permissionRepo  repository.PermissionRepository
+	permissionCache *PermissionCache
+	routeTable      *RouteTable
 }
 
-// NewPermissionService 创建权限服务实例
-func NewPermissionService(permissionRepo repository.PermissionRepository) PermissionService {
+// NewPermissionService 创建权限服务实例，permissionCache 与 routeTable 可为nil；
+// routeTable 为按方法与路径校验权限时使用的接口路由表，接口权限变更后使其重建
+func NewPermissionService(permissionRepo repository.PermissionRepository, permissionCache *PermissionCache, routeTable *RouteTable) PermissionService {
 	return &permissionService{
-		permissionRepo: permissionRepo,
+		permissionRepo:  permissionRepo,
+		permissionCache: permissionCache,
+		routeTable:      routeTable,
 	}
 }
 
 // Create 创建权限
 func (s *permissionService) Create(req *schema.CreatePermissionRequest) (uint64, error) {
+	// 校验权限编码语法
+	if err := validateCode(req.Code); err != nil {
+		return 0, err
+	}
+
 	// 检查权限名是否已存在
 	existingPermission, err := s.permissionRepo.GetByName(req.Name)
 	if err != nil {
@@ -50,17 +67,29 @@ func (s *permissionService) Create(req *schema.CreatePermissionRequest) (uint64,
 		return 0, errors.ErrPermissionExists
 	}
 
+	// 检查父级权限是否存在
+	if err := s.validateParent(0, req.ParentID); err != nil {
+		return 0, err
+	}
+
 	// 创建权限
 	permission := &model.Permission{
 		Code:        req.Code,
 		Name:        req.Name,
 		Description: req.Description,
+		ParentID:    req.ParentID,
+		Type:        req.Type,
+		Sort:        req.Sort,
+		Icon:        req.Icon,
+		Method:      req.Method,
+		Route:       req.Route,
 	}
 
 	if err := s.permissionRepo.Create(permission); err != nil {
 		return 0, err
 	}
 
+	s.routeTable.Invalidate()
 	return permission.ID, nil
 }
 
@@ -90,6 +119,10 @@ func (s *permissionService) Update(req *schema.UpdatePermissionRequest) error {
 
 	// 检查权限标识是否已被其他权限使用
 	if req.Code != existingPermission.Code {
+		if err := validateCode(req.Code); err != nil {
+			return err
+		}
+
 		permission, err := s.permissionRepo.GetByCode(req.Code)
 		if err != nil {
 			return err
@@ -100,15 +133,51 @@ func (s *permissionService) Update(req *schema.UpdatePermissionRequest) error {
 		}
 	}
 
-	// 更新权限信息
+	// 更新权限信息，未指定的树结构字段沿用原值
 	updatedPermission := &model.Permission{
 		ID:          req.ID,
 		Code:        req.Code,
 		Name:        req.Name,
 		Description: req.Description,
+		ParentID:    existingPermission.ParentID,
+		Type:        existingPermission.Type,
+		Sort:        existingPermission.Sort,
+		Icon:        existingPermission.Icon,
+		Method:      existingPermission.Method,
+		Route:       existingPermission.Route,
+	}
+	if req.ParentID != nil && *req.ParentID != existingPermission.ParentID {
+		if err := s.validateParent(req.ID, *req.ParentID); err != nil {
+			return err
+		}
+		updatedPermission.ParentID = *req.ParentID
+	}
+	if req.Type != nil {
+		updatedPermission.Type = *req.Type
+	}
+	if req.Sort != nil {
+		updatedPermission.Sort = *req.Sort
+	}
+	if req.Icon != nil {
+		updatedPermission.Icon = *req.Icon
+	}
+	if req.Method != nil {
+		updatedPermission.Method = *req.Method
+	}
+	if req.Route != nil {
+		updatedPermission.Route = *req.Route
 	}
 
-	return s.permissionRepo.Update(updatedPermission)
+	if err := s.permissionRepo.Update(updatedPermission); err != nil {
+		return err
+	}
+	s.routeTable.Invalidate()
+
+	// 权限编码变更会改变已缓存的判定策略
+	if req.Code != existingPermission.Code {
+		s.permissionCache.InvalidatePermissions(req.ID)
+	}
+	return nil
 }
 
 // Delete 删除权限
@@ -128,8 +197,24 @@ func (s *permissionService) Delete(id uint64) error {
 		return errors.ErrPermissionInUse
 	}
 
+	// 检查权限是否还有子节点
+	children, err := s.permissionRepo.CountChildren(id)
+	if err != nil {
+		return err
+	}
+
+	if children > 0 {
+		return errors.ErrPermissionHasChildren
+	}
+
 	// 删除权限
-	return s.permissionRepo.Delete(id)
+	if err := s.permissionRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidatePermissions(id)
+	s.routeTable.Invalidate()
+	return nil
 }
 
 // GetByID 根据ID获取权限
@@ -172,13 +257,202 @@ func (s *permissionService) List(req *schema.ListPermissionRequest) (*schema.Lis
 	}, nil
 }
 
+// Tree 获取权限树，可按节点类型过滤
+func (s *permissionService) Tree(req *schema.PermissionTreeRequest) ([]*schema.PermissionTreeNode, error) {
+	permissions, err := s.permissionRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]struct{}, len(req.Types))
+	for _, t := range req.Types {
+		types[t] = struct{}{}
+	}
+
+	return buildPermissionTree(permissions, func(permission *model.Permission) bool {
+		if len(types) == 0 {
+			return true
+		}
+		_, ok := types[permission.Type]
+		return ok
+	}), nil
+}
+
+// SyncRoutes 将服务实际注册的接口路由同步为接口权限
+// 未登记的路由按 apiroute 编码创建接口权限，已有同编码但未绑定路由的权限补充方法与路径；
+// 已登记但路由不再存在的权限只报告不删除，避免误删已授予的权限
+func (s *permissionService) SyncRoutes(routes []apiroute.Route) (*schema.RouteSyncReport, error) {
+	// 同步中途出错时可能已写入部分权限，同样需要重建路由表
+	defer s.routeTable.Invalidate()
+
+	existing, err := s.permissionRepo.ListRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	bound := make(map[apiroute.Route]struct{}, len(existing))
+	for _, permission := range existing {
+		bound[apiroute.Route{Method: permission.Method, Path: permission.Route}] = struct{}{}
+	}
+
+	report := &schema.RouteSyncReport{
+		Created: []string{},
+		Linked:  []string{},
+		Stale:   []string{},
+		Skipped: []string{},
+	}
+	registered := make(map[apiroute.Route]struct{}, len(routes))
+	for _, route := range routes {
+		registered[route] = struct{}{}
+		if _, ok := bound[route]; ok {
+			continue
+		}
+
+		code := route.Code()
+		if err := permcode.Validate(code); err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", route, err))
+			continue
+		}
+
+		permission, err := s.permissionRepo.GetByCode(code)
+		if err != nil {
+			return nil, err
+		}
+
+		// 已有同编码的权限（如手工创建），为其补充方法与路径
+		if permission != nil {
+			if permission.Method != "" {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: 编码 %s 已绑定到 %s %s", route, code, permission.Method, permission.Route))
+				continue
+			}
+			permission.Type = model.PermissionTypeAPI
+			permission.Method = route.Method
+			permission.Route = route.Path
+			if err := s.permissionRepo.Update(permission); err != nil {
+				return nil, err
+			}
+			report.Linked = append(report.Linked, route.String())
+			continue
+		}
+
+		permission = &model.Permission{
+			Code:        code,
+			Name:        route.String(),
+			Description: "接口 " + route.String(),
+			Type:        model.PermissionTypeAPI,
+			Method:      route.Method,
+			Route:       route.Path,
+		}
+		if err := s.permissionRepo.Create(permission); err != nil {
+			// 编码或名称与已删除的权限冲突时跳过，不影响其他路由
+			slog.Warn("创建接口权限失败", "route", route.String(), "code", code, "error", err)
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", route, err))
+			continue
+		}
+		report.Created = append(report.Created, route.String())
+	}
+
+	// 手工维护的匹配模式不对应具体路由，不参与失效检查
+	for _, permission := range existing {
+		route := apiroute.Route{Method: permission.Method, Path: permission.Route}
+		if route.IsPattern() {
+			continue
+		}
+		if _, ok := registered[route]; !ok {
+			slog.Warn("接口权限对应的路由已不存在", "id", permission.ID, "code", permission.Code, "route", route.String())
+			report.Stale = append(report.Stale, route.String())
+		}
+	}
+
+	return report, nil
+}
+
+// validateParent 校验父级权限存在，且不是权限自身或其子孙节点（permissionID 为0表示新建）
+func (s *permissionService) validateParent(permissionID, parentID uint64) error {
+	visited := make(map[uint64]struct{})
+	for id := parentID; id != 0; {
+		if id == permissionID {
+			return errors.ErrInvalidParent
+		}
+		if _, ok := visited[id]; ok {
+			return errors.ErrInvalidParent
+		}
+		visited[id] = struct{}{}
+
+		parent, err := s.permissionRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return errors.ErrInvalidParent
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
+
 // convertToPermissionResponse 将权限模型转换为响应结构
 func (s *permissionService) convertToPermissionResponse(permission *model.Permission) *schema.PermissionResponse {
+	return toPermissionResponse(permission)
+}
+
+// toPermissionResponse 将权限模型转换为响应结构
+func toPermissionResponse(permission *model.Permission) *schema.PermissionResponse {
 	return &schema.PermissionResponse{
 		ID:          permission.ID,
 		Code:        permission.Code,
 		Name:        permission.Name,
 		Description: permission.Description,
+		ParentID:    permission.ParentID,
+		Type:        permission.Type,
+		Sort:        permission.Sort,
+		Icon:        permission.Icon,
+		Method:      permission.Method,
+		Route:       permission.Route,
 		CreatedAt:   permission.CreatedAt,
 	}
 }
+
+// buildPermissionTree 按父级关系组装权限树，同级保持输入顺序
+// keep 返回 false 的节点连同其子树一起省略，父级不存在的节点视为根节点
+func buildPermissionTree(permissions []*model.Permission, keep func(*model.Permission) bool) []*schema.PermissionTreeNode {
+	ids := make(map[uint64]struct{}, len(permissions))
+	for _, permission := range permissions {
+		ids[permission.ID] = struct{}{}
+	}
+
+	var roots []*model.Permission
+	children := make(map[uint64][]*model.Permission)
+	for _, permission := range permissions {
+		if _, ok := ids[permission.ParentID]; ok && permission.ParentID != 0 {
+			children[permission.ParentID] = append(children[permission.ParentID], permission)
+		} else {
+			roots = append(roots, permission)
+		}
+	}
+
+	var build func(level []*model.Permission) []*schema.PermissionTreeNode
+	build = func(level []*model.Permission) []*schema.PermissionTreeNode {
+		nodes := make([]*schema.PermissionTreeNode, 0, len(level))
+		for _, permission := range level {
+			if !keep(permission) {
+				continue
+			}
+			nodes = append(nodes, &schema.PermissionTreeNode{
+				PermissionResponse: *toPermissionResponse(permission),
+				Children:           build(children[permission.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// validateCode 校验权限编码是否符合 resource:action 语法（允许通配符）
+func validateCode(code string) error {
+	if err := permcode.Validate(code); err != nil {
+		slog.Warn("权限编码格式错误", "code", code, "reason", err)
+		return errors.ErrInvalidPermissionCode
+	}
+	return nil
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/policyfile"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// PolicyService 策略服务接口，以 Casbin 兼容的 CSV 格式整体导出与导入角色权限模型
+type PolicyService interface {
+	Export() (*policyfile.Policy, error)
+	Import(r io.Reader, dryRun bool) (*schema.PolicyImportReport, error)
+}
+
+// policyService 策略服务实现
+type policyService struct {
+	policyRepo      repository.PolicyRepository
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+	permissionRepo  repository.PermissionRepository
+	tenantRepo      repository.TenantRepository
+	permissionCache *PermissionCache
+	sod             *sodChecker
+}
+
+// NewPolicyService 创建策略服务实例
+func NewPolicyService(
+	policyRepo repository.PolicyRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+	tenantRepo repository.TenantRepository,
+	cache *PermissionCache,
+	sodRepo repository.SoDRepository, // 可为nil，表示导入时不校验职责分离规则
+) PolicyService {
+	return &policyService{
+		policyRepo:      policyRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		permissionRepo:  permissionRepo,
+		tenantRepo:      tenantRepo,
+		permissionCache: cache,
+		sod:             newSoDChecker(sodRepo, roleRepo),
+	}
+}
+
+// policyImport 一次策略导入的解析状态，缓存按编码查到的实体并收集变更与校验错误
+type policyImport struct {
+	roles       map[string]*model.Role
+	permissions map[string]*model.Permission
+	users       map[string]*model.User
+	tenants     map[string]*model.Tenant
+	change      model.PolicyChange
+	report      *schema.PolicyImportReport
+
+	// 角色分配变更涉及的用户与租户名称，用于报告违反职责分离规则的分配
+	usernames   map[uint64]string
+	tenantCodes map[uint64]string
+}
+
+// fail 记录一条校验错误
+func (imp *policyImport) fail(format string, args ...interface{}) {
+	imp.report.Errors = append(imp.report.Errors, fmt.Sprintf(format, args...))
+}
+
+// Export 导出全部角色权限授予（p 规则）与用户角色分配（g 规则）
+func (s *policyService) Export() (*policyfile.Policy, error) {
+	grants, err := s.policyRepo.GetGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := s.policyRepo.GetBindings()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &policyfile.Policy{
+		Grants:      make([]policyfile.Grant, 0, len(grants)),
+		Assignments: make([]policyfile.Assignment, 0, len(bindings)),
+	}
+	for _, grant := range grants {
+		// 条件表达式无法以 Casbin CSV 表达，带条件的授予不导出
+		if grant.IsConditional() {
+			continue
+		}
+		policy.Grants = append(policy.Grants, toPolicyGrant(grant))
+	}
+	for _, binding := range bindings {
+		policy.Assignments = append(policy.Assignments, toPolicyAssignment(binding))
+	}
+	policy.Sort()
+
+	return policy, nil
+}
+
+// Import 将策略内容整体同步到角色权限授予与用户角色分配：
+// 文件中不存在的角色会被创建，数据库中多出的授予与分配会被删除，效果不同的授予会被替换。
+// 存在校验错误时返回 ErrInvalidPolicy 及包含错误明细的报告，不执行任何变更；
+// dryRun 为 true 时只返回差异报告。全部变更在同一事务内执行
+func (s *policyService) Import(r io.Reader, dryRun bool) (*schema.PolicyImportReport, error) {
+	report := &schema.PolicyImportReport{
+		DryRun:       dryRun,
+		CreatedRoles: []string{},
+		Added:        []string{},
+		Removed:      []string{},
+	}
+
+	desired, err := policyfile.Parse(r)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report, errors.ErrInvalidPolicy
+	}
+
+	imp := &policyImport{
+		roles:       make(map[string]*model.Role),
+		permissions: make(map[string]*model.Permission),
+		users:       make(map[string]*model.User),
+		tenants:     make(map[string]*model.Tenant),
+		report:      report,
+		usernames:   make(map[uint64]string),
+		tenantCodes: make(map[uint64]string),
+	}
+	if err := s.diffGrants(imp, desired.Grants); err != nil {
+		return nil, err
+	}
+	if err := s.diffAssignments(imp, desired.Assignments); err != nil {
+		return nil, err
+	}
+	if err := s.checkSoD(imp); err != nil {
+		return nil, err
+	}
+
+	if len(report.Errors) > 0 {
+		return report, errors.ErrInvalidPolicy
+	}
+	if dryRun || imp.change.Empty() {
+		return report, nil
+	}
+
+	if err := s.policyRepo.Apply(&imp.change); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+
+	// 导入可能影响任意用户的有效权限，直接清空缓存
+	s.permissionCache.Purge()
+	slog.Info("策略导入完成", "createdRoles", len(report.CreatedRoles), "added", len(report.Added), "removed", len(report.Removed))
+
+	return report, nil
+}
+
+// diffGrants 对比权限授予规则与数据库现状，生成增删变更
+func (s *policyService) diffGrants(imp *policyImport, grants []policyfile.Grant) error {
+	current, err := s.policyRepo.GetGrants()
+	if err != nil {
+		return err
+	}
+
+	// 目标授予，同一角色对同一权限只能有一种效果
+	wanted := make(map[string]policyfile.Grant, len(grants))
+	for _, grant := range grants {
+		key := grant.Role + "|" + grant.Permission
+		if existing, ok := wanted[key]; ok {
+			if existing.Effect != grant.Effect {
+				imp.fail("角色 %s 对权限 %s 同时声明了 allow 与 deny", grant.Role, grant.Permission)
+			}
+			continue
+		}
+		wanted[key] = grant
+	}
+
+	existing := make(map[string]struct{}, len(current))
+	for _, record := range current {
+		grant := toPolicyGrant(record)
+		key := grant.Role + "|" + grant.Permission
+		// 带条件的授予不在策略文件中表达，导入时原样保留
+		if record.IsConditional() {
+			existing[key] = struct{}{}
+			continue
+		}
+		if target, ok := wanted[key]; ok && target.Effect == grant.Effect {
+			existing[key] = struct{}{}
+			continue
+		}
+
+		imp.change.RemoveGrants = append(imp.change.RemoveGrants, model.PolicyGrant{
+			Role:         &model.Role{ID: record.RoleID, Code: record.RoleCode},
+			PermissionID: record.PermissionID,
+		})
+		imp.report.Removed = append(imp.report.Removed, grant.String())
+	}
+
+	for _, grant := range grants {
+		key := grant.Role + "|" + grant.Permission
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		existing[key] = struct{}{}
+
+		role, err := s.resolveRole(imp, grant.Role, model.GlobalTenantID)
+		if err != nil {
+			return err
+		}
+		permission, err := s.resolvePermission(imp, grant.Permission)
+		if err != nil {
+			return err
+		}
+		if role == nil || permission == nil {
+			continue
+		}
+
+		imp.change.AddGrants = append(imp.change.AddGrants, model.PolicyGrant{
+			Role:         role,
+			PermissionID: permission.ID,
+			Effect:       wanted[key].Effect,
+		})
+		imp.report.Added = append(imp.report.Added, wanted[key].String())
+	}
+
+	return nil
+}
+
+// diffAssignments 对比角色分配规则与数据库现状，生成增删变更；
+// 保留的分配不会被改写，原有的生效区间保持不变
+func (s *policyService) diffAssignments(imp *policyImport, assignments []policyfile.Assignment) error {
+	current, err := s.policyRepo.GetBindings()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]struct{}, len(assignments))
+	for _, assignment := range assignments {
+		wanted[assignment.String()] = struct{}{}
+	}
+
+	existing := make(map[string]struct{}, len(current))
+	for _, binding := range current {
+		imp.tenantCodes[binding.TenantID] = binding.TenantCode
+		line := toPolicyAssignment(binding).String()
+		if _, ok := wanted[line]; ok {
+			existing[line] = struct{}{}
+			continue
+		}
+
+		imp.change.RemoveAssignments = append(imp.change.RemoveAssignments, model.PolicyAssignment{
+			UserID:   binding.UserID,
+			Role:     &model.Role{ID: binding.RoleID, Code: binding.RoleCode},
+			TenantID: binding.TenantID,
+		})
+		imp.usernames[binding.UserID] = binding.Username
+		imp.report.Removed = append(imp.report.Removed, line)
+	}
+
+	for _, assignment := range assignments {
+		line := assignment.String()
+		if _, ok := existing[line]; ok {
+			continue
+		}
+		existing[line] = struct{}{}
+
+		user, err := s.resolveUser(imp, assignment.User)
+		if err != nil {
+			return err
+		}
+		tenantID := model.GlobalTenantID
+		if assignment.Tenant != "" {
+			tenant, err := s.resolveTenant(imp, assignment.Tenant)
+			if err != nil {
+				return err
+			}
+			if tenant == nil {
+				continue
+			}
+			tenantID = tenant.ID
+		}
+		role, err := s.resolveRole(imp, assignment.Role, tenantID)
+		if err != nil {
+			return err
+		}
+		if user == nil || role == nil {
+			continue
+		}
+
+		// 租户角色只能在其所属租户内分配
+		if role.TenantID != model.GlobalTenantID && role.TenantID != tenantID {
+			imp.fail("%s: %v", line, errors.ErrTenantMismatch)
+			continue
+		}
+
+		imp.change.AddAssignments = append(imp.change.AddAssignments, model.PolicyAssignment{
+			UserID:   user.ID,
+			Role:     role,
+			TenantID: tenantID,
+		})
+		imp.usernames[user.ID] = user.Username
+		imp.tenantCodes[tenantID] = assignment.Tenant
+		imp.report.Added = append(imp.report.Added, line)
+	}
+
+	return nil
+}
+
+// checkSoD 校验角色分配有变更的用户在导入后的角色分配是否违反静态职责分离规则，
+// 经由用户组获得的角色同样计入，违反的用户记录为校验错误；导入创建的新角色不在任何规则中，无需计入
+func (s *policyService) checkSoD(imp *policyImport) error {
+	if s.sod == nil || (len(imp.change.AddAssignments) == 0 && len(imp.change.RemoveAssignments) == 0) {
+		return nil
+	}
+
+	rules, err := s.sod.sodRepo.ListByType(model.SoDStatic)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	type binding struct{ roleID, tenantID uint64 }
+	removed := make(map[uint64]map[binding]struct{})
+	var userIDs []uint64
+	for _, assignment := range imp.change.RemoveAssignments {
+		if _, ok := removed[assignment.UserID]; !ok {
+			removed[assignment.UserID] = make(map[binding]struct{})
+			userIDs = append(userIDs, assignment.UserID)
+		}
+		removed[assignment.UserID][binding{assignment.Role.ID, assignment.TenantID}] = struct{}{}
+	}
+	added := make(map[uint64][]model.PolicyAssignment)
+	for _, assignment := range imp.change.AddAssignments {
+		if _, ok := removed[assignment.UserID]; !ok {
+			if _, ok := added[assignment.UserID]; !ok {
+				userIDs = append(userIDs, assignment.UserID)
+			}
+		}
+		added[assignment.UserID] = append(added[assignment.UserID], assignment)
+	}
+
+	ancestors := make(map[uint64][]uint64)
+	for _, userID := range userIDs {
+		current, err := s.sod.sodRepo.GetUserAssignments(userID)
+		if err != nil {
+			return err
+		}
+
+		byTenant := make(map[uint64][]uint64)
+		for _, assignment := range current {
+			if _, ok := removed[userID][binding{assignment.RoleID, assignment.TenantID}]; !ok || assignment.GroupID != 0 {
+				byTenant[assignment.TenantID] = append(byTenant[assignment.TenantID], assignment.RoleID)
+			}
+		}
+		for _, assignment := range added[userID] {
+			if assignment.Role.ID != 0 {
+				byTenant[assignment.TenantID] = append(byTenant[assignment.TenantID], assignment.Role.ID)
+			}
+		}
+
+		rule, tenantID, err := s.sod.violation(rules, byTenant, ancestors)
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			continue
+		}
+		if tenantID == model.GlobalTenantID {
+			imp.fail("用户 %s: %v（%s）", imp.usernames[userID], errors.ErrSoDViolation, rule.Name)
+			continue
+		}
+		code, ok := imp.tenantCodes[tenantID]
+		if !ok {
+			tenant, err := s.tenantRepo.GetByID(tenantID)
+			if err != nil {
+				return err
+			}
+			if tenant != nil {
+				code = tenant.Code
+			}
+		}
+		imp.fail("用户 %s 在租户 %s 内: %v（%s）", imp.usernames[userID], code, errors.ErrSoDViolation, rule.Name)
+	}
+
+	return nil
+}
+
+// resolveRole 按编码查找角色：tenantID 大于0时优先使用该租户内的角色，其次为全局角色；
+// 没有全局角色时使用唯一一个使用该编码的租户角色，编码在多个租户内存在时记录校验错误；
+// 完全不存在时登记为待创建的全局角色
+func (s *policyService) resolveRole(imp *policyImport, code string, tenantID uint64) (*model.Role, error) {
+	key := fmt.Sprintf("%s@%d", code, tenantID)
+	if role, ok := imp.roles[key]; ok {
+		return role, nil
+	}
+
+	if tenantID != model.GlobalTenantID {
+		role, err := s.roleRepo.GetByCode(code, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			if role, err = s.resolveRole(imp, code, model.GlobalTenantID); err != nil {
+				return nil, err
+			}
+		}
+		imp.roles[key] = role
+		return role, nil
+	}
+
+	roles, err := s.roleRepo.ListByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *model.Role
+	switch {
+	case len(roles) > 0 && roles[0].TenantID == model.GlobalTenantID:
+		role = roles[0]
+	case len(roles) == 1:
+		role = roles[0]
+	case len(roles) > 1:
+		imp.fail("角色 %s 在多个租户内存在，无法确定引用的角色", code)
+	default:
+		// 新角色以编码作为名称，名称已被其他全局角色占用时无法创建
+		existing, err := s.roleRepo.GetByName(code, model.GlobalTenantID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			imp.fail("角色 %s 不存在，且同名角色已存在，无法创建", code)
+		} else {
+			role = &model.Role{Code: code, Name: code, Description: "由策略导入创建"}
+			imp.change.CreateRoles = append(imp.change.CreateRoles, role)
+			imp.report.CreatedRoles = append(imp.report.CreatedRoles, code)
+		}
+	}
+
+	imp.roles[key] = role
+	return role, nil
+}
+
+// resolvePermission 按编码查找权限，不存在时记录校验错误
+func (s *policyService) resolvePermission(imp *policyImport, code string) (*model.Permission, error) {
+	if permission, ok := imp.permissions[code]; ok {
+		return permission, nil
+	}
+
+	permission, err := s.permissionRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if permission == nil {
+		imp.fail("权限 %s 不存在", code)
+	}
+
+	imp.permissions[code] = permission
+	return permission, nil
+}
+
+// resolveUser 按用户名查找用户，不存在时记录校验错误
+func (s *policyService) resolveUser(imp *policyImport, username string) (*model.User, error) {
+	if user, ok := imp.users[username]; ok {
+		return user, nil
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		imp.fail("用户 %s 不存在", username)
+	}
+
+	imp.users[username] = user
+	return user, nil
+}
+
+// resolveTenant 按编码查找租户，不存在时记录校验错误
+func (s *policyService) resolveTenant(imp *policyImport, code string) (*model.Tenant, error) {
+	if tenant, ok := imp.tenants[code]; ok {
+		return tenant, nil
+	}
+
+	tenant, err := s.tenantRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		imp.fail("租户 %s 不存在", code)
+	}
+
+	imp.tenants[code] = tenant
+	return tenant, nil
+}
+
+// toPolicyGrant 将权限授予记录转换为 p 规则
+func toPolicyGrant(grant *model.PermissionGrant) policyfile.Grant {
+	effect := policyfile.EffectAllow
+	if grant.Effect == model.EffectDeny {
+		effect = policyfile.EffectDeny
+	}
+	return policyfile.Grant{Role: grant.RoleCode, Permission: grant.PermissionCode, Effect: effect}
+}
+
+// toPolicyAssignment 将用户角色分配转换为 g 规则
+func toPolicyAssignment(binding *model.UserRoleBinding) policyfile.Assignment {
+	return policyfile.Assignment{User: binding.Username, Role: binding.RoleCode, Tenant: binding.TenantCode}
+}
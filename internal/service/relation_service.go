@@ -0,0 +1,437 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/relation"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// RelationService 资源实例关系授权服务接口
+// 与基于角色的权限编码并存，用于判定主体对具体资源实例（如文档42）的访问
+type RelationService interface {
+	Write(req *schema.WriteRelationsRequest) (*schema.WriteRelationsResponse, error)
+	Delete(req *schema.WriteRelationsRequest) (*schema.WriteRelationsResponse, error)
+	Read(req *schema.ReadRelationsRequest) (*schema.ReadRelationsResponse, error)
+	Check(req *schema.CheckRelationRequest) (*schema.CheckRelationResponse, error)
+	Expand(req *schema.ExpandRelationRequest) (*schema.RelationTree, error)
+	ListObjects(req *schema.ListObjectsRequest) (*schema.ListObjectsResponse, error)
+}
+
+// relationService 关系授权服务实现，对象类型定义来自配置文件
+type relationService struct {
+	definitions  *relation.Schema
+	relationRepo repository.RelationRepository
+}
+
+// NewRelationService 创建关系授权服务实例
+func NewRelationService(definitions *relation.Schema, relationRepo repository.RelationRepository) RelationService {
+	return &relationService{
+		definitions:  definitions,
+		relationRepo: relationRepo,
+	}
+}
+
+// Write 写入关系元组，任一元组无效或引用了未定义的关系时不写入任何元组
+func (s *relationService) Write(req *schema.WriteRelationsRequest) (*schema.WriteRelationsResponse, error) {
+	tuples, invalid := s.parseTuples(req.Tuples, true)
+	if len(invalid) > 0 {
+		return &schema.WriteRelationsResponse{Errors: invalid}, errors.ErrInvalidRelationTuple
+	}
+
+	written, err := s.relationRepo.Write(tuples)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.WriteRelationsResponse{Affected: written}, nil
+}
+
+// Delete 删除关系元组，不校验关系是否仍有定义，以便清理配置变更后遗留的元组
+func (s *relationService) Delete(req *schema.WriteRelationsRequest) (*schema.WriteRelationsResponse, error) {
+	tuples, invalid := s.parseTuples(req.Tuples, false)
+	if len(invalid) > 0 {
+		return &schema.WriteRelationsResponse{Errors: invalid}, errors.ErrInvalidRelationTuple
+	}
+
+	deleted, err := s.relationRepo.Delete(tuples)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.WriteRelationsResponse{Affected: deleted}, nil
+}
+
+// parseTuples 解析关系元组，返回解析成功的元组与每条无效元组的错误描述
+func (s *relationService) parseTuples(raw []string, validate bool) ([]*model.RelationTuple, []string) {
+	tuples := make([]*model.RelationTuple, 0, len(raw))
+	var invalid []string
+	for i, item := range raw {
+		tuple, err := relation.ParseTuple(strings.TrimSpace(item))
+		if err == nil && validate {
+			err = s.definitions.Validate(tuple)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("第%d条 %s：%v", i+1, item, err))
+			continue
+		}
+		tuples = append(tuples, &model.RelationTuple{
+			ObjectType:      tuple.Object.Type,
+			ObjectID:        tuple.Object.ID,
+			Relation:        tuple.Relation,
+			SubjectType:     tuple.Subject.Type,
+			SubjectID:       tuple.Subject.ID,
+			SubjectRelation: tuple.Subject.Relation,
+		})
+	}
+	return tuples, invalid
+}
+
+// Read 按条件分页查询关系元组
+func (s *relationService) Read(req *schema.ReadRelationsRequest) (*schema.ReadRelationsResponse, error) {
+	filter := &model.RelationTuple{
+		ObjectType: req.ObjectType,
+		ObjectID:   req.ObjectID,
+		Relation:   req.Relation,
+	}
+	if req.Subject != "" {
+		subject, err := relation.ParseSubject(req.Subject)
+		if err != nil {
+			return nil, errors.ErrInvalidRelationTarget
+		}
+		filter.SubjectType = subject.Type
+		filter.SubjectID = subject.ID
+		filter.SubjectRelation = subject.Relation
+	}
+
+	tuples, total, err := s.relationRepo.List(filter, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.RelationTupleResponse, 0, len(tuples))
+	for _, t := range tuples {
+		tuple := toRelationTuple(t)
+		items = append(items, schema.RelationTupleResponse{
+			ID:        t.ID,
+			Tuple:     tuple.String(),
+			Object:    tuple.Object.String(),
+			Relation:  tuple.Relation,
+			Subject:   tuple.Subject.String(),
+			CreatedAt: t.CreatedAt,
+		})
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ReadRelationsResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// Check 判定主体是否对对象持有指定关系：主体直接写入该关系、属于写入该关系的用户集，
+// 或经由改写规则（包含的关系、关联对象上的关系）持有该关系
+func (s *relationService) Check(req *schema.CheckRelationRequest) (*schema.CheckRelationResponse, error) {
+	object, err := relation.ParseObject(req.Object)
+	if err != nil {
+		return nil, errors.ErrInvalidRelationTarget
+	}
+	subject, err := relation.ParseSubject(req.Subject)
+	if err != nil {
+		return nil, errors.ErrInvalidRelationTarget
+	}
+	if _, ok := s.definitions.Rewrite(object.Type, req.Relation); !ok {
+		return nil, errors.ErrRelationNotDefined
+	}
+
+	check := &relationCheck{
+		definitions: s.definitions,
+		repo:        s.relationRepo,
+		subject:     subject,
+		visiting:    make(map[string]struct{}),
+		memo:        make(map[string]bool),
+	}
+	allowed, err := check.check(object, req.Relation, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.CheckRelationResponse{Allowed: allowed}, nil
+}
+
+// Expand 展开对象上某个关系的全部持有者，返回按改写规则组织的树
+func (s *relationService) Expand(req *schema.ExpandRelationRequest) (*schema.RelationTree, error) {
+	object, err := relation.ParseObject(req.Object)
+	if err != nil {
+		return nil, errors.ErrInvalidRelationTarget
+	}
+	if _, ok := s.definitions.Rewrite(object.Type, req.Relation); !ok {
+		return nil, errors.ErrRelationNotDefined
+	}
+
+	return s.expand(object, req.Relation, make(map[string]struct{}), 0)
+}
+
+// expand 展开一个 对象#关系 节点，path 记录当前路径上的节点，再次出现时标记为环不再展开
+func (s *relationService) expand(object relation.Object, rel string, path map[string]struct{}, depth int) (*schema.RelationTree, error) {
+	if depth > s.definitions.MaxDepth() {
+		return nil, errors.ErrRelationDepthExceeded
+	}
+
+	node := &schema.RelationTree{Object: object.String(), Relation: rel, Subjects: []string{}}
+	key := node.Object + "#" + rel
+	if _, ok := path[key]; ok {
+		node.Cycle = true
+		return node, nil
+	}
+	rewrite, ok := s.definitions.Rewrite(object.Type, rel)
+	if !ok {
+		return node, nil
+	}
+	path[key] = struct{}{}
+	defer delete(path, key)
+
+	// 直接写入的主体与用户集
+	tuples, err := s.relationRepo.GetSubjects(object.Type, object.ID, rel)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tuples {
+		if t.SubjectRelation == "" {
+			node.Subjects = append(node.Subjects, toRelationTuple(t).Subject.String())
+			continue
+		}
+		child, err := s.expand(relation.Object{Type: t.SubjectType, ID: t.SubjectID}, t.SubjectRelation, path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		child.Via = schema.RelationViaUserset
+		node.Children = append(node.Children, child)
+	}
+
+	// 同一对象上包含本关系的关系
+	for _, included := range rewrite.Includes {
+		child, err := s.expand(object, included, path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		child.Via = schema.RelationViaIncludes
+		node.Children = append(node.Children, child)
+	}
+
+	// 经由关联对象获得的关系
+	for _, from := range rewrite.From {
+		related, err := s.relationRepo.GetSubjects(object.Type, object.ID, from.Tupleset)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range related {
+			if t.SubjectRelation != "" {
+				continue
+			}
+			child, err := s.expand(relation.Object{Type: t.SubjectType, ID: t.SubjectID}, from.Relation, path, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.Via = schema.RelationViaFrom
+			child.Tupleset = from.Tupleset
+			node.Children = append(node.Children, child)
+		}
+	}
+
+	return node, nil
+}
+
+// ListObjects 列出主体对其持有指定关系的全部对象
+// 从主体出发沿元组与改写规则反向查找主体所属的全部用户集，结果与逐个调用 Check 一致
+func (s *relationService) ListObjects(req *schema.ListObjectsRequest) (*schema.ListObjectsResponse, error) {
+	subject, err := relation.ParseSubject(req.Subject)
+	if err != nil {
+		return nil, errors.ErrInvalidRelationTarget
+	}
+	if _, ok := s.definitions.Rewrite(req.ObjectType, req.Relation); !ok {
+		return nil, errors.ErrRelationNotDefined
+	}
+
+	seen := map[relation.Subject]struct{}{subject: {}}
+	frontier := []relation.Subject{subject}
+	found := make(map[string]struct{})
+	for depth := 0; len(frontier) > 0; depth++ {
+		if depth > s.definitions.MaxDepth() {
+			return nil, errors.ErrRelationDepthExceeded
+		}
+
+		var next []relation.Subject
+		push := func(userset relation.Subject) {
+			if _, ok := seen[userset]; !ok {
+				seen[userset] = struct{}{}
+				next = append(next, userset)
+			}
+		}
+
+		for _, userset := range frontier {
+			if userset.Type == req.ObjectType && userset.Relation == req.Relation {
+				found[userset.ID] = struct{}{}
+			}
+
+			// 主体直接出现的元组
+			tuples, err := s.relationRepo.GetBySubject(userset.Type, userset.ID, userset.Relation)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range tuples {
+				push(relation.Subject{Type: t.ObjectType, ID: t.ObjectID, Relation: t.Relation})
+			}
+			if !userset.IsUserset() {
+				continue
+			}
+
+			// 同一对象上包含该关系的关系
+			for _, rel := range s.definitions.IncludedBy(userset.Type, userset.Relation) {
+				push(relation.Subject{Type: userset.Type, ID: userset.ID, Relation: rel})
+			}
+
+			// 以该对象为关联对象继承该关系的对象
+			inheritances := s.definitions.InheritedBy(userset.Relation)
+			if len(inheritances) == 0 {
+				continue
+			}
+			related, err := s.relationRepo.GetBySubject(userset.Type, userset.ID, "")
+			if err != nil {
+				return nil, err
+			}
+			for _, inheritance := range inheritances {
+				for _, t := range related {
+					if t.ObjectType == inheritance.ObjectType && t.Relation == inheritance.Tupleset {
+						push(relation.Subject{Type: t.ObjectType, ID: t.ObjectID, Relation: inheritance.Relation})
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	slog.Debug("列出主体可访问的对象", "subject", req.Subject, "objectType", req.ObjectType, "relation", req.Relation, "count", len(ids))
+	return &schema.ListObjectsResponse{ObjectType: req.ObjectType, Relation: req.Relation, ObjectIDs: ids}, nil
+}
+
+// relationCheck 一次关系判定的状态
+type relationCheck struct {
+	definitions *relation.Schema
+	repo        repository.RelationRepository
+	subject     relation.Subject
+	visiting    map[string]struct{} // 当前路径上正在判定的 对象#关系，再次出现时切断以避免环
+	memo        map[string]bool     // 已确定的判定结果
+	cuts        int                 // 因环被切断的次数，期间得到的否定结果依赖于路径，不能缓存
+}
+
+// check 判定主体是否持有对象上的关系
+func (c *relationCheck) check(object relation.Object, rel string, depth int) (bool, error) {
+	if depth > c.definitions.MaxDepth() {
+		return false, errors.ErrRelationDepthExceeded
+	}
+
+	key := object.String() + "#" + rel
+	if allowed, ok := c.memo[key]; ok {
+		return allowed, nil
+	}
+	if _, ok := c.visiting[key]; ok {
+		c.cuts++
+		return false, nil
+	}
+
+	// 用户集主体持有其自身
+	if c.subject.IsUserset() && c.subject.Object() == object && c.subject.Relation == rel {
+		return true, nil
+	}
+
+	// 关联对象的类型可能未定义该关系，视为没有持有者
+	rewrite, ok := c.definitions.Rewrite(object.Type, rel)
+	if !ok {
+		return false, nil
+	}
+
+	c.visiting[key] = struct{}{}
+	cuts := c.cuts
+	allowed, err := c.evaluate(object, rel, rewrite, depth)
+	delete(c.visiting, key)
+	if err != nil {
+		return false, err
+	}
+	if allowed || c.cuts == cuts {
+		c.memo[key] = allowed
+	}
+	return allowed, nil
+}
+
+// evaluate 依次判定直接写入的主体、用户集、包含的关系与关联对象上的关系
+func (c *relationCheck) evaluate(object relation.Object, rel string, rewrite *relation.Rewrite, depth int) (bool, error) {
+	tuples, err := c.repo.GetSubjects(object.Type, object.ID, rel)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tuples {
+		if toRelationTuple(t).Subject == c.subject {
+			return true, nil
+		}
+	}
+	for _, t := range tuples {
+		if t.SubjectRelation == "" {
+			continue
+		}
+		allowed, err := c.check(relation.Object{Type: t.SubjectType, ID: t.SubjectID}, t.SubjectRelation, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, included := range rewrite.Includes {
+		allowed, err := c.check(object, included, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, from := range rewrite.From {
+		related, err := c.repo.GetSubjects(object.Type, object.ID, from.Tupleset)
+		if err != nil {
+			return false, err
+		}
+		for _, t := range related {
+			if t.SubjectRelation != "" {
+				continue
+			}
+			allowed, err := c.check(relation.Object{Type: t.SubjectType, ID: t.SubjectID}, from.Relation, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// toRelationTuple 将元组模型转换为关系元组
+func toRelationTuple(t *model.RelationTuple) relation.Tuple {
+	return relation.Tuple{
+		Object:   relation.Object{Type: t.ObjectType, ID: t.ObjectID},
+		Relation: t.Relation,
+		Subject:  relation.Subject{Type: t.SubjectType, ID: t.SubjectID, Relation: t.SubjectRelation},
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+)
+
+// RoleExpirySweeper 定期物理删除已过期的角色分配
+// 过期分配在权限计算时已被忽略，清除只是为了让角色列表与数据保持整洁，
+// 并为每条被清除的分配输出日志，便于审计临时授权的结束。
+type RoleExpirySweeper struct {
+	userRepo        repository.UserRepository
+	permissionCache *PermissionCache
+	interval        time.Duration
+	now             func() time.Time
+}
+
+// NewRoleExpirySweeper 创建过期角色分配清理任务，permissionCache 可为nil
+func NewRoleExpirySweeper(userRepo repository.UserRepository, permissionCache *PermissionCache, interval time.Duration) *RoleExpirySweeper {
+	return &RoleExpirySweeper{
+		userRepo:        userRepo,
+		permissionCache: permissionCache,
+		interval:        interval,
+		now:             time.Now,
+	}
+}
+
+// Start 在后台按间隔执行清理，直到 ctx 结束
+func (s *RoleExpirySweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		slog.Info("过期角色分配清理任务已启动", "interval", s.interval)
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("过期角色分配清理任务已停止")
+				return
+			case <-ticker.C:
+				if _, err := s.Sweep(); err != nil {
+					slog.Error("清理过期角色分配失败", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Sweep 执行一次清理，返回被删除的角色分配
+func (s *RoleExpirySweeper) Sweep() ([]model.UserRole, error) {
+	expired, err := s.userRepo.DeleteExpiredRoles(s.now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, 0, len(expired))
+	for _, assignment := range expired {
+		slog.Info("角色分配已过期并被清除",
+			"userID", assignment.UserID,
+			"roleID", assignment.RoleID,
+			"tenantID", assignment.TenantID,
+			"validUntil", *assignment.ValidUntil,
+		)
+		userIDs = append(userIDs, assignment.UserID)
+	}
+
+	// 缓存条目已在失效时刻过期，这里再次失效以防时钟偏差
+	if len(userIDs) > 0 {
+		s.permissionCache.InvalidateUsers(userIDs...)
+	}
+	return expired, nil
+}
@@ -1,11 +1,13 @@
 package service
 
 import (
-	"log/slog"
 	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/condition"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/repository"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"log/slog"
+	"strings"
 )
 
 // RoleService 角色服务接口
@@ -15,54 +17,50 @@ type RoleService interface {
 	Delete(id uint64) error
 	GetByID(id uint64) (*schema.RoleResponse, error)
 	List(req *schema.ListRoleRequest) (*schema.ListRoleResponse, error)
-	AssignPermission(roleID uint64, permissionIDs []uint64) error
+	AssignPermission(roleID uint64, grants []schema.PermissionGrant) error
 	GetPermissions(roleID uint64) ([]schema.PermissionResponse, error)
+	GetAncestors(roleID uint64) ([]schema.RoleSimple, error)
+	GetDescendants(roleID uint64) ([]schema.RoleSimple, error)
+	GetEffectivePermissions(roleID uint64) (*schema.RoleEffectivePermissionsResponse, error)
+	Clone(req *schema.CloneRoleRequest) (uint64, error)
+	CreateWithGrants(role *model.Role, parentIDs []uint64, grants []model.RolePermission) (uint64, error)
 }
 
 // roleService 角色服务实现
 type roleService struct {
-	roleRepo       repository.RoleRepository
-	permissionRepo repository.PermissionRepository
+	roleRepo        repository.RoleRepository
+	permissionRepo  repository.PermissionRepository
+	tenantRepo      repository.TenantRepository
+	permissionCache *PermissionCache
 }
 
 // NewRoleService 创建角色服务实例
 func NewRoleService(
 	roleRepo repository.RoleRepository,
 	permissionRepo repository.PermissionRepository,
+	tenantRepo repository.TenantRepository,
+	permissionCache *PermissionCache, // 可为nil，角色授权或继承关系变更时用于失效用户权限缓存
 ) RoleService {
 	return &roleService{
-		roleRepo:       roleRepo,
-		permissionRepo: permissionRepo,
+		roleRepo:        roleRepo,
+		permissionRepo:  permissionRepo,
+		tenantRepo:      tenantRepo,
+		permissionCache: permissionCache,
 	}
 }
 
 // Create 创建角色
 func (s *roleService) Create(req *schema.CreateRoleRequest) (uint64, error) {
-	// 检查角色名是否已存在
-	existingRole, err := s.roleRepo.GetByName(req.Name)
-	if err != nil {
+	if err := s.validateCreate(req.Name, req.Code, req.TenantID, req.ParentIDs); err != nil {
 		return 0, err
 	}
 
-	if existingRole != nil {
-		return 0, errors.ErrRoleExists
-	}
-
-	// 检查角色编码是否已存在
-	existingRoleByCode, err := s.roleRepo.GetByCode(req.Code)
-	if err != nil {
-		return 0, err
-	}
-
-	if existingRoleByCode != nil {
-		return 0, errors.ErrRoleExists
-	}
-
 	// 创建角色
 	role := &model.Role{
 		Name:        req.Name,
 		Code:        req.Code,
 		Description: req.Description,
+		TenantID:    req.TenantID,
 	}
 
 	if err := s.roleRepo.Create(role); err != nil {
@@ -77,9 +75,67 @@ func (s *roleService) Create(req *schema.CreateRoleRequest) (uint64, error) {
 		}
 	}
 
+	// 如果有指定父角色，建立继承关系
+	if len(req.ParentIDs) > 0 {
+		if err := s.roleRepo.SetParents(role.ID, req.ParentIDs); err != nil {
+			return 0, err
+		}
+	}
+
 	return role.ID, nil
 }
 
+// CreateWithGrants 校验后在一个事务内创建角色及其父角色与权限授予记录，供复制角色与按模板创建角色使用
+func (s *roleService) CreateWithGrants(role *model.Role, parentIDs []uint64, grants []model.RolePermission) (uint64, error) {
+	if err := s.validateCreate(role.Name, role.Code, role.TenantID, parentIDs); err != nil {
+		return 0, err
+	}
+
+	if err := s.roleRepo.CreateWithGrants(role, parentIDs, grants); err != nil {
+		return 0, err
+	}
+
+	return role.ID, nil
+}
+
+// validateCreate 校验新角色的名称与编码在所属租户内未被使用、租户存在且父角色有效
+func (s *roleService) validateCreate(name, code string, tenantID uint64, parentIDs []uint64) error {
+	// 检查角色名在所属租户内是否已存在
+	existingRole, err := s.roleRepo.GetByName(name, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if existingRole != nil {
+		return errors.ErrRoleExists
+	}
+
+	// 检查角色编码在所属租户内是否已存在
+	existingRoleByCode, err := s.roleRepo.GetByCode(code, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if existingRoleByCode != nil {
+		return errors.ErrRoleExists
+	}
+
+	// 检查所属租户是否存在
+	if tenantID != model.GlobalTenantID {
+		tenant, err := s.tenantRepo.GetByID(tenantID)
+		if err != nil {
+			return err
+		}
+
+		if tenant == nil {
+			return errors.ErrTenantNotFound
+		}
+	}
+
+	// 校验父角色
+	return s.validateParents(0, tenantID, parentIDs)
+}
+
 // Update 更新角色
 func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 	// 检查角色是否存在
@@ -92,9 +148,9 @@ func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 		return errors.ErrRoleNotFound
 	}
 
-	// 检查角色名是否已被其他角色使用
+	// 检查角色名是否已被同租户的其他角色使用
 	if req.Name != existingRole.Name {
-		role, err := s.roleRepo.GetByName(req.Name)
+		role, err := s.roleRepo.GetByName(req.Name, existingRole.TenantID)
 		if err != nil {
 			return err
 		}
@@ -104,9 +160,9 @@ func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 		}
 	}
 
-	// 检查角色标识是否已被其他角色使用
+	// 检查角色标识是否已被同租户的其他角色使用
 	if req.Code != existingRole.Code {
-		role, err := s.roleRepo.GetByCode(req.Code)
+		role, err := s.roleRepo.GetByCode(req.Code, existingRole.TenantID)
 		if err != nil {
 			return err
 		}
@@ -116,6 +172,13 @@ func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 		}
 	}
 
+	// 校验父角色，避免形成循环继承
+	if req.ParentIDs != nil {
+		if err := s.validateParents(req.ID, existingRole.TenantID, req.ParentIDs); err != nil {
+			return err
+		}
+	}
+
 	// 更新角色信息
 	updatedRole := &model.Role{
 		ID:          req.ID,
@@ -128,6 +191,10 @@ func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 		return err
 	}
 
+	// 角色编码与名称会出现在权限判定结果中，权限与继承关系变更同样影响有效权限，
+	// 在全部写入完成后再失效，避免期间加载的旧数据被缓存
+	defer s.permissionCache.InvalidateRoles(req.ID)
+
 	// 如果提供了权限ID，更新角色权限
 	if req.PermissionIDs != nil {
 		if err := s.roleRepo.UpdatePermissions(req.ID, req.PermissionIDs); err != nil {
@@ -136,6 +203,13 @@ func (s *roleService) Update(req *schema.UpdateRoleRequest) error {
 		}
 	}
 
+	// 如果提供了父角色ID，更新继承关系
+	if req.ParentIDs != nil {
+		if err := s.roleRepo.SetParents(req.ID, req.ParentIDs); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -162,7 +236,12 @@ func (s *roleService) Delete(id uint64) error {
 	}
 
 	// 删除角色
-	return s.roleRepo.Delete(id)
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateRoles(id)
+	return nil
 }
 
 // GetByID 根据ID获取角色
@@ -177,12 +256,26 @@ func (s *roleService) GetByID(id uint64) (*schema.RoleResponse, error) {
 		return nil, errors.ErrRoleNotFound
 	}
 
-	return s.convertToRoleResponse(role), nil
+	response := s.convertToRoleResponse(role)
+
+	// 自定义数据范围时返回可访问的组织单元
+	if role.DataScope == model.DataScopeCustom {
+		units, err := s.roleRepo.GetDataScopeUnits(id)
+		if err != nil {
+			return nil, err
+		}
+		response.DataScopeUnits = make([]schema.OrgUnitSimple, 0, len(units))
+		for _, unit := range units {
+			response.DataScopeUnits = append(response.DataScopeUnits, schema.OrgUnitSimple{ID: unit.ID, Code: unit.Code, Name: unit.Name})
+		}
+	}
+
+	return response, nil
 }
 
 // List 获取角色列表，返回完整分页信息
 func (s *roleService) List(req *schema.ListRoleRequest) (*schema.ListRoleResponse, error) {
-	roles, total, err := s.roleRepo.List(req.Page, req.PageSize, req.Keyword)
+	roles, total, err := s.roleRepo.List(req.Page, req.PageSize, req.Keyword, req.TenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -205,8 +298,8 @@ func (s *roleService) List(req *schema.ListRoleRequest) (*schema.ListRoleRespons
 	}, nil
 }
 
-// AssignPermission 分配权限给角色
-func (s *roleService) AssignPermission(roleID uint64, permissionIDs []uint64) error {
+// AssignPermission 分配权限给角色，覆盖角色原有的允许与拒绝规则
+func (s *roleService) AssignPermission(roleID uint64, grants []schema.PermissionGrant) error {
 	// 检查角色是否存在
 	role, err := s.roleRepo.GetByID(roleID)
 	if err != nil {
@@ -217,22 +310,135 @@ func (s *roleService) AssignPermission(roleID uint64, permissionIDs []uint64) er
 		return errors.ErrRoleNotFound
 	}
 
-	// 检查所有权限是否存在
-	for _, permID := range permissionIDs {
+	// 合并授予项并检查权限是否存在
+	rolePermissions, err := s.resolveGrants(roleID, grants)
+	if err != nil {
+		return err
+	}
+
+	// 更新角色权限
+	if err := s.roleRepo.SetPermissionGrants(roleID, rolePermissions); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateRoles(roleID)
+	return nil
+}
+
+// resolveGrants 合并授予项、编译条件表达式并检查权限是否存在，同一权限同时允许和拒绝时以拒绝为准
+func (s *roleService) resolveGrants(roleID uint64, grants []schema.PermissionGrant) ([]model.RolePermission, error) {
+	merged := make(map[uint64]schema.PermissionGrant, len(grants))
+	order := make([]uint64, 0, len(grants))
+	for _, grant := range grants {
+		if grant.Effect == "" {
+			grant.Effect = model.EffectAllow
+		}
+		if grant.Effect != model.EffectAllow && grant.Effect != model.EffectDeny {
+			return nil, errors.ErrInvalidEffect
+		}
+		grant.Condition = strings.TrimSpace(grant.Condition)
+		if grant.Condition != "" {
+			if err := condition.Validate(grant.Condition); err != nil {
+				slog.Warn("条件表达式无效", "permissionID", grant.PermissionID, "condition", grant.Condition, "error", err)
+				return nil, errors.ErrInvalidCondition
+			}
+		}
+
+		current, ok := merged[grant.PermissionID]
+		if !ok {
+			order = append(order, grant.PermissionID)
+		}
+		if current.Effect != model.EffectDeny {
+			merged[grant.PermissionID] = grant
+		}
+	}
+
+	rolePermissions := make([]model.RolePermission, 0, len(order))
+	for _, permID := range order {
 		perm, err := s.permissionRepo.GetByID(permID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if perm == nil {
-			return errors.ErrPermissionNotFound
+			return nil, errors.ErrPermissionNotFound
 		}
+		rolePermissions = append(rolePermissions, model.RolePermission{
+			RoleID:       roleID,
+			PermissionID: permID,
+			Effect:       merged[permID].Effect,
+			Condition:    merged[permID].Condition,
+		})
 	}
 
-	// 更新角色权限
-	return s.roleRepo.UpdatePermissions(roleID, permissionIDs)
+	return rolePermissions, nil
 }
 
-// GetPermissions 获取角色的权限列表
+// Clone 复制已有角色的权限授予创建新角色，覆盖项可新增权限或替换授予效果
+func (s *roleService) Clone(req *schema.CloneRoleRequest) (uint64, error) {
+	source, err := s.roleRepo.GetByID(req.SourceID)
+	if err != nil {
+		return 0, err
+	}
+
+	if source == nil {
+		return 0, errors.ErrRoleNotFound
+	}
+
+	// 覆盖项在创建角色前校验，避免留下权限不完整的角色
+	overrides, err := s.resolveGrants(0, req.Grants)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := make(map[uint64]struct{}, len(req.RemovePermissionIDs))
+	for _, permID := range req.RemovePermissionIDs {
+		removed[permID] = struct{}{}
+	}
+
+	// 复制源角色的授予记录，覆盖项替换同一权限原有的效果
+	grants := make([]model.RolePermission, 0, len(source.PermissionGrants)+len(overrides))
+	index := make(map[uint64]int, len(source.PermissionGrants))
+	for _, grant := range source.PermissionGrants {
+		if _, ok := removed[grant.PermissionID]; ok {
+			continue
+		}
+		index[grant.PermissionID] = len(grants)
+		grants = append(grants, model.RolePermission{PermissionID: grant.PermissionID, Effect: grant.Effect, Condition: grant.Condition})
+	}
+	for _, grant := range overrides {
+		if idx, ok := index[grant.PermissionID]; ok {
+			grants[idx].Effect = grant.Effect
+			grants[idx].Condition = grant.Condition
+			continue
+		}
+		index[grant.PermissionID] = len(grants)
+		grants = append(grants, grant)
+	}
+
+	role := &model.Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		TenantID:    source.TenantID,
+	}
+	if role.Description == "" {
+		role.Description = source.Description
+	}
+	if req.TenantID != nil {
+		role.TenantID = *req.TenantID
+	}
+	var parentIDs []uint64
+	if req.CopyParents {
+		for _, parent := range source.Parents {
+			parentIDs = append(parentIDs, parent.ID)
+		}
+	}
+
+	// 角色、继承关系与授予记录在同一事务内写入，失败时不留下权限不完整的角色
+	return s.CreateWithGrants(role, parentIDs, grants)
+}
+
+// GetPermissions 获取角色的权限列表（含授予效果）
 func (s *roleService) GetPermissions(roleID uint64) ([]schema.PermissionResponse, error) {
 	// 检查角色是否存在
 	role, err := s.roleRepo.GetByID(roleID)
@@ -251,6 +457,7 @@ func (s *roleService) GetPermissions(roleID uint64) ([]schema.PermissionResponse
 	}
 
 	// 转换为响应格式
+	grants := permissionGrants(role)
 	permissions := make([]schema.PermissionResponse, 0, len(role.Permissions))
 	for _, perm := range role.Permissions {
 		permissions = append(permissions, schema.PermissionResponse{
@@ -259,29 +466,219 @@ func (s *roleService) GetPermissions(roleID uint64) ([]schema.PermissionResponse
 			Name:        perm.Name,
 			Description: perm.Description,
 			CreatedAt:   perm.CreatedAt,
+			Effect:      grants[perm.ID].Effect,
+			Condition:   grants[perm.ID].Condition,
 		})
 	}
 
 	return permissions, nil
 }
 
+// GetAncestors 获取角色的全部祖先角色
+func (s *roleService) GetAncestors(roleID uint64) ([]schema.RoleSimple, error) {
+	if err := s.ensureRoleExists(roleID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetAncestors(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToRoleSimples(roles), nil
+}
+
+// GetDescendants 获取继承自该角色的全部后代角色
+func (s *roleService) GetDescendants(roleID uint64) ([]schema.RoleSimple, error) {
+	if err := s.ensureRoleExists(roleID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetDescendants(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToRoleSimples(roles), nil
+}
+
+// GetEffectivePermissions 获取角色的有效权限，区分直接授予与从祖先角色继承的权限
+func (s *roleService) GetEffectivePermissions(roleID uint64) (*schema.RoleEffectivePermissionsResponse, error) {
+	// 获取角色及其直接权限
+	role, err := s.roleRepo.GetRoleWithPermissions(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, errors.ErrRoleNotFound
+	}
+
+	// 获取祖先角色及其权限
+	ancestors, err := s.roleRepo.GetAncestors(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &schema.RoleEffectivePermissionsResponse{
+		Direct:    make([]schema.PermissionSimple, 0, len(role.Permissions)),
+		Inherited: make([]schema.InheritedPermission, 0),
+	}
+
+	direct := make(map[uint64]struct{}, len(role.Permissions))
+	directGrants := permissionGrants(role)
+	for _, perm := range role.Permissions {
+		direct[perm.ID] = struct{}{}
+		response.Direct = append(response.Direct, convertToPermissionSimple(perm, directGrants))
+	}
+
+	// 直接授予的权限不再重复列为继承权限，同一权限同一效果与条件合并来源角色
+	type inheritedKey struct {
+		permissionID uint64
+		effect       string
+		condition    string
+	}
+	inherited := make(map[inheritedKey]int)
+	for _, ancestor := range ancestors {
+		source := schema.RoleSimple{ID: ancestor.ID, Code: ancestor.Code, Name: ancestor.Name}
+		ancestorGrants := permissionGrants(ancestor)
+		for _, perm := range ancestor.Permissions {
+			if _, ok := direct[perm.ID]; ok {
+				continue
+			}
+			grant := ancestorGrants[perm.ID]
+			key := inheritedKey{permissionID: perm.ID, effect: grant.Effect, condition: grant.Condition}
+			if idx, ok := inherited[key]; ok {
+				response.Inherited[idx].FromRoles = append(response.Inherited[idx].FromRoles, source)
+				continue
+			}
+			inherited[key] = len(response.Inherited)
+			response.Inherited = append(response.Inherited, schema.InheritedPermission{
+				PermissionSimple: convertToPermissionSimple(perm, ancestorGrants),
+				FromRoles:        []schema.RoleSimple{source},
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// validateParents 校验父角色存在且不会形成循环继承，roleID为0表示新建角色
+// 父角色必须是全局角色或与当前角色同属一个租户，避免全局角色继承某个租户的权限
+func (s *roleService) validateParents(roleID, tenantID uint64, parentIDs []uint64) error {
+	for _, parentID := range parentIDs {
+		if roleID != 0 && parentID == roleID {
+			return errors.ErrRoleCycle
+		}
+
+		parent, err := s.roleRepo.GetByID(parentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return errors.ErrRoleNotFound
+		}
+		if parent.TenantID != model.GlobalTenantID && parent.TenantID != tenantID {
+			return errors.ErrTenantMismatch
+		}
+	}
+
+	// 新建角色没有后代，不可能形成循环
+	if roleID == 0 || len(parentIDs) == 0 {
+		return nil
+	}
+
+	// 父角色不能是当前角色的后代
+	descendants, err := s.roleRepo.GetDescendants(roleID)
+	if err != nil {
+		return err
+	}
+
+	for _, descendant := range descendants {
+		for _, parentID := range parentIDs {
+			if descendant.ID == parentID {
+				return errors.ErrRoleCycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensureRoleExists 检查角色是否存在
+func (s *roleService) ensureRoleExists(roleID uint64) error {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return err
+	}
+
+	if role == nil {
+		return errors.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// convertToRoleSimples 将角色模型列表转换为简化的角色信息
+func convertToRoleSimples(roles []*model.Role) []schema.RoleSimple {
+	items := make([]schema.RoleSimple, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, schema.RoleSimple{
+			ID:   role.ID,
+			Code: role.Code,
+			Name: role.Name,
+		})
+	}
+	return items
+}
+
+// permissionGrants 获取角色上每个权限的授予记录，未加载授予记录时为空
+func permissionGrants(role *model.Role) map[uint64]model.RolePermission {
+	grants := make(map[uint64]model.RolePermission, len(role.PermissionGrants))
+	for _, grant := range role.PermissionGrants {
+		grants[grant.PermissionID] = grant
+	}
+	return grants
+}
+
+// convertToPermissionSimple 将权限模型转换为带授予效果与条件的简化权限信息
+func convertToPermissionSimple(perm model.Permission, grants map[uint64]model.RolePermission) schema.PermissionSimple {
+	return schema.PermissionSimple{
+		ID:        perm.ID,
+		Code:      perm.Code,
+		Name:      perm.Name,
+		Effect:    grants[perm.ID].Effect,
+		Condition: grants[perm.ID].Condition,
+	}
+}
+
 // convertToRoleResponse 将角色模型转换为响应结构
 func (s *roleService) convertToRoleResponse(role *model.Role) *schema.RoleResponse {
 	response := &schema.RoleResponse{
-		ID:          role.ID,
-		Code:        role.Code,
-		Name:        role.Name,
-		Description: role.Description,
-		CreatedAt:   role.CreatedAt,
-		Permissions: make([]schema.PermissionSimple, 0, len(role.Permissions)),
+		ID:              role.ID,
+		Code:            role.Code,
+		Name:            role.Name,
+		Description:     role.Description,
+		TenantID:        role.TenantID,
+		TemplateCode:    role.TemplateCode,
+		TemplateVersion: role.TemplateVersion,
+		DataScope:       role.DataScope,
+		CreatedAt:       role.CreatedAt,
+		Permissions:     make([]schema.PermissionSimple, 0, len(role.Permissions)),
 	}
 
 	// 添加权限信息
+	grants := permissionGrants(role)
 	for _, perm := range role.Permissions {
-		response.Permissions = append(response.Permissions, schema.PermissionSimple{
-			ID:   perm.ID,
-			Code: perm.Code,
-			Name: perm.Name,
+		response.Permissions = append(response.Permissions, convertToPermissionSimple(perm, grants))
+	}
+
+	// 添加父角色信息
+	for _, parent := range role.Parents {
+		response.Parents = append(response.Parents, schema.RoleSimple{
+			ID:   parent.ID,
+			Code: parent.Code,
+			Name: parent.Name,
 		})
 	}
 
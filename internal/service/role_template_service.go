@@ -0,0 +1,255 @@
+package service
+
+import (
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// RoleTemplateService 角色模板服务接口
+type RoleTemplateService interface {
+	List() []schema.RoleTemplateResponse
+	Instantiate(req *schema.InstantiateRoleTemplateRequest) (uint64, error)
+	Propagate(req *schema.PropagateRoleTemplateRequest) (*schema.RoleTemplatePropagationReport, error)
+}
+
+// roleTemplateService 角色模板服务实现，模板定义来自配置文件
+type roleTemplateService struct {
+	templates       []config.RoleTemplateConfig
+	roleService     RoleService
+	roleRepo        repository.RoleRepository
+	permissionRepo  repository.PermissionRepository
+	permissionCache *PermissionCache
+}
+
+// NewRoleTemplateService 创建角色模板服务实例
+func NewRoleTemplateService(
+	templates []config.RoleTemplateConfig,
+	roleService RoleService,
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+	permissionCache *PermissionCache, // 可为nil，模板同步修改角色授权时用于失效用户权限缓存
+) RoleTemplateService {
+	return &roleTemplateService{
+		templates:       templates,
+		roleService:     roleService,
+		roleRepo:        roleRepo,
+		permissionRepo:  permissionRepo,
+		permissionCache: permissionCache,
+	}
+}
+
+// List 获取全部角色模板
+func (s *roleTemplateService) List() []schema.RoleTemplateResponse {
+	items := make([]schema.RoleTemplateResponse, 0, len(s.templates))
+	for _, tpl := range s.templates {
+		items = append(items, schema.RoleTemplateResponse{
+			Code:        tpl.Code,
+			Name:        tpl.Name,
+			Description: tpl.Description,
+			Version:     tpl.Version,
+			Permissions: append([]string{}, tpl.Permissions...),
+			Deny:        append([]string{}, tpl.Deny...),
+		})
+	}
+	return items
+}
+
+// Instantiate 根据模板创建角色，角色记录模板编码与版本以便后续同步
+func (s *roleTemplateService) Instantiate(req *schema.InstantiateRoleTemplateRequest) (uint64, error) {
+	tpl := s.find(req.TemplateCode)
+	if tpl == nil {
+		return 0, errors.ErrRoleTemplateNotFound
+	}
+
+	// 先解析模板权限，模板引用了不存在的权限时不创建角色
+	grants, err := s.resolve(tpl)
+	if err != nil {
+		return 0, err
+	}
+
+	role := &model.Role{
+		Code:            req.Code,
+		Name:            req.Name,
+		Description:     req.Description,
+		TenantID:        req.TenantID,
+		TemplateCode:    tpl.Code,
+		TemplateVersion: tpl.Version,
+	}
+	if role.Code == "" {
+		role.Code = tpl.Code
+	}
+	if role.Name == "" {
+		role.Name = tpl.Name
+	}
+	if role.Description == "" {
+		role.Description = tpl.Description
+	}
+
+	// 角色、模板版本与授予记录在同一事务内写入
+	return s.roleService.CreateWithGrants(role, nil, templateGrants(0, grants))
+}
+
+// Propagate 将模板当前的权限同步到由其创建的全部角色，返回每个角色的变更
+// 同步后角色的授予记录与模板完全一致，手动添加到这些角色上的授予会被移除
+func (s *roleTemplateService) Propagate(req *schema.PropagateRoleTemplateRequest) (*schema.RoleTemplatePropagationReport, error) {
+	tpl := s.find(req.TemplateCode)
+	if tpl == nil {
+		return nil, errors.ErrRoleTemplateNotFound
+	}
+
+	grants, err := s.resolve(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.ListByTemplate(tpl.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &schema.RoleTemplatePropagationReport{
+		TemplateCode: tpl.Code,
+		Version:      tpl.Version,
+		DryRun:       req.DryRun,
+		Roles:        make([]schema.RoleTemplateRoleChange, 0),
+		Unchanged:    make([]schema.RoleSimple, 0),
+	}
+
+	syncs := make([]*model.RoleTemplateSync, 0, len(roles))
+	changed := make([]uint64, 0, len(roles))
+	for _, role := range roles {
+		change := diffTemplateGrants(role, grants)
+		sync := &model.RoleTemplateSync{RoleID: role.ID, Version: tpl.Version}
+		if len(change.Added) == 0 && len(change.Removed) == 0 && len(change.Changed) == 0 {
+			report.Unchanged = append(report.Unchanged, change.Role)
+		} else {
+			report.Roles = append(report.Roles, change)
+			sync.Grants = templateGrants(role.ID, grants)
+			changed = append(changed, role.ID)
+		}
+
+		if sync.Grants != nil || role.TemplateVersion != tpl.Version {
+			syncs = append(syncs, sync)
+		}
+	}
+
+	if req.DryRun || len(syncs) == 0 {
+		return report, nil
+	}
+
+	// 全部角色在同一事务内同步，任一角色失败时不会只同步一部分
+	if err := s.roleRepo.SyncTemplate(syncs); err != nil {
+		return nil, err
+	}
+	s.permissionCache.InvalidateRoles(changed...)
+
+	return report, nil
+}
+
+// find 根据编码查找模板
+func (s *roleTemplateService) find(code string) *config.RoleTemplateConfig {
+	for i := range s.templates {
+		if s.templates[i].Code == code {
+			return &s.templates[i]
+		}
+	}
+	return nil
+}
+
+// resolve 将模板引用的权限编码解析为权限，同一权限同时允许和拒绝时以拒绝为准
+func (s *roleTemplateService) resolve(tpl *config.RoleTemplateConfig) ([]templateGrant, error) {
+	grants := make([]templateGrant, 0, len(tpl.Permissions)+len(tpl.Deny))
+	index := make(map[uint64]int, cap(grants))
+
+	add := func(codes []string, effect string) error {
+		for _, code := range codes {
+			perm, err := s.permissionRepo.GetByCode(code)
+			if err != nil {
+				return err
+			}
+			if perm == nil {
+				return errors.ErrPermissionNotFound
+			}
+			if idx, ok := index[perm.ID]; ok {
+				if effect == model.EffectDeny {
+					grants[idx].effect = effect
+				}
+				continue
+			}
+			index[perm.ID] = len(grants)
+			grants = append(grants, templateGrant{permission: perm, effect: effect})
+		}
+		return nil
+	}
+
+	if err := add(tpl.Permissions, model.EffectAllow); err != nil {
+		return nil, err
+	}
+	if err := add(tpl.Deny, model.EffectDeny); err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// templateGrant 模板解析后的一条权限授予
+type templateGrant struct {
+	permission *model.Permission
+	effect     string
+}
+
+// templateGrants 将模板授予转换为角色的授予记录
+func templateGrants(roleID uint64, grants []templateGrant) []model.RolePermission {
+	items := make([]model.RolePermission, 0, len(grants))
+	for _, grant := range grants {
+		items = append(items, model.RolePermission{
+			RoleID:       roleID,
+			PermissionID: grant.permission.ID,
+			Effect:       grant.effect,
+		})
+	}
+	return items
+}
+
+// diffTemplateGrants 比较角色当前的授予记录与模板，得到同步需要的变更
+func diffTemplateGrants(role *model.Role, grants []templateGrant) schema.RoleTemplateRoleChange {
+	change := schema.RoleTemplateRoleChange{
+		Role:        schema.RoleSimple{ID: role.ID, Code: role.Code, Name: role.Name},
+		FromVersion: role.TemplateVersion,
+		Added:       make([]schema.PermissionSimple, 0),
+		Removed:     make([]schema.PermissionSimple, 0),
+		Changed:     make([]schema.PermissionSimple, 0),
+	}
+
+	current := permissionGrants(role)
+	wanted := make(map[uint64]struct{}, len(grants))
+	for _, grant := range grants {
+		wanted[grant.permission.ID] = struct{}{}
+		item := schema.PermissionSimple{
+			ID:     grant.permission.ID,
+			Code:   grant.permission.Code,
+			Name:   grant.permission.Name,
+			Effect: grant.effect,
+		}
+
+		// 模板中的授予没有条件，同步会去掉角色上附加的条件
+		existing, ok := current[grant.permission.ID]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, item)
+		case existing.Effect != grant.effect || existing.Condition != "":
+			change.Changed = append(change.Changed, item)
+		}
+	}
+
+	for _, perm := range role.Permissions {
+		if _, ok := wanted[perm.ID]; !ok {
+			change.Removed = append(change.Removed, convertToPermissionSimple(perm, current))
+		}
+	}
+
+	return change
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
+)
+
+// RouteTable 进程内的接口路由表，缓存编译后的接口权限路由模式，
+// 按方法与路径校验权限时无需每个请求都查询数据库。
+// 权限新增、更新、删除或同步接口路由后需调用 Invalidate，下一次匹配时重新加载；
+// ttl 大于0时加载结果超过 ttl 也会重新加载，用于其他进程修改接口权限的场景。
+// nil 路由表的 Invalidate 为空操作
+type RouteTable struct {
+	source RouteSource
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	routes   []compiledRoute
+	loaded   bool
+	loadedAt time.Time
+	// version 每次失效时递增，用于丢弃失效前开始加载的结果
+	version uint64
+}
+
+// compiledRoute 一条编译后的接口权限路由
+type compiledRoute struct {
+	matcher apiroute.Matcher
+	code    string
+}
+
+// NewRouteTable 创建接口路由表，ttl 小于等于0时只在 Invalidate 后重新加载
+func NewRouteTable(source RouteSource, ttl time.Duration) *RouteTable {
+	return &RouteTable{
+		source: source,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Match 获取方法与路径模式匹配指定请求的接口权限编码
+func (t *RouteTable) Match(method, path string) ([]string, error) {
+	routes, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	for _, route := range routes {
+		if route.matcher.Match(method, path) {
+			codes = append(codes, route.code)
+		}
+	}
+	return codes, nil
+}
+
+// Invalidate 使路由表失效，下一次匹配时从数据来源重新加载
+func (t *RouteTable) Invalidate() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.version++
+	t.loaded = false
+	t.routes = nil
+}
+
+// load 返回已编译的路由，未加载、已失效或超过 ttl 时重新加载
+func (t *RouteTable) load() ([]compiledRoute, error) {
+	t.mu.RLock()
+	if t.loaded && (t.ttl <= 0 || t.now().Sub(t.loadedAt) < t.ttl) {
+		routes := t.routes
+		t.mu.RUnlock()
+		return routes, nil
+	}
+	version := t.version
+	t.mu.RUnlock()
+
+	permissions, err := t.source.ListRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]compiledRoute, 0, len(permissions))
+	for _, permission := range permissions {
+		route := apiroute.Route{Method: permission.Method, Path: permission.Route}
+		routes = append(routes, compiledRoute{matcher: route.Compile(), code: permission.Code})
+	}
+
+	// 加载期间路由表已失效时不保存结果，本次匹配仍使用加载到的路由
+	t.mu.Lock()
+	if version == t.version {
+		t.routes = routes
+		t.loaded = true
+		t.loadedAt = t.now()
+	}
+	t.mu.Unlock()
+
+	return routes, nil
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// SoDService 职责分离规则服务接口
+type SoDService interface {
+	Create(req *schema.CreateSoDRuleRequest) (uint64, error)
+	Update(req *schema.UpdateSoDRuleRequest) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*schema.SoDRuleResponse, error)
+	List(req *schema.ListSoDRuleRequest) (*schema.ListSoDRuleResponse, error)
+	Violations(req *schema.SoDViolationsRequest) (*schema.SoDViolationReport, error)
+}
+
+// sodService 职责分离规则服务实现
+type sodService struct {
+	sodRepo  repository.SoDRepository
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+	checker  *sodChecker
+}
+
+// NewSoDService 创建职责分离规则服务实例
+func NewSoDService(sodRepo repository.SoDRepository, roleRepo repository.RoleRepository, userRepo repository.UserRepository) SoDService {
+	return &sodService{
+		sodRepo:  sodRepo,
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		checker:  newSoDChecker(sodRepo, roleRepo),
+	}
+}
+
+// Create 创建职责分离规则，已违反新规则的用户不受影响，可通过 Violations 查询
+func (s *sodService) Create(req *schema.CreateSoDRuleRequest) (uint64, error) {
+	existing, err := s.sodRepo.GetByName(req.Name)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, errors.ErrSoDRuleExists
+	}
+
+	rule := &model.SoDRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		MaxRoles:    req.MaxRoles,
+	}
+	roleIDs, err := s.validate(rule, req.RoleIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.sodRepo.Create(rule, roleIDs); err != nil {
+		return 0, err
+	}
+
+	return rule.ID, nil
+}
+
+// Update 更新职责分离规则
+func (s *sodService) Update(req *schema.UpdateSoDRuleRequest) error {
+	existing, err := s.sodRepo.GetByID(req.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.ErrSoDRuleNotFound
+	}
+
+	// 检查名称是否已被其他规则使用
+	if req.Name != existing.Name {
+		rule, err := s.sodRepo.GetByName(req.Name)
+		if err != nil {
+			return err
+		}
+		if rule != nil && rule.ID != req.ID {
+			return errors.ErrSoDRuleExists
+		}
+	}
+
+	rule := &model.SoDRule{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		MaxRoles:    req.MaxRoles,
+	}
+	roleIDs, err := s.validate(rule, req.RoleIDs)
+	if err != nil {
+		return err
+	}
+
+	return s.sodRepo.Update(rule, roleIDs)
+}
+
+// validate 校验规则的角色与上限，返回去重后的角色ID
+func (s *sodService) validate(rule *model.SoDRule, roleIDs []uint64) ([]uint64, error) {
+	unique := make([]uint64, 0, len(roleIDs))
+	seen := make(map[uint64]struct{}, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if _, ok := seen[roleID]; ok {
+			continue
+		}
+		seen[roleID] = struct{}{}
+
+		role, err := s.roleRepo.GetByID(roleID)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, errors.ErrRoleNotFound
+		}
+		unique = append(unique, roleID)
+	}
+
+	if rule.MaxRoles <= 0 {
+		rule.MaxRoles = 1
+	}
+	// 上限不小于角色数时规则永远不会被违反
+	if rule.MaxRoles >= len(unique) {
+		return nil, errors.ErrInvalidSoDRule
+	}
+
+	return unique, nil
+}
+
+// Delete 删除职责分离规则
+func (s *sodService) Delete(id uint64) error {
+	rule, err := s.sodRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return errors.ErrSoDRuleNotFound
+	}
+
+	return s.sodRepo.Delete(id)
+}
+
+// GetByID 获取职责分离规则详情
+func (s *sodService) GetByID(id uint64) (*schema.SoDRuleResponse, error) {
+	rule, err := s.sodRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, errors.ErrSoDRuleNotFound
+	}
+
+	return convertToSoDRuleResponse(rule), nil
+}
+
+// List 获取职责分离规则列表，返回完整分页信息
+func (s *sodService) List(req *schema.ListSoDRuleRequest) (*schema.ListSoDRuleResponse, error) {
+	rules, total, err := s.sodRepo.List(req.Page, req.PageSize, req.Keyword, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.SoDRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, *convertToSoDRuleResponse(rule))
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ListSoDRuleResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// Violations 列出当前违反职责分离规则的用户，用于新增规则后清理存量分配
+// 动态规则同样报告同时持有互斥角色的用户，这些用户登录时需选择要激活的角色
+func (s *sodService) Violations(req *schema.SoDViolationsRequest) (*schema.SoDViolationReport, error) {
+	var rules []*model.SoDRule
+	if req.RuleID != 0 {
+		rule, err := s.sodRepo.GetByID(req.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			return nil, errors.ErrSoDRuleNotFound
+		}
+		rules = append(rules, rule)
+	} else {
+		for _, ruleType := range []string{model.SoDStatic, model.SoDDynamic} {
+			items, err := s.sodRepo.ListByType(ruleType)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, items...)
+		}
+	}
+
+	report := &schema.SoDViolationReport{Violations: make([]schema.SoDViolation, 0)}
+	usernames := make(map[uint64]string)
+	ancestors := make(map[uint64][]uint64)
+	for _, rule := range rules {
+		violations, err := s.ruleViolations(rule, ancestors)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range violations {
+			username, ok := usernames[violations[i].UserID]
+			if !ok {
+				user, err := s.userRepo.GetByID(violations[i].UserID)
+				if err != nil {
+					return nil, err
+				}
+				if user != nil {
+					username = user.Username
+				}
+				usernames[violations[i].UserID] = username
+			}
+			violations[i].Username = username
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+
+	report.Total = len(report.Violations)
+	return report, nil
+}
+
+// ruleViolations 查找违反单条规则的用户
+// 只有规则角色及继承了规则角色的后代角色会影响判定，因此只需查询这些角色的分配
+func (s *sodService) ruleViolations(rule *model.SoDRule, ancestors map[uint64][]uint64) ([]schema.SoDViolation, error) {
+	candidates := rule.RoleIDs()
+	for _, roleID := range rule.RoleIDs() {
+		descendants, err := s.roleRepo.GetDescendants(roleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range descendants {
+			candidates = append(candidates, role.ID)
+		}
+	}
+
+	assignments, err := s.sodRepo.GetRoleAssignments(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按用户、租户分组
+	byUser := make(map[uint64]map[uint64][]uint64)
+	var userIDs []uint64
+	for _, assignment := range assignments {
+		tenants, ok := byUser[assignment.UserID]
+		if !ok {
+			tenants = make(map[uint64][]uint64)
+			byUser[assignment.UserID] = tenants
+			userIDs = append(userIDs, assignment.UserID)
+		}
+		tenants[assignment.TenantID] = append(tenants[assignment.TenantID], assignment.RoleID)
+	}
+
+	var violations []schema.SoDViolation
+	for _, userID := range userIDs {
+		globalViolated := false
+		for _, scope := range tenantScopes(byUser[userID]) {
+			held, err := s.checker.closure(scope.roleIDs, ancestors)
+			if err != nil {
+				return nil, err
+			}
+			matched := violatedRoles(rule, held)
+			if matched == nil {
+				continue
+			}
+
+			// 仅由全局分配构成的违反只报告一次
+			if scope.tenantID == model.GlobalTenantID {
+				globalViolated = true
+			} else if globalViolated {
+				continue
+			}
+
+			violations = append(violations, schema.SoDViolation{
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				Type:     rule.Type,
+				UserID:   userID,
+				TenantID: scope.tenantID,
+				Roles:    matched,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// convertToSoDRuleResponse 将规则模型转换为响应结构
+func convertToSoDRuleResponse(rule *model.SoDRule) *schema.SoDRuleResponse {
+	roles := make([]schema.RoleSimple, 0, len(rule.Roles))
+	for _, role := range rule.Roles {
+		roles = append(roles, schema.RoleSimple{ID: role.ID, Code: role.Code, Name: role.Name})
+	}
+
+	return &schema.SoDRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Type:        rule.Type,
+		MaxRoles:    rule.MaxRoles,
+		Roles:       roles,
+		CreatedAt:   rule.CreatedAt,
+	}
+}
+
+// sodChecker 校验角色分配与会话激活的角色是否违反职责分离规则，nil 表示不校验
+type sodChecker struct {
+	sodRepo  repository.SoDRepository
+	roleRepo repository.RoleRepository
+}
+
+// newSoDChecker 创建职责分离校验器，sodRepo 为nil时返回nil
+func newSoDChecker(sodRepo repository.SoDRepository, roleRepo repository.RoleRepository) *sodChecker {
+	if sodRepo == nil {
+		return nil
+	}
+	return &sodChecker{sodRepo: sodRepo, roleRepo: roleRepo}
+}
+
+// checkAssignment 校验将用户在指定租户内直接分配的角色替换为 roleIDs 后是否违反静态规则，
+// 经由用户组获得的角色同样计入；userID 为0表示新用户，没有其他分配
+func (c *sodChecker) checkAssignment(userID, tenantID uint64, roleIDs []uint64) error {
+	if c == nil {
+		return nil
+	}
+
+	rules, err := c.sodRepo.ListByType(model.SoDStatic)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	byTenant := make(map[uint64][]uint64)
+	if userID != 0 {
+		assignments, err := c.sodRepo.GetUserAssignments(userID)
+		if err != nil {
+			return err
+		}
+		for _, assignment := range assignments {
+			if assignment.GroupID != 0 || assignment.TenantID != tenantID {
+				byTenant[assignment.TenantID] = append(byTenant[assignment.TenantID], assignment.RoleID)
+			}
+		}
+	}
+	byTenant[tenantID] = append(byTenant[tenantID], roleIDs...)
+
+	rule, violatedTenantID, err := c.violation(rules, byTenant, make(map[uint64][]uint64))
+	if err != nil {
+		return err
+	}
+	if rule != nil {
+		slog.Warn("角色分配违反职责分离规则", "userID", userID, "tenantID", violatedTenantID, "rule", rule.Name)
+		return errors.ErrSoDViolation
+	}
+
+	return nil
+}
+
+// checkGroupRoles 校验将用户组在指定租户内的角色替换为 roleIDs 后，
+// 经由该组获得角色的用户 userIDs 是否违反静态规则
+func (c *sodChecker) checkGroupRoles(userIDs []uint64, groupID, tenantID uint64, roleIDs []uint64) error {
+	if c == nil || len(userIDs) == 0 {
+		return nil
+	}
+
+	rules, err := c.sodRepo.ListByType(model.SoDStatic)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	current, err := c.sodRepo.PreviewAssignments(userIDs, nil)
+	if err != nil {
+		return err
+	}
+
+	assignments := make([]*model.RoleAssignment, 0, len(current)+len(userIDs)*len(roleIDs))
+	for _, assignment := range current {
+		if assignment.GroupID != groupID || assignment.TenantID != tenantID {
+			assignments = append(assignments, assignment)
+		}
+	}
+	for _, userID := range userIDs {
+		for _, roleID := range roleIDs {
+			assignments = append(assignments, &model.RoleAssignment{
+				UserRole: model.UserRole{UserID: userID, RoleID: roleID, TenantID: tenantID},
+				GroupID:  groupID,
+			})
+		}
+	}
+
+	return c.checkUsers(rules, assignments)
+}
+
+// checkGroupChange 校验用户组成员或嵌套关系变更后，受影响的用户 userIDs 是否违反静态规则
+func (c *sodChecker) checkGroupChange(userIDs []uint64, change *model.GroupChange) error {
+	if c == nil || len(userIDs) == 0 {
+		return nil
+	}
+
+	rules, err := c.sodRepo.ListByType(model.SoDStatic)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	assignments, err := c.sodRepo.PreviewAssignments(userIDs, change)
+	if err != nil {
+		return err
+	}
+
+	return c.checkUsers(rules, assignments)
+}
+
+// checkUsers 按用户分组校验多个用户的角色分配，任一用户违反规则即返回错误
+func (c *sodChecker) checkUsers(rules []*model.SoDRule, assignments []*model.RoleAssignment) error {
+	byUser := make(map[uint64]map[uint64][]uint64)
+	var userIDs []uint64
+	for _, assignment := range assignments {
+		tenants, ok := byUser[assignment.UserID]
+		if !ok {
+			tenants = make(map[uint64][]uint64)
+			byUser[assignment.UserID] = tenants
+			userIDs = append(userIDs, assignment.UserID)
+		}
+		tenants[assignment.TenantID] = append(tenants[assignment.TenantID], assignment.RoleID)
+	}
+
+	ancestors := make(map[uint64][]uint64)
+	for _, userID := range userIDs {
+		rule, tenantID, err := c.violation(rules, byUser[userID], ancestors)
+		if err != nil {
+			return err
+		}
+		if rule != nil {
+			slog.Warn("用户组变更违反职责分离规则", "userID", userID, "tenantID", tenantID, "rule", rule.Name)
+			return errors.ErrSoDViolation
+		}
+	}
+
+	return nil
+}
+
+// violation 返回按租户分组的角色分配违反的第一条规则及所在租户，未违反时规则为nil；
+// ancestors 缓存已查询的祖先，校验多个用户时可共用
+func (c *sodChecker) violation(rules []*model.SoDRule, byTenant map[uint64][]uint64, ancestors map[uint64][]uint64) (*model.SoDRule, uint64, error) {
+	for _, scope := range tenantScopes(byTenant) {
+		held, err := c.closure(scope.roleIDs, ancestors)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, rule := range rules {
+			if violatedRoles(rule, held) != nil {
+				return rule, scope.tenantID, nil
+			}
+		}
+	}
+
+	return nil, 0, nil
+}
+
+// checkActivation 校验同一会话中激活的角色是否违反动态规则，返回是否存在动态规则
+func (c *sodChecker) checkActivation(userID uint64, roleIDs []uint64) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	rules, err := c.sodRepo.ListByType(model.SoDDynamic)
+	if err != nil || len(rules) == 0 {
+		return false, err
+	}
+
+	held, err := c.closure(roleIDs, make(map[uint64][]uint64))
+	if err != nil {
+		return true, err
+	}
+	for _, rule := range rules {
+		if violatedRoles(rule, held) != nil {
+			slog.Warn("激活的角色违反职责分离规则", "userID", userID, "rule", rule.Name)
+			return true, errors.ErrDynamicSoDViolation
+		}
+	}
+
+	return true, nil
+}
+
+// closure 返回角色及其继承的全部祖先角色，ancestors 缓存已查询的祖先
+func (c *sodChecker) closure(roleIDs []uint64, ancestors map[uint64][]uint64) (map[uint64]struct{}, error) {
+	held := make(map[uint64]struct{}, len(roleIDs))
+	for _, roleID := range roleIDs {
+		held[roleID] = struct{}{}
+
+		parents, ok := ancestors[roleID]
+		if !ok {
+			roles, err := c.roleRepo.GetAncestors(roleID)
+			if err != nil {
+				return nil, err
+			}
+			parents = make([]uint64, 0, len(roles))
+			for _, role := range roles {
+				parents = append(parents, role.ID)
+			}
+			ancestors[roleID] = parents
+		}
+		for _, parentID := range parents {
+			held[parentID] = struct{}{}
+		}
+	}
+	return held, nil
+}
+
+// violatedRoles 返回用户持有的规则角色，未超过规则上限时返回nil
+func violatedRoles(rule *model.SoDRule, held map[uint64]struct{}) []schema.RoleSimple {
+	var matched []schema.RoleSimple
+	for _, role := range rule.Roles {
+		if _, ok := held[role.ID]; ok {
+			matched = append(matched, schema.RoleSimple{ID: role.ID, Code: role.Code, Name: role.Name})
+		}
+	}
+	if len(matched) <= rule.MaxRoles {
+		return nil
+	}
+	return matched
+}
+
+// tenantScope 一个租户范围内用户持有的角色
+type tenantScope struct {
+	tenantID uint64
+	roleIDs  []uint64
+}
+
+// tenantScopes 将按租户分组的角色分配展开为租户范围，每个范围包含全局分配与该租户的分配，
+// 全局范围总是排在最前
+func tenantScopes(byTenant map[uint64][]uint64) []tenantScope {
+	tenantIDs := make([]uint64, 0, len(byTenant))
+	for tenantID := range byTenant {
+		if tenantID != model.GlobalTenantID {
+			tenantIDs = append(tenantIDs, tenantID)
+		}
+	}
+	sort.Slice(tenantIDs, func(i, j int) bool { return tenantIDs[i] < tenantIDs[j] })
+
+	global := byTenant[model.GlobalTenantID]
+	scopes := []tenantScope{{tenantID: model.GlobalTenantID, roleIDs: global}}
+	for _, tenantID := range tenantIDs {
+		roleIDs := append(append([]uint64{}, global...), byTenant[tenantID]...)
+		scopes = append(scopes, tenantScope{tenantID: tenantID, roleIDs: roleIDs})
+	}
+	return scopes
+}
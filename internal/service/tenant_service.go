@@ -0,0 +1,184 @@
+package service
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+)
+
+// TenantService 租户服务接口
+type TenantService interface {
+	Create(req *schema.CreateTenantRequest) (uint64, error)
+	Update(req *schema.UpdateTenantRequest) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*schema.TenantResponse, error)
+	List(req *schema.ListTenantRequest) (*schema.ListTenantResponse, error)
+}
+
+// tenantService 租户服务实现
+type tenantService struct {
+	tenantRepo repository.TenantRepository
+}
+
+// NewTenantService 创建租户服务实例
+func NewTenantService(tenantRepo repository.TenantRepository) TenantService {
+	return &tenantService{
+		tenantRepo: tenantRepo,
+	}
+}
+
+// Create 创建租户
+func (s *tenantService) Create(req *schema.CreateTenantRequest) (uint64, error) {
+	// 检查租户编码是否已存在
+	existingTenant, err := s.tenantRepo.GetByCode(req.Code)
+	if err != nil {
+		return 0, err
+	}
+
+	if existingTenant != nil {
+		return 0, errors.ErrTenantExists
+	}
+
+	// 检查租户名称是否已存在
+	existingTenant, err = s.tenantRepo.GetByName(req.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if existingTenant != nil {
+		return 0, errors.ErrTenantExists
+	}
+
+	// 创建租户
+	tenant := &model.Tenant{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := s.tenantRepo.Create(tenant); err != nil {
+		return 0, err
+	}
+
+	return tenant.ID, nil
+}
+
+// Update 更新租户
+func (s *tenantService) Update(req *schema.UpdateTenantRequest) error {
+	// 检查租户是否存在
+	existingTenant, err := s.tenantRepo.GetByID(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if existingTenant == nil {
+		return errors.ErrTenantNotFound
+	}
+
+	// 检查租户编码是否已被其他租户使用
+	if req.Code != existingTenant.Code {
+		tenant, err := s.tenantRepo.GetByCode(req.Code)
+		if err != nil {
+			return err
+		}
+
+		if tenant != nil && tenant.ID != req.ID {
+			return errors.ErrTenantExists
+		}
+	}
+
+	// 检查租户名称是否已被其他租户使用
+	if req.Name != existingTenant.Name {
+		tenant, err := s.tenantRepo.GetByName(req.Name)
+		if err != nil {
+			return err
+		}
+
+		if tenant != nil && tenant.ID != req.ID {
+			return errors.ErrTenantExists
+		}
+	}
+
+	return s.tenantRepo.Update(&model.Tenant{
+		ID:          req.ID,
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+}
+
+// Delete 删除租户，租户下仍有角色、角色分配或直接授予用户的权限时不允许删除
+func (s *tenantService) Delete(id uint64) error {
+	// 检查租户是否存在
+	tenant, err := s.tenantRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if tenant == nil {
+		return errors.ErrTenantNotFound
+	}
+
+	// 检查租户是否仍在使用
+	count, err := s.tenantRepo.CountUsage(id)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.ErrTenantInUse
+	}
+
+	return s.tenantRepo.Delete(id)
+}
+
+// GetByID 根据ID获取租户
+func (s *tenantService) GetByID(id uint64) (*schema.TenantResponse, error) {
+	tenant, err := s.tenantRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant == nil {
+		return nil, errors.ErrTenantNotFound
+	}
+
+	return s.convertToTenantResponse(tenant), nil
+}
+
+// List 获取租户列表，返回完整分页信息
+func (s *tenantService) List(req *schema.ListTenantRequest) (*schema.ListTenantResponse, error) {
+	tenants, total, err := s.tenantRepo.List(req.Page, req.PageSize, req.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]schema.TenantResponse, 0, len(tenants))
+	for _, tenant := range tenants {
+		items = append(items, *s.convertToTenantResponse(tenant))
+	}
+	totalPages := 0
+	if req.PageSize > 0 {
+		totalPages = int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	}
+
+	return &schema.ListTenantResponse{
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Items:      items,
+	}, nil
+}
+
+// convertToTenantResponse 将租户模型转换为响应结构
+func (s *tenantService) convertToTenantResponse(tenant *model.Tenant) *schema.TenantResponse {
+	return &schema.TenantResponse{
+		ID:          tenant.ID,
+		Code:        tenant.Code,
+		Name:        tenant.Name,
+		Description: tenant.Description,
+		CreatedAt:   tenant.CreatedAt,
+	}
+}
@@ -1,39 +1,60 @@
 package service
 
 import (
-	"log/slog"
-	"time"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"github.com/lvyunze/fiber-rbac/config"
 	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/condition"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/hash"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
 	"github.com/lvyunze/fiber-rbac/internal/repository"
 	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"log/slog"
+	"sort"
+	"time"
 )
 
 // UserService 用户服务接口
 type UserService interface {
 	Login(req *schema.LoginRequest) (*schema.LoginResponse, error)
 	RefreshToken(token string) (*schema.LoginResponse, error)
-	CheckPermission(userID uint64, permission string) (bool, error)
-	GetProfile(userID uint64) (*schema.UserResponse, error)
+	CheckPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.CheckPermissionResponse, error)
+	CheckPermissions(userID, tenantID uint64, activeRoles []uint64, codes []string) (map[string]bool, error)
+	ExplainPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.ExplainPermissionResponse, error)
+	EvaluatePermission(userID, tenantID uint64, activeRoles []uint64, req *schema.EvaluatePermissionRequest) (*schema.EvaluatePermissionResponse, error)
+	GetPermissionPolicy(userID, tenantID uint64) (*permcode.Policy, error)
+	GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*permcode.Policy, error)
+	GetRoutePermissions(method, path string) ([]string, error)
+	GetMenus(userID, tenantID uint64, activeRoles []uint64) ([]*schema.PermissionTreeNode, error)
+	GetProfile(userID, tenantID uint64, activeRoles []uint64) (*schema.UserResponse, error)
+	GetPermissionVersion(userID, tenantID uint64, activeRoles []uint64) (string, error)
 	Create(req *schema.CreateUserRequest) (uint64, error)
 	Update(req *schema.UpdateUserRequest) error
 	Delete(id uint64) error
-	GetByID(id uint64) (*schema.UserResponse, error)
-	List(req *schema.ListUserRequest) (*schema.ListUserResponse, error)
-	AssignRole(userID uint64, roleIDs []uint64) error
+	GetByID(id, tenantID uint64) (*schema.UserResponse, error)
+	List(req *schema.ListUserRequest, callerID, tenantID uint64) (*schema.ListUserResponse, error)
+	AssignRole(req *schema.AssignRoleRequest) error
 	GetRoles(userID uint64) ([]schema.RoleResponse, error)
+	AssignPermissions(req *schema.AssignUserPermissionsRequest) error
+	RevokePermissions(req *schema.RevokeUserPermissionsRequest) error
+	GetDirectPermissions(userID uint64) ([]schema.UserPermissionResponse, error)
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo       repository.UserRepository
-	roleRepo       repository.RoleRepository
-	permissionRepo repository.PermissionRepository
-	tokenService   *jwt.TokenService
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	permissionRepo   repository.PermissionRepository
+	tenantRepo       repository.TenantRepository
+	tokenService     *jwt.TokenService
 	refreshTokenRepo repository.RefreshTokenRepository
+	permissionCache  *PermissionCache
+	resolver         *PermissionResolver
+	sod              *sodChecker
 }
 
 // NewUserService 创建用户服务实例
@@ -41,15 +62,26 @@ func NewUserService(
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	permissionRepo repository.PermissionRepository,
+	tenantRepo repository.TenantRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
 	jwtConfig *config.JWTConfig,
+	permissionCache *PermissionCache, // 可为nil，表示不缓存有效权限
+	routeTable *RouteTable, // 可为nil，表示使用该服务独有的接口路由表，需与权限服务共用同一路由表才能在接口权限变更时重建
+	sodRepo repository.SoDRepository, // 可为nil，表示不校验职责分离约束
 ) UserService {
+	if routeTable == nil {
+		routeTable = NewRouteTable(permissionRepo, 0)
+	}
 	return &userService{
-		userRepo:       userRepo,
-		roleRepo:       roleRepo,
-		permissionRepo: permissionRepo,
-		tokenService:   jwt.NewTokenService(jwtConfig),
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		permissionRepo:   permissionRepo,
+		tenantRepo:       tenantRepo,
+		tokenService:     jwt.NewTokenService(jwtConfig),
 		refreshTokenRepo: refreshTokenRepo,
+		permissionCache:  permissionCache,
+		resolver:         NewPermissionResolver(userRepo, routeTable, permissionCache),
+		sod:              newSoDChecker(sodRepo, roleRepo),
 	}
 }
 
@@ -78,8 +110,21 @@ func (s *userService) Login(req *schema.LoginRequest) (*schema.LoginResponse, er
 		return nil, errors.ErrInvalidCredentials
 	}
 
+	// 指定租户登录时校验租户是否存在及用户能否进入
+	if req.TenantID > 0 {
+		if err := s.checkTenantAccess(user.ID, req.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 校验本次会话激活的角色
+	activeRoles, err := s.activateRoles(user.ID, req.TenantID, req.ActiveRoleIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 生成JWT令牌
-	accessToken, refreshToken, err := s.tokenService.GenerateTokenPair(user.ID, user.Username)
+	accessToken, refreshToken, err := s.tokenService.GenerateSessionTokenPair(user.ID, req.TenantID, activeRoles, user.Username)
 	if err != nil {
 		slog.Error("生成令牌失败", "error", err)
 		return nil, err
@@ -137,8 +182,14 @@ func (s *userService) RefreshToken(token string) (*schema.LoginResponse, error)
 		slog.Error("标记refresh_token已用失败", "error", err)
 	}
 
-	// 生成新token对
-	accessToken, refreshToken, err := s.tokenService.GenerateTokenPair(user.ID, user.Username)
+	// 重新校验激活的角色，期间角色分配或职责分离规则可能已变化
+	activeRoles, err := s.activateRoles(user.ID, claims.TenantID, claims.ActiveRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成新token对，沿用原令牌的租户与激活的角色
+	accessToken, refreshToken, err := s.tokenService.GenerateSessionTokenPair(user.ID, claims.TenantID, activeRoles, user.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -161,32 +212,435 @@ func (s *userService) RefreshToken(token string) (*schema.LoginResponse, error)
 	}, nil
 }
 
-// CheckPermission 检查用户权限
-func (s *userService) CheckPermission(userID uint64, permission string) (bool, error) {
-	// 获取用户信息
+// activateRoles 校验会话要激活的角色，返回写入令牌的激活角色，nil 表示激活全部已分配的角色
+// 指定的角色必须是用户在该租户内已分配且生效的角色（含经由用户组获得的角色）；未指定时全部角色同时激活，
+// 二者都需满足动态职责分离规则，存在动态规则时总是返回明确的激活角色
+func (s *userService) activateRoles(userID, tenantID uint64, requested []uint64) ([]uint64, error) {
+	if len(requested) == 0 && s.sod == nil {
+		return nil, nil
+	}
+
+	assignments, err := s.userRepo.GetRoleAssignments(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := model.NowUnix()
+	held := make(map[uint64]struct{}, len(assignments))
+	all := make([]uint64, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.RoleDeletedAt != nil || !assignment.Validity.ActiveAt(now) {
+			continue
+		}
+		if _, ok := held[assignment.RoleID]; !ok {
+			held[assignment.RoleID] = struct{}{}
+			all = append(all, assignment.RoleID)
+		}
+	}
+
+	groupAssignments, err := s.userRepo.GetGroupRoleAssignments(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range groupAssignments {
+		if assignment.TenantID != model.GlobalTenantID && assignment.TenantID != tenantID {
+			continue
+		}
+		if _, ok := held[assignment.RoleID]; !ok {
+			held[assignment.RoleID] = struct{}{}
+			all = append(all, assignment.RoleID)
+		}
+	}
+
+	var active []uint64
+	seen := make(map[uint64]struct{}, len(requested))
+	for _, roleID := range requested {
+		if _, ok := held[roleID]; !ok {
+			return nil, errors.ErrRoleNotActivatable
+		}
+		if _, ok := seen[roleID]; !ok {
+			seen[roleID] = struct{}{}
+			active = append(active, roleID)
+		}
+	}
+
+	activated := active
+	if activated == nil {
+		activated = all
+	}
+	constrained, err := s.sod.checkActivation(userID, activated)
+	if err != nil {
+		return nil, err
+	}
+
+	// 存在动态规则时令牌总是记录明确的激活角色，登录后新分配的角色不会在本会话中自动激活，
+	// 否则空的激活列表会让之后分配的互斥角色与已有角色同时生效
+	if constrained {
+		return activated, nil
+	}
+	return active, nil
+}
+
+// CheckPermission 检查用户在指定租户内的权限，任一角色的拒绝规则优先于所有允许规则
+// activeRoles 为会话激活的角色，为空时计入全部已分配的角色，下同
+func (s *userService) CheckPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.CheckPermissionResponse, error) {
+	// 获取用户的全部权限授予记录
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateGrants(permissions.grants, permission), nil
+}
+
+// CheckPermissions 批量检查用户在指定租户内的权限，只解析一次有效权限
+// 返回每个权限编码是否被允许，拒绝规则优先于允许规则
+func (s *userService) CheckPermissions(userID, tenantID uint64, activeRoles []uint64, codes []string) (map[string]bool, error) {
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		results[code] = permissions.policy.Allows(code)
+	}
+	return results, nil
+}
+
+// EvaluatePermission 使用调用方提供的资源与请求属性检查用户在指定租户内的权限
+// 带条件的授予仅在条件成立时参与判定；条件求值失败时允许不生效，拒绝仍然生效
+func (s *userService) EvaluatePermission(userID, tenantID uint64, activeRoles []uint64, req *schema.EvaluatePermissionRequest) (*schema.EvaluatePermissionResponse, error) {
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := condition.Attributes{
+		condition.NamespaceSubject: {
+			"id":        userID,
+			"username":  user.Username,
+			"tenant_id": tenantID,
+		},
+		condition.NamespaceResource: req.Resource,
+		condition.NamespaceRequest:  requestAttributes(req.Request),
 	}
 
+	result := &schema.EvaluatePermissionResponse{Conditions: []schema.ConditionResult{}}
+	holds := make(map[*model.PermissionGrant]bool)
+	for _, grant := range permissions.grants {
+		if !grant.IsConditional() || !permcode.Match(grant.PermissionCode, req.Permission) {
+			continue
+		}
+
+		item := schema.ConditionResult{
+			Role:           schema.RoleSimple{ID: grant.RoleID, Code: grant.RoleCode, Name: grant.RoleName},
+			PermissionCode: grant.PermissionCode,
+			Effect:         grant.Effect,
+			Condition:      grant.Condition,
+		}
+		expr, err := condition.Compile(grant.Condition)
+		if err == nil {
+			item.Satisfied, err = expr.Eval(attrs)
+		}
+		if err != nil {
+			item.Error = err.Error()
+		}
+		holds[grant] = item.Satisfied || (err != nil && grant.Effect == model.EffectDeny)
+		result.Conditions = append(result.Conditions, item)
+	}
+
+	result.CheckPermissionResponse = *decideGrants(permissions.grants, req.Permission, func(grant *model.PermissionGrant) bool {
+		return holds[grant]
+	})
+	return result, nil
+}
+
+// requestAttributes 复制请求上下文属性，未提供 time 时填入服务端当前时刻
+func requestAttributes(values map[string]interface{}) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(values)+1)
+	for key, value := range values {
+		attrs[key] = value
+	}
+	if _, ok := attrs["time"]; !ok {
+		attrs["time"] = time.Now().Format("15:04")
+	}
+	return attrs
+}
+
+// ExplainPermission 解释用户在指定租户内的权限判定结果
+// 判定与 CheckPermission 基于同一份有效权限，并列出每条候选角色分配经由哪条继承路径
+// 命中了哪些权限授予，或因角色已删除、分配未生效或已过期而不参与判定
+func (s *userService) ExplainPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.ExplainPermissionResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
 	if user == nil {
-		return false, errors.ErrUserNotFound
+		return nil, errors.ErrUserNotFound
 	}
 
-	// 检查用户角色和权限
-	for _, role := range user.Roles {
-		for _, perm := range role.Permissions {
-			if perm.Code == permission {
-				return true, nil
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.userRepo.GetRoleAssignments(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	inheritances, err := s.userRepo.GetEffectiveRoleParents(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 角色继承图及路径上各角色的信息
+	parents := make(map[uint64][]uint64)
+	roles := make(map[uint64]schema.RoleSimple)
+	for _, inheritance := range inheritances {
+		parents[inheritance.RoleID] = append(parents[inheritance.RoleID], inheritance.ParentID)
+		roles[inheritance.ParentID] = schema.RoleSimple{ID: inheritance.ParentID, Code: inheritance.ParentCode, Name: inheritance.ParentName}
+	}
+
+	// 命中被检查权限的授予记录，按授予角色分组
+	matched := make(map[uint64][]*model.PermissionGrant)
+	for _, grant := range permissions.grants {
+		if permcode.Match(grant.PermissionCode, permission) {
+			matched[grant.RoleID] = append(matched[grant.RoleID], grant)
+		}
+	}
+
+	decision := evaluateGrants(permissions.grants, permission)
+	result := &schema.ExplainPermissionResponse{
+		CheckPermissionResponse: *decision,
+		UserID:                  userID,
+		TenantID:                tenantID,
+		Permission:              permission,
+		Assignments:             make([]schema.ExplainAssignment, 0, len(assignments)),
+		DirectGrants:            make([]schema.ExplainGrant, 0, len(matched[0])),
+	}
+
+	// 直接授予用户的权限不经过任何角色
+	for _, grant := range matched[0] {
+		result.DirectGrants = append(result.DirectGrants, schema.ExplainGrant{
+			Path:           []schema.RoleSimple{},
+			PermissionID:   grant.PermissionID,
+			PermissionCode: grant.PermissionCode,
+			Effect:         grant.Effect,
+		})
+	}
+
+	// 沿继承关系收集经由一条生效的分配命中的授予，并判断该分配对结果的作用
+	explain := func(item schema.ExplainAssignment) schema.ExplainAssignment {
+		var allowed, denied bool
+		for _, path := range inheritancePaths(item.Role.ID, parents) {
+			for _, grant := range matched[path[len(path)-1]] {
+				explained := schema.ExplainGrant{
+					Path:           make([]schema.RoleSimple, 0, len(path)),
+					PermissionID:   grant.PermissionID,
+					PermissionCode: grant.PermissionCode,
+					Effect:         grant.Effect,
+					Condition:      grant.Condition,
+				}
+				for _, id := range path {
+					explained.Path = append(explained.Path, roles[id])
+				}
+				item.Grants = append(item.Grants, explained)
+
+				if grant.Effect == model.EffectDeny {
+					denied = true
+				} else if !grant.IsConditional() {
+					allowed = true
+				}
 			}
 		}
+
+		switch {
+		case denied:
+			item.Outcome = schema.OutcomeDenied
+		case allowed && decision.HasPermission:
+			item.Outcome = schema.OutcomeGranted
+		case allowed:
+			item.Outcome = schema.OutcomeOverridden
+		default:
+			item.Outcome = schema.OutcomeNoMatch
+		}
+		return item
+	}
+
+	// 会话只激活部分角色时，其余分配不参与判定
+	activated := make(map[uint64]struct{}, len(activeRoles))
+	for _, roleID := range activeRoles {
+		activated[roleID] = struct{}{}
+	}
+	notActivated := func(roleID uint64) bool {
+		_, ok := activated[roleID]
+		return len(activated) > 0 && !ok
+	}
+
+	now := model.NowUnix()
+	for _, assignment := range assignments {
+		role := schema.RoleSimple{ID: assignment.RoleID, Code: assignment.RoleCode, Name: assignment.RoleName}
+		roles[role.ID] = role
+
+		item := schema.ExplainAssignment{
+			Role:       role,
+			TenantID:   assignment.TenantID,
+			ValidFrom:  assignment.ValidFrom,
+			ValidUntil: assignment.ValidUntil,
+			Status:     assignmentStatus(assignment, now),
+			Outcome:    schema.OutcomeInactive,
+		}
+		if item.Status == schema.AssignmentActive && notActivated(role.ID) {
+			item.Status = schema.AssignmentNotActivated
+		}
+		if item.Status != schema.AssignmentActive {
+			result.Assignments = append(result.Assignments, item)
+			continue
+		}
+		result.Assignments = append(result.Assignments, explain(item))
 	}
 
-	return false, nil
+	// 经由用户组获得的角色分配没有生效区间，总是生效
+	_, groupRoles, err := s.loadGroupRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupRole := range groupRoles {
+		if groupRole.TenantID != model.GlobalTenantID && groupRole.TenantID != tenantID {
+			continue
+		}
+		roles[groupRole.Role.ID] = groupRole.Role
+		item := schema.ExplainAssignment{
+			Role:     groupRole.Role,
+			TenantID: groupRole.TenantID,
+			Status:   schema.AssignmentActive,
+			Groups:   groupRole.Path,
+		}
+		if notActivated(groupRole.Role.ID) {
+			item.Status = schema.AssignmentNotActivated
+			item.Outcome = schema.OutcomeInactive
+			result.Assignments = append(result.Assignments, item)
+			continue
+		}
+		result.Assignments = append(result.Assignments, explain(item))
+	}
+
+	return result, nil
 }
 
-// GetProfile 获取用户个人信息
-func (s *userService) GetProfile(userID uint64) (*schema.UserResponse, error) {
+// loadGroupRoles 获取用户直接所属的用户组及经由用户组获得的角色，
+// 每条角色附带从用户直接所属的组沿嵌套关系到角色所分配的组的最短路径
+func (s *userService) loadGroupRoles(userID uint64) ([]schema.GroupSimple, []schema.UserGroupRole, error) {
+	groups, err := s.userRepo.GetGroups(userID)
+	if err != nil || len(groups) == 0 {
+		return nil, nil, err
+	}
+
+	inheritances, err := s.userRepo.GetEffectiveGroupParents(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignments, err := s.userRepo.GetGroupRoleAssignments(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 组嵌套图及路径上各组的信息
+	parents := make(map[uint64][]uint64)
+	infos := make(map[uint64]schema.GroupSimple)
+	direct := make([]schema.GroupSimple, 0, len(groups))
+	for _, group := range groups {
+		info := schema.GroupSimple{ID: group.ID, Code: group.Code, Name: group.Name}
+		infos[group.ID] = info
+		direct = append(direct, info)
+	}
+	for _, inheritance := range inheritances {
+		parents[inheritance.GroupID] = append(parents[inheritance.GroupID], inheritance.ParentID)
+		infos[inheritance.ParentID] = schema.GroupSimple{ID: inheritance.ParentID, Code: inheritance.ParentCode, Name: inheritance.ParentName}
+	}
+
+	// 从任一直接所属的组出发到每个可达组的最短路径
+	paths := make(map[uint64][]uint64)
+	for _, group := range groups {
+		for _, path := range inheritancePaths(group.ID, parents) {
+			target := path[len(path)-1]
+			if existing, ok := paths[target]; !ok || len(path) < len(existing) {
+				paths[target] = path
+			}
+		}
+	}
+
+	groupRoles := make([]schema.UserGroupRole, 0, len(assignments))
+	for _, assignment := range assignments {
+		path, ok := paths[assignment.GroupID]
+		if !ok {
+			continue
+		}
+		item := schema.UserGroupRole{
+			Role:     schema.RoleSimple{ID: assignment.RoleID, Code: assignment.RoleCode, Name: assignment.RoleName},
+			TenantID: assignment.TenantID,
+			Group:    infos[assignment.GroupID],
+			Path:     make([]schema.GroupSimple, 0, len(path)),
+		}
+		for _, id := range path {
+			item.Path = append(item.Path, infos[id])
+		}
+		groupRoles = append(groupRoles, item)
+	}
+	return direct, groupRoles, nil
+}
+
+// GetPermissionPolicy 获取用户在指定租户内的权限判定策略
+func (s *userService) GetPermissionPolicy(userID, tenantID uint64) (*permcode.Policy, error) {
+	return s.resolver.GetPermissionPolicy(userID, tenantID)
+}
+
+// GetSessionPolicy 获取会话仅激活部分角色时的权限判定策略，activeRoles 为空时等同于 GetPermissionPolicy
+func (s *userService) GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*permcode.Policy, error) {
+	return s.resolver.GetSessionPolicy(userID, tenantID, activeRoles)
+}
+
+// GetRoutePermissions 获取方法与路径模式匹配指定请求的接口权限编码
+func (s *userService) GetRoutePermissions(method, path string) ([]string, error) {
+	return s.resolver.GetRoutePermissions(method, path)
+}
+
+// GetMenus 获取用户在指定租户内可见的模块、菜单与按钮树，用于构建动态导航
+// 只返回被允许的节点，节点未被允许时其整个分支都不返回；接口类型的节点不出现在菜单中
+func (s *userService) GetMenus(userID, tenantID uint64, activeRoles []uint64) ([]*schema.PermissionTreeNode, error) {
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := s.permissionRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPermissionTree(all, func(permission *model.Permission) bool {
+		return permission.Type != model.PermissionTypeAPI && permissions.policy.Allows(permission.Code)
+	}), nil
+}
+
+// loadPermissions 获取用户在指定租户内会话生效的权限授予记录与判定策略，优先读取缓存
+func (s *userService) loadPermissions(userID, tenantID uint64, activeRoles []uint64) (*permissionCacheEntry, error) {
+	return s.resolver.loadSession(userID, tenantID, activeRoles)
+}
+
+// GetProfile 获取用户个人信息，权限按指定租户计算
+func (s *userService) GetProfile(userID, tenantID uint64, activeRoles []uint64) (*schema.UserResponse, error) {
 	// 获取用户信息
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
@@ -197,7 +651,90 @@ func (s *userService) GetProfile(userID uint64) (*schema.UserResponse, error) {
 		return nil, errors.ErrUserNotFound
 	}
 
-	return s.convertToUserResponse(user), nil
+	// 获取用户的有效权限、菜单与权限版本
+	permissions, err := s.loadProfilePermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.convertToUserResponse(user)
+	response.Permissions = permissions.Permissions
+	response.DeniedPermissions = permissions.DeniedPermissions
+	response.Menus = permissions.Menus
+	response.PermissionVersion = permissions.Version
+	response.TenantID = tenantID
+	return response, nil
+}
+
+// GetPermissionVersion 获取用户在指定租户内有效权限与菜单的版本摘要
+// 客户端可携带上次获取的版本，版本一致时无需重新获取个人信息
+func (s *userService) GetPermissionVersion(userID, tenantID uint64, activeRoles []uint64) (string, error) {
+	permissions, err := s.loadProfilePermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return "", err
+	}
+
+	return permissions.Version, nil
+}
+
+// profilePermissions 个人信息中返回的权限部分，Version 为其余字段的摘要
+type profilePermissions struct {
+	TenantID          uint64                       `json:"tenant_id"`
+	Permissions       []string                     `json:"permissions"`
+	DeniedPermissions []string                     `json:"denied_permissions"`
+	Menus             []*schema.PermissionTreeNode `json:"menus"`
+	Version           string                       `json:"-"`
+}
+
+// loadProfilePermissions 获取用户在指定租户内去重后的有效权限编码、拒绝规则与菜单树，并计算版本摘要
+func (s *userService) loadProfilePermissions(userID, tenantID uint64, activeRoles []uint64) (*profilePermissions, error) {
+	permissions, err := s.loadPermissions(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	menus, err := s.GetMenus(userID, tenantID, activeRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	// 被拒绝规则覆盖的允许编码不再返回，排序后保证相同权限集合的摘要一致
+	result := &profilePermissions{
+		TenantID:          tenantID,
+		DeniedPermissions: sortedUnique(permissions.policy.Denied()),
+		Menus:             menus,
+	}
+	for _, code := range sortedUnique(permissions.policy.Allowed()) {
+		if !permissions.policy.Denies(code) {
+			result.Permissions = append(result.Permissions, code)
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	result.Version = hex.EncodeToString(sum[:16])
+
+	return result, nil
+}
+
+// sortedUnique 返回去重并排序后的编码副本
+func sortedUnique(codes []string) []string {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	items := append([]string(nil), codes...)
+	sort.Strings(items)
+	unique := items[:1]
+	for _, code := range items[1:] {
+		if code != unique[len(unique)-1] {
+			unique = append(unique, code)
+		}
+	}
+	return unique
 }
 
 // Create 创建用户
@@ -222,6 +759,16 @@ func (s *userService) Create(req *schema.CreateUserRequest) (uint64, error) {
 		return 0, errors.ErrEmailExists
 	}
 
+	// 校验初始角色是否存在、是否属于该租户以及是否违反职责分离规则
+	if len(req.RoleIDs) > 0 {
+		if err := s.validateRoles(req.TenantID, req.RoleIDs); err != nil {
+			return 0, err
+		}
+		if err := s.sod.checkAssignment(0, req.TenantID, req.RoleIDs); err != nil {
+			return 0, err
+		}
+	}
+
 	// 生成密码哈希
 	hashedPassword, err := hash.GeneratePassword(req.Password)
 	if err != nil {
@@ -242,9 +789,9 @@ func (s *userService) Create(req *schema.CreateUserRequest) (uint64, error) {
 
 	// 如果有指定角色，添加角色关联
 	if len(req.RoleIDs) > 0 {
-		if err := s.userRepo.AddRoles(user.ID, req.RoleIDs); err != nil {
-			slog.Error("添加用户角色失败", "error", err)
-			// 不返回错误，继续执行
+		if err := s.userRepo.AddRoles(user.ID, req.TenantID, req.RoleIDs, model.Validity{}); err != nil {
+			slog.Error("添加用户角色失败", "userID", user.ID, "error", err)
+			return 0, err
 		}
 	}
 
@@ -287,6 +834,16 @@ func (s *userService) Update(req *schema.UpdateUserRequest) error {
 		}
 	}
 
+	// 校验新角色是否存在、是否属于该租户以及是否违反职责分离规则
+	if req.RoleIDs != nil {
+		if err := s.validateRoles(req.TenantID, req.RoleIDs); err != nil {
+			return err
+		}
+		if err := s.sod.checkAssignment(req.ID, req.TenantID, req.RoleIDs); err != nil {
+			return err
+		}
+	}
+
 	// 更新用户信息
 	updatedUser := &model.User{
 		ID:       req.ID,
@@ -309,10 +866,11 @@ func (s *userService) Update(req *schema.UpdateUserRequest) error {
 
 	// 如果提供了角色ID，更新用户角色
 	if req.RoleIDs != nil {
-		if err := s.userRepo.UpdateRoles(req.ID, req.RoleIDs); err != nil {
-			slog.Error("更新用户角色失败", "error", err)
-			// 不返回错误，继续执行
+		if err := s.userRepo.UpdateRoles(req.ID, req.TenantID, req.RoleIDs, model.Validity{}); err != nil {
+			slog.Error("更新用户角色失败", "userID", req.ID, "error", err)
+			return err
 		}
+		s.permissionCache.InvalidateUsers(req.ID)
 	}
 
 	return nil
@@ -331,11 +889,17 @@ func (s *userService) Delete(id uint64) error {
 	}
 
 	// 删除用户
-	return s.userRepo.Delete(id)
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(id)
+	return nil
 }
 
-// GetByID 根据ID获取用户
-func (s *userService) GetByID(id uint64) (*schema.UserResponse, error) {
+// GetByID 根据ID获取用户，并返回用户在指定租户内的有效权限授予及其来源，
+// 以及所属的用户组和经由各用户组获得的角色
+func (s *userService) GetByID(id, tenantID uint64) (*schema.UserResponse, error) {
 	// 获取用户信息
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
@@ -346,12 +910,44 @@ func (s *userService) GetByID(id uint64) (*schema.UserResponse, error) {
 		return nil, errors.ErrUserNotFound
 	}
 
-	return s.convertToUserResponse(user), nil
+	permissions, err := s.loadPermissions(id, tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.convertToUserResponse(user)
+	response.Grants = make([]schema.UserPermissionGrant, 0, len(permissions.grants))
+	for _, grant := range permissions.grants {
+		item := schema.UserPermissionGrant{
+			PermissionID:   grant.PermissionID,
+			PermissionCode: grant.PermissionCode,
+			Effect:         grant.Effect,
+			Condition:      grant.Condition,
+			Source:         schema.PermissionSourceDirect,
+		}
+		if !grant.IsDirect() {
+			item.Source = schema.PermissionSourceRole
+			item.Role = &schema.RoleSimple{ID: grant.RoleID, Code: grant.RoleCode, Name: grant.RoleName}
+		}
+		response.Grants = append(response.Grants, item)
+	}
+
+	response.Groups, response.GroupRoles, err = s.loadGroupRoles(id)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 }
 
 // List 获取用户列表，返回完整分页信息
-func (s *userService) List(req *schema.ListUserRequest) (*schema.ListUserResponse, error) {
-	users, total, err := s.userRepo.List(req.Page, req.PageSize, req.Keyword)
+// 只返回调用方在当前租户内的数据范围覆盖的用户，req.TenantID 是额外的租户过滤条件
+func (s *userService) List(req *schema.ListUserRequest, callerID, tenantID uint64) (*schema.ListUserResponse, error) {
+	scope, err := s.userRepo.GetDataScope(callerID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.userRepo.List(req.Page, req.PageSize, req.Keyword, req.TenantID, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -374,8 +970,17 @@ func (s *userService) List(req *schema.ListUserRequest) (*schema.ListUserRespons
 	}, nil
 }
 
-// AssignRole 分配角色给用户
-func (s *userService) AssignRole(userID uint64, roleIDs []uint64) error {
+// AssignRole 替换用户在指定租户内的角色，租户ID为0表示全局分配
+// 可指定分配的生效区间，区间外的分配不参与权限计算
+func (s *userService) AssignRole(req *schema.AssignRoleRequest) error {
+	userID, tenantID, roleIDs := req.UserID, req.TenantID, req.RoleIDs
+
+	// 校验生效区间
+	validity, err := toValidity(req.ValidFrom, req.ValidUntil)
+	if err != nil {
+		return err
+	}
+
 	// 检查用户是否存在
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
@@ -386,7 +991,36 @@ func (s *userService) AssignRole(userID uint64, roleIDs []uint64) error {
 		return errors.ErrUserNotFound
 	}
 
-	// 检查所有角色是否存在
+	if err := s.validateRoles(tenantID, roleIDs); err != nil {
+		return err
+	}
+
+	// 校验职责分离规则
+	if err := s.sod.checkAssignment(userID, tenantID, roleIDs); err != nil {
+		return err
+	}
+
+	// 更新用户角色
+	if err := s.userRepo.UpdateRoles(userID, tenantID, roleIDs, validity); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(userID)
+	return nil
+}
+
+// validateRoles 检查分配所在租户与所有角色是否存在，租户角色只能在所属租户内分配
+func (s *userService) validateRoles(tenantID uint64, roleIDs []uint64) error {
+	if tenantID != model.GlobalTenantID {
+		tenant, err := s.tenantRepo.GetByID(tenantID)
+		if err != nil {
+			return err
+		}
+		if tenant == nil {
+			return errors.ErrTenantNotFound
+		}
+	}
+
 	for _, roleID := range roleIDs {
 		role, err := s.roleRepo.GetByID(roleID)
 		if err != nil {
@@ -395,10 +1029,12 @@ func (s *userService) AssignRole(userID uint64, roleIDs []uint64) error {
 		if role == nil {
 			return errors.ErrRoleNotFound
 		}
+		if role.TenantID != model.GlobalTenantID && role.TenantID != tenantID {
+			return errors.ErrTenantMismatch
+		}
 	}
 
-	// 更新用户角色
-	return s.userRepo.UpdateRoles(userID, roleIDs)
+	return nil
 }
 
 // GetRoles 获取用户的角色列表
@@ -427,6 +1063,7 @@ func (s *userService) GetRoles(userID uint64) ([]schema.RoleResponse, error) {
 			Code:        role.Code,
 			Name:        role.Name,
 			Description: role.Description,
+			TenantID:    role.TenantID,
 			CreatedAt:   role.CreatedAt,
 		})
 	}
@@ -434,6 +1071,137 @@ func (s *userService) GetRoles(userID uint64) ([]schema.RoleResponse, error) {
 	return roles, nil
 }
 
+// AssignPermissions 在指定租户内直接为用户授予权限，租户ID为0表示全局授予
+// 已直接授予的权限只更新效果，其他直接授予保持不变
+func (s *userService) AssignPermissions(req *schema.AssignUserPermissionsRequest) error {
+	if err := s.checkDirectGrantTarget(req.UserID, req.TenantID); err != nil {
+		return err
+	}
+
+	// 检查所有权限是否存在
+	for _, permissionID := range req.PermissionIDs {
+		permission, err := s.permissionRepo.GetByID(permissionID)
+		if err != nil {
+			return err
+		}
+		if permission == nil {
+			return errors.ErrPermissionNotFound
+		}
+	}
+
+	effect := req.Effect
+	if effect == "" {
+		effect = model.EffectAllow
+	}
+	if err := s.userRepo.AddPermissions(req.UserID, req.TenantID, req.PermissionIDs, effect); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(req.UserID)
+	return nil
+}
+
+// RevokePermissions 撤销在指定租户内直接授予用户的权限，经由角色获得的权限不受影响
+func (s *userService) RevokePermissions(req *schema.RevokeUserPermissionsRequest) error {
+	if err := s.checkDirectGrantTarget(req.UserID, req.TenantID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.RemovePermissions(req.UserID, req.TenantID, req.PermissionIDs); err != nil {
+		return err
+	}
+
+	s.permissionCache.InvalidateUsers(req.UserID)
+	return nil
+}
+
+// GetDirectPermissions 获取直接授予用户的全部权限
+func (s *userService) GetDirectPermissions(userID uint64) ([]schema.UserPermissionResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	permissions, err := s.userRepo.GetDirectPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]schema.UserPermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, schema.UserPermissionResponse{
+			PermissionID:   permission.PermissionID,
+			PermissionCode: permission.PermissionCode,
+			PermissionName: permission.PermissionName,
+			TenantID:       permission.TenantID,
+			Effect:         permission.Effect,
+			CreatedAt:      permission.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// checkDirectGrantTarget 校验直接授予的用户与租户存在
+func (s *userService) checkDirectGrantTarget(userID, tenantID uint64) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound
+	}
+
+	if tenantID != model.GlobalTenantID {
+		tenant, err := s.tenantRepo.GetByID(tenantID)
+		if err != nil {
+			return err
+		}
+		if tenant == nil {
+			return errors.ErrTenantNotFound
+		}
+	}
+	return nil
+}
+
+// checkTenantAccess 校验租户存在且用户可以进入该租户
+func (s *userService) checkTenantAccess(userID, tenantID uint64) error {
+	tenant, err := s.tenantRepo.GetByID(tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant == nil {
+		return errors.ErrTenantNotFound
+	}
+
+	ok, err := s.userRepo.HasTenantAccess(userID, tenantID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrTenantForbidden
+	}
+	return nil
+}
+
+// toValidity 将请求中的生效区间转换为模型，0表示不限制
+// 失效时间必须晚于当前时间与生效时间
+func toValidity(validFrom, validUntil int64) (model.Validity, error) {
+	var validity model.Validity
+	if validFrom > 0 {
+		validity.ValidFrom = &validFrom
+	}
+	if validUntil > 0 {
+		if validUntil <= model.NowUnix() || (validFrom > 0 && validUntil <= validFrom) {
+			return model.Validity{}, errors.ErrInvalidRoleValidity
+		}
+		validity.ValidUntil = &validUntil
+	}
+	return validity, nil
+}
+
 // convertToUserResponse 将用户模型转换为响应结构
 func (s *userService) convertToUserResponse(user *model.User) *schema.UserResponse {
 	response := &schema.UserResponse{
@@ -455,3 +1223,102 @@ func (s *userService) convertToUserResponse(user *model.User) *schema.UserRespon
 
 	return response
 }
+
+// evaluateGrants 按拒绝优先的语义判定权限，拒绝时返回产生拒绝的角色
+// 没有属性可用于求值，带条件的允许不生效，带条件的拒绝总是生效
+func evaluateGrants(grants []*model.PermissionGrant, permission string) *schema.CheckPermissionResponse {
+	return decideGrants(grants, permission, func(grant *model.PermissionGrant) bool {
+		return grant.Effect == model.EffectDeny
+	})
+}
+
+// decideGrants 按拒绝优先的语义判定权限，带条件的授予仅在 holds 返回 true 时参与判定
+func decideGrants(grants []*model.PermissionGrant, permission string, holds func(grant *model.PermissionGrant) bool) *schema.CheckPermissionResponse {
+	result := &schema.CheckPermissionResponse{}
+	applies := func(grant *model.PermissionGrant) bool {
+		return permcode.Match(grant.PermissionCode, permission) && (!grant.IsConditional() || holds(grant))
+	}
+
+	// 任一拒绝规则命中即拒绝
+	for _, grant := range grants {
+		if grant.Effect == model.EffectDeny && applies(grant) {
+			if grant.IsDirect() {
+				result.DeniedDirectly = true
+			} else {
+				result.DeniedBy = &schema.RoleSimple{
+					ID:   grant.RoleID,
+					Code: grant.RoleCode,
+					Name: grant.RoleName,
+				}
+			}
+			result.DeniedPermission = grant.PermissionCode
+			return result
+		}
+	}
+
+	for _, grant := range grants {
+		if grant.Effect != model.EffectDeny && applies(grant) {
+			result.HasPermission = true
+			break
+		}
+	}
+
+	return result
+}
+
+// assignmentStatus 判断角色分配在指定时刻的状态
+func assignmentStatus(assignment *model.RoleAssignment, now int64) string {
+	switch {
+	case assignment.RoleDeletedAt != nil:
+		return schema.AssignmentRoleDeleted
+	case assignment.ValidFrom != nil && *assignment.ValidFrom > now:
+		return schema.AssignmentPending
+	case !assignment.ActiveAt(now):
+		return schema.AssignmentExpired
+	default:
+		return schema.AssignmentActive
+	}
+}
+
+// inheritancePaths 广度优先遍历角色继承图，返回从起始角色到每个可达角色（含自身）的最短路径
+func inheritancePaths(roleID uint64, parents map[uint64][]uint64) [][]uint64 {
+	paths := [][]uint64{{roleID}}
+	visited := map[uint64]struct{}{roleID: {}}
+	for i := 0; i < len(paths); i++ {
+		path := paths[i]
+		for _, parentID := range parents[path[len(path)-1]] {
+			if _, ok := visited[parentID]; ok {
+				continue
+			}
+			visited[parentID] = struct{}{}
+
+			next := make([]uint64, len(path), len(path)+1)
+			copy(next, path)
+			paths = append(paths, append(next, parentID))
+		}
+	}
+	return paths
+}
+
+// splitGrants 按授予效果拆分权限编码（已去重）
+// 判定策略不携带属性，带条件的允许不计入，带条件的拒绝按无条件拒绝计入
+func splitGrants(grants []*model.PermissionGrant) (allow, deny []string) {
+	seen := make(map[string]struct{}, len(grants))
+	for _, grant := range grants {
+		if grant.IsConditional() && grant.Effect != model.EffectDeny {
+			continue
+		}
+		key := grant.Effect + "|" + grant.PermissionCode
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if grant.Effect == model.EffectDeny {
+			deny = append(deny, grant.PermissionCode)
+		} else {
+			allow = append(allow, grant.PermissionCode)
+		}
+	}
+	return allow, deny
+}
@@ -0,0 +1,70 @@
+package rbac
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/app"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/relation"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AdminOptions 挂载内置管理接口的选项
+type AdminOptions struct {
+	Prefix        string               // 管理接口前缀，为空时使用 /api/v1
+	Security      SecurityConfig       // 其中 EnforceRoutes 控制是否按方法与路径校验接口权限
+	RoleTemplates []RoleTemplateConfig // 可据此创建角色的角色模板
+	ForwardAuth   ForwardAuthConfig    // 反向代理转发认证的规则表
+	Relations     RelationsConfig      // 资源实例关系授权的对象类型与改写规则
+	AutoMigrate   bool                 // 挂载前迁移表结构并初始化默认数据
+}
+
+// MountAdminRoutes 在宿主应用的路由下挂载内置的认证与管理接口
+// 管理接口与权限判定器共用令牌配置、权限缓存与接口路由表，角色或权限变更会立即反映到判定结果
+func (e *Enforcer) MountAdminRoutes(router fiber.Router, db *gorm.DB, opts AdminOptions) error {
+	relationSchema, err := relation.NewSchema(&opts.Relations)
+	if err != nil {
+		return err
+	}
+
+	if opts.AutoMigrate {
+		if err := model.AutoMigrate(db); err != nil {
+			return err
+		}
+		if err := model.InitDefaultData(db); err != nil {
+			return err
+		}
+	}
+
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = app.APIPrefix
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	sodRepo := repository.NewSoDRepository(db)
+
+	userService := service.NewUserService(userRepo, roleRepo, permissionRepo, tenantRepo, repository.NewRefreshTokenRepository(db), e.jwtConfig, e.cache, e.routes, sodRepo)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, tenantRepo, e.cache)
+	services := &app.Services{
+		User:         userService,
+		Role:         roleService,
+		RoleTemplate: service.NewRoleTemplateService(opts.RoleTemplates, roleService, roleRepo, permissionRepo, e.cache),
+		Permission:   service.NewPermissionService(permissionRepo, e.cache, e.routes),
+		Tenant:       service.NewTenantService(tenantRepo),
+		Policy:       service.NewPolicyService(repository.NewPolicyRepository(db), userRepo, roleRepo, permissionRepo, tenantRepo, e.cache, sodRepo),
+		ForwardAuth:  service.NewForwardAuthService(&opts.ForwardAuth, userRepo, userService, e.jwtConfig),
+		SoD:          service.NewSoDService(sodRepo, roleRepo, userRepo),
+		Relation:     service.NewRelationService(relationSchema, repository.NewRelationRepository(db)),
+		Group:        service.NewGroupService(repository.NewGroupRepository(db), userRepo, roleRepo, tenantRepo, e.cache, sodRepo),
+		OrgUnit:      service.NewOrgUnitService(repository.NewOrgUnitRepository(db), userRepo, roleRepo),
+	}
+
+	app.MountRoutes(router, prefix, services, e.jwtConfig, &opts.Security)
+	return nil
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// decisionCache 按用户、租户与权限编码缓存的判定结果；ttl 小于0时不缓存
+type decisionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	entries map[subjectKey]map[string]decision // 主体 -> 权限编码 -> 判定
+	size    int
+
+	// gen 每次失效时递增，用于丢弃失效前开始请求的结果
+	gen uint64
+}
+
+// decision 单个权限的缓存判定
+type decision struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// newDecisionCache 创建判定结果缓存
+func newDecisionCache(ttl time.Duration, maxEntries int) *decisionCache {
+	return &decisionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[subjectKey]map[string]decision),
+	}
+}
+
+// get 获取未过期的缓存判定
+func (c *decisionCache) get(key subjectKey, code string) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d, ok := c.entries[key][code]
+	if !ok {
+		return false, false
+	}
+	if !c.now().Before(d.expiresAt) {
+		delete(c.entries[key], code)
+		c.size--
+		return false, false
+	}
+	return d.allowed, true
+}
+
+// generation 返回当前失效代数，发送请求前获取并在写入时传回
+func (c *decisionCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// set 写入一次批量检查的判定结果，若请求期间发生过失效则丢弃
+func (c *decisionCache) set(generation uint64, key subjectKey, results map[string]bool) {
+	if c.ttl < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.gen {
+		return
+	}
+
+	now := c.now()
+	// 超出容量时先清理过期条目，仍然超出则整体清空
+	if c.maxEntries > 0 && c.size+len(results) > c.maxEntries {
+		c.removeExpired(now)
+		if c.size+len(results) > c.maxEntries {
+			c.entries = make(map[subjectKey]map[string]decision)
+			c.size = 0
+		}
+	}
+
+	codes, ok := c.entries[key]
+	if !ok {
+		codes = make(map[string]decision, len(results))
+		c.entries[key] = codes
+	}
+	expiresAt := now.Add(c.ttl)
+	for code, allowed := range results {
+		if _, ok := codes[code]; !ok {
+			c.size++
+		}
+		codes[code] = decision{allowed: allowed, expiresAt: expiresAt}
+	}
+}
+
+// invalidateUsers 删除指定用户在所有租户内的缓存判定
+func (c *decisionCache) invalidateUsers(userIDs ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	users := make(map[uint64]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		users[userID] = struct{}{}
+	}
+	for key, codes := range c.entries {
+		if _, ok := users[key.userID]; ok {
+			c.size -= len(codes)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// purge 清空全部缓存判定
+func (c *decisionCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.entries = make(map[subjectKey]map[string]decision)
+	c.size = 0
+}
+
+// removeExpired 删除全部过期条目，调用方需持有锁
+func (c *decisionCache) removeExpired(now time.Time) {
+	for key, codes := range c.entries {
+		for code, d := range codes {
+			if !now.Before(d.expiresAt) {
+				delete(codes, code)
+				c.size--
+			}
+		}
+		if len(codes) == 0 {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package client 是供未嵌入权限引擎的下游服务使用的远程授权客户端。
+//
+// 访问令牌使用与权限服务共享的 JWT 配置在本地校验，权限判定通过
+// POST /api/v1/auth/check-batch 交由权限服务完成：同一用户在同一租户内短时间内的
+// 判定请求会合并为一次批量检查，判定结果按 TTL 缓存，激活不同角色的会话分别缓存。
+// 权限服务不可达或返回异常时判定一律失败，中间件拒绝请求（fail closed）。
+//
+//	c := client.New(client.Options{BaseURL: "http://rbac:8080", JWT: &cfg.JWT})
+//	app.Get("/reports", c.Authenticate(), c.RequirePermission("report:view"), handler)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/pkg/rbac"
+)
+
+// 默认参数
+const (
+	DefaultPrefix      = "/api/v1"
+	DefaultCacheTTL    = 30 * time.Second
+	DefaultTimeout     = 3 * time.Second
+	DefaultBatchWindow = 2 * time.Millisecond
+
+	// maxBatchSize 单次批量检查的权限数量上限，与服务端的参数校验一致
+	maxBatchSize = 200
+)
+
+var (
+	// ErrUnavailable 权限服务不可达或返回了无法识别的响应
+	ErrUnavailable = errors.New("权限服务不可用")
+	// ErrUnauthorized 权限服务拒绝了访问令牌，例如用户已被删除
+	ErrUnauthorized = errors.New("权限服务拒绝了访问令牌")
+)
+
+// Options 远程授权客户端配置
+type Options struct {
+	BaseURL     string          // 权限服务地址，如 http://rbac:8080
+	Prefix      string          // 接口前缀，默认 /api/v1
+	JWT         *rbac.JWTConfig // 与权限服务共享的 JWT 配置，用于本地校验令牌
+	HTTPClient  *http.Client    // 可为nil，默认使用超时为 Timeout 的客户端
+	Timeout     time.Duration   // 单次请求超时，默认3秒
+	CacheTTL    time.Duration   // 判定结果缓存时间，默认30秒，小于0时不缓存
+	MaxEntries  int             // 判定结果缓存的最大条目数，小于等于0时不限制
+	BatchWindow time.Duration   // 合并判定请求的等待时间，默认2毫秒，小于0时不等待
+}
+
+// Client 远程授权客户端，并发安全
+type Client struct {
+	endpoint    string
+	httpClient  *http.Client
+	jwtConfig   *rbac.JWTConfig
+	tokens      *jwt.TokenService
+	batchWindow time.Duration
+	cache       *decisionCache
+
+	mu      sync.Mutex
+	pending map[subjectKey]*batch // 用户、租户与激活角色 -> 等待发送的批量检查
+}
+
+// subjectKey 判定主体，同一用户在不同租户内或激活不同角色的会话中的判定分别合并与缓存
+type subjectKey struct {
+	userID      uint64
+	tenantID    uint64
+	activeRoles string // 会话激活的角色ID，排序后以逗号连接，为空表示激活全部角色
+}
+
+// activeRolesKey 将会话激活的角色转换为与顺序无关的缓存键
+func activeRolesKey(activeRoles []uint64) string {
+	if len(activeRoles) == 0 {
+		return ""
+	}
+
+	ids := append([]uint64(nil), activeRoles...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	parts := make([]string, 0, len(ids))
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.FormatUint(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// batch 一次合并后的批量检查，发送完成后关闭 done
+type batch struct {
+	token   string
+	codes   []string
+	seen    map[string]struct{}
+	done    chan struct{}
+	results map[string]bool
+	err     error
+}
+
+// New 创建远程授权客户端
+func New(opts Options) *Client {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: opts.Timeout}
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.BatchWindow == 0 {
+		opts.BatchWindow = DefaultBatchWindow
+	}
+
+	return &Client{
+		endpoint:    strings.TrimRight(opts.BaseURL, "/") + opts.Prefix + "/auth/check-batch",
+		httpClient:  opts.HTTPClient,
+		jwtConfig:   opts.JWT,
+		tokens:      jwt.NewTokenService(opts.JWT),
+		batchWindow: opts.BatchWindow,
+		cache:       newDecisionCache(opts.CacheTTL, opts.MaxEntries),
+		pending:     make(map[subjectKey]*batch),
+	}
+}
+
+// ValidateToken 在本地校验访问令牌并返回其声明，刷新令牌不能用于访问
+func (c *Client) ValidateToken(token string) (*rbac.Claims, error) {
+	claims, err := c.tokens.ValidateToken(token)
+	if err != nil {
+		if err == jwt.ErrExpiredToken {
+			return nil, rbac.ErrExpiredToken
+		}
+		return nil, rbac.ErrInvalidToken
+	}
+
+	if claims.TokenType != "access" {
+		return nil, rbac.ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// Check 判断令牌所属用户在指定租户内是否具备权限，返回每个权限编码的结果
+// 已缓存的结果直接返回，其余权限与同一用户的并发请求合并后交由权限服务判定
+func (c *Client) Check(ctx context.Context, token string, tenantID uint64, permissions ...string) (map[string]bool, error) {
+	claims, err := c.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key := subjectKey{userID: claims.UserID, tenantID: tenantID, activeRoles: activeRolesKey(claims.ActiveRoles)}
+	results := make(map[string]bool, len(permissions))
+	missing := make([]string, 0, len(permissions))
+	for _, code := range permissions {
+		if allowed, ok := c.cache.get(key, code); ok {
+			results[code] = allowed
+		} else {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	// 超过单次批量上限的权限拆分到多个批次，全部批次返回后汇总结果
+	decisions := make(map[string]bool, len(missing))
+	for _, b := range c.enqueue(key, token, missing) {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+		for code, allowed := range b.results {
+			decisions[code] = allowed
+		}
+	}
+
+	for _, code := range missing {
+		allowed, ok := decisions[code]
+		if !ok {
+			return nil, ErrUnavailable
+		}
+		results[code] = allowed
+	}
+
+	return results, nil
+}
+
+// Allowed 判断令牌所属用户在指定租户内是否具备全部权限
+func (c *Client) Allowed(ctx context.Context, token string, tenantID uint64, permissions ...string) (bool, error) {
+	results, err := c.Check(ctx, token, tenantID, permissions...)
+	if err != nil {
+		return false, err
+	}
+
+	for _, code := range permissions {
+		if !results[code] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Invalidate 清除指定用户在所有租户内的缓存判定
+func (c *Client) Invalidate(userIDs ...uint64) {
+	c.cache.invalidateUsers(userIDs...)
+}
+
+// Purge 清空全部缓存判定
+func (c *Client) Purge() {
+	c.cache.purge()
+}
+
+// enqueue 将权限加入该主体等待发送的批量检查，没有则新建并在合并窗口结束后发送
+// 每个批次最多包含 maxBatchSize 个权限，超出的部分放入新的批次，返回这些权限所在的全部批次
+func (c *Client) enqueue(key subjectKey, token string, codes []string) []*batch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var batches []*batch
+	b := c.pending[key]
+	for _, code := range codes {
+		if b != nil {
+			if _, ok := b.seen[code]; ok {
+				batches = appendBatch(batches, b)
+				continue
+			}
+		}
+		if b == nil || len(b.codes) >= maxBatchSize {
+			b = &batch{token: token, seen: make(map[string]struct{}), done: make(chan struct{})}
+			c.pending[key] = b
+			go c.flush(key, b)
+		}
+		b.seen[code] = struct{}{}
+		b.codes = append(b.codes, code)
+		batches = appendBatch(batches, b)
+	}
+
+	return batches
+}
+
+// appendBatch 将批次追加到列表末尾，与最后一个批次相同时不重复追加
+func appendBatch(batches []*batch, b *batch) []*batch {
+	if len(batches) > 0 && batches[len(batches)-1] == b {
+		return batches
+	}
+	return append(batches, b)
+}
+
+// flush 等待合并窗口结束后发送批量检查，并缓存判定结果
+func (c *Client) flush(key subjectKey, b *batch) {
+	if c.batchWindow > 0 {
+		time.Sleep(c.batchWindow)
+	}
+
+	c.mu.Lock()
+	if c.pending[key] == b {
+		delete(c.pending, key)
+	}
+	c.mu.Unlock()
+
+	generation := c.cache.generation()
+	b.results, b.err = c.send(b.token, key.tenantID, b.codes)
+	if b.err == nil {
+		c.cache.set(generation, key, b.results)
+	}
+	close(b.done)
+}
+
+// send 调用权限服务的批量检查接口
+func (c *Client) send(token string, tenantID uint64, codes []string) (map[string]bool, error) {
+	body, err := json.Marshal(&schema.CheckPermissionsRequest{Permissions: codes})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if tenantID != 0 {
+		req.Header.Set(rbac.TenantHeader, strconv.FormatUint(tenantID, 10))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var result struct {
+		Code    int                             `json:"code"`
+		Message string                          `json:"message"`
+		Data    schema.CheckPermissionsResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	switch result.Code {
+	case response.CodeSuccess:
+		if result.Data.Results == nil {
+			return nil, ErrUnavailable
+		}
+		return result.Data.Results, nil
+	case response.CodeUnauthorized, response.CodeForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, result.Message)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, result.Message)
+	}
+}
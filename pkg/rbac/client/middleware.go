@@ -0,0 +1,89 @@
+package client
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Authenticate 认证中间件，在本地校验 Authorization 头中的访问令牌并将用户与租户写入上下文
+func (c *Client) Authenticate() fiber.Handler {
+	return middleware.Auth(c.jwtConfig)
+}
+
+// RequirePermission 权限校验中间件，要求当前用户具备指定权限，需在 Authenticate 之后使用
+func (c *Client) RequirePermission(permission string) fiber.Handler {
+	return c.RequireAll(permission)
+}
+
+// RequireAny 权限校验中间件，要求当前用户具备任意一个指定权限
+func (c *Client) RequireAny(permissions ...string) fiber.Handler {
+	return c.requirePermissions(permissions, false)
+}
+
+// RequireAll 权限校验中间件，要求当前用户具备全部指定权限
+func (c *Client) RequireAll(permissions ...string) fiber.Handler {
+	return c.requirePermissions(permissions, true)
+}
+
+// requirePermissions 通过权限服务校验当前用户的权限，权限服务不可用时拒绝请求
+func (c *Client) requirePermissions(permissions []string, all bool) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 必须在认证中间件之后使用
+		userID := middleware.GetUserID(ctx)
+		if userID == 0 {
+			return response.Unauthorized(ctx, "未授权的访问")
+		}
+
+		token := strings.TrimPrefix(ctx.Get("Authorization"), "Bearer ")
+		tenantID := middleware.GetTenantID(ctx)
+		results, err := c.Check(ctx.UserContext(), token, tenantID, permissions...)
+		if err != nil {
+			if errors.Is(err, ErrUnauthorized) {
+				return response.Unauthorized(ctx, "")
+			}
+			slog.Error("远程权限校验失败", "userID", userID, "tenantID", tenantID, "error", err)
+			return response.ServerError(ctx, "权限校验失败")
+		}
+
+		if !matchResults(results, permissions, all) {
+			slog.Warn("权限不足，拒绝访问", "userID", userID, "tenantID", tenantID, "path", ctx.Path(), "required", permissions)
+			return response.Forbidden(ctx, "")
+		}
+
+		return ctx.Next()
+	}
+}
+
+// matchResults 按匹配模式汇总判定结果，未要求任何权限时放行
+func matchResults(results map[string]bool, required []string, all bool) bool {
+	if len(required) == 0 {
+		return true
+	}
+	for _, code := range required {
+		if results[code] != all {
+			return !all
+		}
+	}
+	return all
+}
+
+// UserID 从上下文中获取认证中间件写入的用户ID，未认证时为0
+func UserID(c *fiber.Ctx) uint64 {
+	return middleware.GetUserID(c)
+}
+
+// Username 从上下文中获取认证中间件写入的用户名
+func Username(c *fiber.Ctx) string {
+	return middleware.GetUsername(c)
+}
+
+// TenantID 从上下文中获取当前租户ID，0表示未选择租户
+func TenantID(c *fiber.Ctx) uint64 {
+	return middleware.GetTenantID(c)
+}
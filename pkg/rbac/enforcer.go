@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+)
+
+// Enforcer 权限判定器，校验访问令牌并判定用户在租户内是否具备权限
+type Enforcer struct {
+	jwtConfig *JWTConfig
+	tokens    *jwt.TokenService
+	cache     *PermissionCache
+	routes    *service.RouteTable
+	resolver  *service.PermissionResolver
+}
+
+// routeTableTTL 接口路由表的最长保留时间。通过 MountAdminRoutes 挂载的管理接口变更接口权限时路由表立即重建，
+// 其他进程修改的接口权限在该时间内生效
+const routeTableTTL = time.Minute
+
+// NewPermissionCache 创建用户有效权限缓存，maxEntries 小于等于0时不限制容量
+func NewPermissionCache(ttl time.Duration, maxEntries int) *PermissionCache {
+	return service.NewPermissionCache(ttl, maxEntries)
+}
+
+// NewEnforcer 创建权限判定器
+func NewEnforcer(
+	repo Repository,
+	jwtConfig *JWTConfig,
+	cache *PermissionCache, // 可为nil，表示不缓存有效权限
+) *Enforcer {
+	routes := service.NewRouteTable(repo, routeTableTTL)
+	return &Enforcer{
+		jwtConfig: jwtConfig,
+		tokens:    jwt.NewTokenService(jwtConfig),
+		cache:     cache,
+		routes:    routes,
+		resolver:  service.NewPermissionResolver(repo, routes, cache),
+	}
+}
+
+// ValidateToken 校验访问令牌并返回其声明，刷新令牌不能用于访问
+func (e *Enforcer) ValidateToken(token string) (*Claims, error) {
+	claims, err := e.tokens.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != "access" {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// GetPermissionPolicy 获取用户在指定租户内的权限判定策略
+func (e *Enforcer) GetPermissionPolicy(userID, tenantID uint64) (*Policy, error) {
+	return e.resolver.GetPermissionPolicy(userID, tenantID)
+}
+
+// GetSessionPolicy 获取会话仅激活部分角色时的权限判定策略，activeRoles 为空时等同于 GetPermissionPolicy
+func (e *Enforcer) GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*Policy, error) {
+	return e.resolver.GetSessionPolicy(userID, tenantID, activeRoles)
+}
+
+// GetRoutePermissions 获取方法与路径模式匹配指定请求的接口权限编码
+func (e *Enforcer) GetRoutePermissions(method, path string) ([]string, error) {
+	return e.resolver.GetRoutePermissions(method, path)
+}
+
+// Check 判断用户在指定租户内是否具备全部权限，权限编码可包含通配符
+func (e *Enforcer) Check(userID, tenantID uint64, permissions ...string) (bool, error) {
+	policy, err := e.GetPermissionPolicy(userID, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	return policy.AllowsAll(permissions...), nil
+}
+
+// Cache 返回权限判定器使用的缓存，未启用缓存时为nil
+func (e *Enforcer) Cache() *PermissionCache {
+	return e.cache
+}
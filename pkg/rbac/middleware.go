@@ -0,0 +1,56 @@
+package rbac
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantHeader 指定当前租户的请求头，令牌未携带租户声明时生效
+const TenantHeader = middleware.TenantHeader
+
+// Authenticate 认证中间件，校验 Authorization 头中的访问令牌并将用户与租户写入上下文
+func (e *Enforcer) Authenticate() fiber.Handler {
+	return middleware.Auth(e.jwtConfig)
+}
+
+// RequirePermission 权限校验中间件，要求当前用户具备指定权限，需在 Authenticate 之后使用
+func (e *Enforcer) RequirePermission(permission string) fiber.Handler {
+	return middleware.RequirePermission(e, permission)
+}
+
+// RequireAny 权限校验中间件，要求当前用户具备任意一个指定权限
+func (e *Enforcer) RequireAny(permissions ...string) fiber.Handler {
+	return middleware.RequireAny(e, permissions...)
+}
+
+// RequireAll 权限校验中间件，要求当前用户具备全部指定权限
+func (e *Enforcer) RequireAll(permissions ...string) fiber.Handler {
+	return middleware.RequireAll(e, permissions...)
+}
+
+// RequireRoute 按请求方法与路径匹配接口权限的中间件，未匹配任何接口权限的请求一律拒绝
+func (e *Enforcer) RequireRoute() fiber.Handler {
+	return middleware.RequireRoute(e)
+}
+
+// UserID 从上下文中获取认证中间件写入的用户ID，未认证时为0
+func UserID(c *fiber.Ctx) uint64 {
+	return middleware.GetUserID(c)
+}
+
+// Username 从上下文中获取认证中间件写入的用户名
+func Username(c *fiber.Ctx) string {
+	return middleware.GetUsername(c)
+}
+
+// TenantID 从上下文中获取当前租户ID，0表示未选择租户
+func TenantID(c *fiber.Ctx) uint64 {
+	return middleware.GetTenantID(c)
+}
+
+// ActiveRoles 从上下文中获取会话激活的角色ID，为空表示激活全部已分配的角色，
+// 可与 Enforcer.GetSessionPolicy 配合在处理器中自行判定
+func ActiveRoles(c *fiber.Ctx) []uint64 {
+	return middleware.GetActiveRoles(c)
+}
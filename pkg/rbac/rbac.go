@@ -0,0 +1,107 @@
+// Package rbac 将 RBAC 系统以库的形式嵌入到其他 Go 服务中
+//
+// Enforcer 基于 Repository 解析用户的有效权限，提供令牌校验与 Fiber 权限中间件；
+// 宿主服务还可通过 Enforcer.MountAdminRoutes 在自己的路由下挂载内置的管理接口，
+// 无需单独部署 RBAC 服务。
+package rbac
+
+import (
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"gorm.io/gorm"
+)
+
+// 对外公开的内部类型
+type (
+	// JWTConfig 令牌配置，需与签发令牌的 RBAC 服务一致
+	JWTConfig = config.JWTConfig
+	// SecurityConfig 安全配置
+	SecurityConfig = config.SecurityConfig
+	// RoleTemplateConfig 角色模板配置
+	RoleTemplateConfig = config.RoleTemplateConfig
+	// ForwardAuthConfig 反向代理转发认证配置
+	ForwardAuthConfig = config.ForwardAuthConfig
+	// ForwardAuthRule 转发认证规则
+	ForwardAuthRule = config.ForwardAuthRule
+	// RelationsConfig 关系授权配置
+	RelationsConfig = config.RelationsConfig
+	// Claims 访问令牌声明
+	Claims = jwt.Claims
+	// Policy 用户在租户内的权限判定策略，拒绝优先于允许
+	Policy = permcode.Policy
+	// Grant 用户经由角色或直接获得的一条权限授予
+	Grant = model.PermissionGrant
+	// Permission 权限
+	Permission = model.Permission
+	// RoleInheritance 有效角色与其直接上级角色的继承关系
+	RoleInheritance = model.RoleInheritance
+	// PermissionCache 用户有效权限缓存
+	PermissionCache = service.PermissionCache
+)
+
+// 令牌校验错误
+var (
+	ErrInvalidToken     = jwt.ErrInvalidToken
+	ErrExpiredToken     = jwt.ErrExpiredToken
+	ErrInvalidTokenType = errors.ErrInvalidTokenType
+)
+
+// Repository 权限判定所需的数据来源
+// NewRepository 返回基于内置数据表的实现，宿主服务也可以自行实现
+type Repository interface {
+	// GetEffectivePermissionGrants 获取用户在租户内生效的全部权限授予，租户ID为0时仅计入全局授予
+	GetEffectivePermissionGrants(userID, tenantID uint64) ([]*Grant, error)
+	// GetEffectiveRoleIDs 获取用户在租户内生效的角色ID（含继承），用于缓存失效
+	GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error)
+	// GetNextValidityChange 获取用户的角色分配下一次生效或失效的时间戳，0表示没有
+	GetNextValidityChange(userID, tenantID uint64) (int64, error)
+	// GetEffectiveRoleParents 获取用户有效角色之间的继承关系，用于只激活部分角色的会话
+	GetEffectiveRoleParents(userID, tenantID uint64) ([]*RoleInheritance, error)
+	// ListRoutes 获取携带方法与路径模式的接口权限
+	ListRoutes() ([]*Permission, error)
+}
+
+// gormRepository 基于内置数据表的 Repository 实现
+type gormRepository struct {
+	users       repository.UserRepository
+	permissions repository.PermissionRepository
+}
+
+// NewRepository 基于内置数据表创建 Repository，db 需已迁移 RBAC 表结构
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{
+		users:       repository.NewUserRepository(db),
+		permissions: repository.NewPermissionRepository(db),
+	}
+}
+
+// GetEffectivePermissionGrants 获取用户在租户内生效的全部权限授予
+func (r *gormRepository) GetEffectivePermissionGrants(userID, tenantID uint64) ([]*Grant, error) {
+	return r.users.GetEffectivePermissionGrants(userID, tenantID)
+}
+
+// GetEffectiveRoleIDs 获取用户在租户内生效的角色ID
+func (r *gormRepository) GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error) {
+	return r.users.GetEffectiveRoleIDs(userID, tenantID)
+}
+
+// GetNextValidityChange 获取用户的角色分配下一次生效或失效的时间戳
+func (r *gormRepository) GetNextValidityChange(userID, tenantID uint64) (int64, error) {
+	return r.users.GetNextValidityChange(userID, tenantID)
+}
+
+// GetEffectiveRoleParents 获取用户有效角色之间的继承关系
+func (r *gormRepository) GetEffectiveRoleParents(userID, tenantID uint64) ([]*RoleInheritance, error) {
+	return r.users.GetEffectiveRoleParents(userID, tenantID)
+}
+
+// ListRoutes 获取携带方法与路径模式的接口权限
+func (r *gormRepository) ListRoutes() ([]*Permission, error) {
+	return r.permissions.ListRoutes()
+}
@@ -0,0 +1,95 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/handler/auth"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试转发认证以HTTP状态码与响应头返回判定结果
+func TestForwardHandler(t *testing.T) {
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserService := new(mocks.MockUserService)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "analyst"}, nil)
+	mockUserRepo.On("GetEffectiveRoles", uint64(1), uint64(0)).Return([]*model.Role{
+		{ID: 2, Code: "analyst"},
+		{ID: 3, Code: "viewer"},
+	}, nil)
+	mockUserService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(permcode.NewPolicy([]string{"report:view"}, nil), nil)
+
+	forwardAuthService := service.NewForwardAuthService(&config.ForwardAuthConfig{
+		Rules: []config.ForwardAuthRule{
+			{Method: "GET", Path: "/reports/*", Permissions: []string{"report:view"}},
+			{Method: "DELETE", Path: "/reports/*", Permissions: []string{"report:delete"}},
+		},
+	}, mockUserRepo, mockUserService, jwtConfig)
+
+	app := fiber.New()
+	handler := auth.NewForwardHandler(forwardAuthService)
+	app.All("/auth/forward", handler.Handle)
+	app.All("/auth/forward/*", handler.Handle)
+
+	token, err := jwt.NewTokenService(jwtConfig).GenerateToken(1, "analyst", "access")
+	require.NoError(t, err)
+
+	call := func(method, path string, headers map[string]string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("放行时返回用户信息", func(t *testing.T) {
+		resp := call("GET", "/auth/forward", map[string]string{
+			"Authorization":            "Bearer " + token,
+			auth.ForwardedMethodHeader: "GET",
+			auth.ForwardedURIHeader:    "/reports/1?format=csv",
+		})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get(auth.AuthUserIDHeader))
+		assert.Equal(t, "analyst", resp.Header.Get(auth.AuthUsernameHeader))
+		assert.Equal(t, "analyst,viewer", resp.Header.Get(auth.AuthRolesHeader))
+	})
+
+	t.Run("未携带令牌", func(t *testing.T) {
+		resp := call("GET", "/auth/forward", map[string]string{
+			auth.ForwardedMethodHeader: "GET",
+			auth.ForwardedURIHeader:    "/reports/1",
+		})
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("权限不足", func(t *testing.T) {
+		resp := call("GET", "/auth/forward", map[string]string{
+			"Authorization":            "Bearer " + token,
+			auth.ForwardedMethodHeader: "DELETE",
+			auth.ForwardedURIHeader:    "/reports/1",
+		})
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(auth.AuthUserIDHeader))
+	})
+
+	t.Run("未提供转发头时使用本请求的方法与剩余路径", func(t *testing.T) {
+		resp := call("DELETE", "/auth/forward/reports/1", map[string]string{"Authorization": "Bearer " + token})
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+		resp = call("GET", "/auth/forward/reports/1", map[string]string{"Authorization": "Bearer " + token})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/handler/auth"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProfileApp 创建挂载个人信息处理器的应用，跳过认证直接注入用户ID
+func newProfileApp(userService *mocks.MockUserService) *fiber.App {
+	app := fiber.New()
+	app.Post("/profile", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint64(1))
+		c.Locals("tenantID", uint64(0))
+		return c.Next()
+	}, auth.NewProfileHandler(userService).Handle)
+	return app
+}
+
+func TestProfileHandler_PermissionVersion(t *testing.T) {
+	userService := new(mocks.MockUserService)
+	userService.On("GetPermissionVersion", uint64(1), uint64(0), []uint64(nil)).Return("v2", nil)
+	userService.On("GetProfile", uint64(1), uint64(0), []uint64(nil)).Return(&schema.UserResponse{ID: 1, Permissions: []string{"user:list"}, PermissionVersion: "v2"}, nil)
+	app := newProfileApp(userService)
+
+	t.Run("不携带版本时返回完整信息", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("POST", "/profile", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"v2"`, resp.Header.Get(fiber.HeaderETag))
+
+		var res struct {
+			Data schema.UserResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		assert.Equal(t, []string{"user:list"}, res.Data.Permissions)
+	})
+
+	t.Run("请求体版本一致时只返回未变化", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/profile", strings.NewReader(`{"permission_version":"v2"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var res struct {
+			Data schema.ProfileUnchangedResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		assert.True(t, res.Data.Unchanged)
+		assert.Equal(t, "v2", res.Data.PermissionVersion)
+	})
+
+	t.Run("If-None-Match 一致时返回304", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/profile", nil)
+		req.Header.Set(fiber.HeaderIfNoneMatch, `"v2"`)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("版本过期时返回完整信息", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/profile", strings.NewReader(`{"permission_version":"v1"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var res struct {
+			Data schema.UserResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		assert.Equal(t, "v2", res.Data.PermissionVersion)
+	})
+}
@@ -0,0 +1,223 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// 创建权限校验测试应用，userID为0表示未登录
+func createPermissionTestApp(userID uint64, guards ...fiber.Handler) *fiber.App {
+	app := fiber.New()
+
+	// 模拟认证中间件写入用户ID
+	app.Use(func(c *fiber.Ctx) error {
+		if userID != 0 {
+			c.Locals("userID", userID)
+		}
+		return c.Next()
+	})
+
+	handlers := append(guards, func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	app.Get("/test", handlers...)
+
+	return app
+}
+
+// 发送测试请求并解析响应
+func doPermissionRequest(t *testing.T, app *fiber.App) (int, *response.Response) {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.NoError(t, err)
+
+	if resp.Header.Get("Content-Type") != fiber.MIMEApplicationJSON {
+		return resp.StatusCode, nil
+	}
+
+	var res response.Response
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	return resp.StatusCode, &res
+}
+
+// 测试权限校验中间件
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       uint64
+		granted      []string
+		denied       []string
+		resolveErr   error
+		guard        func(userService *mocks.MockUserService) fiber.Handler
+		expectedCode int // 0 表示放行
+	}{
+		{
+			name:    "具备权限时放行",
+			userID:  1,
+			granted: []string{"user:list", "user:delete"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:delete")
+			},
+		},
+		{
+			name:    "缺少权限时拒绝",
+			userID:  1,
+			granted: []string{"user:list"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:delete")
+			},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:    "RequireAny具备任一权限时放行",
+			userID:  1,
+			granted: []string{"role:list"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAny(userService, "role:update", "role:list")
+			},
+		},
+		{
+			name:    "RequireAny均不具备时拒绝",
+			userID:  1,
+			granted: []string{"user:list"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAny(userService, "role:update", "role:list")
+			},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:    "RequireAll全部具备时放行",
+			userID:  1,
+			granted: []string{"role:list", "role:update"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAll(userService, "role:update", "role:list")
+			},
+		},
+		{
+			name:    "RequireAll缺少任一权限时拒绝",
+			userID:  1,
+			granted: []string{"role:list"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAll(userService, "role:update", "role:list")
+			},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:    "通配符权限覆盖具体操作",
+			userID:  1,
+			granted: []string{"user:*"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAll(userService, "user:list", "user:update")
+			},
+		},
+		{
+			name:    "通配符权限不跨资源",
+			userID:  1,
+			granted: []string{"user:*"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "role:list")
+			},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:    "首段通配符匹配任意资源",
+			userID:  1,
+			granted: []string{"*:list"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAny(userService, "role:update", "role:list")
+			},
+		},
+		{
+			name:    "拒绝规则优先于通配符允许",
+			userID:  1,
+			granted: []string{"user:*"},
+			denied:  []string{"user:delete"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:delete")
+			},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:    "拒绝规则不影响其他权限",
+			userID:  1,
+			granted: []string{"user:*"},
+			denied:  []string{"user:delete"},
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequireAny(userService, "user:delete", "user:update")
+			},
+		},
+		{
+			name:   "未登录时返回未授权",
+			userID: 0,
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:list")
+			},
+			expectedCode: response.CodeUnauthorized,
+		},
+		{
+			name:       "用户不存在时返回未授权",
+			userID:     1,
+			resolveErr: errors.ErrUserNotFound,
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:list")
+			},
+			expectedCode: response.CodeUnauthorized,
+		},
+		{
+			name:       "解析权限失败时返回服务器错误",
+			userID:     1,
+			resolveErr: errors.ErrDB,
+			guard: func(userService *mocks.MockUserService) fiber.Handler {
+				return middleware.RequirePermission(userService, "user:list")
+			},
+			expectedCode: response.CodeServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			userService := new(mocks.MockUserService)
+			if tt.resolveErr != nil {
+				userService.On("GetPermissionPolicy", tt.userID, uint64(0)).Return(nil, tt.resolveErr)
+			} else if tt.userID != 0 {
+				userService.On("GetPermissionPolicy", tt.userID, uint64(0)).Return(permcode.NewPolicy(tt.granted, tt.denied), nil)
+			}
+
+			app := createPermissionTestApp(tt.userID, tt.guard(userService))
+			status, res := doPermissionRequest(t, app)
+
+			assert.Equal(t, http.StatusOK, status)
+			if tt.expectedCode == 0 {
+				assert.Nil(t, res)
+			} else {
+				assert.NotNil(t, res)
+				assert.Equal(t, tt.expectedCode, res.Code)
+			}
+		})
+	}
+}
+
+// 测试同一请求内多个权限中间件只解析一次权限
+func TestRequirePermission_ResolveOncePerRequest(t *testing.T) {
+	userService := new(mocks.MockUserService)
+	userService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(permcode.NewPolicy([]string{"user:list", "user:update"}, nil), nil).Once()
+
+	app := createPermissionTestApp(1,
+		middleware.RequirePermission(userService, "user:list"),
+		middleware.RequirePermission(userService, "user:update"),
+	)
+	_, res := doPermissionRequest(t, app)
+
+	assert.Nil(t, res)
+	userService.AssertNumberOfCalls(t, "GetPermissionPolicy", 1)
+}
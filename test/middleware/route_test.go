@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试按请求方法与路径校验接口权限的中间件
+func TestRequireRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       uint64
+		matched      []string
+		matchErr     error
+		granted      []string
+		denied       []string
+		expectedCode int // 0 表示放行
+	}{
+		{
+			name:    "被允许匹配的接口权限时放行",
+			userID:  1,
+			matched: []string{"user:read"},
+			granted: []string{"user:read"},
+		},
+		{
+			name:    "被允许任一匹配的接口权限时放行",
+			userID:  1,
+			matched: []string{"api:get:test", "user:read"},
+			granted: []string{"user:read"},
+		},
+		{
+			name:    "通配符授予全部接口",
+			userID:  1,
+			matched: []string{"api:get:test"},
+			granted: []string{"api:*"},
+		},
+		{
+			name:         "未被允许时拒绝",
+			userID:       1,
+			matched:      []string{"user:read"},
+			granted:      []string{"user:list"},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:         "任一匹配的接口权限被拒绝时拒绝",
+			userID:       1,
+			matched:      []string{"api:get:test", "user:read"},
+			granted:      []string{"user:read"},
+			denied:       []string{"api:get:test"},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:         "未匹配任何接口权限时拒绝",
+			userID:       1,
+			matched:      nil,
+			granted:      []string{"api:*"},
+			expectedCode: response.CodeForbidden,
+		},
+		{
+			name:         "未登录时返回未授权",
+			userID:       0,
+			expectedCode: response.CodeUnauthorized,
+		},
+		{
+			name:         "匹配接口权限失败时返回服务器错误",
+			userID:       1,
+			matchErr:     errors.ErrDB,
+			expectedCode: response.CodeServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			userService := new(mocks.MockUserService)
+			userService.On("GetRoutePermissions", fiber.MethodGet, "/test").Return(tt.matched, tt.matchErr)
+			userService.On("GetPermissionPolicy", tt.userID, uint64(0)).Return(permcode.NewPolicy(tt.granted, tt.denied), nil)
+
+			app := createPermissionTestApp(tt.userID, middleware.RequireRoute(userService))
+			status, res := doPermissionRequest(t, app)
+
+			assert.Equal(t, http.StatusOK, status)
+			if tt.expectedCode == 0 {
+				assert.Nil(t, res)
+			} else {
+				assert.NotNil(t, res)
+				assert.Equal(t, tt.expectedCode, res.Code)
+			}
+			if len(tt.matched) == 0 {
+				userService.AssertNotCalled(t, "GetPermissionPolicy", tt.userID, uint64(0))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试令牌只激活部分角色时按会话权限校验
+func TestRequirePermission_ActiveRoles(t *testing.T) {
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600}
+	tokenService := jwt.NewTokenService(jwtConfig)
+
+	tests := []struct {
+		name        string
+		activeRoles []uint64
+		allowed     bool
+	}{
+		{name: "激活全部角色时使用完整权限", allowed: true},
+		{name: "只激活部分角色时使用会话权限", activeRoles: []uint64{10}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			userService := new(mocks.MockUserService)
+			userService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(permcode.NewPolicy([]string{"payment:approve"}, nil), nil)
+			userService.On("GetSessionPolicy", uint64(1), uint64(0), []uint64{10}).Return(permcode.NewPolicy([]string{"payment:create"}, nil), nil)
+
+			app := fiber.New()
+			app.Get("/test", middleware.Auth(jwtConfig), middleware.RequirePermission(userService, "payment:approve"), func(c *fiber.Ctx) error {
+				return c.SendString("OK")
+			})
+
+			token, err := tokenService.GenerateSessionToken(1, 0, tt.activeRoles, "alice", "access")
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, resp.Header.Get("Content-Type") != fiber.MIMEApplicationJSON)
+		})
+	}
+}
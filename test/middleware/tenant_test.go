@@ -0,0 +1,138 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/middleware"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试认证中间件解析当前租户
+func TestAuth_TenantContext(t *testing.T) {
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600}
+	tokenService := jwt.NewTokenService(jwtConfig)
+
+	tests := []struct {
+		name           string
+		tokenTenantID  uint64
+		header         string
+		expectedCode   int // 0 表示放行
+		expectedTenant uint64
+	}{
+		{name: "未指定租户"},
+		{name: "使用令牌中的租户", tokenTenantID: 7, expectedTenant: 7},
+		{name: "令牌无租户时使用请求头", header: "8", expectedTenant: 8},
+		{name: "请求头与令牌一致", tokenTenantID: 7, header: "7", expectedTenant: 7},
+		{name: "请求头与令牌不一致", tokenTenantID: 7, header: "8", expectedCode: response.CodeForbidden},
+		{name: "请求头格式错误", header: "acme", expectedCode: response.CodeParamError},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/test", middleware.Auth(jwtConfig), func(c *fiber.Ctx) error {
+				return c.SendString(strconv.FormatUint(middleware.GetTenantID(c), 10))
+			})
+
+			token, err := tokenService.GenerateTenantToken(1, tt.tokenTenantID, "alice", "access")
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			if tt.header != "" {
+				req.Header.Set(middleware.TenantHeader, tt.header)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if tt.expectedCode == 0 {
+				assert.Equal(t, strconv.FormatUint(tt.expectedTenant, 10), string(body))
+				return
+			}
+			var res response.Response
+			require.NoError(t, json.Unmarshal(body, &res))
+			assert.Equal(t, tt.expectedCode, res.Code)
+		})
+	}
+}
+
+// 测试跨租户操作只认可全局分配获得的权限
+func TestAllowTenant(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentTenant uint64
+		targetTenant  uint64
+		tenantGranted []string // 当前租户内（含全局分配）的权限
+		globalGranted []string // 仅全局分配的权限
+		expected      bool
+	}{
+		{name: "操作当前租户", currentTenant: 7, targetTenant: 7, tenantGranted: []string{"user:update"}, expected: true},
+		{name: "租户内授权不能操作其他租户", currentTenant: 7, targetTenant: 8, tenantGranted: []string{"user:update"}},
+		{name: "租户内授权不能进行全局分配", currentTenant: 7, targetTenant: 0, tenantGranted: []string{"user:update"}},
+		{name: "全局授权可以操作其他租户", currentTenant: 7, targetTenant: 8, tenantGranted: []string{"user:update"}, globalGranted: []string{"user:update"}, expected: true},
+		{name: "未选择租户时按全局授权操作租户", targetTenant: 8, globalGranted: []string{"user:update"}, tenantGranted: []string{"user:update"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			userService := new(mocks.MockUserService)
+			userService.On("GetPermissionPolicy", uint64(1), tt.currentTenant).Return(permcode.NewPolicy(tt.tenantGranted, nil), nil)
+			if tt.currentTenant != 0 {
+				userService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(permcode.NewPolicy(tt.globalGranted, nil), nil)
+			}
+
+			app := fiber.New()
+			app.Get("/test", func(c *fiber.Ctx) error {
+				c.Locals("userID", uint64(1))
+				c.Locals("tenantID", tt.currentTenant)
+				return c.Next()
+			}, middleware.RequirePermission(userService, "user:update"), func(c *fiber.Ctx) error {
+				allowed, err := middleware.AllowTenant(c, userService, tt.targetTenant)
+				require.NoError(t, err)
+				return c.SendString(strconv.FormatBool(allowed))
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/test", nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.FormatBool(tt.expected), string(body))
+		})
+	}
+}
+
+// 测试未经过权限中间件的请求不允许跨租户操作
+func TestAllowTenant_WithoutGuard(t *testing.T) {
+	userService := new(mocks.MockUserService)
+	app := fiber.New()
+	app.Get("/test", func(c *fiber.Ctx) error {
+		c.Locals("userID", uint64(1))
+		allowed, err := middleware.AllowTenant(c, userService, 8)
+		require.NoError(t, err)
+		return c.SendString(strconv.FormatBool(allowed))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/test", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "false", string(body))
+	userService.AssertNotCalled(t, "GetPermissionPolicy", mock.Anything, mock.Anything)
+}
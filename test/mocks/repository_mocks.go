@@ -50,26 +50,34 @@ func (m *MockUserRepository) GetByEmail(email string) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *MockUserRepository) List(page, pageSize int, keyword string) ([]*model.User, int64, error) {
-	args := m.Called(page, pageSize, keyword)
+func (m *MockUserRepository) List(page, pageSize int, keyword string, tenantID uint64, scope *model.DataScope) ([]*model.User, int64, error) {
+	args := m.Called(page, pageSize, keyword, tenantID, scope)
 	return args.Get(0).([]*model.User), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockUserRepository) AddRoles(userID uint64, roleIDs []uint64) error {
-	args := m.Called(userID, roleIDs)
+func (m *MockUserRepository) AddRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error {
+	args := m.Called(userID, tenantID, roleIDs, validity)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) RemoveRoles(userID uint64, roleIDs []uint64) error {
-	args := m.Called(userID, roleIDs)
+func (m *MockUserRepository) RemoveRoles(userID, tenantID uint64, roleIDs []uint64) error {
+	args := m.Called(userID, tenantID, roleIDs)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) UpdateRoles(userID uint64, roleIDs []uint64) error {
-	args := m.Called(userID, roleIDs)
+func (m *MockUserRepository) UpdateRoles(userID, tenantID uint64, roleIDs []uint64, validity model.Validity) error {
+	args := m.Called(userID, tenantID, roleIDs, validity)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) DeleteExpiredRoles(now int64) ([]model.UserRole, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserRole), args.Error(1)
+}
+
 func (m *MockUserRepository) GetUserWithRoles(userID uint64) (*model.User, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
@@ -78,6 +86,106 @@ func (m *MockUserRepository) GetUserWithRoles(userID uint64) (*model.User, error
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) HasTenantAccess(userID, tenantID uint64) (bool, error) {
+	args := m.Called(userID, tenantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *MockUserRepository) GetNextValidityChange(userID, tenantID uint64) (int64, error) {
+	args := m.Called(userID, tenantID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) GetRoleAssignments(userID, tenantID uint64) ([]*model.RoleAssignment, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoleAssignment), args.Error(1)
+}
+
+func (m *MockUserRepository) GetEffectiveRoles(userID, tenantID uint64) ([]*model.Role, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Role), args.Error(1)
+}
+
+func (m *MockUserRepository) GetEffectiveRoleParents(userID, tenantID uint64) ([]*model.RoleInheritance, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoleInheritance), args.Error(1)
+}
+
+func (m *MockUserRepository) AddPermissions(userID, tenantID uint64, permissionIDs []uint64, effect string) error {
+	args := m.Called(userID, tenantID, permissionIDs, effect)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RemovePermissions(userID, tenantID uint64, permissionIDs []uint64) error {
+	args := m.Called(userID, tenantID, permissionIDs)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetDirectPermissions(userID uint64) ([]*model.UserPermissionDetail, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.UserPermissionDetail), args.Error(1)
+}
+
+func (m *MockUserRepository) GetGroups(userID uint64) ([]*model.Group, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Group), args.Error(1)
+}
+
+func (m *MockUserRepository) GetEffectiveGroupParents(userID uint64) ([]*model.GroupInheritance, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupInheritance), args.Error(1)
+}
+
+func (m *MockUserRepository) GetGroupRoleAssignments(userID uint64) ([]*model.GroupRoleAssignment, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupRoleAssignment), args.Error(1)
+}
+
+func (m *MockUserRepository) GetDataScope(userID, tenantID uint64) (*model.DataScope, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DataScope), args.Error(1)
+}
+
+func (m *MockUserRepository) GetEffectivePermissionGrants(userID, tenantID uint64) ([]*model.PermissionGrant, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PermissionGrant), args.Error(1)
+}
+
 // MockRoleRepository 角色仓库的模拟实现
 type MockRoleRepository struct {
 	mock.Mock
@@ -106,24 +214,32 @@ func (m *MockRoleRepository) GetByID(id uint64) (*model.Role, error) {
 	return args.Get(0).(*model.Role), args.Error(1)
 }
 
-func (m *MockRoleRepository) GetByCode(code string) (*model.Role, error) {
-	args := m.Called(code)
+func (m *MockRoleRepository) GetByCode(code string, tenantID uint64) (*model.Role, error) {
+	args := m.Called(code, tenantID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Role), args.Error(1)
 }
 
-func (m *MockRoleRepository) GetByName(name string) (*model.Role, error) {
-	args := m.Called(name)
+func (m *MockRoleRepository) ListByCode(code string) ([]*model.Role, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByName(name string, tenantID uint64) (*model.Role, error) {
+	args := m.Called(name, tenantID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Role), args.Error(1)
 }
 
-func (m *MockRoleRepository) List(page, pageSize int, keyword string) ([]*model.Role, int64, error) {
-	args := m.Called(page, pageSize, keyword)
+func (m *MockRoleRepository) List(page, pageSize int, keyword string, tenantID uint64) ([]*model.Role, int64, error) {
+	args := m.Called(page, pageSize, keyword, tenantID)
 	return args.Get(0).([]*model.Role), args.Get(1).(int64), args.Error(2)
 }
 
@@ -142,6 +258,11 @@ func (m *MockRoleRepository) UpdatePermissions(roleID uint64, permissionIDs []ui
 	return args.Error(0)
 }
 
+func (m *MockRoleRepository) SetPermissionGrants(roleID uint64, grants []model.RolePermission) error {
+	args := m.Called(roleID, grants)
+	return args.Error(0)
+}
+
 func (m *MockRoleRepository) GetRoleWithPermissions(roleID uint64) (*model.Role, error) {
 	args := m.Called(roleID)
 	if args.Get(0) == nil {
@@ -155,7 +276,60 @@ func (m *MockRoleRepository) GetUsersByRoleID(roleID uint64) ([]*model.User, err
 	return args.Get(0).([]*model.User), args.Error(1)
 }
 
+func (m *MockRoleRepository) SetParents(roleID uint64, parentIDs []uint64) error {
+	args := m.Called(roleID, parentIDs)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) GetAncestors(roleID uint64) ([]*model.Role, error) {
+	args := m.Called(roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetDescendants(roleID uint64) ([]*model.Role, error) {
+	args := m.Called(roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) ListByTemplate(templateCode string) ([]*model.Role, error) {
+	args := m.Called(templateCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) SetDataScope(roleID uint64, dataScope string, unitIDs []uint64) error {
+	args := m.Called(roleID, dataScope, unitIDs)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) CreateWithGrants(role *model.Role, parentIDs []uint64, grants []model.RolePermission) error {
+	args := m.Called(role, parentIDs, grants)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) SyncTemplate(syncs []*model.RoleTemplateSync) error {
+	args := m.Called(syncs)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) GetDataScopeUnits(roleID uint64) ([]*model.OrgUnit, error) {
+	args := m.Called(roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OrgUnit), args.Error(1)
+}
+
 // MockRefreshTokenRepository 刷新令牌仓库mock
+//
 //go:generate mockery --name=RefreshTokenRepository --output=. --outpkg=mocks --case=underscore
 type MockRefreshTokenRepository struct {
 	mock.Mock
@@ -242,3 +416,360 @@ func (m *MockPermissionRepository) GetByNames(names []string) ([]*model.Permissi
 	args := m.Called(names)
 	return args.Get(0).([]*model.Permission), args.Error(1)
 }
+
+func (m *MockPermissionRepository) ListAll() ([]*model.Permission, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Permission), args.Error(1)
+}
+
+func (m *MockPermissionRepository) CountChildren(id uint64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPermissionRepository) ListRoutes() ([]*model.Permission, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Permission), args.Error(1)
+}
+
+// MockTenantRepository 租户仓库的模拟实现
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) Create(tenant *model.Tenant) error {
+	args := m.Called(tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) Update(tenant *model.Tenant) error {
+	args := m.Called(tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) GetByID(id uint64) (*model.Tenant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) GetByCode(code string) (*model.Tenant, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) GetByName(name string) (*model.Tenant, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) List(page, pageSize int, keyword string) ([]*model.Tenant, int64, error) {
+	args := m.Called(page, pageSize, keyword)
+	return args.Get(0).([]*model.Tenant), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTenantRepository) CountUsage(id uint64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockGroupRepository 用户组仓库的模拟实现
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockGroupRepository) Create(group *model.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Update(group *model.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) GetByID(id uint64) (*model.Group, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetByCode(code string) (*model.Group, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetByName(name string) (*model.Group, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) List(page, pageSize int, keyword string) ([]*model.Group, int64, error) {
+	args := m.Called(page, pageSize, keyword)
+	return args.Get(0).([]*model.Group), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockGroupRepository) SetParents(groupID uint64, parentIDs []uint64) error {
+	args := m.Called(groupID, parentIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) GetDescendantIDs(groupID uint64) ([]uint64, error) {
+	args := m.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *MockGroupRepository) AddMembers(groupID uint64, userIDs []uint64) error {
+	args := m.Called(groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) RemoveMembers(groupID uint64, userIDs []uint64) error {
+	args := m.Called(groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) ListMembers(groupID uint64, page, pageSize int) ([]*model.User, int64, error) {
+	args := m.Called(groupID, page, pageSize)
+	return args.Get(0).([]*model.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockGroupRepository) CountMembers(groupID uint64) (int64, error) {
+	args := m.Called(groupID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetAffectedUserIDs(groupID uint64) ([]uint64, error) {
+	args := m.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *MockGroupRepository) GetRoleAssignments(groupID uint64) ([]*model.GroupRoleAssignment, error) {
+	args := m.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupRoleAssignment), args.Error(1)
+}
+
+func (m *MockGroupRepository) UpdateRoles(groupID, tenantID uint64, roleIDs []uint64) error {
+	args := m.Called(groupID, tenantID, roleIDs)
+	return args.Error(0)
+}
+
+// MockOrgUnitRepository 组织单元仓库的模拟实现
+type MockOrgUnitRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrgUnitRepository) Create(unit *model.OrgUnit) error {
+	args := m.Called(unit)
+	return args.Error(0)
+}
+
+func (m *MockOrgUnitRepository) Update(unit *model.OrgUnit) error {
+	args := m.Called(unit)
+	return args.Error(0)
+}
+
+func (m *MockOrgUnitRepository) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOrgUnitRepository) GetByID(id uint64) (*model.OrgUnit, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrgUnit), args.Error(1)
+}
+
+func (m *MockOrgUnitRepository) GetByCode(code string) (*model.OrgUnit, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrgUnit), args.Error(1)
+}
+
+func (m *MockOrgUnitRepository) ListAll() ([]*model.OrgUnit, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OrgUnit), args.Error(1)
+}
+
+func (m *MockOrgUnitRepository) CountChildren(id uint64) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrgUnitRepository) GetSubtreeIDs(id uint64) ([]uint64, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
+func (m *MockOrgUnitRepository) AddMembers(unitID uint64, userIDs []uint64) error {
+	args := m.Called(unitID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockOrgUnitRepository) RemoveMembers(unitID uint64, userIDs []uint64) error {
+	args := m.Called(unitID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockOrgUnitRepository) ListMembers(unitID uint64, page, pageSize int) ([]*model.User, int64, error) {
+	args := m.Called(unitID, page, pageSize)
+	return args.Get(0).([]*model.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOrgUnitRepository) CountMembers(unitID uint64) (int64, error) {
+	args := m.Called(unitID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockPolicyRepository 策略仓库的模拟实现
+type MockPolicyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPolicyRepository) GetGrants() ([]*model.PermissionGrant, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PermissionGrant), args.Error(1)
+}
+
+func (m *MockPolicyRepository) GetBindings() ([]*model.UserRoleBinding, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.UserRoleBinding), args.Error(1)
+}
+
+func (m *MockPolicyRepository) Apply(change *model.PolicyChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+// MockSoDRepository 职责分离规则仓库的模拟实现
+type MockSoDRepository struct {
+	mock.Mock
+}
+
+func (m *MockSoDRepository) Create(rule *model.SoDRule, roleIDs []uint64) error {
+	args := m.Called(rule, roleIDs)
+	return args.Error(0)
+}
+
+func (m *MockSoDRepository) Update(rule *model.SoDRule, roleIDs []uint64) error {
+	args := m.Called(rule, roleIDs)
+	return args.Error(0)
+}
+
+func (m *MockSoDRepository) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSoDRepository) GetByID(id uint64) (*model.SoDRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDRepository) GetByName(name string) (*model.SoDRule, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDRepository) List(page, pageSize int, keyword, ruleType string) ([]*model.SoDRule, int64, error) {
+	args := m.Called(page, pageSize, keyword, ruleType)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*model.SoDRule), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSoDRepository) ListByType(ruleType string) ([]*model.SoDRule, error) {
+	args := m.Called(ruleType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SoDRule), args.Error(1)
+}
+
+func (m *MockSoDRepository) GetUserAssignments(userID uint64) ([]*model.RoleAssignment, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoleAssignment), args.Error(1)
+}
+
+func (m *MockSoDRepository) GetRoleAssignments(roleIDs []uint64) ([]*model.RoleAssignment, error) {
+	args := m.Called(roleIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoleAssignment), args.Error(1)
+}
+
+func (m *MockSoDRepository) PreviewAssignments(userIDs []uint64, change *model.GroupChange) ([]*model.RoleAssignment, error) {
+	args := m.Called(userIDs, change)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RoleAssignment), args.Error(1)
+}
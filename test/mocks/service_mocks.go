@@ -0,0 +1,168 @@
+package mocks
+
+import (
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserService 用户服务的模拟实现
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Login(req *schema.LoginRequest) (*schema.LoginResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) RefreshToken(token string) (*schema.LoginResponse, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) CheckPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.CheckPermissionResponse, error) {
+	args := m.Called(userID, tenantID, activeRoles, permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.CheckPermissionResponse), args.Error(1)
+}
+
+func (m *MockUserService) CheckPermissions(userID, tenantID uint64, activeRoles []uint64, codes []string) (map[string]bool, error) {
+	args := m.Called(userID, tenantID, activeRoles, codes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockUserService) ExplainPermission(userID, tenantID uint64, activeRoles []uint64, permission string) (*schema.ExplainPermissionResponse, error) {
+	args := m.Called(userID, tenantID, activeRoles, permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.ExplainPermissionResponse), args.Error(1)
+}
+
+func (m *MockUserService) EvaluatePermission(userID, tenantID uint64, activeRoles []uint64, req *schema.EvaluatePermissionRequest) (*schema.EvaluatePermissionResponse, error) {
+	args := m.Called(userID, tenantID, activeRoles, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.EvaluatePermissionResponse), args.Error(1)
+}
+
+func (m *MockUserService) GetMenus(userID, tenantID uint64, activeRoles []uint64) ([]*schema.PermissionTreeNode, error) {
+	args := m.Called(userID, tenantID, activeRoles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*schema.PermissionTreeNode), args.Error(1)
+}
+
+func (m *MockUserService) GetPermissionPolicy(userID, tenantID uint64) (*permcode.Policy, error) {
+	args := m.Called(userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*permcode.Policy), args.Error(1)
+}
+
+func (m *MockUserService) GetSessionPolicy(userID, tenantID uint64, activeRoles []uint64) (*permcode.Policy, error) {
+	args := m.Called(userID, tenantID, activeRoles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*permcode.Policy), args.Error(1)
+}
+
+func (m *MockUserService) GetRoutePermissions(method, path string) ([]string, error) {
+	args := m.Called(method, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserService) GetProfile(userID, tenantID uint64, activeRoles []uint64) (*schema.UserResponse, error) {
+	args := m.Called(userID, tenantID, activeRoles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) GetPermissionVersion(userID, tenantID uint64, activeRoles []uint64) (string, error) {
+	args := m.Called(userID, tenantID, activeRoles)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) Create(req *schema.CreateUserRequest) (uint64, error) {
+	args := m.Called(req)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockUserService) Update(req *schema.UpdateUserRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Delete(id uint64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetByID(id, tenantID uint64) (*schema.UserResponse, error) {
+	args := m.Called(id, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) List(req *schema.ListUserRequest, callerID, tenantID uint64) (*schema.ListUserResponse, error) {
+	args := m.Called(req, callerID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schema.ListUserResponse), args.Error(1)
+}
+
+func (m *MockUserService) AssignRole(req *schema.AssignRoleRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetRoles(userID uint64) ([]schema.RoleResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.RoleResponse), args.Error(1)
+}
+
+func (m *MockUserService) AssignPermissions(req *schema.AssignUserPermissionsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RevokePermissions(req *schema.RevokeUserPermissionsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetDirectPermissions(userID uint64) ([]schema.UserPermissionResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]schema.UserPermissionResponse), args.Error(1)
+}
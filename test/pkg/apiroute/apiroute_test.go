@@ -0,0 +1,120 @@
+package apiroute_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试路由到接口权限编码的转换
+func TestRoute_Code(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    apiroute.Route
+		expected string
+	}{
+		{name: "普通路由", route: apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}, expected: "api:post:api:v1:users:list"},
+		{name: "中划线保留", route: apiroute.Route{Method: "POST", Path: "/api/v1/permissions/sync-routes"}, expected: "api:post:api:v1:permissions:sync-routes"},
+		{name: "路径参数", route: apiroute.Route{Method: "GET", Path: "/api/v1/users/:id"}, expected: "api:get:api:v1:users:_id"},
+		{name: "末尾斜杠", route: apiroute.Route{Method: "GET", Path: "/api/v1/users/"}, expected: "api:get:api:v1:users"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			code := tt.route.Code()
+			assert.Equal(t, tt.expected, code)
+			assert.NoError(t, permcode.Validate(code))
+		})
+	}
+}
+
+// 测试从 Fiber 应用中收集接口路由
+func TestCollect(t *testing.T) {
+	handler := func(c *fiber.Ctx) error { return nil }
+
+	app := fiber.New()
+	app.Get("/health", handler)
+	api := app.Group("/api/v1")
+	api.Use(handler)
+	api.Post("/users/list", handler)
+	api.Post("/users/create", handler)
+	api.Get("/users/detail", handler)
+	api.Post("/users/list", handler)
+
+	routes := apiroute.Collect(app, "/api/v1")
+	require.Len(t, routes, 3)
+	assert.Equal(t, []apiroute.Route{
+		{Method: "POST", Path: "/api/v1/users/create"},
+		{Method: "GET", Path: "/api/v1/users/detail"},
+		{Method: "POST", Path: "/api/v1/users/list"},
+	}, routes)
+	assert.Equal(t, "POST /api/v1/users/create", routes[0].String())
+}
+
+// 测试路由模式匹配请求方法与路径
+func TestRoute_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  apiroute.Route
+		method   string
+		path     string
+		expected bool
+	}{
+		{name: "精确匹配", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}, method: "POST", path: "/api/v1/users/list", expected: true},
+		{name: "方法不同", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}, method: "GET", path: "/api/v1/users/list"},
+		{name: "任意方法", pattern: apiroute.Route{Method: "*", Path: "/api/v1/users/list"}, method: "DELETE", path: "/api/v1/users/list", expected: true},
+		{name: "路径参数", pattern: apiroute.Route{Method: "GET", Path: "/api/v1/users/:id"}, method: "GET", path: "/api/v1/users/42", expected: true},
+		{name: "路径参数不匹配多段", pattern: apiroute.Route{Method: "GET", Path: "/api/v1/users/:id"}, method: "GET", path: "/api/v1/users/42/roles"},
+		{name: "末尾通配符匹配多段", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/*"}, method: "POST", path: "/api/v1/users/roles/assign", expected: true},
+		{name: "末尾通配符匹配前缀本身", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/*"}, method: "POST", path: "/api/v1/users", expected: true},
+		{name: "末尾通配符不跨资源", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/*"}, method: "POST", path: "/api/v1/roles/list"},
+		{name: "中间通配符匹配一段", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/*/list"}, method: "POST", path: "/api/v1/roles/list", expected: true},
+		{name: "路径更短", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}, method: "POST", path: "/api/v1/users"},
+		{name: "忽略末尾斜杠", pattern: apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}, method: "POST", path: "/api/v1/users/list/", expected: true},
+		{name: "未规范化的路径不匹配", pattern: apiroute.Route{Method: "*", Path: "/public/*"}, method: "GET", path: "/public/../admin"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.pattern.Match(tt.method, tt.path))
+		})
+	}
+}
+
+// 测试规范化反向代理转发的原始请求路径
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		expected string
+		ok       bool
+	}{
+		{name: "去掉查询参数与片段", uri: "/reports/1?x=1#top", expected: "/reports/1", ok: true},
+		{name: "消除上级目录分段", uri: "/public/../admin/users", expected: "/admin/users", ok: true},
+		{name: "解码后消除上级目录分段", uri: "/public/%2e%2e/admin/users", expected: "/admin/users", ok: true},
+		{name: "不能越过根路径", uri: "/../../admin", expected: "/admin", ok: true},
+		{name: "合并重复斜杠与当前目录", uri: "//reports/./1/", expected: "/reports/1", ok: true},
+		{name: "解码普通字符", uri: "/files/a%20b", expected: "/files/a b", ok: true},
+		{name: "编码的斜杠", uri: "/public/..%2Fadmin"},
+		{name: "编码的反斜杠", uri: "/public/..%5cadmin"},
+		{name: "反斜杠", uri: "/public/..\\admin"},
+		{name: "多重编码", uri: "/public/%252e%252e/admin"},
+		{name: "无效的编码", uri: "/public/%zz"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			cleaned, ok := apiroute.CleanPath(tt.uri)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, cleaned)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package condition_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/condition"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试条件表达式的编译期校验
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr error
+	}{
+		{name: "属性比较", source: "resource.owner_id == subject.id"},
+		{name: "网段", source: "request.ip in 10.0.0.0/8"},
+		{name: "引号中的网段", source: `request.ip in "fd00::/8"`},
+		{name: "时间窗口", source: "request.time >= 09:00 && request.time < 18:00"},
+		{name: "列表与取反", source: `!(resource.status in ["locked", "archived"]) || subject.id == 1`},
+		{name: "布尔属性", source: "resource.public"},
+		{name: "未知命名空间", source: "user.id == 1", wantErr: condition.ErrSyntax},
+		{name: "缺少属性名", source: "resource == 1", wantErr: condition.ErrSyntax},
+		{name: "单个等号", source: "resource.owner_id = subject.id", wantErr: condition.ErrSyntax},
+		{name: "缺少比较", source: "resource.owner_id == 1 && 5", wantErr: condition.ErrSyntax},
+		{name: "无效网段", source: "request.ip in 10.0.0.0/40", wantErr: condition.ErrSyntax},
+		{name: "无效时刻", source: "request.time < 25:00", wantErr: condition.ErrSyntax},
+		{name: "in 右侧为数值", source: "resource.id in 5", wantErr: condition.ErrSyntax},
+		{name: "布尔值比较大小", source: "resource.id < true", wantErr: condition.ErrSyntax},
+		{name: "不支持函数调用", source: "exec(resource.cmd)", wantErr: condition.ErrSyntax},
+		{name: "括号不匹配", source: "(resource.id == 1", wantErr: condition.ErrSyntax},
+		{name: "嵌套过深", source: strings.Repeat("!", condition.MaxDepth+1) + "resource.public", wantErr: condition.ErrSyntax},
+		{name: "超出长度", source: "resource.id == " + strings.Repeat("1", condition.MaxLength), wantErr: condition.ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := condition.Compile(tt.source)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 测试条件表达式求值
+func TestExpr_Eval(t *testing.T) {
+	attrs := condition.Attributes{
+		condition.NamespaceSubject:  {"id": uint64(7), "username": "alice"},
+		condition.NamespaceResource: {"owner_id": float64(7), "team_id": "42", "tags": []interface{}{"a", "b"}, "meta": map[string]interface{}{"level": float64(3)}},
+		condition.NamespaceRequest:  {"ip": "10.1.2.3", "time": "20:30", "trusted": "10.1.0.0/16"},
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		expected bool
+		wantErr  error
+	}{
+		{name: "本人资源", source: "resource.owner_id == subject.id", expected: true},
+		{name: "数值与字符串比较", source: "resource.team_id == 42", expected: true},
+		{name: "网段内", source: "request.ip in 10.0.0.0/8", expected: true},
+		{name: "网段外", source: "request.ip in 192.168.0.0/16"},
+		{name: "属性中的网段", source: "request.ip in request.trusted", expected: true},
+		{name: "时间窗口外", source: "request.time >= 09:00 && request.time < 18:00"},
+		{name: "跨零点的时间窗口", source: "request.time >= 20:00 || request.time < 06:00", expected: true},
+		{name: "属性列表", source: `"b" in resource.tags`, expected: true},
+		{name: "嵌套属性", source: "resource.meta.level >= 3", expected: true},
+		{name: "缺失属性为null", source: "resource.deleted_at == null", expected: true},
+		{name: "缺失属性比较大小", source: "resource.size > 10"},
+		{name: "缺失属性作为条件", source: "resource.public"},
+		{name: "非布尔属性作为条件", source: "subject.username", wantErr: condition.ErrTypeMismatch},
+		{name: "字符串与数值比较大小", source: "subject.username > 3", wantErr: condition.ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := condition.Compile(tt.source)
+			require.NoError(t, err)
+
+			result, err := expr.Eval(attrs)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
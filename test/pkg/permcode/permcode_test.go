@@ -0,0 +1,108 @@
+package permcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试权限编码语法校验
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		expectedErr error
+	}{
+		{name: "资源加操作", code: "user:list"},
+		{name: "多级编码", code: "report:export:csv"},
+		{name: "末尾通配符", code: "user:*"},
+		{name: "首段通配符", code: "*:list"},
+		{name: "超级权限", code: "*:*"},
+		{name: "允许下划线中划线和点", code: "order_item:bulk-update.v2"},
+		{name: "空编码", code: "", expectedErr: permcode.ErrEmptyCode},
+		{name: "缺少操作", code: "user", expectedErr: permcode.ErrTooFewSegments},
+		{name: "空分段", code: "user::list", expectedErr: permcode.ErrEmptySegment},
+		{name: "末尾分隔符", code: "user:", expectedErr: permcode.ErrEmptySegment},
+		{name: "通配符与字符混用", code: "user:li*", expectedErr: permcode.ErrInvalidSegment},
+		{name: "包含空格", code: "user:list all", expectedErr: permcode.ErrInvalidSegment},
+		{name: "超出长度", code: "user:" + strings.Repeat("a", permcode.MaxLength), expectedErr: permcode.ErrCodeTooLong},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedErr, permcode.Validate(tt.code))
+		})
+	}
+}
+
+// 测试权限模式匹配
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		code     string
+		expected bool
+	}{
+		{name: "精确匹配", pattern: "user:list", code: "user:list", expected: true},
+		{name: "精确不匹配", pattern: "user:list", code: "user:update", expected: false},
+		{name: "末尾通配符匹配一段", pattern: "user:*", code: "user:list", expected: true},
+		{name: "末尾通配符匹配多段", pattern: "user:*", code: "user:profile:update", expected: true},
+		{name: "末尾通配符不匹配空操作", pattern: "user:*", code: "user", expected: false},
+		{name: "末尾通配符不跨资源", pattern: "user:*", code: "role:list", expected: false},
+		{name: "首段通配符", pattern: "*:list", code: "role:list", expected: true},
+		{name: "首段通配符不匹配其他操作", pattern: "*:list", code: "role:update", expected: false},
+		{name: "中间通配符只匹配一段", pattern: "*:list", code: "report:export:list", expected: false},
+		{name: "多级末尾通配符", pattern: "report:export:*", code: "report:export:csv", expected: true},
+		{name: "多级末尾通配符不匹配父级", pattern: "report:export:*", code: "report:export", expected: false},
+		{name: "超级权限", pattern: "*:*", code: "permission:delete", expected: true},
+		{name: "被检查的编码不视为模式", pattern: "user:list", code: "user:*", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, permcode.Match(tt.pattern, tt.code))
+			assert.Equal(t, tt.expected, permcode.NewSet([]string{tt.pattern}).Has(tt.code))
+		})
+	}
+}
+
+// 测试权限集合的组合判断
+func TestSet(t *testing.T) {
+	set := permcode.NewSet([]string{"user:list", "role:*"})
+
+	assert.True(t, set.Has("user:list"))
+	assert.True(t, set.Has("role:update"))
+	assert.False(t, set.Has("user:update"))
+
+	assert.True(t, set.HasAny("user:update", "role:delete"))
+	assert.False(t, set.HasAny("user:update", "permission:list"))
+	assert.True(t, set.HasAny())
+
+	assert.True(t, set.HasAll("user:list", "role:list"))
+	assert.False(t, set.HasAll("user:list", "user:update"))
+	assert.True(t, set.HasAll())
+
+	assert.Equal(t, []string{"user:list", "role:*"}, set.Codes())
+}
+
+// 测试拒绝优先的判定策略
+func TestPolicy(t *testing.T) {
+	policy := permcode.NewPolicy([]string{"user:*", "role:list"}, []string{"user:delete"})
+
+	assert.True(t, policy.Allows("user:list"))
+	assert.False(t, policy.Allows("user:delete"))
+	assert.True(t, policy.Denies("user:delete"))
+	assert.False(t, policy.Allows("role:update"))
+
+	assert.True(t, policy.AllowsAny("user:delete", "role:list"))
+	assert.False(t, policy.AllowsAll("user:delete", "role:list"))
+	assert.True(t, policy.AllowsAll("user:update", "role:list"))
+
+	// 通配符拒绝覆盖精确允许
+	policy = permcode.NewPolicy([]string{"report:export:csv"}, []string{"report:*"})
+	assert.False(t, policy.Allows("report:export:csv"))
+}
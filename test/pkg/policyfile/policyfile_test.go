@@ -0,0 +1,82 @@
+package policyfile_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/pkg/policyfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试解析 Casbin CSV 策略
+func TestParse(t *testing.T) {
+	content := `# 角色权限
+p, admin, user:*
+p,auditor,user:delete,deny
+
+g, alice, admin
+g, bob, auditor, acme
+`
+	policy, err := policyfile.Parse(strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []policyfile.Grant{
+		{Role: "admin", Permission: "user:*", Effect: policyfile.EffectAllow},
+		{Role: "auditor", Permission: "user:delete", Effect: policyfile.EffectDeny},
+	}, policy.Grants)
+	assert.Equal(t, []policyfile.Assignment{
+		{User: "alice", Role: "admin"},
+		{User: "bob", Role: "auditor", Tenant: "acme"},
+	}, policy.Assignments)
+}
+
+// 测试解析错误携带行号
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		line        int
+		expectedErr error
+	}{
+		{name: "未知规则类型", content: "p, admin, user:list\ng2, editor, admin", line: 2, expectedErr: policyfile.ErrUnknownType},
+		{name: "字段过少", content: "p, admin", line: 1, expectedErr: policyfile.ErrFieldCount},
+		{name: "字段过多", content: "g, alice, admin, acme, extra", line: 1, expectedErr: policyfile.ErrFieldCount},
+		{name: "空字段", content: "# 注释\np, , user:list", line: 2, expectedErr: policyfile.ErrEmptyField},
+		{name: "无效效果", content: "p, admin, user:list, maybe", line: 1, expectedErr: policyfile.ErrInvalidEffect},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := policyfile.Parse(strings.NewReader(tt.content))
+			var lineErr *policyfile.LineError
+			require.True(t, errors.As(err, &lineErr))
+			assert.Equal(t, tt.line, lineErr.Line)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+// 测试导出格式稳定且可被重新解析
+func TestPolicy_RoundTrip(t *testing.T) {
+	policy := &policyfile.Policy{
+		Grants: []policyfile.Grant{
+			{Role: "user", Permission: "user:list", Effect: policyfile.EffectAllow},
+			{Role: "admin", Permission: "user:delete", Effect: policyfile.EffectDeny},
+		},
+		Assignments: []policyfile.Assignment{
+			{User: "bob", Role: "user", Tenant: "acme"},
+			{User: "alice", Role: "admin"},
+		},
+	}
+	policy.Sort()
+
+	expected := "p, admin, user:delete, deny\np, user, user:list\ng, alice, admin\ng, bob, user, acme\n"
+	assert.Equal(t, expected, policy.String())
+
+	parsed, err := policyfile.Parse(strings.NewReader(policy.String()))
+	require.NoError(t, err)
+	assert.Equal(t, policy, parsed)
+}
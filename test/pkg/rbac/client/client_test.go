@@ -0,0 +1,244 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/pkg/rbac"
+	"github.com/lvyunze/fiber-rbac/pkg/rbac/client"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testJWTConfig = &rbac.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+// rbacServer 以 httptest 启动的权限服务，记录批量检查接口的调用次数
+type rbacServer struct {
+	*httptest.Server
+	calls   atomic.Int32
+	user    *model.User
+	analyst *model.Role
+}
+
+// newRBACServer 启动挂载了完整管理接口的权限服务，并创建经由 analyst 角色拥有 report:view、
+// 经由 auditor 角色拥有 audit:read 权限的用户
+func newRBACServer(t *testing.T) *rbacServer {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, model.AutoMigrate(db))
+
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	user := &model.User{Username: "analyst", Email: "analyst@example.com", Password: "x"}
+	require.NoError(t, db.Create(user).Error)
+	permission := &model.Permission{Code: "report:view", Name: "report:view"}
+	require.NoError(t, db.Create(permission).Error)
+	role := &model.Role{Code: "analyst", Name: "analyst"}
+	require.NoError(t, db.Create(role).Error)
+	require.NoError(t, db.Create(&model.RolePermission{RoleID: role.ID, PermissionID: permission.ID}).Error)
+	require.NoError(t, db.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID}).Error)
+	audit := &model.Permission{Code: "audit:read", Name: "audit:read"}
+	require.NoError(t, db.Create(audit).Error)
+	auditor := &model.Role{Code: "auditor", Name: "auditor"}
+	require.NoError(t, db.Create(auditor).Error)
+	require.NoError(t, db.Create(&model.RolePermission{RoleID: auditor.ID, PermissionID: audit.ID}).Error)
+	require.NoError(t, db.Create(&model.UserRole{UserID: user.ID, RoleID: auditor.ID}).Error)
+
+	app := fiber.New()
+	enforcer := rbac.NewEnforcer(rbac.NewRepository(db), testJWTConfig, nil)
+	require.NoError(t, enforcer.MountAdminRoutes(app, db, rbac.AdminOptions{}))
+
+	server := &rbacServer{user: user, analyst: role}
+	handler := adaptor.FiberApp(app)
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/auth/check-batch" {
+			server.calls.Add(1)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// accessToken 签发访问令牌
+func accessToken(t *testing.T, user *model.User, tokenType string) string {
+	t.Helper()
+	token, err := jwt.NewTokenService(testJWTConfig).GenerateToken(user.ID, user.Username, tokenType)
+	require.NoError(t, err)
+	return token
+}
+
+// 测试远程判定结果与缓存
+func TestClient_CheckCachesDecisions(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig})
+	token := accessToken(t, server.user, "access")
+
+	results, err := c.Check(context.Background(), token, 0, "report:view", "report:export")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"report:view": true, "report:export": false}, results)
+	assert.Equal(t, int32(1), server.calls.Load())
+
+	// 已缓存的判定不再请求权限服务
+	allowed, err := c.Allowed(context.Background(), token, 0, "report:view")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int32(1), server.calls.Load())
+
+	// 失效后重新请求
+	c.Invalidate(server.user.ID)
+	_, err = c.Check(context.Background(), token, 0, "report:view")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), server.calls.Load())
+}
+
+// 测试只激活部分角色的会话按激活的角色判定，且与激活全部角色的会话分别缓存
+func TestClient_CheckSessionActiveRoles(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig})
+	token := accessToken(t, server.user, "access")
+	session, err := jwt.NewTokenService(testJWTConfig).GenerateSessionToken(server.user.ID, 0, []uint64{server.analyst.ID}, server.user.Username, "access")
+	require.NoError(t, err)
+
+	results, err := c.Check(context.Background(), token, 0, "report:view", "audit:read")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"report:view": true, "audit:read": true}, results)
+
+	results, err = c.Check(context.Background(), session, 0, "report:view", "audit:read")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"report:view": true, "audit:read": false}, results)
+	assert.Equal(t, int32(2), server.calls.Load())
+
+	// 两种会话的判定都已缓存
+	allowed, err := c.Allowed(context.Background(), token, 0, "audit:read")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = c.Allowed(context.Background(), session, 0, "audit:read")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int32(2), server.calls.Load())
+}
+
+// 测试并发判定请求合并为一次批量检查
+func TestClient_CheckBatchesConcurrentRequests(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig, BatchWindow: 50 * time.Millisecond})
+	token := accessToken(t, server.user, "access")
+
+	codes := []string{"report:view", "report:export", "user:list", "user:create"}
+	var wg sync.WaitGroup
+	for _, code := range codes {
+		wg.Add(1)
+		go func(code string) {
+			defer wg.Done()
+			allowed, err := c.Allowed(context.Background(), token, 0, code)
+			assert.NoError(t, err)
+			assert.Equal(t, code == "report:view", allowed)
+		}(code)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), server.calls.Load())
+}
+
+// 测试单次判定的权限超过批量检查上限时拆分为多次请求
+func TestClient_CheckSplitsLargeRequests(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig})
+	token := accessToken(t, server.user, "access")
+
+	codes := []string{"report:view"}
+	for i := 0; len(codes) < 450; i++ {
+		codes = append(codes, fmt.Sprintf("report:export%d", i))
+	}
+	results, err := c.Check(context.Background(), token, 0, codes...)
+	require.NoError(t, err)
+	require.Len(t, results, len(codes))
+	assert.True(t, results["report:view"])
+	assert.False(t, results["report:export448"])
+	assert.Equal(t, int32(3), server.calls.Load())
+}
+
+// 测试令牌在本地校验，无效令牌不会请求权限服务
+func TestClient_ValidateTokenLocally(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig})
+
+	claims, err := c.ValidateToken(accessToken(t, server.user, "access"))
+	require.NoError(t, err)
+	assert.Equal(t, server.user.ID, claims.UserID)
+
+	_, err = c.Check(context.Background(), accessToken(t, server.user, "refresh"), 0, "report:view")
+	assert.Equal(t, rbac.ErrInvalidTokenType, err)
+
+	_, err = c.Check(context.Background(), "invalid", 0, "report:view")
+	assert.Equal(t, rbac.ErrInvalidToken, err)
+	assert.Equal(t, int32(0), server.calls.Load())
+}
+
+// 测试中间件在权限服务可用与不可用时的行为
+func TestClient_RequirePermission(t *testing.T) {
+	server := newRBACServer(t)
+	c := client.New(client.Options{BaseURL: server.URL, JWT: testJWTConfig, CacheTTL: -1})
+	token := accessToken(t, server.user, "access")
+
+	host := fiber.New()
+	host.Get("/reports", c.Authenticate(), c.RequirePermission("report:view"), func(ctx *fiber.Ctx) error {
+		return response.Success(ctx, client.Username(ctx), "")
+	})
+	host.Get("/exports", c.Authenticate(), c.RequireAny("report:export", "report:delete"), func(ctx *fiber.Ctx) error {
+		return response.Success(ctx, nil, "")
+	})
+	host.Get("/open", c.Authenticate(), c.RequireAny(), func(ctx *fiber.Ctx) error {
+		return response.Success(ctx, nil, "")
+	})
+
+	call := func(path, token string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := host.Test(req, -1)
+		require.NoError(t, err)
+		var res response.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res.Code
+	}
+
+	assert.Equal(t, response.CodeUnauthorized, call("/reports", ""))
+	assert.Equal(t, response.CodeSuccess, call("/reports", token))
+	assert.Equal(t, response.CodeForbidden, call("/exports", token))
+	// 未要求任何权限时认证通过即放行
+	assert.Equal(t, response.CodeSuccess, call("/open", token))
+
+	// 权限服务不可达时拒绝请求
+	server.Close()
+	assert.Equal(t, response.CodeServerError, call("/reports", token))
+
+	_, err := c.Check(context.Background(), token, 0, "report:view")
+	assert.ErrorIs(t, err, client.ErrUnavailable)
+}
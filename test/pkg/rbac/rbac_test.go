@@ -0,0 +1,223 @@
+package rbac_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/hash"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/response"
+	"github.com/lvyunze/fiber-rbac/pkg/rbac"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testJWTConfig = &rbac.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+// setupTestDB 创建已迁移表结构的内存数据库，并创建拥有 report:view 权限的用户
+func setupTestDB(t *testing.T) (*gorm.DB, *model.User) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, model.AutoMigrate(db))
+
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	password, err := hash.GeneratePassword("secret123")
+	require.NoError(t, err)
+	user := &model.User{Username: "analyst", Email: "analyst@example.com", Password: password}
+	require.NoError(t, db.Create(user).Error)
+	permission := &model.Permission{Code: "report:view", Name: "report:view"}
+	require.NoError(t, db.Create(permission).Error)
+	role := &model.Role{Code: "analyst", Name: "analyst"}
+	require.NoError(t, db.Create(role).Error)
+	require.NoError(t, db.Create(&model.RolePermission{RoleID: role.ID, PermissionID: permission.ID}).Error)
+	require.NoError(t, db.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID}).Error)
+
+	return db, user
+}
+
+// accessToken 签发访问令牌
+func accessToken(t *testing.T, user *model.User, tokenType string) string {
+	t.Helper()
+	token, err := jwt.NewTokenService(testJWTConfig).GenerateToken(user.ID, user.Username, tokenType)
+	require.NoError(t, err)
+	return token
+}
+
+// 测试权限判定与令牌校验
+func TestEnforcer_CheckAndValidateToken(t *testing.T) {
+	db, user := setupTestDB(t)
+	enforcer := rbac.NewEnforcer(rbac.NewRepository(db), testJWTConfig, nil)
+
+	allowed, err := enforcer.Check(user.ID, 0, "report:view")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = enforcer.Check(user.ID, 0, "report:view", "report:export")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	claims, err := enforcer.ValidateToken(accessToken(t, user, "access"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	_, err = enforcer.ValidateToken(accessToken(t, user, "refresh"))
+	assert.Equal(t, rbac.ErrInvalidTokenType, err)
+
+	_, err = enforcer.ValidateToken("invalid")
+	assert.Equal(t, rbac.ErrInvalidToken, err)
+}
+
+// 测试宿主应用使用权限中间件保护自己的路由
+func TestEnforcer_Middleware(t *testing.T) {
+	db, user := setupTestDB(t)
+	enforcer := rbac.NewEnforcer(rbac.NewRepository(db), testJWTConfig, rbac.NewPermissionCache(0, 0))
+
+	host := fiber.New()
+	host.Get("/reports", enforcer.Authenticate(), enforcer.RequirePermission("report:view"), func(c *fiber.Ctx) error {
+		return c.SendString(rbac.Username(c))
+	})
+	host.Get("/exports", enforcer.Authenticate(), enforcer.RequireAny("report:export", "report:*"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	call := func(path, token string) response.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := host.Test(req, -1)
+		require.NoError(t, err)
+		var res response.Response
+		if resp.Header.Get(fiber.HeaderContentType) == fiber.MIMEApplicationJSON {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		} else {
+			res.Code = response.CodeSuccess
+		}
+		return res
+	}
+
+	token := accessToken(t, user, "access")
+	assert.Equal(t, response.CodeUnauthorized, call("/reports", "").Code)
+	assert.Equal(t, response.CodeSuccess, call("/reports", token).Code)
+	assert.Equal(t, response.CodeForbidden, call("/exports", token).Code)
+}
+
+// 测试在宿主应用的路由分组下挂载管理接口
+func TestEnforcer_MountAdminRoutes(t *testing.T) {
+	db, _ := setupTestDB(t)
+	enforcer := rbac.NewEnforcer(rbac.NewRepository(db), testJWTConfig, nil)
+
+	host := fiber.New()
+	require.NoError(t, enforcer.MountAdminRoutes(host.Group("/rbac"), db, rbac.AdminOptions{}))
+
+	req := httptest.NewRequest("POST", "/rbac/api/v1/auth/login", strings.NewReader(`{"username":"analyst","password":"secret123"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := host.Test(req, -1)
+	require.NoError(t, err)
+
+	var res struct {
+		Code int `json:"code"`
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Equal(t, response.CodeSuccess, res.Code)
+
+	// 管理接口签发的令牌可直接用于权限判定器
+	claims, err := enforcer.ValidateToken(res.Data.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "analyst", claims.Username)
+
+	// 管理接口受同一套权限校验保护
+	req = httptest.NewRequest("POST", "/rbac/api/v1/users/list", strings.NewReader(`{}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set("Authorization", "Bearer "+res.Data.Token)
+	resp, err = host.Test(req, -1)
+	require.NoError(t, err)
+	var denied response.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&denied))
+	assert.Equal(t, response.CodeForbidden, denied.Code)
+}
+
+// 测试开启按路径校验后，个人信息与权限检查接口仍只需认证
+func TestEnforcer_MountAdminRoutesEnforceRoutes(t *testing.T) {
+	db, user := setupTestDB(t)
+	enforcer := rbac.NewEnforcer(rbac.NewRepository(db), testJWTConfig, nil)
+
+	host := fiber.New()
+	require.NoError(t, enforcer.MountAdminRoutes(host, db, rbac.AdminOptions{Security: rbac.SecurityConfig{EnforceRoutes: true}}))
+
+	token := accessToken(t, user, "access")
+	call := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := host.Test(req, -1)
+		require.NoError(t, err)
+		var res response.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res.Code
+	}
+
+	// 用户未被授予任何接口权限
+	assert.Equal(t, response.CodeSuccess, call("/api/v1/auth/profile", `{}`))
+	assert.Equal(t, response.CodeSuccess, call("/api/v1/auth/check", `{"permission":"report:view"}`))
+	assert.Equal(t, response.CodeSuccess, call("/api/v1/auth/menus", `{}`))
+	assert.Equal(t, response.CodeForbidden, call("/api/v1/users/list", `{}`))
+}
+
+// staticRepository 宿主服务自行实现的 Repository，只使用 rbac 包公开的类型
+type staticRepository struct {
+	grants []*rbac.Grant
+}
+
+func (r *staticRepository) GetEffectivePermissionGrants(userID, tenantID uint64) ([]*rbac.Grant, error) {
+	return r.grants, nil
+}
+
+func (r *staticRepository) GetEffectiveRoleIDs(userID, tenantID uint64) ([]uint64, error) {
+	return []uint64{1}, nil
+}
+
+func (r *staticRepository) GetNextValidityChange(userID, tenantID uint64) (int64, error) {
+	return 0, nil
+}
+
+func (r *staticRepository) GetEffectiveRoleParents(userID, tenantID uint64) ([]*rbac.RoleInheritance, error) {
+	return nil, nil
+}
+
+func (r *staticRepository) ListRoutes() ([]*rbac.Permission, error) {
+	return nil, nil
+}
+
+// 测试使用自行实现的 Repository 创建权限判定器
+func TestEnforcer_CustomRepository(t *testing.T) {
+	repo := &staticRepository{grants: []*rbac.Grant{{RoleID: 1, RoleCode: "analyst", PermissionCode: "report:view", Effect: "allow"}}}
+	enforcer := rbac.NewEnforcer(repo, testJWTConfig, nil)
+
+	allowed, err := enforcer.Check(7, 0, "report:view")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	policy, err := enforcer.GetSessionPolicy(7, 0, []uint64{2})
+	require.NoError(t, err)
+	assert.False(t, policy.Allows("report:view"))
+}
@@ -0,0 +1,133 @@
+package relation_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/relation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试关系元组解析
+func TestParseTuple(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    relation.Tuple
+		expectedErr error
+	}{
+		{
+			name:     "主体为用户",
+			input:    "document:42#viewer@user:7",
+			expected: relation.Tuple{Object: relation.Object{Type: "document", ID: "42"}, Relation: "viewer", Subject: relation.Subject{Type: "user", ID: "7"}},
+		},
+		{
+			name:     "主体为用户集",
+			input:    "folder:f1#viewer@group:eng#member",
+			expected: relation.Tuple{Object: relation.Object{Type: "folder", ID: "f1"}, Relation: "viewer", Subject: relation.Subject{Type: "group", ID: "eng", Relation: "member"}},
+		},
+		{name: "缺少主体", input: "document:42#viewer", expectedErr: relation.ErrInvalidTuple},
+		{name: "缺少关系", input: "document:42@user:7", expectedErr: relation.ErrInvalidTuple},
+		{name: "对象缺少ID", input: "document#viewer@user:7", expectedErr: relation.ErrInvalidObject},
+		{name: "类型名含大写", input: "Document:42#viewer@user:7", expectedErr: relation.ErrInvalidObject},
+		{name: "主体ID含空白", input: "document:42#viewer@user:a b", expectedErr: relation.ErrInvalidSubject},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			tuple, err := relation.ParseTuple(tt.input)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.expected, tuple)
+				assert.Equal(t, tt.input, tuple.String())
+			}
+		})
+	}
+}
+
+// 测试对象类型定义的校验
+func TestNewSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   []config.RelationTypeConfig
+		wantErr bool
+	}{
+		{
+			name: "合法定义",
+			types: []config.RelationTypeConfig{
+				{Name: "folder", Relations: []config.RelationConfig{{Name: "viewer"}}},
+				{Name: "document", Relations: []config.RelationConfig{
+					{Name: "parent"},
+					{Name: "owner"},
+					{Name: "viewer", Includes: []string{"owner"}, From: []config.RelationFromConfig{{Tupleset: "parent", Relation: "viewer"}}},
+				}},
+			},
+		},
+		{
+			name:    "类型重复定义",
+			types:   []config.RelationTypeConfig{{Name: "group"}, {Name: "group"}},
+			wantErr: true,
+		},
+		{
+			name: "包含未定义的关系",
+			types: []config.RelationTypeConfig{
+				{Name: "document", Relations: []config.RelationConfig{{Name: "viewer", Includes: []string{"editor"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "关联关系未定义",
+			types: []config.RelationTypeConfig{
+				{Name: "document", Relations: []config.RelationConfig{{Name: "viewer", From: []config.RelationFromConfig{{Tupleset: "parent", Relation: "viewer"}}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "非法关系名",
+			types: []config.RelationTypeConfig{
+				{Name: "document", Relations: []config.RelationConfig{{Name: "can-view"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := relation.NewSchema(&config.RelationsConfig{Types: tt.types})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// 测试反向索引与元组校验
+func TestSchema_Lookup(t *testing.T) {
+	s, err := relation.NewSchema(&config.RelationsConfig{Types: []config.RelationTypeConfig{
+		{Name: "folder", Relations: []config.RelationConfig{{Name: "viewer"}}},
+		{Name: "document", Relations: []config.RelationConfig{
+			{Name: "parent"},
+			{Name: "owner"},
+			{Name: "editor", Includes: []string{"owner"}},
+			{Name: "viewer", Includes: []string{"editor"}, From: []config.RelationFromConfig{{Tupleset: "parent", Relation: "viewer"}}},
+		}},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, relation.DefaultMaxDepth, s.MaxDepth())
+	assert.Equal(t, []string{"editor"}, s.IncludedBy("document", "owner"))
+	assert.Equal(t, []relation.Inheritance{{ObjectType: "document", Relation: "viewer", Tupleset: "parent"}}, s.InheritedBy("viewer"))
+
+	valid, _ := relation.ParseTuple("document:1#viewer@folder:f1#viewer")
+	assert.NoError(t, s.Validate(valid))
+	undefined, _ := relation.ParseTuple("document:1#commenter@user:7")
+	assert.Error(t, s.Validate(undefined))
+	badSubject, _ := relation.ParseTuple("document:1#viewer@folder:f1#owner")
+	assert.Error(t, s.Validate(badSubject))
+}
@@ -0,0 +1,193 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createTestGroup 创建测试用户组，并嵌套到指定的上级组中
+func createTestGroup(t *testing.T, db *gorm.DB, code string, parents ...*model.Group) *model.Group {
+	t.Helper()
+	group := &model.Group{Code: code, Name: code}
+	require.NoError(t, db.Create(group).Error)
+	for _, parent := range parents {
+		require.NoError(t, db.Create(&model.GroupParent{GroupID: group.ID, ParentID: parent.ID}).Error)
+	}
+	return group
+}
+
+// 测试经由嵌套用户组获得的角色计入有效权限
+func TestUserRepository_GroupRoles(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+
+	userList := createTestPermission(t, db, "user:list")
+	roleList := createTestPermission(t, db, "role:list")
+	reportView := createTestPermission(t, db, "report:view")
+	viewer := createTestRole(t, db, "viewer", userList)
+	auditor := createTestRole(t, db, "auditor", roleList)
+	base := createTestRole(t, db, "base", reportView)
+	setTestParents(t, db, auditor, base)
+
+	// alice 属于 backend，backend 嵌套在 engineering 中
+	engineering := createTestGroup(t, db, "engineering")
+	backend := createTestGroup(t, db, "backend", engineering)
+	alice := createTestUser(t, db, "alice")
+	require.NoError(t, groupRepo.AddMembers(backend.ID, []uint64{alice.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(backend.ID, 0, []uint64{viewer.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(engineering.ID, 0, []uint64{auditor.ID}))
+
+	t.Run("汇总组及上级组的角色与继承的角色", func(t *testing.T) {
+		grants, err := userRepo.GetEffectivePermissionGrants(alice.ID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"report:view", "role:list", "user:list"}, allowedCodes(grants))
+
+		roleIDs, err := userRepo.GetEffectiveRoleIDs(alice.ID, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uint64{viewer.ID, auditor.ID, base.ID}, roleIDs)
+
+		roles, err := userRepo.GetEffectiveRoles(alice.ID, 0)
+		require.NoError(t, err)
+		codes := make([]string, 0, len(roles))
+		for _, role := range roles {
+			codes = append(codes, role.Code)
+		}
+		assert.ElementsMatch(t, []string{"viewer", "auditor", "base"}, codes)
+	})
+
+	t.Run("记录所属组、嵌套关系与组角色", func(t *testing.T) {
+		groups, err := userRepo.GetGroups(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, backend.ID, groups[0].ID)
+
+		parents, err := userRepo.GetEffectiveGroupParents(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, parents, 1)
+		assert.Equal(t, model.GroupInheritance{GroupID: backend.ID, ParentID: engineering.ID, ParentCode: "engineering", ParentName: "engineering"}, *parents[0])
+
+		assignments, err := userRepo.GetGroupRoleAssignments(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, assignments, 2)
+		assert.Equal(t, "viewer", assignments[0].RoleCode)
+		assert.Equal(t, "backend", assignments[0].GroupCode)
+		assert.Equal(t, "auditor", assignments[1].RoleCode)
+		assert.Equal(t, "engineering", assignments[1].GroupCode)
+	})
+
+	t.Run("嵌套关系存在环时递归仍能终止", func(t *testing.T) {
+		require.NoError(t, db.Create(&model.GroupParent{GroupID: engineering.ID, ParentID: backend.ID}).Error)
+		defer db.Where("group_id = ? AND parent_id = ?", engineering.ID, backend.ID).Delete(&model.GroupParent{})
+
+		roleIDs, err := userRepo.GetEffectiveRoleIDs(alice.ID, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uint64{viewer.ID, auditor.ID, base.ID}, roleIDs)
+
+		affected, err := groupRepo.GetAffectedUserIDs(engineering.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{alice.ID}, affected)
+	})
+
+	t.Run("删除上级组后不再获得其角色", func(t *testing.T) {
+		require.NoError(t, groupRepo.Delete(engineering.ID))
+
+		grants, err := userRepo.GetEffectivePermissionGrants(alice.ID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:list"}, allowedCodes(grants))
+	})
+}
+
+// 测试组的租户角色分配只在该租户内生效，并使成员可以进入该租户
+func TestUserRepository_GroupTenantRoles(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+
+	acme := createTestTenant(t, db, "acme")
+	other := createTestTenant(t, db, "other")
+	orderList := createTestPermission(t, db, "order:list")
+	clerk := createTestTenantRole(t, db, acme, "clerk", orderList)
+
+	sales := createTestGroup(t, db, "sales")
+	bob := createTestUser(t, db, "bob")
+	require.NoError(t, groupRepo.AddMembers(sales.ID, []uint64{bob.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(sales.ID, acme.ID, []uint64{clerk.ID}))
+
+	// 租户角色不能分配到其他租户
+	assert.Error(t, groupRepo.UpdateRoles(sales.ID, other.ID, []uint64{clerk.ID}))
+
+	grants, err := userRepo.GetEffectivePermissionGrants(bob.ID, acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order:list"}, allowedCodes(grants))
+
+	grants, err = userRepo.GetEffectivePermissionGrants(bob.ID, other.ID)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+
+	ok, err := userRepo.HasTenantAccess(bob.ID, acme.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = userRepo.HasTenantAccess(bob.ID, other.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 移出组后失去组的角色
+	require.NoError(t, groupRepo.RemoveMembers(sales.ID, []uint64{bob.ID}))
+	grants, err = userRepo.GetEffectivePermissionGrants(bob.ID, acme.ID)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
+
+// 测试用户组的后代查询、成员分页与受影响用户
+func TestGroupRepository_MembersAndDescendants(t *testing.T) {
+	db := setupTestDB(t)
+	groupRepo := repository.NewGroupRepository(db)
+
+	company := createTestGroup(t, db, "company")
+	engineering := createTestGroup(t, db, "engineering", company)
+	backend := createTestGroup(t, db, "backend", engineering)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	carol := createTestUser(t, db, "carol")
+	require.NoError(t, groupRepo.AddMembers(company.ID, []uint64{alice.ID}))
+	require.NoError(t, groupRepo.AddMembers(backend.ID, []uint64{bob.ID, carol.ID}))
+	// 重复加入保持不变
+	require.NoError(t, groupRepo.AddMembers(backend.ID, []uint64{bob.ID}))
+	softDelete(t, db, &model.User{}, carol.ID)
+
+	descendants, err := groupRepo.GetDescendantIDs(company.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{engineering.ID, backend.ID}, descendants)
+
+	users, total, err := groupRepo.ListMembers(backend.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "bob", users[0].Username)
+
+	count, err := groupRepo.CountMembers(backend.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// 上级组的变化影响全部后代组的成员
+	affected, err := groupRepo.GetAffectedUserIDs(engineering.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{bob.ID, carol.ID}, affected)
+
+	affected, err = groupRepo.GetAffectedUserIDs(company.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{alice.ID, bob.ID, carol.ID}, affected)
+
+	group, err := groupRepo.GetByID(backend.ID)
+	require.NoError(t, err)
+	require.Len(t, group.Parents, 1)
+	assert.Equal(t, engineering.ID, group.Parents[0].ID)
+}
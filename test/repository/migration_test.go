@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// adminPermissionCodes 查询管理员角色已授予的权限编码
+func adminPermissionCodes(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var codes []string
+	require.NoError(t, db.Table("role_permissions").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.code = ?", "admin").
+		Pluck("permissions.code", &codes).Error)
+	return codes
+}
+
+// 测试已部署的系统重启时补齐新增的默认权限并授予管理员
+func TestInitDefaultData_SyncsNewPermissions(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, model.InitDefaultData(db))
+	assert.Contains(t, adminPermissionCodes(t, db), "org:list")
+	assert.Contains(t, adminPermissionCodes(t, db), "api:*")
+
+	// 模拟升级前的部署：org:list 尚不存在，group:delete 已被收回
+	var orgList, groupDelete model.Permission
+	require.NoError(t, db.Where("code = ?", "org:list").First(&orgList).Error)
+	require.NoError(t, db.Where("code = ?", "group:delete").First(&groupDelete).Error)
+	require.NoError(t, db.Where("permission_id IN ?", []uint64{orgList.ID, groupDelete.ID}).Delete(&model.RolePermission{}).Error)
+	require.NoError(t, db.Delete(&orgList).Error)
+
+	require.NoError(t, model.InitDefaultData(db))
+
+	codes := adminPermissionCodes(t, db)
+	assert.Contains(t, codes, "org:list")
+	assert.NotContains(t, codes, "group:delete")
+
+	var count int64
+	require.NoError(t, db.Model(&model.Permission{}).Where("code = ?", "org:list").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// 再次启动不产生变化
+	require.NoError(t, model.InitDefaultData(db))
+	assert.ElementsMatch(t, codes, adminPermissionCodes(t, db))
+}
+
+// 测试迁移删除旧版本角色编码与名称的全局唯一索引
+func TestAutoMigrate_DropsGlobalRoleUniqueIndexes(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_roles_code ON roles (code)").Error)
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_roles_name ON roles (name)").Error)
+
+	require.NoError(t, model.AutoMigrate(db))
+
+	assert.False(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_code"))
+	assert.False(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_name"))
+	assert.True(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_tenant_code"))
+	assert.True(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_tenant_name"))
+}
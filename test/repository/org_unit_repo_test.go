@@ -0,0 +1,281 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createTestOrgUnit 创建测试组织单元，parent 为nil时作为根节点，并加入给定的成员
+func createTestOrgUnit(t *testing.T, db *gorm.DB, code string, parent *model.OrgUnit, members ...*model.User) *model.OrgUnit {
+	t.Helper()
+	unit := &model.OrgUnit{Code: code, Name: code}
+	if parent != nil {
+		unit.ParentID = parent.ID
+	}
+	require.NoError(t, db.Create(unit).Error)
+	for _, member := range members {
+		require.NoError(t, db.Create(&model.OrgUnitMember{OrgUnitID: unit.ID, UserID: member.ID}).Error)
+	}
+	return unit
+}
+
+// dataScopeFixture 数据范围测试数据：company 下有 sales 与 engineering，engineering 下有 backend
+type dataScopeFixture struct {
+	company, sales, engineering, backend *model.OrgUnit
+	ceo, seller, lead, dev, outsider     *model.User
+}
+
+func newDataScopeFixture(t *testing.T, db *gorm.DB) *dataScopeFixture {
+	f := &dataScopeFixture{
+		ceo:      createTestUser(t, db, "ceo"),
+		seller:   createTestUser(t, db, "seller"),
+		lead:     createTestUser(t, db, "lead"),
+		dev:      createTestUser(t, db, "dev"),
+		outsider: createTestUser(t, db, "outsider"),
+	}
+	f.company = createTestOrgUnit(t, db, "company", nil, f.ceo)
+	f.sales = createTestOrgUnit(t, db, "sales", f.company, f.seller)
+	f.engineering = createTestOrgUnit(t, db, "engineering", f.company, f.lead)
+	f.backend = createTestOrgUnit(t, db, "backend", f.engineering, f.dev)
+	return f
+}
+
+// createScopedRole 创建指定数据范围的角色并分配给用户
+func createScopedRole(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, user *model.User, code, dataScope string, unitIDs ...uint64) *model.Role {
+	t.Helper()
+	role := createTestRole(t, db, code)
+	require.NoError(t, roleRepo.SetDataScope(role.ID, dataScope, unitIDs))
+	assignTestRoles(t, db, user, role)
+	return role
+}
+
+// listUsernames 按数据范围列出全部用户名
+func listUsernames(t *testing.T, userRepo repository.UserRepository, scope *model.DataScope) []string {
+	t.Helper()
+	users, total, err := userRepo.List(1, 100, "", 0, scope)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(users)), total)
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	return names
+}
+
+// 测试按角色数据范围解析用户可访问的组织单元，并过滤用户列表
+func TestUserRepository_DataScope(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User
+		expectedUnits func(f *dataScopeFixture) []uint64
+		expectedAll   bool
+		expectedSelf  bool
+		expectedUsers []string
+	}{
+		{
+			name: "全部数据",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.lead, "admin", model.DataScopeAll)
+				return f.lead
+			},
+			expectedAll:   true,
+			expectedUsers: []string{"outsider", "dev", "lead", "seller", "ceo"},
+		},
+		{
+			name: "本单元",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.lead, "manager", model.DataScopeUnit)
+				return f.lead
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{f.engineering.ID} },
+			expectedUsers: []string{"lead"},
+		},
+		{
+			name: "本单元及下级单元",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.lead, "manager", model.DataScopeUnitAndChildren)
+				return f.lead
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{f.engineering.ID, f.backend.ID} },
+			expectedUsers: []string{"dev", "lead"},
+		},
+		{
+			name: "仅本人",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.dev, "staff", model.DataScopeSelf)
+				return f.dev
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{} },
+			expectedSelf:  true,
+			expectedUsers: []string{"dev"},
+		},
+		{
+			name: "自定义单元",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.outsider, "auditor", model.DataScopeCustom, f.sales.ID)
+				return f.outsider
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{f.sales.ID} },
+			expectedUsers: []string{"seller"},
+		},
+		{
+			name: "多个角色取并集，继承的角色同样计入",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				staff := createScopedRole(t, db, roleRepo, f.dev, "staff", model.DataScopeSelf)
+				auditor := createTestRole(t, db, "auditor")
+				require.NoError(t, roleRepo.SetDataScope(auditor.ID, model.DataScopeCustom, []uint64{f.sales.ID}))
+				setTestParents(t, db, staff, auditor)
+				return f.dev
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{f.sales.ID} },
+			expectedSelf:  true,
+			expectedUsers: []string{"dev", "seller"},
+		},
+		{
+			name: "任一角色为全部数据时不受限制",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.dev, "staff", model.DataScopeSelf)
+				createScopedRole(t, db, roleRepo, f.dev, "viewer", model.DataScopeAll)
+				return f.dev
+			},
+			expectedAll:   true,
+			expectedUsers: []string{"outsider", "dev", "lead", "seller", "ceo"},
+		},
+		{
+			name: "没有角色时只能访问本人",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				return f.seller
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{} },
+			expectedSelf:  true,
+			expectedUsers: []string{"seller"},
+		},
+		{
+			name: "不属于任何单元时本单元范围为空",
+			setup: func(t *testing.T, db *gorm.DB, roleRepo repository.RoleRepository, f *dataScopeFixture) *model.User {
+				createScopedRole(t, db, roleRepo, f.outsider, "manager", model.DataScopeUnitAndChildren)
+				return f.outsider
+			},
+			expectedUnits: func(f *dataScopeFixture) []uint64 { return []uint64{} },
+			expectedUsers: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			userRepo := repository.NewUserRepository(db)
+			roleRepo := repository.NewRoleRepository(db)
+			f := newDataScopeFixture(t, db)
+			caller := tt.setup(t, db, roleRepo, f)
+
+			scope, err := userRepo.GetDataScope(caller.ID, 0)
+			require.NoError(t, err)
+			assert.Equal(t, caller.ID, scope.UserID)
+			assert.Equal(t, tt.expectedAll, scope.All)
+			assert.Equal(t, tt.expectedSelf, scope.Self)
+			if tt.expectedUnits != nil {
+				assert.Equal(t, tt.expectedUnits(f), scope.UnitIDs)
+			}
+
+			assert.Equal(t, tt.expectedUsers, listUsernames(t, userRepo, scope))
+		})
+	}
+}
+
+// 测试经由用户组获得的角色与租户角色参与数据范围解析
+func TestUserRepository_DataScopeGroupAndTenant(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	f := newDataScopeFixture(t, db)
+
+	// lead 经由用户组获得本单元及下级范围的全局角色
+	manager := createTestRole(t, db, "manager")
+	require.NoError(t, roleRepo.SetDataScope(manager.ID, model.DataScopeUnitAndChildren, nil))
+	leads := createTestGroup(t, db, "leads")
+	require.NoError(t, groupRepo.AddMembers(leads.ID, []uint64{f.lead.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(leads.ID, 0, []uint64{manager.ID}))
+
+	// 在 acme 租户内还有全部数据范围的租户角色
+	acme := createTestTenant(t, db, "acme")
+	tenantAdmin := createTestTenantRole(t, db, acme, "tenant-admin")
+	assignTestTenantRoles(t, db, f.lead, acme, tenantAdmin)
+
+	scope, err := userRepo.GetDataScope(f.lead.ID, 0)
+	require.NoError(t, err)
+	assert.False(t, scope.All)
+	assert.Equal(t, []uint64{f.engineering.ID, f.backend.ID}, scope.UnitIDs)
+
+	scope, err = userRepo.GetDataScope(f.lead.ID, acme.ID)
+	require.NoError(t, err)
+	assert.True(t, scope.All)
+
+	// 删除下级单元后不再计入
+	require.NoError(t, repository.NewOrgUnitRepository(db).Delete(f.backend.ID))
+	scope, err = userRepo.GetDataScope(f.lead.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{f.engineering.ID}, scope.UnitIDs)
+}
+
+// 测试组织单元子树、成员与角色自定义数据范围的维护
+func TestOrgUnitRepository_SubtreeAndMembers(t *testing.T) {
+	db := setupTestDB(t)
+	orgUnitRepo := repository.NewOrgUnitRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	f := newDataScopeFixture(t, db)
+
+	subtree, err := orgUnitRepo.GetSubtreeIDs(f.company.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{f.company.ID, f.sales.ID, f.engineering.ID, f.backend.ID}, subtree)
+
+	count, err := orgUnitRepo.CountChildren(f.company.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// 重复加入保持不变，已删除的用户不计入成员
+	require.NoError(t, orgUnitRepo.AddMembers(f.backend.ID, []uint64{f.dev.ID, f.outsider.ID}))
+	softDelete(t, db, &model.User{}, f.outsider.ID)
+	users, total, err := orgUnitRepo.ListMembers(f.backend.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "dev", users[0].Username)
+
+	// 移动为根节点时上级单元更新为零值
+	f.backend.ParentID = 0
+	require.NoError(t, orgUnitRepo.Update(f.backend))
+	unit, err := orgUnitRepo.GetByID(f.backend.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), unit.ParentID)
+
+	// 非自定义范围会清空已有的组织单元
+	role := createTestRole(t, db, "auditor")
+	assert.Equal(t, model.DataScopeAll, role.DataScope)
+	require.NoError(t, roleRepo.SetDataScope(role.ID, model.DataScopeCustom, []uint64{f.sales.ID, f.sales.ID}))
+	units, err := roleRepo.GetDataScopeUnits(role.ID)
+	require.NoError(t, err)
+	require.Len(t, units, 1)
+	assert.Equal(t, "sales", units[0].Code)
+	assert.Error(t, roleRepo.SetDataScope(role.ID, model.DataScopeCustom, []uint64{999}))
+
+	require.NoError(t, roleRepo.SetDataScope(role.ID, model.DataScopeSelf, []uint64{f.sales.ID}))
+	units, err = roleRepo.GetDataScopeUnits(role.ID)
+	require.NoError(t, err)
+	assert.Empty(t, units)
+
+	// 删除单元时移除角色自定义范围中对它的引用
+	require.NoError(t, roleRepo.SetDataScope(role.ID, model.DataScopeCustom, []uint64{f.sales.ID}))
+	require.NoError(t, orgUnitRepo.Delete(f.sales.ID))
+	units, err = roleRepo.GetDataScopeUnits(role.ID)
+	require.NoError(t, err)
+	assert.Empty(t, units)
+}
@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试权限树字段的读写：默认类型、按排序列出、统计子节点以及移动回根节点
+func TestPermissionRepository_Tree(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewPermissionRepository(db)
+
+	module := &model.Permission{Code: "system:view", Name: "系统管理", Type: model.PermissionTypeModule, Sort: 2}
+	require.NoError(t, repo.Create(module))
+	menu := &model.Permission{Code: "user:menu", Name: "用户管理", Type: model.PermissionTypeMenu, ParentID: module.ID, Sort: 1, Route: "/system/users"}
+	require.NoError(t, repo.Create(menu))
+	api := &model.Permission{Code: "user:list", Name: "用户列表", ParentID: menu.ID}
+	require.NoError(t, repo.Create(api))
+	assert.Equal(t, model.PermissionTypeAPI, api.Type)
+
+	all, err := repo.ListAll()
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"user:list", "user:menu", "system:view"}, []string{all[0].Code, all[1].Code, all[2].Code})
+
+	count, err := repo.CountChildren(module.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// 零值字段同样会被更新
+	menu.ParentID = 0
+	menu.Sort = 0
+	menu.Route = ""
+	require.NoError(t, repo.Update(menu))
+
+	updated, err := repo.GetByID(menu.ID)
+	require.NoError(t, err)
+	assert.Zero(t, updated.ParentID)
+	assert.Zero(t, updated.Sort)
+	assert.Empty(t, updated.Route)
+	assert.Equal(t, model.PermissionTypeMenu, updated.Type)
+
+	count, err = repo.CountChildren(module.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+// 测试只列出绑定了接口路由的权限
+func TestPermissionRepository_ListRoutes(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewPermissionRepository(db)
+
+	require.NoError(t, repo.Create(&model.Permission{Code: "user:menu", Name: "用户管理", Type: model.PermissionTypeMenu, Route: "/system/users"}))
+	require.NoError(t, repo.Create(&model.Permission{Code: "api:post:api:v1:users:list", Name: "POST /api/v1/users/list", Method: "POST", Route: "/api/v1/users/list"}))
+	require.NoError(t, repo.Create(&model.Permission{Code: "api:post:api:v1:users:create", Name: "POST /api/v1/users/create", Method: "POST", Route: "/api/v1/users/create"}))
+	removed := &model.Permission{Code: "api:post:api:v1:users:delete", Name: "POST /api/v1/users/delete", Method: "POST", Route: "/api/v1/users/delete"}
+	require.NoError(t, repo.Create(removed))
+	require.NoError(t, repo.Delete(removed.ID))
+
+	routes, err := repo.ListRoutes()
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, "/api/v1/users/create", routes[0].Route)
+	assert.Equal(t, "/api/v1/users/list", routes[1].Route)
+	assert.Equal(t, "POST", routes[1].Method)
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试导出策略时读取直接授予与角色分配，已删除的实体不参与导出
+func TestPolicyRepository_GetGrantsAndBindings(t *testing.T) {
+	db := setupTestDB(t)
+	userList := createTestPermission(t, db, "user:list")
+	userDelete := createTestPermission(t, db, "user:delete")
+	admin := createTestRole(t, db, "admin", userList)
+	denyTestPermissions(t, db, admin, userDelete)
+	removed := createTestRole(t, db, "removed", userList)
+	acme := createTestTenant(t, db, "acme")
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	assignTestRoles(t, db, alice, admin, removed)
+	assignTestTenantRoles(t, db, bob, acme, admin)
+	softDelete(t, db, &model.Role{}, removed.ID)
+	repo := repository.NewPolicyRepository(db)
+
+	grants, err := repo.GetGrants()
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, "user:delete", grants[0].PermissionCode)
+	assert.Equal(t, model.EffectDeny, grants[0].Effect)
+	assert.Equal(t, "user:list", grants[1].PermissionCode)
+
+	bindings, err := repo.GetBindings()
+	require.NoError(t, err)
+	assert.Equal(t, []*model.UserRoleBinding{
+		{UserID: alice.ID, Username: "alice", RoleID: admin.ID, RoleCode: "admin"},
+		{UserID: bob.ID, Username: "bob", RoleID: admin.ID, RoleCode: "admin", TenantID: acme.ID, TenantCode: "acme"},
+	}, bindings)
+}
+
+// 测试在一个事务内执行导入变更，新建角色的授予与分配使用事务内生成的ID
+func TestPolicyRepository_Apply(t *testing.T) {
+	db := setupTestDB(t)
+	userList := createTestPermission(t, db, "user:list")
+	userDelete := createTestPermission(t, db, "user:delete")
+	admin := createTestRole(t, db, "admin", userList, userDelete)
+	alice := createTestUser(t, db, "alice")
+	assignTestRoles(t, db, alice, admin)
+	repo := repository.NewPolicyRepository(db)
+
+	auditor := &model.Role{Code: "auditor", Name: "auditor"}
+	require.NoError(t, repo.Apply(&model.PolicyChange{
+		CreateRoles:       []*model.Role{auditor},
+		RemoveGrants:      []model.PolicyGrant{{Role: admin, PermissionID: userDelete.ID}},
+		AddGrants:         []model.PolicyGrant{{Role: auditor, PermissionID: userDelete.ID, Effect: model.EffectDeny}},
+		RemoveAssignments: []model.PolicyAssignment{{UserID: alice.ID, Role: admin}},
+		AddAssignments:    []model.PolicyAssignment{{UserID: alice.ID, Role: auditor}},
+	}))
+	require.NotZero(t, auditor.ID)
+
+	grants, err := repo.GetGrants()
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, "admin", grants[0].RoleCode)
+	assert.Equal(t, "user:list", grants[0].PermissionCode)
+	assert.Equal(t, "auditor", grants[1].RoleCode)
+	assert.Equal(t, model.EffectDeny, grants[1].Effect)
+
+	bindings, err := repo.GetBindings()
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	assert.Equal(t, auditor.ID, bindings[0].RoleID)
+
+	// 任一变更失败时整体回滚
+	err = repo.Apply(&model.PolicyChange{
+		CreateRoles:    []*model.Role{{Code: "viewer", Name: "viewer"}},
+		AddAssignments: []model.PolicyAssignment{{UserID: alice.ID, Role: auditor}},
+	})
+	assert.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&model.Role{}).Where("code = ?", "viewer").Count(&count).Error)
+	assert.Zero(t, count)
+}
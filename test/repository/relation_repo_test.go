@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试关系元组的写入、查询与删除
+func TestRelationRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRelationRepository(db)
+
+	viewer := &model.RelationTuple{ObjectType: "document", ObjectID: "42", Relation: "viewer", SubjectType: "user", SubjectID: "7"}
+	groupViewer := &model.RelationTuple{ObjectType: "document", ObjectID: "42", Relation: "viewer", SubjectType: "group", SubjectID: "eng", SubjectRelation: "member"}
+	member := &model.RelationTuple{ObjectType: "group", ObjectID: "eng", Relation: "member", SubjectType: "user", SubjectID: "7"}
+
+	written, err := repo.Write([]*model.RelationTuple{viewer, groupViewer, member})
+	require.NoError(t, err)
+	assert.Equal(t, 3, written)
+
+	// 重复写入的元组被忽略
+	written, err = repo.Write([]*model.RelationTuple{
+		{ObjectType: "document", ObjectID: "42", Relation: "viewer", SubjectType: "user", SubjectID: "7"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, written)
+
+	subjects, err := repo.GetSubjects("document", "42", "viewer")
+	require.NoError(t, err)
+	assert.Len(t, subjects, 2)
+
+	// 空的主体关系只匹配非用户集主体
+	bySubject, err := repo.GetBySubject("user", "7", "")
+	require.NoError(t, err)
+	assert.Len(t, bySubject, 2)
+	bySubject, err = repo.GetBySubject("group", "eng", "member")
+	require.NoError(t, err)
+	require.Len(t, bySubject, 1)
+	assert.Equal(t, "document", bySubject[0].ObjectType)
+
+	tuples, total, err := repo.List(&model.RelationTuple{ObjectType: "document"}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, tuples, 2)
+
+	deleted, err := repo.Delete([]*model.RelationTuple{
+		{ObjectType: "document", ObjectID: "42", Relation: "viewer", SubjectType: "user", SubjectID: "7"},
+		{ObjectType: "document", ObjectID: "42", Relation: "owner", SubjectType: "user", SubjectID: "7"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	subjects, err = repo.GetSubjects("document", "42", "viewer")
+	require.NoError(t, err)
+	require.Len(t, subjects, 1)
+	assert.Equal(t, "member", subjects[0].SubjectRelation)
+}
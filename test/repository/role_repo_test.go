@@ -0,0 +1,251 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 提取角色编码便于断言
+func roleCodes(roles []*model.Role) []string {
+	codes := make([]string, 0, len(roles))
+	for _, role := range roles {
+		codes = append(codes, role.Code)
+	}
+	return codes
+}
+
+// 测试查询角色的祖先与后代
+func TestRoleRepository_AncestorsAndDescendants(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	// viewer <- editor <- owner，auditor <- owner
+	viewer := createTestRole(t, db, "viewer", createTestPermission(t, db, "user:list"))
+	editor := createTestRole(t, db, "editor")
+	auditor := createTestRole(t, db, "auditor")
+	owner := createTestRole(t, db, "owner")
+	require.NoError(t, repo.SetParents(editor.ID, []uint64{viewer.ID}))
+	require.NoError(t, repo.SetParents(owner.ID, []uint64{editor.ID, auditor.ID}))
+
+	ancestors, err := repo.GetAncestors(owner.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"viewer", "editor", "auditor"}, roleCodes(ancestors))
+
+	descendants, err := repo.GetDescendants(viewer.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"editor", "owner"}, roleCodes(descendants))
+
+	// 祖先角色需携带其直接权限
+	for _, ancestor := range ancestors {
+		if ancestor.ID == viewer.ID {
+			assert.Len(t, ancestor.Permissions, 1)
+		}
+	}
+
+	// 重新设置父角色会覆盖原有继承关系
+	require.NoError(t, repo.SetParents(owner.ID, []uint64{auditor.ID}))
+	ancestors, err = repo.GetAncestors(owner.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"auditor"}, roleCodes(ancestors))
+
+	// 父角色不存在时返回错误
+	assert.Error(t, repo.SetParents(owner.ID, []uint64{999}))
+}
+
+// 测试删除角色时清理继承关系
+func TestRoleRepository_DeleteRemovesHierarchy(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	viewer := createTestRole(t, db, "viewer")
+	editor := createTestRole(t, db, "editor")
+	owner := createTestRole(t, db, "owner")
+	require.NoError(t, repo.SetParents(editor.ID, []uint64{viewer.ID}))
+	require.NoError(t, repo.SetParents(owner.ID, []uint64{editor.ID}))
+
+	require.NoError(t, repo.Delete(editor.ID))
+
+	var count int64
+	require.NoError(t, db.Model(&model.RoleParent{}).Where("role_id = ? OR parent_id = ?", editor.ID, editor.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	ancestors, err := repo.GetAncestors(owner.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, ancestors)
+}
+
+// 测试设置角色的允许与拒绝授予
+func TestRoleRepository_PermissionGrants(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	userAll := createTestPermission(t, db, "user:*")
+	userList := createTestPermission(t, db, "user:list")
+	userDelete := createTestPermission(t, db, "user:delete")
+	support := createTestRole(t, db, "support")
+
+	require.NoError(t, repo.SetPermissionGrants(support.ID, []model.RolePermission{
+		{PermissionID: userAll.ID, Effect: model.EffectAllow},
+		{PermissionID: userDelete.ID, Effect: model.EffectDeny},
+	}))
+
+	role, err := repo.GetRoleWithPermissions(support.ID)
+	require.NoError(t, err)
+	effects := make(map[uint64]string)
+	for _, grant := range role.PermissionGrants {
+		effects[grant.PermissionID] = grant.Effect
+	}
+	assert.Equal(t, map[uint64]string{userAll.ID: model.EffectAllow, userDelete.ID: model.EffectDeny}, effects)
+
+	// 按权限ID更新时只替换允许的权限，拒绝规则保持不变
+	require.NoError(t, repo.UpdatePermissions(support.ID, []uint64{userList.ID}))
+	var grants []model.RolePermission
+	require.NoError(t, db.Where("role_id = ?", support.ID).Order("permission_id").Find(&grants).Error)
+	require.Len(t, grants, 2)
+	assert.Equal(t, userList.ID, grants[0].PermissionID)
+	assert.Equal(t, model.EffectAllow, grants[0].Effect)
+	assert.Equal(t, userDelete.ID, grants[1].PermissionID)
+	assert.Equal(t, model.EffectDeny, grants[1].Effect)
+
+	// 权限不存在时整体回滚
+	assert.Error(t, repo.SetPermissionGrants(support.ID, []model.RolePermission{{PermissionID: 999, Effect: model.EffectAllow}}))
+	var count int64
+	require.NoError(t, db.Model(&model.RolePermission{}).Where("role_id = ?", support.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+// 测试按模板查询角色，忽略已删除与非模板创建的角色
+func TestRoleRepo_ListByTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	perm := createTestPermission(t, db, "user:list")
+	first := createTestRole(t, db, "auditor", perm)
+	second := createTestRole(t, db, "auditor-eu")
+	deleted := createTestRole(t, db, "auditor-old")
+	createTestRole(t, db, "other")
+	for _, role := range []*model.Role{first, second, deleted} {
+		require.NoError(t, db.Model(role).Updates(map[string]interface{}{"template_code": "auditor", "template_version": 1}).Error)
+	}
+	softDelete(t, db, &model.Role{}, deleted.ID)
+
+	roles, err := repo.ListByTemplate("auditor")
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	assert.Equal(t, first.ID, roles[0].ID)
+	assert.Equal(t, 1, roles[0].TemplateVersion)
+	require.Len(t, roles[0].PermissionGrants, 1)
+	assert.Equal(t, second.ID, roles[1].ID)
+}
+
+// 测试角色编码与名称只在所属租户内唯一
+func TestRoleRepo_UniquePerTenant(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	acme := createTestTenant(t, db, "acme")
+	globex := createTestTenant(t, db, "globex")
+	global := &model.Role{Code: "admin", Name: "管理员"}
+	first := &model.Role{Code: "admin", Name: "管理员", TenantID: acme.ID}
+	second := &model.Role{Code: "admin", Name: "管理员", TenantID: globex.ID}
+	for _, role := range []*model.Role{global, first, second} {
+		require.NoError(t, repo.Create(role))
+	}
+
+	// 同一租户内编码或名称重复时由唯一索引拒绝
+	assert.Error(t, repo.Create(&model.Role{Code: "admin", Name: "另一个管理员", TenantID: acme.ID}))
+	assert.Error(t, repo.Create(&model.Role{Code: "admin2", Name: "管理员", TenantID: acme.ID}))
+
+	role, err := repo.GetByCode("admin", acme.ID)
+	require.NoError(t, err)
+	require.NotNil(t, role)
+	assert.Equal(t, first.ID, role.ID)
+
+	role, err = repo.GetByName("管理员", globex.ID)
+	require.NoError(t, err)
+	require.NotNil(t, role)
+	assert.Equal(t, second.ID, role.ID)
+
+	role, err = repo.GetByCode("admin", model.GlobalTenantID)
+	require.NoError(t, err)
+	require.NotNil(t, role)
+	assert.Equal(t, global.ID, role.ID)
+
+	assert.False(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_code"))
+	assert.True(t, db.Migrator().HasIndex(&model.Role{}, "idx_roles_tenant_code"))
+}
+
+// 测试创建角色与写入授予记录在同一事务内，授予失败时不留下角色
+func TestRoleRepo_CreateWithGrants(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	perm := createTestPermission(t, db, "user:list")
+	parent := createTestRole(t, db, "viewer")
+
+	role := &model.Role{Code: "auditor", Name: "auditor", TemplateCode: "auditor", TemplateVersion: 2}
+	require.NoError(t, repo.CreateWithGrants(role, []uint64{parent.ID}, []model.RolePermission{
+		{PermissionID: perm.ID, Effect: model.EffectDeny},
+	}))
+	created, err := repo.GetByID(role.ID)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, 2, created.TemplateVersion)
+	require.Len(t, created.PermissionGrants, 1)
+	assert.Equal(t, model.EffectDeny, created.PermissionGrants[0].Effect)
+	require.Len(t, created.Parents, 1)
+	assert.Equal(t, parent.ID, created.Parents[0].ID)
+
+	// 引用不存在的权限时整体回滚
+	err = repo.CreateWithGrants(&model.Role{Code: "broken", Name: "broken"}, []uint64{parent.ID}, []model.RolePermission{
+		{PermissionID: perm.ID, Effect: model.EffectAllow},
+		{PermissionID: 999, Effect: model.EffectAllow},
+	})
+	assert.Error(t, err)
+	missing, err := repo.GetByCode("broken", model.GlobalTenantID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	var parents int64
+	require.NoError(t, db.Model(&model.RoleParent{}).Where("parent_id = ?", parent.ID).Count(&parents).Error)
+	assert.Equal(t, int64(1), parents)
+}
+
+// 测试模板同步在同一事务内写入全部角色，任一角色失败时全部回滚
+func TestRoleRepo_SyncTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRoleRepository(db)
+
+	userList := createTestPermission(t, db, "user:list")
+	roleList := createTestPermission(t, db, "role:list")
+	first := createTestRole(t, db, "auditor", userList)
+	second := createTestRole(t, db, "auditor-eu", userList)
+
+	grants := []model.RolePermission{{PermissionID: userList.ID, Effect: model.EffectAllow}, {PermissionID: roleList.ID, Effect: model.EffectAllow}}
+	err := repo.SyncTemplate([]*model.RoleTemplateSync{
+		{RoleID: first.ID, Version: 2, Grants: grants},
+		{RoleID: second.ID, Version: 2, Grants: []model.RolePermission{{PermissionID: 999, Effect: model.EffectAllow}}},
+	})
+	assert.Error(t, err)
+	role, err := repo.GetByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, role.TemplateVersion)
+	assert.Len(t, role.PermissionGrants, 1)
+
+	require.NoError(t, repo.SyncTemplate([]*model.RoleTemplateSync{
+		{RoleID: first.ID, Version: 2, Grants: grants},
+		{RoleID: second.ID, Version: 2},
+	}))
+	role, err = repo.GetByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, role.TemplateVersion)
+	assert.Len(t, role.PermissionGrants, 2)
+	role, err = repo.GetByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, role.TemplateVersion)
+	assert.Len(t, role.PermissionGrants, 1)
+}
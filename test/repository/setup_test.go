@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB 创建基于内存SQLite的测试数据库并迁移表结构
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	// 与服务启动时一致的迁移流程
+	require.NoError(t, model.AutoMigrate(db))
+
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	return db
+}
+
+// createTestUser 创建测试用户
+func createTestUser(t *testing.T, db *gorm.DB, username string) *model.User {
+	t.Helper()
+	user := &model.User{Username: username, Email: username + "@example.com", Password: "x"}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+// createTestRole 创建测试角色并分配权限
+func createTestRole(t *testing.T, db *gorm.DB, code string, permissions ...*model.Permission) *model.Role {
+	t.Helper()
+	role := &model.Role{Code: code, Name: code}
+	require.NoError(t, db.Create(role).Error)
+	for _, p := range permissions {
+		require.NoError(t, db.Create(&model.RolePermission{RoleID: role.ID, PermissionID: p.ID}).Error)
+	}
+	return role
+}
+
+// denyTestPermissions 以拒绝效果为角色授予权限
+func denyTestPermissions(t *testing.T, db *gorm.DB, role *model.Role, permissions ...*model.Permission) {
+	t.Helper()
+	for _, p := range permissions {
+		require.NoError(t, db.Create(&model.RolePermission{RoleID: role.ID, PermissionID: p.ID, Effect: model.EffectDeny}).Error)
+	}
+}
+
+// createTestPermission 创建测试权限
+func createTestPermission(t *testing.T, db *gorm.DB, code string) *model.Permission {
+	t.Helper()
+	permission := &model.Permission{Code: code, Name: code}
+	require.NoError(t, db.Create(permission).Error)
+	return permission
+}
+
+// assignTestRoles 为用户分配角色
+func assignTestRoles(t *testing.T, db *gorm.DB, user *model.User, roles ...*model.Role) {
+	t.Helper()
+	for _, role := range roles {
+		require.NoError(t, db.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID}).Error)
+	}
+}
+
+// setTestParents 设置角色的父角色
+func setTestParents(t *testing.T, db *gorm.DB, role *model.Role, parents ...*model.Role) {
+	t.Helper()
+	for _, parent := range parents {
+		require.NoError(t, db.Create(&model.RoleParent{RoleID: role.ID, ParentID: parent.ID}).Error)
+	}
+}
+
+// softDelete 直接标记记录为已删除，模拟软删除
+func softDelete(t *testing.T, db *gorm.DB, value interface{}, id uint64) {
+	t.Helper()
+	require.NoError(t, db.Model(value).Where("id = ?", id).Update("deleted_at", model.SoftDelete()).Error)
+}
+
+// createTestTenant 创建测试租户
+func createTestTenant(t *testing.T, db *gorm.DB, code string) *model.Tenant {
+	t.Helper()
+	tenant := &model.Tenant{Code: code, Name: code}
+	require.NoError(t, db.Create(tenant).Error)
+	return tenant
+}
+
+// createTestTenantRole 创建属于指定租户的测试角色并分配权限
+func createTestTenantRole(t *testing.T, db *gorm.DB, tenant *model.Tenant, code string, permissions ...*model.Permission) *model.Role {
+	t.Helper()
+	role := createTestRole(t, db, code, permissions...)
+	require.NoError(t, db.Model(role).Update("tenant_id", tenant.ID).Error)
+	role.TenantID = tenant.ID
+	return role
+}
+
+// assignTestTenantRoles 在指定租户内为用户分配角色
+func assignTestTenantRoles(t *testing.T, db *gorm.DB, user *model.User, tenant *model.Tenant, roles ...*model.Role) {
+	t.Helper()
+	for _, role := range roles {
+		require.NoError(t, db.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID, TenantID: tenant.ID}).Error)
+	}
+}
+
+// assignTestRoleWithValidity 为用户分配带生效区间的全局角色
+func assignTestRoleWithValidity(t *testing.T, db *gorm.DB, user *model.User, role *model.Role, validFrom, validUntil *int64) {
+	t.Helper()
+	require.NoError(t, db.Create(&model.UserRole{
+		UserID:   user.ID,
+		RoleID:   role.ID,
+		Validity: model.Validity{ValidFrom: validFrom, ValidUntil: validUntil},
+	}).Error)
+}
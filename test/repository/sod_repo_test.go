@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试职责分离规则的增删改查
+func TestSoDRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewSoDRepository(db)
+
+	maker := createTestRole(t, db, "maker")
+	checker := createTestRole(t, db, "checker")
+	auditor := createTestRole(t, db, "auditor")
+
+	rule := &model.SoDRule{Name: "付款审批", Type: model.SoDStatic, MaxRoles: 1}
+	require.NoError(t, repo.Create(rule, []uint64{maker.ID, checker.ID}))
+	assert.NotZero(t, rule.ID)
+
+	found, err := repo.GetByName("付款审批")
+	assert.NoError(t, err)
+	require.NotNil(t, found)
+	assert.ElementsMatch(t, []uint64{maker.ID, checker.ID}, found.RoleIDs())
+
+	// 更新会替换规则的角色
+	rule.Type = model.SoDDynamic
+	require.NoError(t, repo.Update(rule, []uint64{maker.ID, checker.ID, auditor.ID}))
+	found, err = repo.GetByID(rule.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.SoDDynamic, found.Type)
+	assert.Len(t, found.Roles, 3)
+
+	static, err := repo.ListByType(model.SoDStatic)
+	assert.NoError(t, err)
+	assert.Empty(t, static)
+
+	rules, total, err := repo.List(1, 10, "付款", model.SoDDynamic)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, rules, 1)
+
+	// 已删除的角色不再出现在规则中
+	softDelete(t, db, &model.Role{}, auditor.ID)
+	found, err = repo.GetByID(rule.ID)
+	assert.NoError(t, err)
+	assert.Len(t, found.Roles, 2)
+
+	require.NoError(t, repo.Delete(rule.ID))
+	found, err = repo.GetByID(rule.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+// 测试查询职责分离校验所需的角色分配，过期分配与已删除的角色、用户不计入
+func TestSoDRepository_Assignments(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewSoDRepository(db)
+
+	tenant := createTestTenant(t, db, "acme")
+	maker := createTestRole(t, db, "maker")
+	checker := createTestRole(t, db, "checker")
+	retired := createTestRole(t, db, "retired")
+
+	alice := createTestUser(t, db, "alice")
+	assignTestRoles(t, db, alice, maker, retired)
+	assignTestTenantRoles(t, db, alice, tenant, checker)
+	softDelete(t, db, &model.Role{}, retired.ID)
+
+	past := time.Now().Add(-time.Hour).Unix()
+	bob := createTestUser(t, db, "bob")
+	assignTestRoleWithValidity(t, db, bob, checker, nil, &past)
+
+	carol := createTestUser(t, db, "carol")
+	assignTestRoles(t, db, carol, maker)
+	softDelete(t, db, &model.User{}, carol.ID)
+
+	assignments, err := repo.GetUserAssignments(alice.ID)
+	assert.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, maker.ID, assignments[0].RoleID)
+	assert.Equal(t, uint64(0), assignments[0].TenantID)
+	assert.Equal(t, checker.ID, assignments[1].RoleID)
+	assert.Equal(t, tenant.ID, assignments[1].TenantID)
+	assert.Equal(t, "checker", assignments[1].RoleCode)
+
+	assignments, err = repo.GetRoleAssignments([]uint64{maker.ID, checker.ID})
+	assert.NoError(t, err)
+	require.Len(t, assignments, 2)
+	for _, assignment := range assignments {
+		assert.Equal(t, alice.ID, assignment.UserID)
+	}
+}
+
+// 测试经由嵌套用户组获得的角色计入职责分离校验，并可预览用户组变更后的分配
+func TestSoDRepository_GroupAssignments(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewSoDRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+
+	tenant := createTestTenant(t, db, "acme")
+	maker := createTestRole(t, db, "maker")
+	checker := createTestRole(t, db, "checker")
+	auditor := createTestRole(t, db, "auditor")
+
+	// alice 直接持有 auditor，并属于嵌套在 finance 中的 payments
+	finance := createTestGroup(t, db, "finance")
+	payments := createTestGroup(t, db, "payments", finance)
+	audit := createTestGroup(t, db, "audit")
+	require.NoError(t, groupRepo.UpdateRoles(finance.ID, 0, []uint64{checker.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(payments.ID, tenant.ID, []uint64{maker.ID}))
+	require.NoError(t, groupRepo.UpdateRoles(audit.ID, 0, []uint64{auditor.ID}))
+
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	assignTestRoles(t, db, alice, auditor)
+	require.NoError(t, groupRepo.AddMembers(payments.ID, []uint64{alice.ID}))
+
+	// 只比较角色、租户与来源用户组
+	type assignmentKey struct{ userID, roleID, tenantID, groupID uint64 }
+	keys := func(assignments []*model.RoleAssignment) []assignmentKey {
+		result := make([]assignmentKey, 0, len(assignments))
+		for _, assignment := range assignments {
+			result = append(result, assignmentKey{assignment.UserID, assignment.RoleID, assignment.TenantID, assignment.GroupID})
+		}
+		return result
+	}
+
+	t.Run("合并直接分配与经由上级组的分配", func(t *testing.T) {
+		assignments, err := repo.GetUserAssignments(alice.ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []assignmentKey{
+			{alice.ID, auditor.ID, 0, 0},
+			{alice.ID, checker.ID, 0, finance.ID},
+			{alice.ID, maker.ID, tenant.ID, payments.ID},
+		}, keys(assignments))
+
+		assignments, err = repo.GetRoleAssignments([]uint64{checker.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []assignmentKey{{alice.ID, checker.ID, 0, finance.ID}}, keys(assignments))
+	})
+
+	t.Run("预览加入用户组", func(t *testing.T) {
+		assignments, err := repo.PreviewAssignments([]uint64{bob.ID}, &model.GroupChange{GroupID: payments.ID, Members: []uint64{bob.ID}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []assignmentKey{
+			{bob.ID, checker.ID, 0, finance.ID},
+			{bob.ID, maker.ID, tenant.ID, payments.ID},
+		}, keys(assignments))
+
+		// 预览不会写入成员关系
+		assignments, err = repo.GetUserAssignments(bob.ID)
+		require.NoError(t, err)
+		assert.Empty(t, assignments)
+	})
+
+	t.Run("预览替换上级组", func(t *testing.T) {
+		assignments, err := repo.PreviewAssignments([]uint64{alice.ID}, &model.GroupChange{GroupID: payments.ID, Parents: []uint64{audit.ID}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []assignmentKey{
+			{alice.ID, auditor.ID, 0, 0},
+			{alice.ID, auditor.ID, 0, audit.ID},
+			{alice.ID, maker.ID, tenant.ID, payments.ID},
+		}, keys(assignments))
+
+		assignments, err = repo.PreviewAssignments([]uint64{alice.ID}, &model.GroupChange{GroupID: payments.ID, Parents: []uint64{}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []assignmentKey{
+			{alice.ID, auditor.ID, 0, 0},
+			{alice.ID, maker.ID, tenant.ID, payments.ID},
+		}, keys(assignments))
+	})
+
+	t.Run("已删除的用户组不计入", func(t *testing.T) {
+		softDelete(t, db, &model.Group{}, finance.ID)
+		assignments, err := repo.GetUserAssignments(alice.ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []assignmentKey{
+			{alice.ID, auditor.ID, 0, 0},
+			{alice.ID, maker.ID, tenant.ID, payments.ID},
+		}, keys(assignments))
+	})
+}
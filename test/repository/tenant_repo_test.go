@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// tenantFixture 多租户测试数据：全局角色 member 与两个租户各自的角色
+type tenantFixture struct {
+	acme, globex *model.Tenant
+	member       *model.Role
+	acmeAdmin    *model.Role
+	globexAdmin  *model.Role
+	user         *model.User
+}
+
+// setupTenantFixture 创建两个租户，用户全局拥有 member，在各租户内拥有该租户的管理员角色
+func setupTenantFixture(t *testing.T, db *gorm.DB) *tenantFixture {
+	t.Helper()
+	f := &tenantFixture{
+		acme:   createTestTenant(t, db, "acme"),
+		globex: createTestTenant(t, db, "globex"),
+	}
+	f.member = createTestRole(t, db, "member", createTestPermission(t, db, "profile:read"))
+	f.acmeAdmin = createTestTenantRole(t, db, f.acme, "acme-admin", createTestPermission(t, db, "acme:manage"))
+	f.globexAdmin = createTestTenantRole(t, db, f.globex, "globex-admin", createTestPermission(t, db, "globex:manage"))
+	f.user = createTestUser(t, db, "kate")
+	assignTestRoles(t, db, f.user, f.member)
+	assignTestTenantRoles(t, db, f.user, f.acme, f.acmeAdmin)
+	assignTestTenantRoles(t, db, f.user, f.globex, f.globexAdmin)
+	return f
+}
+
+// 测试有效权限只计入全局分配与当前租户的分配
+func TestUserRepository_TenantScopedPermissions(t *testing.T) {
+	db := setupTestDB(t)
+	f := setupTenantFixture(t, db)
+	repo := repository.NewUserRepository(db)
+
+	tests := []struct {
+		name          string
+		tenantID      uint64
+		expectedCodes []string
+		expectedRoles []uint64
+	}{
+		{
+			name:          "未选择租户时仅全局角色生效",
+			tenantID:      0,
+			expectedCodes: []string{"profile:read"},
+			expectedRoles: []uint64{f.member.ID},
+		},
+		{
+			name:          "租户内叠加该租户的角色",
+			tenantID:      f.acme.ID,
+			expectedCodes: []string{"profile:read", "acme:manage"},
+			expectedRoles: []uint64{f.member.ID, f.acmeAdmin.ID},
+		},
+		{
+			name:          "不同租户的角色互不可见",
+			tenantID:      f.globex.ID,
+			expectedCodes: []string{"profile:read", "globex:manage"},
+			expectedRoles: []uint64{f.member.ID, f.globexAdmin.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			grants, err := repo.GetEffectivePermissionGrants(f.user.ID, tt.tenantID)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedCodes, allowedCodes(grants))
+
+			roleIDs, err := repo.GetEffectiveRoleIDs(f.user.ID, tt.tenantID)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedRoles, roleIDs)
+		})
+	}
+}
+
+// 测试按租户维护用户角色分配
+func TestUserRepository_TenantRoles(t *testing.T) {
+	db := setupTestDB(t)
+	f := setupTenantFixture(t, db)
+	repo := repository.NewUserRepository(db)
+
+	// 租户角色不能在其他租户内分配
+	assert.Error(t, repo.AddRoles(f.user.ID, f.globex.ID, []uint64{f.acmeAdmin.ID}, model.Validity{}))
+
+	// 全局角色可以在任意租户内分配
+	require.NoError(t, repo.AddRoles(f.user.ID, f.acme.ID, []uint64{f.member.ID}, model.Validity{}))
+
+	// 替换某个租户的分配不影响其他租户与全局分配
+	require.NoError(t, repo.UpdateRoles(f.user.ID, f.acme.ID, nil, model.Validity{}))
+	var count int64
+	require.NoError(t, db.Model(&model.UserRole{}).Where("user_id = ?", f.user.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	require.NoError(t, repo.RemoveRoles(f.user.ID, f.globex.ID, []uint64{f.globexAdmin.ID}))
+	grants, err := repo.GetEffectivePermissionGrants(f.user.ID, f.globex.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"profile:read"}, allowedCodes(grants))
+}
+
+// 测试用户能否进入租户
+func TestUserRepository_HasTenantAccess(t *testing.T) {
+	db := setupTestDB(t)
+	f := setupTenantFixture(t, db)
+	other := createTestTenant(t, db, "initech")
+	outsider := createTestUser(t, db, "leo")
+	assignTestTenantRoles(t, db, outsider, f.acme, f.acmeAdmin)
+	repo := repository.NewUserRepository(db)
+
+	tests := []struct {
+		name     string
+		userID   uint64
+		tenantID uint64
+		expected bool
+	}{
+		{name: "租户内有角色分配", userID: outsider.ID, tenantID: f.acme.ID, expected: true},
+		{name: "租户内无角色分配", userID: outsider.ID, tenantID: f.globex.ID, expected: false},
+		{name: "全局角色跨租户生效", userID: f.user.ID, tenantID: other.ID, expected: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := repo.HasTenantAccess(tt.userID, tt.tenantID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
+
+// 测试用户与角色列表的租户过滤
+func TestTenantFilters(t *testing.T) {
+	db := setupTestDB(t)
+	f := setupTenantFixture(t, db)
+	outsider := createTestUser(t, db, "mike")
+	assignTestRoles(t, db, outsider, f.member)
+
+	users, total, err := repository.NewUserRepository(db).List(1, 10, "", f.acme.ID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, f.user.ID, users[0].ID)
+
+	roles, total, err := repository.NewRoleRepository(db).List(1, 10, "", f.acme.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	var codes []string
+	for _, role := range roles {
+		codes = append(codes, role.Code)
+	}
+	assert.ElementsMatch(t, []string{"member", "acme-admin"}, codes)
+}
+
+// 测试租户使用计数
+func TestTenantRepository_CountUsage(t *testing.T) {
+	db := setupTestDB(t)
+	f := setupTenantFixture(t, db)
+	empty := createTestTenant(t, db, "initech")
+	repo := repository.NewTenantRepository(db)
+
+	count, err := repo.CountUsage(f.acme.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count) // 一个租户角色与一条角色分配
+
+	count, err = repo.CountUsage(empty.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	// 租户内直接授予用户的权限同样计入
+	permission := createTestPermission(t, db, "report:export")
+	require.NoError(t, repository.NewUserRepository(db).AddPermissions(f.user.ID, empty.ID, []uint64{permission.ID}, model.EffectAllow))
+	count, err = repo.CountUsage(empty.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	tenant, err := repo.GetByCode("globex")
+	assert.NoError(t, err)
+	require.NotNil(t, tenant)
+	assert.Equal(t, f.globex.ID, tenant.ID)
+}
@@ -0,0 +1,374 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// allowedCodes 提取授予记录中允许的权限编码（去重）
+func allowedCodes(grants []*model.PermissionGrant) []string {
+	var codes []string
+	seen := make(map[string]struct{})
+	for _, grant := range grants {
+		if _, ok := seen[grant.PermissionCode]; ok || grant.Effect != model.EffectAllow {
+			continue
+		}
+		seen[grant.PermissionCode] = struct{}{}
+		codes = append(codes, grant.PermissionCode)
+	}
+	return codes
+}
+
+// 测试联表查询用户有效权限
+func TestUserRepository_GetEffectivePermissionGrants(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, db *gorm.DB) uint64
+		expected []string
+	}{
+		{
+			name: "汇总多个角色的权限并去重",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userUpdate := createTestPermission(t, db, "user:update")
+				roleList := createTestPermission(t, db, "role:list")
+				editor := createTestRole(t, db, "editor", userList, userUpdate)
+				viewer := createTestRole(t, db, "viewer", userList, roleList)
+				user := createTestUser(t, db, "alice")
+				assignTestRoles(t, db, user, editor, viewer)
+				return user.ID
+			},
+			expected: []string{"role:list", "user:list", "user:update"},
+		},
+		{
+			name: "忽略已软删除的角色",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userDelete := createTestPermission(t, db, "user:delete")
+				viewer := createTestRole(t, db, "viewer", userList)
+				admin := createTestRole(t, db, "admin", userDelete)
+				user := createTestUser(t, db, "bob")
+				assignTestRoles(t, db, user, viewer, admin)
+				softDelete(t, db, &model.Role{}, admin.ID)
+				return user.ID
+			},
+			expected: []string{"user:list"},
+		},
+		{
+			name: "忽略已软删除的权限",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userDelete := createTestPermission(t, db, "user:delete")
+				admin := createTestRole(t, db, "admin", userList, userDelete)
+				user := createTestUser(t, db, "carol")
+				assignTestRoles(t, db, user, admin)
+				softDelete(t, db, &model.Permission{}, userDelete.ID)
+				return user.ID
+			},
+			expected: []string{"user:list"},
+		},
+		{
+			name: "已软删除的用户没有任何权限",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				viewer := createTestRole(t, db, "viewer", userList)
+				user := createTestUser(t, db, "dave")
+				assignTestRoles(t, db, user, viewer)
+				softDelete(t, db, &model.User{}, user.ID)
+				return user.ID
+			},
+			expected: nil,
+		},
+		{
+			name: "包含从祖先角色继承的权限",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userUpdate := createTestPermission(t, db, "user:update")
+				userDelete := createTestPermission(t, db, "user:delete")
+				viewer := createTestRole(t, db, "viewer", userList)
+				editor := createTestRole(t, db, "editor", userUpdate)
+				owner := createTestRole(t, db, "owner", userDelete)
+				setTestParents(t, db, editor, viewer)
+				setTestParents(t, db, owner, editor)
+				user := createTestUser(t, db, "frank")
+				assignTestRoles(t, db, user, owner)
+				return user.ID
+			},
+			expected: []string{"user:delete", "user:list", "user:update"},
+		},
+		{
+			name: "已软删除的中间角色中断继承链",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userUpdate := createTestPermission(t, db, "user:update")
+				userDelete := createTestPermission(t, db, "user:delete")
+				viewer := createTestRole(t, db, "viewer", userList)
+				editor := createTestRole(t, db, "editor", userUpdate)
+				owner := createTestRole(t, db, "owner", userDelete)
+				setTestParents(t, db, editor, viewer)
+				setTestParents(t, db, owner, editor)
+				user := createTestUser(t, db, "grace")
+				assignTestRoles(t, db, user, owner)
+				softDelete(t, db, &model.Role{}, editor.ID)
+				return user.ID
+			},
+			expected: []string{"user:delete"},
+		},
+		{
+			name: "继承关系存在环时查询仍能终止",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				userList := createTestPermission(t, db, "user:list")
+				userUpdate := createTestPermission(t, db, "user:update")
+				a := createTestRole(t, db, "a", userList)
+				b := createTestRole(t, db, "b", userUpdate)
+				setTestParents(t, db, a, b)
+				setTestParents(t, db, b, a)
+				user := createTestUser(t, db, "heidi")
+				assignTestRoles(t, db, user, a)
+				return user.ID
+			},
+			expected: []string{"user:list", "user:update"},
+		},
+		{
+			name: "未分配角色的用户没有任何权限",
+			setup: func(t *testing.T, db *gorm.DB) uint64 {
+				createTestPermission(t, db, "user:list")
+				return createTestUser(t, db, "erin").ID
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			userID := tt.setup(t, db)
+
+			grants, err := repository.NewUserRepository(db).GetEffectivePermissionGrants(userID, 0)
+
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, allowedCodes(grants))
+		})
+	}
+}
+
+// 测试授予记录携带效果与来源角色
+func TestUserRepository_GetEffectivePermissionGrants_Deny(t *testing.T) {
+	db := setupTestDB(t)
+	userAll := createTestPermission(t, db, "user:*")
+	userDelete := createTestPermission(t, db, "user:delete")
+	support := createTestRole(t, db, "support", userAll)
+	restricted := createTestRole(t, db, "restricted")
+	denyTestPermissions(t, db, restricted, userDelete)
+	setTestParents(t, db, support, restricted)
+	user := createTestUser(t, db, "ivan")
+	assignTestRoles(t, db, user, support)
+
+	grants, err := repository.NewUserRepository(db).GetEffectivePermissionGrants(user.ID, 0)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*model.PermissionGrant{
+		{RoleID: support.ID, RoleCode: "support", RoleName: "support", PermissionID: userAll.ID, PermissionCode: "user:*", Effect: model.EffectAllow},
+		{RoleID: restricted.ID, RoleCode: "restricted", RoleName: "restricted", PermissionID: userDelete.ID, PermissionCode: "user:delete", Effect: model.EffectDeny},
+	}, grants)
+}
+
+// 测试授予记录携带条件表达式，直接授予的权限没有条件
+func TestUserRepository_GetEffectivePermissionGrants_Condition(t *testing.T) {
+	db := setupTestDB(t)
+	userUpdate := createTestPermission(t, db, "user:update")
+	userList := createTestPermission(t, db, "user:list")
+	self := createTestRole(t, db, "self")
+	require.NoError(t, repository.NewRoleRepository(db).SetPermissionGrants(self.ID, []model.RolePermission{
+		{PermissionID: userUpdate.ID, Effect: model.EffectAllow, Condition: "resource.id == subject.id"},
+	}))
+	user := createTestUser(t, db, "judy")
+	assignTestRoles(t, db, user, self)
+	require.NoError(t, db.Create(&model.UserPermission{UserID: user.ID, PermissionID: userList.ID}).Error)
+
+	grants, err := repository.NewUserRepository(db).GetEffectivePermissionGrants(user.ID, 0)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*model.PermissionGrant{
+		{RoleID: self.ID, RoleCode: "self", RoleName: "self", PermissionID: userUpdate.ID, PermissionCode: "user:update", Effect: model.EffectAllow, Condition: "resource.id == subject.id"},
+		{PermissionID: userList.ID, PermissionCode: "user:list", Effect: model.EffectAllow},
+	}, grants)
+}
+
+// 测试查询用户有效角色（含继承的祖先角色）
+func TestUserRepository_GetEffectiveRoleIDs(t *testing.T) {
+	db := setupTestDB(t)
+	viewer := createTestRole(t, db, "viewer")
+	editor := createTestRole(t, db, "editor")
+	archived := createTestRole(t, db, "archived")
+	setTestParents(t, db, editor, viewer, archived)
+	softDelete(t, db, &model.Role{}, archived.ID)
+	user := createTestUser(t, db, "judy")
+	assignTestRoles(t, db, user, editor)
+
+	roleIDs, err := repository.NewUserRepository(db).GetEffectiveRoleIDs(user.ID, 0)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{viewer.ID, editor.ID}, roleIDs)
+}
+
+// 测试角色分配的生效区间
+func TestUserRepository_RoleValidity(t *testing.T) {
+	db := setupTestDB(t)
+	now := model.NowUnix()
+	past, soon, later := now-3600, now+600, now+7200
+
+	user := createTestUser(t, db, "nina")
+	permanent := createTestRole(t, db, "permanent", createTestPermission(t, db, "user:list"))
+	onCall := createTestRole(t, db, "on-call", createTestPermission(t, db, "incident:ack"))
+	upcoming := createTestRole(t, db, "upcoming", createTestPermission(t, db, "deploy:run"))
+	expired := createTestRole(t, db, "expired", createTestPermission(t, db, "billing:read"))
+	assignTestRoles(t, db, user, permanent)
+	assignTestRoleWithValidity(t, db, user, onCall, &past, &later)
+	assignTestRoleWithValidity(t, db, user, upcoming, &soon, nil)
+	assignTestRoleWithValidity(t, db, user, expired, nil, &past)
+	repo := repository.NewUserRepository(db)
+
+	// 只计入当前时刻生效的分配
+	grants, err := repo.GetEffectivePermissionGrants(user.ID, 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user:list", "incident:ack"}, allowedCodes(grants))
+
+	roleIDs, err := repo.GetEffectiveRoleIDs(user.ID, 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{permanent.ID, onCall.ID}, roleIDs)
+
+	// 下一次变化是 upcoming 生效
+	next, err := repo.GetNextValidityChange(user.ID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, soon, next)
+
+	// 清理只删除已过期的分配
+	deleted, err := repo.DeleteExpiredRoles(now)
+	assert.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, expired.ID, deleted[0].RoleID)
+
+	var count int64
+	require.NoError(t, db.Model(&model.UserRole{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+// 测试重复分配角色时更新生效区间
+func TestUserRepository_AddRolesUpdatesValidity(t *testing.T) {
+	db := setupTestDB(t)
+	user := createTestUser(t, db, "oscar")
+	role := createTestRole(t, db, "contractor")
+	repo := repository.NewUserRepository(db)
+	until := model.NowUnix() + 3600
+
+	require.NoError(t, repo.AddRoles(user.ID, 0, []uint64{role.ID}, model.Validity{ValidUntil: &until}))
+	require.NoError(t, repo.AddRoles(user.ID, 0, []uint64{role.ID}, model.Validity{}))
+
+	var assignment model.UserRole
+	require.NoError(t, db.Where("user_id = ? AND role_id = ?", user.ID, role.ID).First(&assignment).Error)
+	assert.Nil(t, assignment.ValidUntil)
+}
+
+// 测试解释权限所需的角色分配与继承关系，已删除的角色与过期分配仍作为候选返回
+func TestUserRepository_RoleAssignmentsAndParents(t *testing.T) {
+	db := setupTestDB(t)
+	past := model.NowUnix() - 3600
+
+	user := createTestUser(t, db, "paula")
+	base := createTestRole(t, db, "base", createTestPermission(t, db, "user:list"))
+	removedParent := createTestRole(t, db, "removed-parent")
+	editor := createTestRole(t, db, "editor")
+	setTestParents(t, db, editor, base, removedParent)
+	deleted := createTestRole(t, db, "deleted")
+	expired := createTestRole(t, db, "expired")
+	assignTestRoles(t, db, user, editor, deleted)
+	assignTestRoleWithValidity(t, db, user, expired, nil, &past)
+	softDelete(t, db, &model.Role{}, deleted.ID)
+	softDelete(t, db, &model.Role{}, removedParent.ID)
+	repo := repository.NewUserRepository(db)
+
+	assignments, err := repo.GetRoleAssignments(user.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, assignments, 3)
+	for _, assignment := range assignments {
+		switch assignment.RoleID {
+		case editor.ID:
+			assert.Equal(t, "editor", assignment.RoleCode)
+			assert.Nil(t, assignment.RoleDeletedAt)
+		case deleted.ID:
+			assert.NotNil(t, assignment.RoleDeletedAt)
+		case expired.ID:
+			require.NotNil(t, assignment.ValidUntil)
+			assert.Equal(t, past, *assignment.ValidUntil)
+		}
+	}
+
+	// 只返回有效角色之间的继承关系，不含已删除的父角色
+	parents, err := repo.GetEffectiveRoleParents(user.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	assert.Equal(t, editor.ID, parents[0].RoleID)
+	assert.Equal(t, base.ID, parents[0].ParentID)
+	assert.Equal(t, "base", parents[0].ParentCode)
+}
+
+// 测试直接授予用户的权限：按租户合并进有效权限、重复授予更新效果、撤销以及删除权限时清理
+func TestUserRepository_DirectPermissions(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewUserRepository(db)
+	permRepo := repository.NewPermissionRepository(db)
+
+	user := createTestUser(t, db, "alice")
+	tenant := createTestTenant(t, db, "acme")
+	userList := createTestPermission(t, db, "user:list")
+	reportExport := createTestPermission(t, db, "report:export")
+	auditView := createTestPermission(t, db, "audit:view")
+	assignTestRoles(t, db, user, createTestRole(t, db, "viewer", userList))
+
+	require.NoError(t, repo.AddPermissions(user.ID, 0, []uint64{reportExport.ID}, model.EffectAllow))
+	require.NoError(t, repo.AddPermissions(user.ID, tenant.ID, []uint64{auditView.ID}, model.EffectAllow))
+	assert.Error(t, repo.AddPermissions(user.ID, 0, []uint64{9999}, model.EffectAllow))
+
+	// 全局授予在所有租户内生效，租户内授予只在该租户内生效
+	grants, err := repo.GetEffectivePermissionGrants(user.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report:export", "user:list"}, allowedCodes(grants))
+	for _, grant := range grants {
+		assert.Equal(t, grant.PermissionCode == "report:export", grant.IsDirect())
+	}
+
+	grants, err = repo.GetEffectivePermissionGrants(user.ID, tenant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit:view", "report:export", "user:list"}, allowedCodes(grants))
+
+	// 重复授予只更新效果
+	require.NoError(t, repo.AddPermissions(user.ID, 0, []uint64{reportExport.ID}, model.EffectDeny))
+	direct, err := repo.GetDirectPermissions(user.ID)
+	require.NoError(t, err)
+	require.Len(t, direct, 2)
+	assert.Equal(t, "report:export", direct[0].PermissionCode)
+	assert.Equal(t, model.EffectDeny, direct[0].Effect)
+	assert.Equal(t, "audit:view", direct[1].PermissionCode)
+	assert.Equal(t, tenant.ID, direct[1].TenantID)
+
+	// 撤销只作用于指定租户
+	require.NoError(t, repo.RemovePermissions(user.ID, 0, []uint64{reportExport.ID, auditView.ID}))
+	direct, err = repo.GetDirectPermissions(user.ID)
+	require.NoError(t, err)
+	require.Len(t, direct, 1)
+	assert.Equal(t, "audit:view", direct[0].PermissionCode)
+
+	// 删除权限时一并清理直接授予
+	require.NoError(t, permRepo.Delete(auditView.ID))
+	var count int64
+	require.NoError(t, db.Model(&model.UserPermission{}).Where("permission_id = ?", auditView.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
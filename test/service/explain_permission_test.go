@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建用于解释权限判定的用户服务：
+// editor(10) 继承 base(20) 获得 user:*，auditor(30) 拒绝 user:delete，ops(60) 无相关授予，
+// expired(40) 的分配已过期，gone(50) 角色已删除；
+// 用户属于 dev(70) 组，dev 嵌套在 eng(80) 中，viewer(90) 分配给 eng，tenant-only(95) 只分配在租户5内
+func newExplainUserService() service.UserService {
+	past := model.NowUnix() - 3600
+	deletedAt := past
+
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("GetByID", uint64(2)).Return(nil, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 20, RoleCode: "base", PermissionID: 100, PermissionCode: "user:*", Effect: model.EffectAllow},
+		{RoleID: 30, RoleCode: "auditor", PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectDeny},
+		{RoleID: 60, RoleCode: "ops", PermissionID: 300, PermissionCode: "deploy:run", Effect: model.EffectAllow},
+		{RoleID: 90, RoleCode: "viewer", PermissionID: 400, PermissionCode: "user:list", Effect: model.EffectAllow},
+	}, nil)
+	mockUserRepo.On("GetRoleAssignments", uint64(1), uint64(0)).Return([]*model.RoleAssignment{
+		{UserRole: model.UserRole{UserID: 1, RoleID: 10}, RoleCode: "editor"},
+		{UserRole: model.UserRole{UserID: 1, RoleID: 30}, RoleCode: "auditor"},
+		{UserRole: model.UserRole{UserID: 1, RoleID: 40, Validity: model.Validity{ValidUntil: &past}}, RoleCode: "expired"},
+		{UserRole: model.UserRole{UserID: 1, RoleID: 50}, RoleCode: "gone", RoleDeletedAt: &deletedAt},
+		{UserRole: model.UserRole{UserID: 1, RoleID: 60}, RoleCode: "ops"},
+	}, nil)
+	mockUserRepo.On("GetEffectiveRoleParents", uint64(1), uint64(0)).Return([]*model.RoleInheritance{
+		{RoleID: 10, ParentID: 20, ParentCode: "base"},
+	}, nil)
+	mockUserRepo.On("GetGroups", uint64(1)).Return([]*model.Group{{ID: 70, Code: "dev"}}, nil)
+	mockUserRepo.On("GetEffectiveGroupParents", uint64(1)).Return([]*model.GroupInheritance{
+		{GroupID: 70, ParentID: 80, ParentCode: "eng"},
+	}, nil)
+	mockUserRepo.On("GetGroupRoleAssignments", uint64(1)).Return([]*model.GroupRoleAssignment{
+		{GroupRole: model.GroupRole{GroupID: 80, RoleID: 90}, GroupCode: "eng", RoleCode: "viewer"},
+		{GroupRole: model.GroupRole{GroupID: 70, RoleID: 95, TenantID: 5}, GroupCode: "dev", RoleCode: "tenant-only"},
+	}, nil)
+
+	return service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+}
+
+// 按角色编码汇总解释结果中各分配的状态与作用
+func explainOutcomes(result *schema.ExplainPermissionResponse) map[string]string {
+	outcomes := make(map[string]string, len(result.Assignments))
+	for _, assignment := range result.Assignments {
+		outcomes[assignment.Role.Code] = assignment.Status + "/" + assignment.Outcome
+	}
+	return outcomes
+}
+
+// 测试权限判定解释：判定与 CheckPermission 一致，并说明每条分配的作用
+func TestUserService_ExplainPermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		permission    string
+		activeRoles   []uint64
+		hasPermission bool
+		outcomes      map[string]string
+	}{
+		{
+			name:          "继承获得允许",
+			permission:    "user:list",
+			hasPermission: true,
+			outcomes: map[string]string{
+				"editor":  "active/granted",
+				"auditor": "active/no_match",
+				"expired": "expired/inactive",
+				"gone":    "role_deleted/inactive",
+				"ops":     "active/no_match",
+				"viewer":  "active/granted",
+			},
+		},
+		{
+			name:          "拒绝覆盖允许",
+			permission:    "user:delete",
+			hasPermission: false,
+			outcomes: map[string]string{
+				"editor":  "active/overridden",
+				"auditor": "active/denied",
+				"expired": "expired/inactive",
+				"gone":    "role_deleted/inactive",
+				"ops":     "active/no_match",
+				"viewer":  "active/no_match",
+			},
+		},
+		{
+			name:          "会话未激活的角色不参与判定",
+			permission:    "user:delete",
+			activeRoles:   []uint64{10},
+			hasPermission: true,
+			outcomes: map[string]string{
+				"editor":  "active/granted",
+				"auditor": "not_activated/inactive",
+				"expired": "expired/inactive",
+				"gone":    "role_deleted/inactive",
+				"ops":     "not_activated/inactive",
+				"viewer":  "not_activated/inactive",
+			},
+		},
+	}
+
+	userService := newExplainUserService()
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := userService.ExplainPermission(1, 0, tt.activeRoles, tt.permission)
+			require.NoError(t, err)
+
+			// 判定结果与 CheckPermission 一致
+			check, err := userService.CheckPermission(1, 0, tt.activeRoles, tt.permission)
+			require.NoError(t, err)
+			assert.Equal(t, *check, result.CheckPermissionResponse)
+			assert.Equal(t, tt.hasPermission, result.HasPermission)
+			assert.Equal(t, tt.outcomes, explainOutcomes(result))
+		})
+	}
+
+	t.Run("记录继承路径", func(t *testing.T) {
+		result, err := userService.ExplainPermission(1, 0, nil, "user:list")
+		require.NoError(t, err)
+		require.Len(t, result.Assignments[0].Grants, 1)
+
+		grant := result.Assignments[0].Grants[0]
+		assert.Equal(t, "user:*", grant.PermissionCode)
+		assert.Equal(t, []schema.RoleSimple{{ID: 10, Code: "editor"}, {ID: 20, Code: "base"}}, grant.Path)
+	})
+
+	t.Run("记录用户组路径", func(t *testing.T) {
+		result, err := userService.ExplainPermission(1, 0, nil, "user:list")
+		require.NoError(t, err)
+
+		last := result.Assignments[len(result.Assignments)-1]
+		assert.Equal(t, "viewer", last.Role.Code)
+		assert.Equal(t, []schema.GroupSimple{{ID: 70, Code: "dev"}, {ID: 80, Code: "eng"}}, last.Groups)
+		require.Len(t, last.Grants, 1)
+		assert.Equal(t, []schema.RoleSimple{{ID: 90, Code: "viewer"}}, last.Grants[0].Path)
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		_, err := userService.ExplainPermission(2, 0, nil, "user:list")
+		assert.Equal(t, errors.ErrUserNotFound, err)
+	})
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/permcode"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var forwardAuthJWTConfig = &config.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+// forwardAuthRules 测试使用的转发认证规则表
+var forwardAuthRules = &config.ForwardAuthConfig{
+	Rules: []config.ForwardAuthRule{
+		{Path: "/health", Public: true},
+		{Path: "/public/*", Public: true},
+		{Method: "get", Path: "/reports/*", Permissions: []string{"report:view"}},
+		{Method: "*", Path: "/admin/*", Permissions: []string{"user:update", "role:update"}, Mode: schema.CheckModeAny},
+		{Path: "/me"},
+	},
+}
+
+// newForwardAuthService 创建转发认证服务，用户1拥有 report:view 权限与 analyst 角色
+func newForwardAuthService(t *testing.T, cfg *config.ForwardAuthConfig) (service.ForwardAuthService, string) {
+	t.Helper()
+
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserService := new(mocks.MockUserService)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "analyst"}, nil)
+	mockUserRepo.On("GetEffectiveRoles", uint64(1), uint64(0)).Return([]*model.Role{{ID: 2, Code: "analyst"}}, nil)
+	mockUserService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(permcode.NewPolicy([]string{"report:view"}, nil), nil)
+
+	token, err := jwt.NewTokenService(forwardAuthJWTConfig).GenerateToken(1, "analyst", "access")
+	require.NoError(t, err)
+
+	return service.NewForwardAuthService(cfg, mockUserRepo, mockUserService, forwardAuthJWTConfig), token
+}
+
+// 测试按规则表判定原始请求
+func TestForwardAuthService_Authorize(t *testing.T) {
+	svc, token := newForwardAuthService(t, forwardAuthRules)
+
+	t.Run("公开规则无需令牌", func(t *testing.T) {
+		result, err := svc.Authorize(&schema.ForwardAuthRequest{Method: "GET", URI: "/health?probe=1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.True(t, result.Public)
+	})
+
+	t.Run("路径穿越按规范化后的路径匹配", func(t *testing.T) {
+		for _, uri := range []string{"/public/../admin/users", "/public/%2e%2e/admin/users", "/public/%2E%2E/admin/users?x=1"} {
+			_, err := svc.Authorize(&schema.ForwardAuthRequest{Method: "POST", URI: uri})
+			assert.Equal(t, errors.ErrInvalidToken, err, uri)
+
+			result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "POST", URI: uri})
+			require.NoError(t, err, uri)
+			assert.False(t, result.Allowed, uri)
+			assert.Equal(t, []string{"user:update", "role:update"}, result.Required, uri)
+		}
+	})
+
+	t.Run("拒绝编码的斜杠与多重编码", func(t *testing.T) {
+		for _, uri := range []string{"/public/..%2fadmin/users", "/public/%252e%252e/admin/users", "/public/..%5cadmin"} {
+			_, err := svc.Authorize(&schema.ForwardAuthRequest{Method: "POST", URI: uri})
+			assert.Equal(t, errors.ErrInvalidForwardedURI, err, uri)
+		}
+	})
+
+	t.Run("缺少令牌", func(t *testing.T) {
+		_, err := svc.Authorize(&schema.ForwardAuthRequest{Method: "GET", URI: "/reports/1"})
+		assert.Equal(t, errors.ErrInvalidToken, err)
+	})
+
+	t.Run("具备所需权限时返回用户与生效角色", func(t *testing.T) {
+		result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "GET", URI: "/reports/2024/q1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, uint64(1), result.UserID)
+		assert.Equal(t, "analyst", result.Username)
+		assert.Equal(t, []string{"analyst"}, result.Roles)
+	})
+
+	t.Run("方法不匹配时视为未匹配规则", func(t *testing.T) {
+		result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "DELETE", URI: "/reports/1"})
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("权限不足", func(t *testing.T) {
+		result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "POST", URI: "/admin/users"})
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, []string{"user:update", "role:update"}, result.Required)
+	})
+
+	t.Run("未配置权限的规则认证通过即放行", func(t *testing.T) {
+		result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "GET", URI: "/me"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("请求头租户与令牌租户不一致", func(t *testing.T) {
+		tenantToken, err := jwt.NewTokenService(forwardAuthJWTConfig).GenerateTenantToken(1, 5, "analyst", "access")
+		require.NoError(t, err)
+		_, err = svc.Authorize(&schema.ForwardAuthRequest{Token: tenantToken, TenantID: 6, Method: "GET", URI: "/me"})
+		assert.Equal(t, errors.ErrTokenTenantMismatch, err)
+	})
+
+	t.Run("刷新令牌不能用于访问", func(t *testing.T) {
+		refreshToken, err := jwt.NewTokenService(forwardAuthJWTConfig).GenerateToken(1, "analyst", "refresh")
+		require.NoError(t, err)
+		_, err = svc.Authorize(&schema.ForwardAuthRequest{Token: refreshToken, Method: "GET", URI: "/me"})
+		assert.Equal(t, errors.ErrInvalidTokenType, err)
+	})
+}
+
+// 测试未匹配任何规则的请求
+func TestForwardAuthService_Unmatched(t *testing.T) {
+	svc, token := newForwardAuthService(t, forwardAuthRules)
+	result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "GET", URI: "/unknown"})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	svc, token = newForwardAuthService(t, &config.ForwardAuthConfig{Rules: forwardAuthRules.Rules, AllowUnmatched: true})
+	result, err = svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "GET", URI: "/unknown"})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+// 测试转发的角色包含经由用户组获得与继承的角色，会话只激活部分角色时仅返回这些角色及其祖先角色
+func TestForwardAuthService_EffectiveRoles(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserService := new(mocks.MockUserService)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "analyst"}, nil)
+	// analyst(2) 为直接分配，auditor(4) 经由用户组获得，viewer(3) 为 analyst 的父角色
+	mockUserRepo.On("GetEffectiveRoles", uint64(1), uint64(0)).Return([]*model.Role{
+		{ID: 2, Code: "analyst"},
+		{ID: 3, Code: "viewer"},
+		{ID: 4, Code: "auditor"},
+	}, nil)
+	mockUserRepo.On("GetEffectiveRoleParents", uint64(1), uint64(0)).Return([]*model.RoleInheritance{
+		{RoleID: 2, ParentID: 3, ParentCode: "viewer"},
+	}, nil)
+	policy := permcode.NewPolicy([]string{"report:view"}, nil)
+	mockUserService.On("GetPermissionPolicy", uint64(1), uint64(0)).Return(policy, nil)
+	mockUserService.On("GetSessionPolicy", uint64(1), uint64(0), []uint64{2}).Return(policy, nil)
+
+	svc := service.NewForwardAuthService(forwardAuthRules, mockUserRepo, mockUserService, forwardAuthJWTConfig)
+	tokens := jwt.NewTokenService(forwardAuthJWTConfig)
+
+	token, err := tokens.GenerateToken(1, "analyst", "access")
+	require.NoError(t, err)
+	result, err := svc.Authorize(&schema.ForwardAuthRequest{Token: token, Method: "GET", URI: "/reports/1"})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, []string{"analyst", "auditor", "viewer"}, result.Roles)
+
+	sessionToken, err := tokens.GenerateSessionToken(1, 0, []uint64{2}, "analyst", "access")
+	require.NoError(t, err)
+	result, err = svc.Authorize(&schema.ForwardAuthRequest{Token: sessionToken, Method: "GET", URI: "/reports/1"})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, []string{"analyst", "viewer"}, result.Roles)
+}
@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试创建用户组
+func TestGroupService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *schema.CreateGroupRequest
+		mockSetup     func(mockGroupRepo *mocks.MockGroupRepository)
+		expectedID    uint64
+		expectedError error
+	}{
+		{
+			name: "创建并嵌套到上级组",
+			req:  &schema.CreateGroupRequest{Code: "backend", Name: "后端", ParentIDs: []uint64{1}},
+			mockSetup: func(mockGroupRepo *mocks.MockGroupRepository) {
+				mockGroupRepo.On("GetByCode", "backend").Return(nil, nil)
+				mockGroupRepo.On("GetByName", "后端").Return(nil, nil)
+				mockGroupRepo.On("GetByID", uint64(1)).Return(&model.Group{ID: 1}, nil)
+				mockGroupRepo.On("Create", mock.AnythingOfType("*model.Group")).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.Group).ID = 2
+				}).Return(nil)
+				mockGroupRepo.On("SetParents", uint64(2), []uint64{1}).Return(nil)
+			},
+			expectedID: 2,
+		},
+		{
+			name: "用户组编码已存在",
+			req:  &schema.CreateGroupRequest{Code: "backend", Name: "后端"},
+			mockSetup: func(mockGroupRepo *mocks.MockGroupRepository) {
+				mockGroupRepo.On("GetByCode", "backend").Return(&model.Group{ID: 3}, nil)
+			},
+			expectedError: errors.ErrGroupExists,
+		},
+		{
+			name: "上级组不存在",
+			req:  &schema.CreateGroupRequest{Code: "backend", Name: "后端", ParentIDs: []uint64{9}},
+			mockSetup: func(mockGroupRepo *mocks.MockGroupRepository) {
+				mockGroupRepo.On("GetByCode", "backend").Return(nil, nil)
+				mockGroupRepo.On("GetByName", "后端").Return(nil, nil)
+				mockGroupRepo.On("GetByID", uint64(9)).Return(nil, nil)
+			},
+			expectedError: errors.ErrGroupNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockGroupRepo := new(mocks.MockGroupRepository)
+			tt.mockSetup(mockGroupRepo)
+
+			groupService := service.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository), new(mocks.MockTenantRepository), nil, nil)
+			id, err := groupService.Create(tt.req)
+
+			assert.Equal(t, tt.expectedID, id)
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+// 测试更新用户组时拒绝形成循环嵌套
+func TestGroupService_UpdateCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		parentIDs []uint64
+	}{
+		{name: "以自身为上级组", parentIDs: []uint64{1}},
+		{name: "以后代组为上级组", parentIDs: []uint64{3}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			// 组1下嵌套组2，组2下嵌套组3
+			mockGroupRepo := new(mocks.MockGroupRepository)
+			mockGroupRepo.On("GetByID", uint64(1)).Return(&model.Group{ID: 1, Code: "company", Name: "公司"}, nil)
+			mockGroupRepo.On("GetByID", uint64(3)).Return(&model.Group{ID: 3}, nil)
+			mockGroupRepo.On("GetByCode", "company").Return(&model.Group{ID: 1}, nil)
+			mockGroupRepo.On("GetByName", "公司").Return(&model.Group{ID: 1}, nil)
+			mockGroupRepo.On("GetDescendantIDs", uint64(1)).Return([]uint64{2, 3}, nil)
+
+			groupService := service.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository), new(mocks.MockTenantRepository), nil, nil)
+			err := groupService.Update(&schema.UpdateGroupRequest{ID: 1, Code: "company", Name: "公司", ParentIDs: tt.parentIDs})
+
+			assert.Equal(t, errors.ErrGroupCycle, err)
+			mockGroupRepo.AssertNotCalled(t, "Update", mock.Anything)
+			mockGroupRepo.AssertNotCalled(t, "SetParents", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// 测试为用户组分配角色
+func TestGroupService_AssignRoles(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *schema.AssignGroupRolesRequest
+		expectedError error
+	}{
+		{
+			name: "分配全局角色",
+			req:  &schema.AssignGroupRolesRequest{GroupID: 1, RoleIDs: []uint64{10}},
+		},
+		{
+			name:          "租户角色不能分配到其他租户",
+			req:           &schema.AssignGroupRolesRequest{GroupID: 1, TenantID: 8, RoleIDs: []uint64{20}},
+			expectedError: errors.ErrTenantMismatch,
+		},
+		{
+			name:          "用户组不存在",
+			req:           &schema.AssignGroupRolesRequest{GroupID: 9, RoleIDs: []uint64{10}},
+			expectedError: errors.ErrGroupNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockGroupRepo := new(mocks.MockGroupRepository)
+			mockGroupRepo.On("GetByID", uint64(1)).Return(&model.Group{ID: 1}, nil)
+			mockGroupRepo.On("GetByID", uint64(9)).Return(nil, nil)
+			mockGroupRepo.On("GetAffectedUserIDs", uint64(1)).Return([]uint64{1}, nil)
+			mockGroupRepo.On("UpdateRoles", uint64(1), uint64(0), []uint64{10}).Return(nil)
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockRoleRepo.On("GetByID", uint64(10)).Return(&model.Role{ID: 10}, nil)
+			mockRoleRepo.On("GetByID", uint64(20)).Return(&model.Role{ID: 20, TenantID: 7}, nil)
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			mockTenantRepo.On("GetByID", uint64(8)).Return(&model.Tenant{ID: 8}, nil)
+
+			groupService := service.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository), mockRoleRepo, mockTenantRepo, nil, nil)
+			err := groupService.AssignRoles(tt.req)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				mockGroupRepo.AssertCalled(t, "UpdateRoles", tt.req.GroupID, tt.req.TenantID, tt.req.RoleIDs)
+			} else {
+				mockGroupRepo.AssertNotCalled(t, "UpdateRoles", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试用户组的角色或成员变化后失效受影响用户的权限缓存
+func TestGroupService_CacheInvalidation(t *testing.T) {
+	t.Run("分配角色后失效组及后代组的成员", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 0)
+		userService, _ := newCachedUserService(cache)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockGroupRepo.On("GetByID", uint64(5)).Return(&model.Group{ID: 5}, nil)
+		mockGroupRepo.On("GetAffectedUserIDs", uint64(5)).Return([]uint64{1}, nil)
+		mockGroupRepo.On("UpdateRoles", uint64(5), uint64(0), []uint64{}).Return(nil)
+		groupService := service.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository), new(mocks.MockTenantRepository), cache, nil)
+
+		mustCheck(t, userService, 1, "user:list")
+		mustCheck(t, userService, 2, "permission:list")
+		require.NoError(t, groupService.AssignRoles(&schema.AssignGroupRolesRequest{GroupID: 5, RoleIDs: []uint64{}}))
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+
+	t.Run("移出成员后失效该用户", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 0)
+		userService, _ := newCachedUserService(cache)
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockGroupRepo.On("GetByID", uint64(5)).Return(&model.Group{ID: 5}, nil)
+		mockGroupRepo.On("RemoveMembers", uint64(5), []uint64{2}).Return(nil)
+		groupService := service.NewGroupService(mockGroupRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository), new(mocks.MockTenantRepository), cache, nil)
+
+		mustCheck(t, userService, 1, "user:list")
+		mustCheck(t, userService, 2, "permission:list")
+		require.NoError(t, groupService.RemoveMembers(&schema.GroupMembersRequest{GroupID: 5, UserIDs: []uint64{2}}))
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+}
+
+// 测试用户详情标注经由哪个用户组获得角色，路径取自直接所属组出发的最短嵌套路径
+func TestUserService_GroupRoles(t *testing.T) {
+	// alice 直接属于 backend(2) 和 company(1)，backend 嵌套在 engineering(3) 中，engineering 嵌套在 company 中
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{}, nil)
+	mockUserRepo.On("GetGroups", uint64(1)).Return([]*model.Group{
+		{ID: 1, Code: "company"},
+		{ID: 2, Code: "backend"},
+	}, nil)
+	mockUserRepo.On("GetEffectiveGroupParents", uint64(1)).Return([]*model.GroupInheritance{
+		{GroupID: 2, ParentID: 3, ParentCode: "engineering"},
+		{GroupID: 3, ParentID: 1, ParentCode: "company"},
+	}, nil)
+	mockUserRepo.On("GetGroupRoleAssignments", uint64(1)).Return([]*model.GroupRoleAssignment{
+		{GroupRole: model.GroupRole{GroupID: 3, RoleID: 10}, GroupCode: "engineering", RoleCode: "developer"},
+		{GroupRole: model.GroupRole{GroupID: 1, RoleID: 20, TenantID: 7}, GroupCode: "company", RoleCode: "employee"},
+	}, nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	user, err := userService.GetByID(1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupSimple{{ID: 1, Code: "company"}, {ID: 2, Code: "backend"}}, user.Groups)
+	assert.Equal(t, []schema.UserGroupRole{
+		{
+			Role:  schema.RoleSimple{ID: 10, Code: "developer"},
+			Group: schema.GroupSimple{ID: 3, Code: "engineering"},
+			Path:  []schema.GroupSimple{{ID: 2, Code: "backend"}, {ID: 3, Code: "engineering"}},
+		},
+		{
+			Role:     schema.RoleSimple{ID: 20, Code: "employee"},
+			TenantID: 7,
+			Group:    schema.GroupSimple{ID: 1, Code: "company"},
+			Path:     []schema.GroupSimple{{ID: 1, Code: "company"}},
+		},
+	}, user.GroupRoles)
+}
@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试创建组织单元
+func TestOrgUnitService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *schema.CreateOrgUnitRequest
+		mockSetup     func(mockOrgUnitRepo *mocks.MockOrgUnitRepository)
+		expectedID    uint64
+		expectedError error
+	}{
+		{
+			name: "在上级单元下创建",
+			req:  &schema.CreateOrgUnitRequest{ParentID: 1, Code: "sales", Name: "销售部"},
+			mockSetup: func(mockOrgUnitRepo *mocks.MockOrgUnitRepository) {
+				mockOrgUnitRepo.On("GetByCode", "sales").Return(nil, nil)
+				mockOrgUnitRepo.On("GetByID", uint64(1)).Return(&model.OrgUnit{ID: 1}, nil)
+				mockOrgUnitRepo.On("Create", mock.AnythingOfType("*model.OrgUnit")).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.OrgUnit).ID = 2
+				}).Return(nil)
+			},
+			expectedID: 2,
+		},
+		{
+			name: "组织单元编码已存在",
+			req:  &schema.CreateOrgUnitRequest{Code: "sales", Name: "销售部"},
+			mockSetup: func(mockOrgUnitRepo *mocks.MockOrgUnitRepository) {
+				mockOrgUnitRepo.On("GetByCode", "sales").Return(&model.OrgUnit{ID: 3}, nil)
+			},
+			expectedError: errors.ErrOrgUnitExists,
+		},
+		{
+			name: "上级单元不存在",
+			req:  &schema.CreateOrgUnitRequest{ParentID: 9, Code: "sales", Name: "销售部"},
+			mockSetup: func(mockOrgUnitRepo *mocks.MockOrgUnitRepository) {
+				mockOrgUnitRepo.On("GetByCode", "sales").Return(nil, nil)
+				mockOrgUnitRepo.On("GetByID", uint64(9)).Return(nil, nil)
+			},
+			expectedError: errors.ErrOrgUnitNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrgUnitRepo := new(mocks.MockOrgUnitRepository)
+			tt.mockSetup(mockOrgUnitRepo)
+
+			orgUnitService := service.NewOrgUnitService(mockOrgUnitRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository))
+			id, err := orgUnitService.Create(tt.req)
+
+			assert.Equal(t, tt.expectedID, id)
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+// 测试更新与删除组织单元时保持树结构
+func TestOrgUnitService_UpdateAndDelete(t *testing.T) {
+	// 单元1下有单元2，单元2下有单元3
+	newService := func() (service.OrgUnitService, *mocks.MockOrgUnitRepository) {
+		mockOrgUnitRepo := new(mocks.MockOrgUnitRepository)
+		for _, id := range []uint64{1, 2, 3} {
+			mockOrgUnitRepo.On("GetByID", id).Return(&model.OrgUnit{ID: id}, nil)
+		}
+		mockOrgUnitRepo.On("GetByCode", "engineering").Return(&model.OrgUnit{ID: 2}, nil)
+		mockOrgUnitRepo.On("GetSubtreeIDs", uint64(2)).Return([]uint64{2, 3}, nil)
+		mockOrgUnitRepo.On("CountChildren", uint64(2)).Return(int64(1), nil)
+		mockOrgUnitRepo.On("CountChildren", uint64(3)).Return(int64(0), nil)
+		mockOrgUnitRepo.On("Update", mock.AnythingOfType("*model.OrgUnit")).Return(nil)
+		mockOrgUnitRepo.On("Delete", uint64(3)).Return(nil)
+		return service.NewOrgUnitService(mockOrgUnitRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository)), mockOrgUnitRepo
+	}
+
+	t.Run("不能移动到自身或下级单元下", func(t *testing.T) {
+		for _, parentID := range []uint64{2, 3} {
+			orgUnitService, mockOrgUnitRepo := newService()
+			err := orgUnitService.Update(&schema.UpdateOrgUnitRequest{ID: 2, ParentID: parentID, Code: "engineering", Name: "研发部"})
+			assert.Equal(t, errors.ErrOrgUnitCycle, err)
+			mockOrgUnitRepo.AssertNotCalled(t, "Update", mock.Anything)
+		}
+	})
+
+	t.Run("移动为根节点", func(t *testing.T) {
+		orgUnitService, mockOrgUnitRepo := newService()
+		require.NoError(t, orgUnitService.Update(&schema.UpdateOrgUnitRequest{ID: 2, Code: "engineering", Name: "研发部"}))
+		mockOrgUnitRepo.AssertCalled(t, "Update", &model.OrgUnit{ID: 2, Code: "engineering", Name: "研发部"})
+	})
+
+	t.Run("仍有下级单元时不能删除", func(t *testing.T) {
+		orgUnitService, mockOrgUnitRepo := newService()
+		assert.Equal(t, errors.ErrOrgUnitHasChildren, orgUnitService.Delete(2))
+		mockOrgUnitRepo.AssertNotCalled(t, "Delete", mock.Anything)
+
+		require.NoError(t, orgUnitService.Delete(3))
+	})
+}
+
+// 测试组织单元树按上级关系组装，上级单元不存在的节点作为根节点
+func TestOrgUnitService_Tree(t *testing.T) {
+	mockOrgUnitRepo := new(mocks.MockOrgUnitRepository)
+	mockOrgUnitRepo.On("ListAll").Return([]*model.OrgUnit{
+		{ID: 1, Code: "company"},
+		{ID: 3, ParentID: 1, Code: "sales"},
+		{ID: 2, ParentID: 1, Code: "engineering"},
+		{ID: 4, ParentID: 2, Code: "backend"},
+		{ID: 5, ParentID: 99, Code: "orphan"},
+	}, nil)
+
+	orgUnitService := service.NewOrgUnitService(mockOrgUnitRepo, new(mocks.MockUserRepository), new(mocks.MockRoleRepository))
+	tree, err := orgUnitService.Tree()
+	require.NoError(t, err)
+
+	require.Len(t, tree, 2)
+	assert.Equal(t, "company", tree[0].Code)
+	assert.Equal(t, "orphan", tree[1].Code)
+	require.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "sales", tree[0].Children[0].Code)
+	assert.Equal(t, "engineering", tree[0].Children[1].Code)
+	require.Len(t, tree[0].Children[1].Children, 1)
+	assert.Equal(t, "backend", tree[0].Children[1].Children[0].Code)
+}
+
+// 测试设置角色数据范围
+func TestOrgUnitService_AssignRoleDataScope(t *testing.T) {
+	tests := []struct {
+		name            string
+		req             *schema.AssignDataScopeRequest
+		expectedUnitIDs []uint64
+		expectedError   error
+	}{
+		{
+			name:            "自定义范围保存组织单元",
+			req:             &schema.AssignDataScopeRequest{RoleID: 1, DataScope: model.DataScopeCustom, OrgUnitIDs: []uint64{10}},
+			expectedUnitIDs: []uint64{10},
+		},
+		{
+			name: "其余范围忽略组织单元",
+			req:  &schema.AssignDataScopeRequest{RoleID: 1, DataScope: model.DataScopeUnit, OrgUnitIDs: []uint64{10}},
+		},
+		{
+			name:          "组织单元不存在",
+			req:           &schema.AssignDataScopeRequest{RoleID: 1, DataScope: model.DataScopeCustom, OrgUnitIDs: []uint64{10, 11}},
+			expectedError: errors.ErrOrgUnitNotFound,
+		},
+		{
+			name:          "角色不存在",
+			req:           &schema.AssignDataScopeRequest{RoleID: 9, DataScope: model.DataScopeSelf},
+			expectedError: errors.ErrRoleNotFound,
+		},
+		{
+			name:          "无效的数据范围",
+			req:           &schema.AssignDataScopeRequest{RoleID: 1, DataScope: "department"},
+			expectedError: errors.ErrInvalidDataScope,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrgUnitRepo := new(mocks.MockOrgUnitRepository)
+			mockOrgUnitRepo.On("GetByID", uint64(10)).Return(&model.OrgUnit{ID: 10}, nil)
+			mockOrgUnitRepo.On("GetByID", uint64(11)).Return(nil, nil)
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockRoleRepo.On("GetByID", uint64(1)).Return(&model.Role{ID: 1}, nil)
+			mockRoleRepo.On("GetByID", uint64(9)).Return(nil, nil)
+			mockRoleRepo.On("SetDataScope", uint64(1), tt.req.DataScope, tt.expectedUnitIDs).Return(nil)
+
+			orgUnitService := service.NewOrgUnitService(mockOrgUnitRepo, new(mocks.MockUserRepository), mockRoleRepo)
+			err := orgUnitService.AssignRoleDataScope(tt.req)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				mockRoleRepo.AssertCalled(t, "SetDataScope", uint64(1), tt.req.DataScope, tt.expectedUnitIDs)
+			} else {
+				mockRoleRepo.AssertNotCalled(t, "SetDataScope", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试用户列表按调用方在当前租户内的数据范围过滤
+func TestUserService_ListDataScope(t *testing.T) {
+	scope := &model.DataScope{UserID: 7, UnitIDs: []uint64{2, 3}}
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetDataScope", uint64(7), uint64(5)).Return(scope, nil)
+	mockUserRepo.On("List", 1, 10, "", uint64(0), scope).Return([]*model.User{{ID: 8, Username: "dev"}}, int64(1), nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	result, err := userService.List(&schema.ListUserRequest{Page: 1, PageSize: 10}, 7, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "dev", result.Items[0].Username)
+}
+
+// 测试角色详情在自定义数据范围时返回可访问的组织单元
+func TestRoleService_DataScopeUnits(t *testing.T) {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockRoleRepo.On("GetByID", uint64(1)).Return(&model.Role{ID: 1, Code: "auditor", DataScope: model.DataScopeCustom}, nil)
+	mockRoleRepo.On("GetByID", uint64(2)).Return(&model.Role{ID: 2, Code: "staff", DataScope: model.DataScopeSelf}, nil)
+	mockRoleRepo.On("GetDataScopeUnits", uint64(1)).Return([]*model.OrgUnit{{ID: 3, Code: "sales", Name: "销售部"}}, nil)
+	roleService := service.NewRoleService(mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), nil)
+
+	role, err := roleService.GetByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, model.DataScopeCustom, role.DataScope)
+	assert.Equal(t, []schema.OrgUnitSimple{{ID: 3, Code: "sales", Name: "销售部"}}, role.DataScopeUnits)
+
+	role, err = roleService.GetByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, model.DataScopeSelf, role.DataScope)
+	assert.Nil(t, role.DataScopeUnits)
+	mockRoleRepo.AssertNotCalled(t, "GetDataScopeUnits", uint64(2))
+}
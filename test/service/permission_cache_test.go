@@ -0,0 +1,272 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建带权限缓存的用户服务，用户1经由角色10获得 user:list，经由角色20获得 role:list
+func newCachedUserService(cache *service.PermissionCache) (service.UserService, *mocks.MockUserRepository) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetNextValidityChange", mock.Anything, mock.Anything).Return(int64(0), nil)
+	mockUserRepo.On("GetEffectiveRoleIDs", uint64(1), uint64(0)).Return([]uint64{10, 20}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionID: 100, PermissionCode: "user:list", Effect: model.EffectAllow},
+		{RoleID: 20, PermissionID: 200, PermissionCode: "role:list", Effect: model.EffectAllow},
+	}, nil)
+	mockUserRepo.On("GetEffectiveRoleIDs", uint64(2), uint64(0)).Return([]uint64{30}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(2), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 30, PermissionID: 300, PermissionCode: "permission:list", Effect: model.EffectAllow},
+	}, nil)
+
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, cache, nil, nil)
+	return userService, mockUserRepo
+}
+
+// 检查权限并断言结果
+func mustCheck(t *testing.T, userService service.UserService, userID uint64, permission string) bool {
+	t.Helper()
+	result, err := userService.CheckPermission(userID, 0, nil, permission)
+	require.NoError(t, err)
+	return result.HasPermission
+}
+
+// 测试缓存命中后不再访问数据库
+func TestPermissionCache_HitAndMiss(t *testing.T) {
+	cache := service.NewPermissionCache(time.Minute, 0)
+	userService, mockUserRepo := newCachedUserService(cache)
+
+	assert.True(t, mustCheck(t, userService, 1, "user:list"))
+	assert.False(t, mustCheck(t, userService, 1, "user:delete"))
+	_, err := userService.GetPermissionPolicy(1, 0)
+	require.NoError(t, err)
+
+	mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 1)
+	assert.Equal(t, service.PermissionCacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+}
+
+// 测试按用户、角色、权限精确失效
+func TestPermissionCache_Invalidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		invalidate  func(cache *service.PermissionCache)
+		expectUser1 int // 用户1的加载次数
+		expectUser2 int // 用户2的加载次数
+	}{
+		{
+			name:        "按用户失效",
+			invalidate:  func(cache *service.PermissionCache) { cache.InvalidateUsers(2) },
+			expectUser1: 1,
+			expectUser2: 2,
+		},
+		{
+			name:        "按有效角色失效",
+			invalidate:  func(cache *service.PermissionCache) { cache.InvalidateRoles(20) },
+			expectUser1: 2,
+			expectUser2: 1,
+		},
+		{
+			name:        "按权限失效",
+			invalidate:  func(cache *service.PermissionCache) { cache.InvalidatePermissions(300) },
+			expectUser1: 1,
+			expectUser2: 2,
+		},
+		{
+			name:        "无关角色不影响缓存",
+			invalidate:  func(cache *service.PermissionCache) { cache.InvalidateRoles(99) },
+			expectUser1: 1,
+			expectUser2: 1,
+		},
+		{
+			name:        "清空全部缓存",
+			invalidate:  func(cache *service.PermissionCache) { cache.Purge() },
+			expectUser1: 2,
+			expectUser2: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			cache := service.NewPermissionCache(time.Minute, 0)
+			userService, mockUserRepo := newCachedUserService(cache)
+
+			mustCheck(t, userService, 1, "user:list")
+			mustCheck(t, userService, 2, "permission:list")
+			tt.invalidate(cache)
+			mustCheck(t, userService, 1, "user:list")
+			mustCheck(t, userService, 2, "permission:list")
+
+			calls := map[uint64]int{}
+			for _, call := range mockUserRepo.Calls {
+				if call.Method == "GetEffectivePermissionGrants" {
+					calls[call.Arguments.Get(0).(uint64)]++
+				}
+			}
+			assert.Equal(t, tt.expectUser1, calls[1])
+			assert.Equal(t, tt.expectUser2, calls[2])
+		})
+	}
+}
+
+// 测试缓存过期与容量淘汰
+func TestPermissionCache_TTLAndCapacity(t *testing.T) {
+	t.Run("过期后重新加载", func(t *testing.T) {
+		cache := service.NewPermissionCache(20*time.Millisecond, 0)
+		userService, mockUserRepo := newCachedUserService(cache)
+
+		mustCheck(t, userService, 1, "user:list")
+		time.Sleep(30 * time.Millisecond)
+		mustCheck(t, userService, 1, "user:list")
+
+		mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 2)
+		assert.Equal(t, uint64(1), cache.Stats().Evictions)
+	})
+
+	t.Run("超出容量时淘汰最久未使用的用户", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 1)
+		userService, mockUserRepo := newCachedUserService(cache)
+
+		mustCheck(t, userService, 1, "user:list")
+		mustCheck(t, userService, 2, "permission:list")
+		mustCheck(t, userService, 1, "user:list")
+
+		mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 3)
+		assert.Equal(t, 1, cache.Stats().Entries)
+		assert.Equal(t, uint64(2), cache.Stats().Evictions)
+	})
+}
+
+// 测试加载期间发生失效时不写入旧数据
+func TestPermissionCache_DiscardStaleLoad(t *testing.T) {
+	cache := service.NewPermissionCache(time.Minute, 0)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetNextValidityChange", uint64(1), uint64(0)).Return(int64(0), nil)
+	mockUserRepo.On("GetEffectiveRoleIDs", uint64(1), uint64(0)).Return([]uint64{10}, nil)
+	// 模拟加载授予记录期间角色权限被修改
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Run(func(args mock.Arguments) {
+		cache.InvalidateRoles(10)
+	}).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionID: 100, PermissionCode: "user:list", Effect: model.EffectAllow},
+	}, nil)
+
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, cache, nil, nil)
+
+	assert.True(t, mustCheck(t, userService, 1, "user:list"))
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+// 测试服务层写操作触发缓存失效
+func TestPermissionCache_ServiceInvalidation(t *testing.T) {
+	t.Run("分配用户角色后失效该用户", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 0)
+		userService, mockUserRepo := newCachedUserService(cache)
+		mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+		mockUserRepo.On("UpdateRoles", uint64(1), uint64(0), []uint64{}, model.Validity{}).Return(nil)
+
+		mustCheck(t, userService, 1, "user:list")
+		require.NoError(t, userService.AssignRole(&schema.AssignRoleRequest{UserID: 1, RoleIDs: []uint64{}}))
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+
+	t.Run("修改角色授权后失效相关用户", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 0)
+		userService, _ := newCachedUserService(cache)
+		mockRoleRepo := new(mocks.MockRoleRepository)
+		mockPermRepo := new(mocks.MockPermissionRepository)
+		mockRoleRepo.On("GetByID", uint64(20)).Return(&model.Role{ID: 20}, nil)
+		mockPermRepo.On("GetByID", uint64(200)).Return(&model.Permission{ID: 200}, nil)
+		mockRoleRepo.On("SetPermissionGrants", uint64(20), mock.Anything).Return(nil)
+		roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), cache)
+
+		mustCheck(t, userService, 1, "user:list")
+		mustCheck(t, userService, 2, "permission:list")
+		require.NoError(t, roleService.AssignPermission(20, nil))
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+
+	t.Run("删除权限后失效相关用户", func(t *testing.T) {
+		cache := service.NewPermissionCache(time.Minute, 0)
+		userService, _ := newCachedUserService(cache)
+		mockPermRepo := new(mocks.MockPermissionRepository)
+		mockPermRepo.On("GetByID", uint64(300)).Return(&model.Permission{ID: 300}, nil)
+		mockPermRepo.On("CountChildren", uint64(300)).Return(int64(0), nil)
+		mockPermRepo.On("Delete", uint64(300)).Return(nil)
+		permissionService := service.NewPermissionService(mockPermRepo, cache, nil)
+
+		mustCheck(t, userService, 1, "user:list")
+		mustCheck(t, userService, 2, "permission:list")
+		require.NoError(t, permissionService.Delete(300))
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+}
+
+// 测试同一用户在不同租户内的权限分别缓存，用户失效时清除全部租户
+func TestPermissionCache_TenantIsolation(t *testing.T) {
+	cache := service.NewPermissionCache(time.Minute, 0)
+	userService, mockUserRepo := newCachedUserService(cache)
+	mockUserRepo.On("GetEffectiveRoleIDs", uint64(1), uint64(7)).Return([]uint64{10, 70}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(7)).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionID: 100, PermissionCode: "user:list", Effect: model.EffectAllow},
+		{RoleID: 70, PermissionID: 700, PermissionCode: "user:delete", Effect: model.EffectAllow},
+	}, nil)
+
+	assert.False(t, mustCheck(t, userService, 1, "user:delete"))
+	result, err := userService.CheckPermission(1, 7, nil, "user:delete")
+	require.NoError(t, err)
+	assert.True(t, result.HasPermission)
+	assert.Equal(t, 2, cache.Stats().Entries)
+
+	// 租户角色变更只影响该租户的条目
+	cache.InvalidateRoles(70)
+	assert.Equal(t, 1, cache.Stats().Entries)
+
+	cache.InvalidateUsers(1)
+	assert.Zero(t, cache.Stats().Entries)
+}
+
+// 测试角色分配即将生效或失效时，缓存条目不会越过该时刻
+func TestPermissionCache_ValidityDeadline(t *testing.T) {
+	cache := service.NewPermissionCache(time.Minute, 0)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetNextValidityChange", uint64(1), uint64(0)).Return(time.Now().Unix(), nil)
+	mockUserRepo.On("GetEffectiveRoleIDs", uint64(1), uint64(0)).Return([]uint64{10}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionID: 100, PermissionCode: "user:list", Effect: model.EffectAllow},
+	}, nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, cache, nil, nil)
+
+	mustCheck(t, userService, 1, "user:list")
+	mustCheck(t, userService, 1, "user:list")
+
+	mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 2)
+}
+
+// 测试批量检查权限只解析一次有效权限，拒绝规则优先
+func TestUserService_CheckPermissions(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionID: 100, PermissionCode: "user:*", Effect: model.EffectAllow},
+		{RoleID: 20, PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectDeny},
+	}, nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	results, err := userService.CheckPermissions(1, 0, nil, []string{"user:list", "user:delete", "role:list"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"user:list":   true,
+		"user:delete": false,
+		"role:list":   false,
+	}, results)
+	mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 1)
+}
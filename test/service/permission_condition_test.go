@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建带条件授予的用户服务：
+// self(10) 只能更新本人资料，ops(20) 可删除用户但夜间与内网外的拒绝规则优先
+func newConditionUserService() service.UserService {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 10, RoleCode: "self", PermissionID: 100, PermissionCode: "user:update", Effect: model.EffectAllow, Condition: "resource.id == subject.id"},
+		{RoleID: 20, RoleCode: "ops", PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectAllow},
+		{RoleID: 20, RoleCode: "ops", PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectDeny, Condition: "request.time >= 22:00 || request.time < 06:00"},
+		{RoleID: 20, RoleCode: "ops", PermissionID: 300, PermissionCode: "user:*", Effect: model.EffectDeny, Condition: "!(request.ip in 10.0.0.0/8)"},
+	}, nil)
+
+	return service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+}
+
+// 测试携带属性的权限检查
+func TestUserService_EvaluatePermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *schema.EvaluatePermissionRequest
+		hasPermission bool
+		deniedBy      string
+	}{
+		{
+			name: "更新本人资料",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:update",
+				Resource:   map[string]interface{}{"id": float64(1)},
+				Request:    map[string]interface{}{"ip": "10.0.0.5"},
+			},
+			hasPermission: true,
+		},
+		{
+			name: "更新他人资料",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:update",
+				Resource:   map[string]interface{}{"id": float64(2)},
+				Request:    map[string]interface{}{"ip": "10.0.0.5"},
+			},
+		},
+		{
+			name: "白天在内网删除用户",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:delete",
+				Request:    map[string]interface{}{"ip": "10.0.0.5", "time": "10:00"},
+			},
+			hasPermission: true,
+		},
+		{
+			name: "夜间删除用户",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:delete",
+				Request:    map[string]interface{}{"ip": "10.0.0.5", "time": "23:30"},
+			},
+			deniedBy: "ops",
+		},
+		{
+			name: "内网外访问",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:delete",
+				Request:    map[string]interface{}{"ip": "203.0.113.9", "time": "10:00"},
+			},
+			deniedBy: "ops",
+		},
+		{
+			name: "拒绝条件求值失败时拒绝生效",
+			req: &schema.EvaluatePermissionRequest{
+				Permission: "user:delete",
+				Request:    map[string]interface{}{"ip": "10.0.0.5", "time": float64(10)},
+			},
+			deniedBy: "ops",
+		},
+	}
+
+	userService := newConditionUserService()
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := userService.EvaluatePermission(1, 0, nil, tt.req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.hasPermission, result.HasPermission)
+			if tt.deniedBy == "" {
+				assert.Nil(t, result.DeniedBy)
+			} else {
+				require.NotNil(t, result.DeniedBy)
+				assert.Equal(t, tt.deniedBy, result.DeniedBy.Code)
+			}
+		})
+	}
+
+	t.Run("返回条件求值结果", func(t *testing.T) {
+		result, err := userService.EvaluatePermission(1, 0, nil, &schema.EvaluatePermissionRequest{
+			Permission: "user:update",
+			Resource:   map[string]interface{}{"id": "1"},
+			Request:    map[string]interface{}{"ip": "10.0.0.5"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Conditions, 2)
+		assert.Equal(t, "resource.id == subject.id", result.Conditions[0].Condition)
+		assert.True(t, result.Conditions[0].Satisfied)
+		assert.False(t, result.Conditions[1].Satisfied)
+	})
+}
+
+// 测试不携带属性时带条件授予的处理：允许不生效，拒绝总是生效
+func TestUserService_ConditionalGrantsWithoutAttributes(t *testing.T) {
+	userService := newConditionUserService()
+
+	result, err := userService.CheckPermission(1, 0, nil, "user:update")
+	require.NoError(t, err)
+	assert.False(t, result.HasPermission)
+
+	result, err = userService.CheckPermission(1, 0, nil, "user:delete")
+	require.NoError(t, err)
+	assert.False(t, result.HasPermission)
+	require.NotNil(t, result.DeniedBy)
+
+	policy, err := userService.GetPermissionPolicy(1, 0)
+	require.NoError(t, err)
+	assert.False(t, policy.Allows("user:update"))
+	assert.False(t, policy.Allows("user:delete"))
+}
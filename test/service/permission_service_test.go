@@ -69,6 +69,39 @@ func TestPermissionService_Create(t *testing.T) {
 			expectedError:  errors.ErrPermissionExists,
 			expectedCalled: false,
 		},
+		{
+			name: "权限编码格式错误",
+			request: &schema.CreatePermissionRequest{
+				Name:        "格式错误权限",
+				Code:        "user:li*",
+				Description: "通配符必须独占一个分段",
+			},
+			mockSetup: func(mockPermRepo *mocks.MockPermissionRepository) {
+				// 格式错误时不应访问仓储
+			},
+			expectedID:     0,
+			expectedError:  errors.ErrInvalidPermissionCode,
+			expectedCalled: false,
+		},
+		{
+			name: "成功创建通配符权限",
+			request: &schema.CreatePermissionRequest{
+				Name:        "用户管理全部权限",
+				Code:        "user:*",
+				Description: "覆盖全部用户管理操作",
+			},
+			mockSetup: func(mockPermRepo *mocks.MockPermissionRepository) {
+				mockPermRepo.On("GetByName", "用户管理全部权限").Return(nil, nil)
+				mockPermRepo.On("GetByCode", "user:*").Return(nil, nil)
+				mockPermRepo.On("Create", mock.AnythingOfType("*model.Permission")).Run(func(args mock.Arguments) {
+					perm := args.Get(0).(*model.Permission)
+					perm.ID = 2
+				}).Return(nil)
+			},
+			expectedID:     2,
+			expectedError:  nil,
+			expectedCalled: true,
+		},
 		{
 			name: "数据库错误",
 			request: &schema.CreatePermissionRequest{
@@ -103,7 +136,7 @@ func TestPermissionService_Create(t *testing.T) {
 			tt.mockSetup(mockPermRepo)
 			
 			// 创建服务实例
-			permissionService := service.NewPermissionService(mockPermRepo)
+			permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
 			
 			// 调用被测试的方法
 			id, err := permissionService.Create(tt.request)
@@ -221,7 +254,7 @@ func TestPermissionService_Update(t *testing.T) {
 			tt.mockSetup(mockPermRepo)
 			
 			// 创建服务实例
-			permissionService := service.NewPermissionService(mockPermRepo)
+			permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
 			
 			// 调用被测试的方法
 			err := permissionService.Update(tt.request)
@@ -251,7 +284,10 @@ func TestPermissionService_Delete(t *testing.T) {
 				
 				// 模拟权限没有被角色使用
 				mockPermRepo.On("GetRolesByPermissionID", uint64(1)).Return([]*model.Role{}, nil)
-				
+
+				// 模拟权限没有子节点
+				mockPermRepo.On("CountChildren", uint64(1)).Return(int64(0), nil)
+
 				// 模拟删除权限成功
 				mockPermRepo.On("Delete", uint64(1)).Return(nil)
 			},
@@ -295,7 +331,7 @@ func TestPermissionService_Delete(t *testing.T) {
 			tt.mockSetup(mockPermRepo)
 			
 			// 创建服务实例
-			permissionService := service.NewPermissionService(mockPermRepo)
+			permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
 			
 			// 调用被测试的方法
 			err := permissionService.Delete(tt.permissionID)
@@ -309,7 +345,7 @@ func TestPermissionService_Delete(t *testing.T) {
 // TestPermissionService_List 分页列表测试
 func TestPermissionService_List(t *testing.T) {
 	mockPermRepo := new(mocks.MockPermissionRepository)
-	service := service.NewPermissionService(mockPermRepo)
+	service := service.NewPermissionService(mockPermRepo, nil, nil)
 
 	tests := []struct {
 		name       string
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/apiroute"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试接口路由同步：新建、关联已有编码、跳过冲突以及报告失效路由
+func TestPermissionService_SyncRoutes(t *testing.T) {
+	listRoute := apiroute.Route{Method: "POST", Path: "/api/v1/users/list"}
+	createRoute := apiroute.Route{Method: "POST", Path: "/api/v1/users/create"}
+	detailRoute := apiroute.Route{Method: "POST", Path: "/api/v1/users/detail"}
+	deleteRoute := apiroute.Route{Method: "POST", Path: "/api/v1/users/delete"}
+	updateRoute := apiroute.Route{Method: "POST", Path: "/api/v1/users/update"}
+
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	// 已登记的接口：列表仍存在，导出已下线
+	mockPermRepo.On("ListRoutes").Return([]*model.Permission{
+		{ID: 1, Code: listRoute.Code(), Method: listRoute.Method, Route: listRoute.Path},
+		{ID: 2, Code: "api:post:api:v1:users:export", Method: "POST", Route: "/api/v1/users/export"},
+		// 手工维护的匹配模式不视为失效
+		{ID: 5, Code: "user:write", Method: "POST", Route: "/api/v1/users/*"},
+	}, nil)
+	// 创建接口尚未登记
+	mockPermRepo.On("GetByCode", createRoute.Code()).Return(nil, nil)
+	mockPermRepo.On("Create", mock.MatchedBy(func(p *model.Permission) bool { return p.Code == createRoute.Code() })).Return(nil)
+	// 详情接口已有手工创建的同编码权限
+	mockPermRepo.On("GetByCode", detailRoute.Code()).Return(&model.Permission{ID: 3, Code: detailRoute.Code(), Type: model.PermissionTypeButton}, nil)
+	mockPermRepo.On("Update", mock.Anything).Return(nil)
+	// 删除接口的编码已绑定到其他路由
+	mockPermRepo.On("GetByCode", deleteRoute.Code()).Return(&model.Permission{ID: 4, Code: deleteRoute.Code(), Method: "GET", Route: "/api/v1/users/delete"}, nil)
+	// 更新接口创建失败
+	mockPermRepo.On("GetByCode", updateRoute.Code()).Return(nil, nil)
+	mockPermRepo.On("Create", mock.MatchedBy(func(p *model.Permission) bool { return p.Code == updateRoute.Code() })).Return(stderrors.New("名称冲突"))
+
+	permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
+	report, err := permissionService.SyncRoutes([]apiroute.Route{listRoute, createRoute, detailRoute, deleteRoute, updateRoute})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{createRoute.String()}, report.Created)
+	assert.Equal(t, []string{detailRoute.String()}, report.Linked)
+	assert.Equal(t, []string{"POST /api/v1/users/export"}, report.Stale)
+	assert.Len(t, report.Skipped, 2)
+
+	var created, linked *model.Permission
+	for _, call := range mockPermRepo.Calls {
+		switch call.Method {
+		case "Create":
+			if p := call.Arguments.Get(0).(*model.Permission); p.Code == createRoute.Code() {
+				created = p
+			}
+		case "Update":
+			linked = call.Arguments.Get(0).(*model.Permission)
+		}
+	}
+	require.NotNil(t, created)
+	assert.Equal(t, model.PermissionTypeAPI, created.Type)
+	assert.Equal(t, createRoute.Method, created.Method)
+	assert.Equal(t, createRoute.Path, created.Route)
+	require.NotNil(t, linked)
+	assert.Equal(t, uint64(3), linked.ID)
+	assert.Equal(t, model.PermissionTypeAPI, linked.Type)
+	assert.Equal(t, detailRoute.Path, linked.Route)
+	mockPermRepo.AssertNotCalled(t, "GetByCode", listRoute.Code())
+}
+
+// 测试按请求方法与路径查找匹配的接口权限
+func TestUserService_GetRoutePermissions(t *testing.T) {
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("ListRoutes").Return([]*model.Permission{
+		{ID: 1, Code: "api:post:api:v1:users:list", Method: "POST", Route: "/api/v1/users/list"},
+		{ID: 2, Code: "user:write", Method: "POST", Route: "/api/v1/users/*"},
+		{ID: 3, Code: "user:read", Method: "GET", Route: "/api/v1/users/:id"},
+	}, nil)
+	userService := service.NewUserService(new(mocks.MockUserRepository), new(mocks.MockRoleRepository), mockPermRepo, new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	codes, err := userService.GetRoutePermissions("POST", "/api/v1/users/list")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api:post:api:v1:users:list", "user:write"}, codes)
+
+	codes, err = userService.GetRoutePermissions("GET", "/api/v1/users/42")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:read"}, codes)
+
+	codes, err = userService.GetRoutePermissions("DELETE", "/api/v1/users/42")
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+
+	// 路由表只加载一次
+	mockPermRepo.AssertNumberOfCalls(t, "ListRoutes", 1)
+}
+
+// 测试接口权限变更后重建共用的接口路由表
+func TestRouteTable_RebuildOnPermissionChange(t *testing.T) {
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("ListRoutes").Return([]*model.Permission{
+		{ID: 1, Code: "user:read", Method: "GET", Route: "/api/v1/users/:id"},
+	}, nil).Once()
+	mockPermRepo.On("ListRoutes").Return([]*model.Permission{
+		{ID: 1, Code: "user:read", Method: "GET", Route: "/api/v1/users/:id"},
+		{ID: 2, Code: "user:remove", Method: "DELETE", Route: "/api/v1/users/:id"},
+	}, nil)
+	mockPermRepo.On("GetByName", "删除用户").Return(nil, nil)
+	mockPermRepo.On("GetByCode", "user:remove").Return(nil, nil)
+	mockPermRepo.On("Create", mock.AnythingOfType("*model.Permission")).Return(nil)
+
+	routeTable := service.NewRouteTable(mockPermRepo, 0)
+	userService := service.NewUserService(new(mocks.MockUserRepository), new(mocks.MockRoleRepository), mockPermRepo, new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, routeTable, nil)
+	permissionService := service.NewPermissionService(mockPermRepo, nil, routeTable)
+
+	codes, err := userService.GetRoutePermissions("DELETE", "/api/v1/users/42")
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+
+	_, err = permissionService.Create(&schema.CreatePermissionRequest{Code: "user:remove", Name: "删除用户", Method: "DELETE", Route: "/api/v1/users/:id"})
+	require.NoError(t, err)
+
+	codes, err = userService.GetRoutePermissions("DELETE", "/api/v1/users/42")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:remove"}, codes)
+	mockPermRepo.AssertNumberOfCalls(t, "ListRoutes", 2)
+}
+
+// 测试设置了 ttl 的接口路由表过期后重新加载
+func TestRouteTable_TTL(t *testing.T) {
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("ListRoutes").Return([]*model.Permission{}, nil)
+
+	routeTable := service.NewRouteTable(mockPermRepo, time.Millisecond)
+	_, err := routeTable.Match("GET", "/api/v1/users/42")
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = routeTable.Match("GET", "/api/v1/users/42")
+	require.NoError(t, err)
+
+	mockPermRepo.AssertNumberOfCalls(t, "ListRoutes", 2)
+}
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 权限树夹具：系统管理模块下有用户菜单与角色菜单，用户菜单下有删除按钮与列表接口
+func permissionTreeFixture() []*model.Permission {
+	return []*model.Permission{
+		{ID: 1, Code: "system:view", Type: model.PermissionTypeModule},
+		{ID: 2, Code: "user:menu", Type: model.PermissionTypeMenu, ParentID: 1, Route: "/system/users"},
+		{ID: 3, Code: "role:menu", Type: model.PermissionTypeMenu, ParentID: 1, Route: "/system/roles"},
+		{ID: 4, Code: "user:delete", Type: model.PermissionTypeButton, ParentID: 2},
+		{ID: 5, Code: "user:list", Type: model.PermissionTypeAPI, ParentID: 2},
+		{ID: 6, Code: "audit:view", Type: model.PermissionTypeMenu},
+	}
+}
+
+// treeCodes 以 编码 -> 子节点编码 的形式展开权限树，便于断言结构
+func treeCodes(nodes []*schema.PermissionTreeNode) map[string][]string {
+	result := make(map[string][]string)
+	var walk func(parent string, nodes []*schema.PermissionTreeNode)
+	walk = func(parent string, nodes []*schema.PermissionTreeNode) {
+		for _, node := range nodes {
+			result[parent] = append(result[parent], node.Code)
+			walk(node.Code, node.Children)
+		}
+	}
+	walk("", nodes)
+	return result
+}
+
+// 测试按父级关系组装权限树并按类型过滤
+func TestPermissionService_Tree(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    []string
+		expected map[string][]string
+	}{
+		{
+			name:  "完整权限树",
+			types: nil,
+			expected: map[string][]string{
+				"":            {"system:view", "audit:view"},
+				"system:view": {"user:menu", "role:menu"},
+				"user:menu":   {"user:delete", "user:list"},
+			},
+		},
+		{
+			name:  "只保留模块与菜单",
+			types: []string{model.PermissionTypeModule, model.PermissionTypeMenu},
+			expected: map[string][]string{
+				"":            {"system:view", "audit:view"},
+				"system:view": {"user:menu", "role:menu"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockPermRepo := new(mocks.MockPermissionRepository)
+			mockPermRepo.On("ListAll").Return(permissionTreeFixture(), nil)
+			permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
+
+			tree, err := permissionService.Tree(&schema.PermissionTreeRequest{Types: tt.types})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, treeCodes(tree))
+		})
+	}
+}
+
+// 测试更新父级权限时拒绝不存在的父级以及形成循环的移动
+func TestPermissionService_UpdateParent(t *testing.T) {
+	uint64Ptr := func(v uint64) *uint64 { return &v }
+
+	tests := []struct {
+		name          string
+		id            uint64
+		parentID      uint64
+		expectedError error
+	}{
+		{name: "移动到其他模块", id: 2, parentID: 6},
+		{name: "移动到根节点", id: 2, parentID: 0},
+		{name: "父级不存在", id: 2, parentID: 99, expectedError: errors.ErrInvalidParent},
+		{name: "父级为自身", id: 2, parentID: 2, expectedError: errors.ErrInvalidParent},
+		{name: "父级为子孙节点", id: 1, parentID: 4, expectedError: errors.ErrInvalidParent},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockPermRepo := new(mocks.MockPermissionRepository)
+			for _, permission := range permissionTreeFixture() {
+				mockPermRepo.On("GetByID", permission.ID).Return(permission, nil)
+			}
+			mockPermRepo.On("GetByID", uint64(99)).Return(nil, nil)
+			mockPermRepo.On("GetByName", mock.Anything).Return(nil, nil)
+			mockPermRepo.On("Update", mock.Anything).Return(nil)
+			permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
+
+			existing := permissionTreeFixture()[tt.id-1]
+			err := permissionService.Update(&schema.UpdatePermissionRequest{
+				ID:          tt.id,
+				Code:        existing.Code,
+				Name:        "新名称",
+				Description: "描述",
+				ParentID:    uint64Ptr(tt.parentID),
+			})
+			assert.Equal(t, tt.expectedError, err)
+
+			if tt.expectedError == nil {
+				updated := mockPermRepo.Calls[len(mockPermRepo.Calls)-1].Arguments.Get(0).(*model.Permission)
+				assert.Equal(t, tt.parentID, updated.ParentID)
+				assert.Equal(t, existing.Type, updated.Type)
+			} else {
+				mockPermRepo.AssertNotCalled(t, "Update", mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试删除仍有子节点的权限
+func TestPermissionService_DeleteWithChildren(t *testing.T) {
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("GetByID", uint64(1)).Return(&model.Permission{ID: 1}, nil)
+	mockPermRepo.On("CountChildren", uint64(1)).Return(int64(2), nil)
+	permissionService := service.NewPermissionService(mockPermRepo, nil, nil)
+
+	assert.Equal(t, errors.ErrPermissionHasChildren, permissionService.Delete(1))
+	mockPermRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+// 测试用户菜单只包含被允许的分支，拒绝规则会剪掉整个分支，接口节点不出现在菜单中
+func TestUserService_GetMenus(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 10, PermissionCode: "system:view", Effect: model.EffectAllow},
+		{RoleID: 10, PermissionCode: "user:*", Effect: model.EffectAllow},
+		{RoleID: 10, PermissionCode: "role:menu", Effect: model.EffectAllow},
+		{RoleID: 20, PermissionCode: "role:menu", Effect: model.EffectDeny},
+	}, nil)
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("ListAll").Return(permissionTreeFixture(), nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), mockPermRepo, new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	menus, err := userService.GetMenus(1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"":            {"system:view"},
+		"system:view": {"user:menu"},
+		"user:menu":   {"user:delete"},
+	}, treeCodes(menus))
+}
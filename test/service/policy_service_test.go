@@ -0,0 +1,228 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 策略服务的模拟依赖，数据库中 admin 拥有 user:list 与 user:delete，alice 与 carol 为 admin，
+// maker 与 checker 受静态职责分离规则约束
+type policyMocks struct {
+	policyRepo     *mocks.MockPolicyRepository
+	userRepo       *mocks.MockUserRepository
+	roleRepo       *mocks.MockRoleRepository
+	permissionRepo *mocks.MockPermissionRepository
+	tenantRepo     *mocks.MockTenantRepository
+	sodRepo        *mocks.MockSoDRepository
+}
+
+// 创建带模拟依赖的策略服务，bobBindings 为 bob 已有的角色分配
+func newPolicyService(bobBindings ...*model.UserRoleBinding) (service.PolicyService, *policyMocks) {
+	m := &policyMocks{
+		policyRepo:     new(mocks.MockPolicyRepository),
+		userRepo:       new(mocks.MockUserRepository),
+		roleRepo:       newSoDRoleRepo(),
+		permissionRepo: new(mocks.MockPermissionRepository),
+		tenantRepo:     new(mocks.MockTenantRepository),
+		sodRepo:        new(mocks.MockSoDRepository),
+	}
+	m.policyRepo.On("GetGrants").Return([]*model.PermissionGrant{
+		{RoleID: 1, RoleCode: "admin", PermissionID: 10, PermissionCode: "user:list", Effect: model.EffectAllow},
+		{RoleID: 1, RoleCode: "admin", PermissionID: 11, PermissionCode: "user:delete", Effect: model.EffectAllow},
+	}, nil)
+	m.policyRepo.On("GetBindings").Return(append([]*model.UserRoleBinding{
+		{UserID: 1, Username: "alice", RoleID: 1, RoleCode: "admin"},
+		{UserID: 2, Username: "carol", RoleID: 1, RoleCode: "admin"},
+	}, bobBindings...), nil)
+
+	bobAssignments := make([]*model.RoleAssignment, 0, len(bobBindings))
+	for _, binding := range bobBindings {
+		bobAssignments = append(bobAssignments, &model.RoleAssignment{UserRole: model.UserRole{UserID: 3, RoleID: binding.RoleID, TenantID: binding.TenantID}})
+	}
+	m.sodRepo.On("ListByType", model.SoDStatic).Return([]*model.SoDRule{sodRule(model.SoDStatic)}, nil)
+	m.sodRepo.On("GetUserAssignments", uint64(3)).Return(bobAssignments, nil)
+	m.sodRepo.On("GetUserAssignments", mock.Anything).Return([]*model.RoleAssignment{}, nil)
+
+	m.roleRepo.On("ListByCode", "admin").Return([]*model.Role{{ID: 1, Code: "admin"}}, nil)
+	m.roleRepo.On("ListByCode", "acme-admin").Return([]*model.Role{{ID: 2, Code: "acme-admin", TenantID: 8}}, nil)
+	m.roleRepo.On("ListByCode", "maker").Return([]*model.Role{&sodMaker}, nil)
+	m.roleRepo.On("ListByCode", "checker").Return([]*model.Role{&sodChecker}, nil)
+	m.roleRepo.On("ListByCode", "ops").Return([]*model.Role{{ID: 5, Code: "ops", TenantID: 7}, {ID: 6, Code: "ops", TenantID: 9}}, nil)
+	m.roleRepo.On("ListByCode", mock.Anything).Return([]*model.Role{}, nil)
+	m.roleRepo.On("GetByCode", "ops", uint64(7)).Return(&model.Role{ID: 5, Code: "ops", TenantID: 7}, nil)
+	m.roleRepo.On("GetByCode", mock.Anything, mock.Anything).Return(nil, nil)
+	m.roleRepo.On("GetByName", mock.Anything, model.GlobalTenantID).Return(nil, nil)
+	m.permissionRepo.On("GetByCode", "user:list").Return(&model.Permission{ID: 10, Code: "user:list"}, nil)
+	m.permissionRepo.On("GetByCode", "user:delete").Return(&model.Permission{ID: 11, Code: "user:delete"}, nil)
+	m.permissionRepo.On("GetByCode", mock.Anything).Return(nil, nil)
+	m.userRepo.On("GetByUsername", "bob").Return(&model.User{ID: 3, Username: "bob"}, nil)
+	m.userRepo.On("GetByUsername", mock.Anything).Return(nil, nil)
+	m.tenantRepo.On("GetByCode", "acme").Return(&model.Tenant{ID: 7, Code: "acme"}, nil)
+	m.tenantRepo.On("GetByCode", mock.Anything).Return(nil, nil)
+
+	policyService := service.NewPolicyService(m.policyRepo, m.userRepo, m.roleRepo, m.permissionRepo, m.tenantRepo, nil, m.sodRepo)
+	return policyService, m
+}
+
+const policyContent = `p, admin, user:list
+p, admin, user:delete, deny
+p, auditor, user:list
+g, alice, admin
+g, bob, auditor, acme
+`
+
+// 测试导出结果排序稳定
+func TestPolicyService_Export(t *testing.T) {
+	policyService, _ := newPolicyService()
+
+	policy, err := policyService.Export()
+	require.NoError(t, err)
+	assert.Equal(t, "p, admin, user:delete\np, admin, user:list\ng, alice, admin\ng, carol, admin\n", policy.String())
+}
+
+// 测试导入差异：效果变化替换授予，缺失的角色被创建，多余的分配被删除
+func TestPolicyService_Import(t *testing.T) {
+	tests := []struct {
+		name    string
+		dryRun  bool
+		applied bool
+	}{
+		{name: "预览不写入", dryRun: true},
+		{name: "同步写入", applied: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			policyService, m := newPolicyService()
+			var change *model.PolicyChange
+			m.policyRepo.On("Apply", mock.Anything).Run(func(args mock.Arguments) {
+				change = args.Get(0).(*model.PolicyChange)
+			}).Return(nil)
+
+			report, err := policyService.Import(strings.NewReader(policyContent), tt.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, &schema.PolicyImportReport{
+				DryRun:       tt.dryRun,
+				Applied:      tt.applied,
+				CreatedRoles: []string{"auditor"},
+				Added:        []string{"p, admin, user:delete, deny", "p, auditor, user:list", "g, bob, auditor, acme"},
+				Removed:      []string{"p, admin, user:delete", "g, carol, admin"},
+			}, report)
+
+			if !tt.applied {
+				m.policyRepo.AssertNotCalled(t, "Apply", mock.Anything)
+				return
+			}
+			m.policyRepo.AssertNumberOfCalls(t, "Apply", 1)
+			require.Len(t, change.CreateRoles, 1)
+			// 新建角色的授予与分配引用同一个角色对象，以便使用事务内生成的ID
+			assert.Same(t, change.CreateRoles[0], change.AddGrants[1].Role)
+			assert.Same(t, change.CreateRoles[0], change.AddAssignments[0].Role)
+			assert.Equal(t, uint64(7), change.AddAssignments[0].TenantID)
+		})
+	}
+}
+
+// 测试存在校验错误时不执行任何变更
+func TestPolicyService_ImportInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errors  []string
+	}{
+		{name: "格式错误", content: "p, admin", errors: []string{"第1行: 规则字段数量错误"}},
+		{name: "权限不存在", content: "p, admin, order:list", errors: []string{"权限 order:list 不存在"}},
+		{name: "用户与租户不存在", content: "g, dave, admin, globex", errors: []string{"用户 dave 不存在", "租户 globex 不存在"}},
+		{name: "效果冲突", content: "p, admin, user:list\np, admin, user:list, deny", errors: []string{"角色 admin 对权限 user:list 同时声明了 allow 与 deny"}},
+		{name: "租户角色跨租户分配", content: "g, bob, acme-admin, acme", errors: []string{"g, bob, acme-admin, acme: " + errors.ErrTenantMismatch.Error()}},
+		{name: "角色编码在多个租户内存在", content: "p, ops, user:list", errors: []string{"角色 ops 在多个租户内存在，无法确定引用的角色"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			policyService, m := newPolicyService()
+
+			report, err := policyService.Import(strings.NewReader(tt.content), false)
+			assert.Equal(t, errors.ErrInvalidPolicy, err)
+			assert.Equal(t, tt.errors, report.Errors)
+			m.policyRepo.AssertNotCalled(t, "Apply", mock.Anything)
+		})
+	}
+}
+
+// 测试带租户的角色分配优先引用该租户内同编码的角色
+func TestPolicyService_ImportResolvesTenantRole(t *testing.T) {
+	policyService, m := newPolicyService()
+	var change *model.PolicyChange
+	m.policyRepo.On("Apply", mock.Anything).Run(func(args mock.Arguments) {
+		change = args.Get(0).(*model.PolicyChange)
+	}).Return(nil)
+
+	content := "p, admin, user:list\np, admin, user:delete\ng, alice, admin\ng, carol, admin\ng, bob, ops, acme\n"
+	report, err := policyService.Import(strings.NewReader(content), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Empty(t, report.CreatedRoles)
+	require.Len(t, change.AddAssignments, 1)
+	assert.Equal(t, uint64(5), change.AddAssignments[0].Role.ID)
+	assert.Equal(t, uint64(7), change.AddAssignments[0].TenantID)
+}
+
+// 测试导入后的角色分配违反静态职责分离规则时报告校验错误
+func TestPolicyService_ImportSoD(t *testing.T) {
+	const base = "p, admin, user:list\np, admin, user:delete\ng, alice, admin\ng, carol, admin\n"
+
+	tests := []struct {
+		name     string
+		bindings []*model.UserRoleBinding
+		content  string
+		errors   []string
+	}{
+		{
+			name:    "同时分配互斥角色",
+			content: base + "g, bob, maker\ng, bob, checker\n",
+			errors:  []string{"用户 bob: " + errors.ErrSoDViolation.Error() + "（付款审批）"},
+		},
+		{
+			name:     "租户内保留复核时全局分配制单",
+			bindings: []*model.UserRoleBinding{{UserID: 3, Username: "bob", RoleID: 20, RoleCode: "checker", TenantID: 7, TenantCode: "acme"}},
+			content:  base + "g, bob, checker, acme\ng, bob, maker\n",
+			errors:   []string{"用户 bob 在租户 acme 内: " + errors.ErrSoDViolation.Error() + "（付款审批）"},
+		},
+		{
+			name:     "以制单替换复核",
+			bindings: []*model.UserRoleBinding{{UserID: 3, Username: "bob", RoleID: 20, RoleCode: "checker"}},
+			content:  base + "g, bob, maker\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			policyService, m := newPolicyService(tt.bindings...)
+			m.policyRepo.On("Apply", mock.Anything).Return(nil)
+
+			report, err := policyService.Import(strings.NewReader(tt.content), false)
+			assert.Equal(t, tt.errors, report.Errors)
+			if tt.errors != nil {
+				assert.Equal(t, errors.ErrInvalidPolicy, err)
+				m.policyRepo.AssertNotCalled(t, "Apply", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			m.policyRepo.AssertNumberOfCalls(t, "Apply", 1)
+		})
+	}
+}
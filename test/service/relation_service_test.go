@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/relation"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRelationRepo 基于内存的关系元组仓储，便于构造关系图
+type memoryRelationRepo struct {
+	tuples []*model.RelationTuple
+}
+
+func (r *memoryRelationRepo) Write(tuples []*model.RelationTuple) (int, error) {
+	r.tuples = append(r.tuples, tuples...)
+	return len(tuples), nil
+}
+
+func (r *memoryRelationRepo) Delete(tuples []*model.RelationTuple) (int, error) {
+	return 0, nil
+}
+
+func (r *memoryRelationRepo) List(filter *model.RelationTuple, page, pageSize int) ([]*model.RelationTuple, int64, error) {
+	return r.tuples, int64(len(r.tuples)), nil
+}
+
+func (r *memoryRelationRepo) GetSubjects(objectType, objectID, rel string) ([]*model.RelationTuple, error) {
+	var tuples []*model.RelationTuple
+	for _, t := range r.tuples {
+		if t.ObjectType == objectType && t.ObjectID == objectID && t.Relation == rel {
+			tuples = append(tuples, t)
+		}
+	}
+	return tuples, nil
+}
+
+func (r *memoryRelationRepo) GetBySubject(subjectType, subjectID, subjectRelation string) ([]*model.RelationTuple, error) {
+	var tuples []*model.RelationTuple
+	for _, t := range r.tuples {
+		if t.SubjectType == subjectType && t.SubjectID == subjectID && t.SubjectRelation == subjectRelation {
+			tuples = append(tuples, t)
+		}
+	}
+	return tuples, nil
+}
+
+// 与配置文件示例一致的对象类型定义
+var testRelationTypes = []config.RelationTypeConfig{
+	{Name: "group", Relations: []config.RelationConfig{{Name: "member"}}},
+	{Name: "folder", Relations: []config.RelationConfig{{Name: "owner"}, {Name: "viewer", Includes: []string{"owner"}}}},
+	{Name: "document", Relations: []config.RelationConfig{
+		{Name: "parent"},
+		{Name: "owner"},
+		{Name: "editor", Includes: []string{"owner"}},
+		{Name: "viewer", Includes: []string{"editor"}, From: []config.RelationFromConfig{{Tupleset: "parent", Relation: "viewer"}}},
+	}},
+}
+
+// newTestRelationService 创建关系授权服务并写入元组
+func newTestRelationService(t *testing.T, maxDepth int, tuples ...string) service.RelationService {
+	t.Helper()
+	definitions, err := relation.NewSchema(&config.RelationsConfig{Types: testRelationTypes, MaxDepth: maxDepth})
+	require.NoError(t, err)
+
+	relationService := service.NewRelationService(definitions, &memoryRelationRepo{})
+	_, err = relationService.Write(&schema.WriteRelationsRequest{Tuples: tuples})
+	require.NoError(t, err)
+	return relationService
+}
+
+// 用户1、2（经由嵌套组 leads）是 eng 组成员，eng 组可查看文件夹 f1，文档 d1 位于 f1 中；
+// 用户3 拥有 d1，用户4 可查看 d2；a、b 两组互为成员形成环
+var testRelationTuples = []string{
+	"group:eng#member@user:1",
+	"group:eng#member@group:leads#member",
+	"group:leads#member@user:2",
+	"folder:f1#viewer@group:eng#member",
+	"document:d1#parent@folder:f1",
+	"document:d1#owner@user:3",
+	"document:d2#viewer@user:4",
+	"group:a#member@group:b#member",
+	"group:b#member@group:a#member",
+	"group:a#member@user:5",
+}
+
+// 测试关系判定
+func TestRelationService_Check(t *testing.T) {
+	tests := []struct {
+		name          string
+		object        string
+		relation      string
+		subject       string
+		allowed       bool
+		expectedError error
+	}{
+		{name: "拥有者经由包含关系可以查看", object: "document:d1", relation: "viewer", subject: "user:3", allowed: true},
+		{name: "拥有者可以编辑", object: "document:d1", relation: "editor", subject: "user:3", allowed: true},
+		{name: "组成员经由文件夹可以查看", object: "document:d1", relation: "viewer", subject: "user:1", allowed: true},
+		{name: "嵌套组成员经由文件夹可以查看", object: "document:d1", relation: "viewer", subject: "user:2", allowed: true},
+		{name: "查看者不能编辑", object: "document:d1", relation: "editor", subject: "user:1"},
+		{name: "其他文档的查看者", object: "document:d1", relation: "viewer", subject: "user:4"},
+		{name: "用户集主体", object: "document:d1", relation: "viewer", subject: "group:eng#member", allowed: true},
+		{name: "环中的成员", object: "group:b", relation: "member", subject: "user:5", allowed: true},
+		{name: "环中没有的成员", object: "group:b", relation: "member", subject: "user:6"},
+		{name: "未定义的关系", object: "document:d1", relation: "commenter", subject: "user:3", expectedError: errors.ErrRelationNotDefined},
+		{name: "对象格式错误", object: "d1", relation: "viewer", subject: "user:3", expectedError: errors.ErrInvalidRelationTarget},
+	}
+
+	relationService := newTestRelationService(t, 0, testRelationTuples...)
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := relationService.Check(&schema.CheckRelationRequest{Object: tt.object, Relation: tt.relation, Subject: tt.subject})
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				assert.Equal(t, tt.allowed, result.Allowed)
+			}
+		})
+	}
+}
+
+// 测试超过展开层数上限
+func TestRelationService_CheckDepthExceeded(t *testing.T) {
+	relationService := newTestRelationService(t, 2,
+		"group:g1#member@group:g2#member",
+		"group:g2#member@group:g3#member",
+		"group:g3#member@group:g4#member",
+		"group:g4#member@user:1",
+	)
+
+	_, err := relationService.Check(&schema.CheckRelationRequest{Object: "group:g1", Relation: "member", Subject: "user:1"})
+	assert.Equal(t, errors.ErrRelationDepthExceeded, err)
+}
+
+// 测试列出可访问的对象，结果与逐个判定一致
+func TestRelationService_ListObjects(t *testing.T) {
+	relationService := newTestRelationService(t, 0, testRelationTuples...)
+
+	for _, subject := range []string{"user:1", "user:2", "user:3", "user:4", "user:5", "group:eng#member"} {
+		result, err := relationService.ListObjects(&schema.ListObjectsRequest{ObjectType: "document", Relation: "viewer", Subject: subject})
+		require.NoError(t, err)
+
+		expected := []string{}
+		for _, id := range []string{"d1", "d2"} {
+			check, err := relationService.Check(&schema.CheckRelationRequest{Object: "document:" + id, Relation: "viewer", Subject: subject})
+			require.NoError(t, err)
+			if check.Allowed {
+				expected = append(expected, id)
+			}
+		}
+		assert.Equal(t, expected, result.ObjectIDs, subject)
+	}
+
+	result, err := relationService.ListObjects(&schema.ListObjectsRequest{ObjectType: "group", Relation: "member", Subject: "user:5"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result.ObjectIDs)
+}
+
+// 测试展开关系持有者
+func TestRelationService_Expand(t *testing.T) {
+	relationService := newTestRelationService(t, 0, testRelationTuples...)
+
+	tree, err := relationService.Expand(&schema.ExpandRelationRequest{Object: "document:d1", Relation: "viewer"})
+	require.NoError(t, err)
+	require.Len(t, tree.Children, 2)
+
+	// 编辑者包含拥有者
+	editor := tree.Children[0]
+	assert.Equal(t, schema.RelationViaIncludes, editor.Via)
+	require.Len(t, editor.Children, 1)
+	assert.Equal(t, []string{"user:3"}, editor.Children[0].Subjects)
+
+	// 经由所在文件夹的查看者
+	folder := tree.Children[1]
+	assert.Equal(t, schema.RelationViaFrom, folder.Via)
+	assert.Equal(t, "parent", folder.Tupleset)
+	assert.Equal(t, "folder:f1", folder.Object)
+	require.Len(t, folder.Children, 2)
+	eng := folder.Children[0]
+	assert.Equal(t, schema.RelationViaUserset, eng.Via)
+	assert.Equal(t, []string{"user:1"}, eng.Subjects)
+
+	// 环只展开一次
+	tree, err = relationService.Expand(&schema.ExpandRelationRequest{Object: "group:a", Relation: "member"})
+	require.NoError(t, err)
+	require.Len(t, tree.Children, 1)
+	require.Len(t, tree.Children[0].Children, 1)
+	assert.True(t, tree.Children[0].Children[0].Cycle)
+}
+
+// 测试写入无效元组时不写入任何元组
+func TestRelationService_WriteInvalid(t *testing.T) {
+	definitions, err := relation.NewSchema(&config.RelationsConfig{Types: testRelationTypes})
+	require.NoError(t, err)
+	repo := &memoryRelationRepo{}
+
+	result, err := service.NewRelationService(definitions, repo).Write(&schema.WriteRelationsRequest{Tuples: []string{
+		"document:d1#viewer@user:1",
+		"document:d1#commenter@user:1",
+		"document:d1#viewer",
+	}})
+	assert.Equal(t, errors.ErrInvalidRelationTuple, err)
+	assert.Len(t, result.Errors, 2)
+	assert.Empty(t, repo.tuples)
+}
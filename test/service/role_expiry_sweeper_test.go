@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试清理过期角色分配并失效相关用户的缓存
+func TestRoleExpirySweeper_Sweep(t *testing.T) {
+	cache := service.NewPermissionCache(time.Minute, 0)
+	userService, mockUserRepo := newCachedUserService(cache)
+	validUntil := time.Now().Add(-time.Minute).Unix()
+	mockUserRepo.On("DeleteExpiredRoles", mock.AnythingOfType("int64")).Return([]model.UserRole{
+		{UserID: 1, RoleID: 20, Validity: model.Validity{ValidUntil: &validUntil}},
+	}, nil)
+
+	mustCheck(t, userService, 1, "user:list")
+	mustCheck(t, userService, 2, "permission:list")
+
+	expired, err := service.NewRoleExpirySweeper(mockUserRepo, cache, time.Minute).Sweep()
+
+	require.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, 1, cache.Stats().Entries) // 仅用户1的条目失效
+}
+
+// 测试分配角色时校验生效区间
+func TestUserService_AssignRoleValidity(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name          string
+		validFrom     int64
+		validUntil    int64
+		expected      model.Validity
+		expectedError error
+	}{
+		{
+			name:     "不限制有效期",
+			expected: model.Validity{},
+		},
+		{
+			name:       "限定生效区间",
+			validFrom:  now + 60,
+			validUntil: now + 3600,
+			expected:   model.Validity{ValidFrom: int64Ptr(now + 60), ValidUntil: int64Ptr(now + 3600)},
+		},
+		{
+			name:          "失效时间早于当前时间",
+			validUntil:    now - 60,
+			expectedError: errors.ErrInvalidRoleValidity,
+		},
+		{
+			name:          "失效时间早于生效时间",
+			validFrom:     now + 7200,
+			validUntil:    now + 3600,
+			expectedError: errors.ErrInvalidRoleValidity,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+			mockRoleRepo.On("GetByID", uint64(10)).Return(&model.Role{ID: 10}, nil)
+			mockUserRepo.On("UpdateRoles", uint64(1), uint64(0), []uint64{10}, tt.expected).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+			err := userService.AssignRole(&schema.AssignRoleRequest{
+				UserID:     1,
+				RoleIDs:    []uint64{10},
+				ValidFrom:  tt.validFrom,
+				ValidUntil: tt.validUntil,
+			})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				mockUserRepo.AssertCalled(t, "UpdateRoles", uint64(1), uint64(0), []uint64{10}, tt.expected)
+			}
+		})
+	}
+}
+
+// int64Ptr 返回整数指针
+func int64Ptr(v int64) *int64 {
+	return &v
+}
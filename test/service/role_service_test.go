@@ -32,10 +32,10 @@ func TestRoleService_Create(t *testing.T) {
 			},
 			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "测试角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "测试角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识不存在
-				mockRoleRepo.On("GetByCode", "test:role").Return(nil, nil)
+				mockRoleRepo.On("GetByCode", "test:role", uint64(0)).Return(nil, nil)
 				
 				// 模拟创建角色成功
 				mockRoleRepo.On("Create", mock.AnythingOfType("*model.Role")).Run(func(args mock.Arguments) {
@@ -56,11 +56,11 @@ func TestRoleService_Create(t *testing.T) {
 			},
 			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "已存在角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "已存在角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识已存在
 				existingRole := &model.Role{ID: 1, Code: "existing:role"}
-				mockRoleRepo.On("GetByCode", "existing:role").Return(existingRole, nil)
+				mockRoleRepo.On("GetByCode", "existing:role", uint64(0)).Return(existingRole, nil)
 			},
 			expectedID:     0,
 			expectedError:  errors.ErrRoleExists,
@@ -75,10 +75,10 @@ func TestRoleService_Create(t *testing.T) {
 			},
 			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "测试角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "测试角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识不存在
-				mockRoleRepo.On("GetByCode", "test:role").Return(nil, nil)
+				mockRoleRepo.On("GetByCode", "test:role", uint64(0)).Return(nil, nil)
 				
 				// 模拟创建角色失败
 				mockRoleRepo.On("Create", mock.AnythingOfType("*model.Role")).Return(assert.AnError)
@@ -96,7 +96,7 @@ func TestRoleService_Create(t *testing.T) {
 			},
 			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
 				// 模拟角色名称已存在
-				mockRoleRepo.On("GetByName", "已存在角色").Return(&model.Role{ID: 1, Name: "已存在角色"}, nil)
+				mockRoleRepo.On("GetByName", "已存在角色", uint64(0)).Return(&model.Role{ID: 1, Name: "已存在角色"}, nil)
 			},
 			expectedID:     0,
 			expectedError:  errors.ErrRoleExists,
@@ -116,7 +116,7 @@ func TestRoleService_Create(t *testing.T) {
 			tt.mockSetup(mockRoleRepo, mockPermRepo)
 			
 			// 创建服务实例
-			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo)
+			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
 			
 			// 调用被测试的方法
 			id, err := roleService.Create(tt.request)
@@ -158,10 +158,10 @@ func TestRoleService_Update(t *testing.T) {
 				mockRoleRepo.On("GetByID", uint64(1)).Return(existingRole, nil)
 				
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "更新后的角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "更新后的角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识不存在
-				mockRoleRepo.On("GetByCode", "updated:role").Return(nil, nil)
+				mockRoleRepo.On("GetByCode", "updated:role", uint64(0)).Return(nil, nil)
 				
 				// 模拟更新角色成功
 				mockRoleRepo.On("Update", mock.AnythingOfType("*model.Role")).Return(nil)
@@ -199,11 +199,11 @@ func TestRoleService_Update(t *testing.T) {
 				mockRoleRepo.On("GetByID", uint64(1)).Return(existingRole, nil)
 				
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "更新后的角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "更新后的角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识已被其他角色使用
 				otherRole := &model.Role{ID: 2, Code: "existing:role"}
-				mockRoleRepo.On("GetByCode", "existing:role").Return(otherRole, nil)
+				mockRoleRepo.On("GetByCode", "existing:role", uint64(0)).Return(otherRole, nil)
 			},
 			expectedError: errors.ErrRoleExists,
 		},
@@ -221,10 +221,10 @@ func TestRoleService_Update(t *testing.T) {
 				mockRoleRepo.On("GetByID", uint64(1)).Return(existingRole, nil)
 				
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "更新后的角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "更新后的角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识不存在
-				mockRoleRepo.On("GetByCode", "updated:role").Return(nil, nil)
+				mockRoleRepo.On("GetByCode", "updated:role", uint64(0)).Return(nil, nil)
 				
 				// 模拟更新角色成功
 				mockRoleRepo.On("Update", mock.AnythingOfType("*model.Role")).Return(nil)
@@ -250,11 +250,11 @@ func TestRoleService_Update(t *testing.T) {
 				mockRoleRepo.On("GetByID", uint64(1)).Return(existingRole, nil)
 				
 				// 模拟角色名称不存在
-				mockRoleRepo.On("GetByName", "更新后的角色").Return(nil, nil)
+				mockRoleRepo.On("GetByName", "更新后的角色", uint64(0)).Return(nil, nil)
 				
 				// 模拟角色标识已被其他角色使用
 				otherRole := &model.Role{ID: 2, Code: "existing:role"}
-				mockRoleRepo.On("GetByCode", "existing:role").Return(otherRole, nil)
+				mockRoleRepo.On("GetByCode", "existing:role", uint64(0)).Return(otherRole, nil)
 			},
 			expectedError: errors.ErrRoleExists,
 		},
@@ -272,7 +272,7 @@ func TestRoleService_Update(t *testing.T) {
 			tt.mockSetup(mockRoleRepo, mockPermRepo)
 			
 			// 创建服务实例
-			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo)
+			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
 			
 			// 调用被测试的方法
 			err := roleService.Update(tt.request)
@@ -348,7 +348,7 @@ func TestRoleService_Delete(t *testing.T) {
 			tt.mockSetup(mockRoleRepo, mockPermRepo)
 			
 			// 创建服务实例
-			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo)
+			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
 			
 			// 调用被测试的方法
 			err := roleService.Delete(tt.roleID)
@@ -363,7 +363,7 @@ func TestRoleService_Delete(t *testing.T) {
 func TestRoleService_List(t *testing.T) {
 	mockRoleRepo := new(mocks.MockRoleRepository)
 	mockPermRepo := new(mocks.MockPermissionRepository)
-	service := service.NewRoleService(mockRoleRepo, mockPermRepo)
+	service := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
 
 	tests := []struct {
 		name       string
@@ -381,7 +381,7 @@ func TestRoleService_List(t *testing.T) {
 			keyword:  "",
 			mockSetup: func() {
 				roles := []*model.Role{{ID: 1, Name: "admin"}, {ID: 2, Name: "user"}}
-				mockRoleRepo.On("List", 1, 2, "").Return(roles, int64(2), nil)
+				mockRoleRepo.On("List", 1, 2, "", uint64(0)).Return(roles, int64(2), nil)
 			},
 			expectResp: &schema.ListRoleResponse{
 				Total:      2,
@@ -398,7 +398,7 @@ func TestRoleService_List(t *testing.T) {
 			pageSize: 10,
 			keyword:  "",
 			mockSetup: func() {
-				mockRoleRepo.On("List", 1, 10, "").Return([]*model.Role{}, int64(0), nil)
+				mockRoleRepo.On("List", 1, 10, "", uint64(0)).Return([]*model.Role{}, int64(0), nil)
 			},
 			expectResp: &schema.ListRoleResponse{
 				Total:      0,
@@ -415,7 +415,7 @@ func TestRoleService_List(t *testing.T) {
 			pageSize: 10,
 			keyword:  "",
 			mockSetup: func() {
-				mockRoleRepo.On("List", 1, 10, "").Return([]*model.Role(nil), int64(0), errors.ErrDB)
+				mockRoleRepo.On("List", 1, 10, "", uint64(0)).Return([]*model.Role(nil), int64(0), errors.ErrDB)
 			},
 			expectResp: nil,
 			expectErr: errors.ErrDB,
@@ -434,3 +434,199 @@ func TestRoleService_List(t *testing.T) {
 		})
 	}
 }
+
+// 测试角色继承关系的循环校验
+func TestRoleService_UpdateParents(t *testing.T) {
+	existingRole := &model.Role{ID: 1, Name: "editor", Code: "editor"}
+
+	tests := []struct {
+		name          string
+		parentIDs     []uint64
+		mockSetup     func(mockRoleRepo *mocks.MockRoleRepository)
+		expectedError error
+	}{
+		{
+			name:      "设置父角色成功",
+			parentIDs: []uint64{2},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository) {
+				mockRoleRepo.On("GetByID", uint64(2)).Return(&model.Role{ID: 2, Code: "viewer"}, nil)
+				mockRoleRepo.On("GetDescendants", uint64(1)).Return([]*model.Role{{ID: 3}}, nil)
+				mockRoleRepo.On("Update", mock.AnythingOfType("*model.Role")).Return(nil)
+				mockRoleRepo.On("SetParents", uint64(1), []uint64{2}).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "不能继承自身",
+			parentIDs:     []uint64{1},
+			mockSetup:     func(mockRoleRepo *mocks.MockRoleRepository) {},
+			expectedError: errors.ErrRoleCycle,
+		},
+		{
+			name:      "不能继承自己的后代",
+			parentIDs: []uint64{3},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository) {
+				mockRoleRepo.On("GetByID", uint64(3)).Return(&model.Role{ID: 3, Code: "owner"}, nil)
+				mockRoleRepo.On("GetDescendants", uint64(1)).Return([]*model.Role{{ID: 3}}, nil)
+			},
+			expectedError: errors.ErrRoleCycle,
+		},
+		{
+			name:      "父角色不存在",
+			parentIDs: []uint64{9},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository) {
+				mockRoleRepo.On("GetByID", uint64(9)).Return(nil, nil)
+			},
+			expectedError: errors.ErrRoleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockRoleRepo.On("GetByID", uint64(1)).Return(existingRole, nil)
+			tt.mockSetup(mockRoleRepo)
+
+			roleService := service.NewRoleService(mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), nil)
+			err := roleService.Update(&schema.UpdateRoleRequest{
+				ID:          1,
+				Name:        "editor",
+				Code:        "editor",
+				Description: "编辑",
+				ParentIDs:   tt.parentIDs,
+			})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				mockRoleRepo.AssertNotCalled(t, "Update", mock.Anything)
+				mockRoleRepo.AssertNotCalled(t, "SetParents", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试角色有效权限区分直接与继承
+func TestRoleService_GetEffectivePermissions(t *testing.T) {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockRoleRepo.On("GetRoleWithPermissions", uint64(1)).Return(&model.Role{
+		ID:          1,
+		Code:        "editor",
+		Permissions: []model.Permission{{ID: 10, Code: "user:update"}, {ID: 11, Code: "user:list"}},
+	}, nil)
+	mockRoleRepo.On("GetAncestors", uint64(1)).Return([]*model.Role{
+		{ID: 2, Code: "viewer", Permissions: []model.Permission{{ID: 11, Code: "user:list"}, {ID: 12, Code: "role:list"}}},
+		{ID: 3, Code: "auditor", Permissions: []model.Permission{{ID: 12, Code: "role:list"}}},
+	}, nil)
+
+	roleService := service.NewRoleService(mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), nil)
+	resp, err := roleService.GetEffectivePermissions(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Direct, 2)
+	assert.Len(t, resp.Inherited, 1)
+	assert.Equal(t, "role:list", resp.Inherited[0].Code)
+	assert.Equal(t, []schema.RoleSimple{{ID: 2, Code: "viewer"}, {ID: 3, Code: "auditor"}}, resp.Inherited[0].FromRoles)
+}
+
+// 测试为角色分配带效果的权限
+func TestRoleService_AssignPermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		grants        []schema.PermissionGrant
+		mockSetup     func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository)
+		expectedError error
+	}{
+		{
+			name: "同一权限同时允许和拒绝时以拒绝为准",
+			grants: []schema.PermissionGrant{
+				{PermissionID: 1},
+				{PermissionID: 2, Effect: model.EffectAllow},
+				{PermissionID: 2, Effect: model.EffectDeny},
+				{PermissionID: 2, Effect: model.EffectAllow},
+			},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
+				mockPermRepo.On("GetByID", uint64(1)).Return(&model.Permission{ID: 1, Code: "user:*"}, nil)
+				mockPermRepo.On("GetByID", uint64(2)).Return(&model.Permission{ID: 2, Code: "user:delete"}, nil)
+				mockRoleRepo.On("SetPermissionGrants", uint64(1), []model.RolePermission{
+					{RoleID: 1, PermissionID: 1, Effect: model.EffectAllow},
+					{RoleID: 1, PermissionID: 2, Effect: model.EffectDeny},
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "无效的授予效果",
+			grants: []schema.PermissionGrant{{PermissionID: 1, Effect: "maybe"}},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
+			},
+			expectedError: errors.ErrInvalidEffect,
+		},
+		{
+			name:   "权限不存在",
+			grants: []schema.PermissionGrant{{PermissionID: 3, Effect: model.EffectDeny}},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
+				mockPermRepo.On("GetByID", uint64(3)).Return(nil, nil)
+			},
+			expectedError: errors.ErrPermissionNotFound,
+		},
+		{
+			name:   "附带条件表达式",
+			grants: []schema.PermissionGrant{{PermissionID: 1, Condition: " resource.owner_id == subject.id "}},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
+				mockPermRepo.On("GetByID", uint64(1)).Return(&model.Permission{ID: 1, Code: "user:update"}, nil)
+				mockRoleRepo.On("SetPermissionGrants", uint64(1), []model.RolePermission{
+					{RoleID: 1, PermissionID: 1, Effect: model.EffectAllow, Condition: "resource.owner_id == subject.id"},
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "无效的条件表达式",
+			grants: []schema.PermissionGrant{{PermissionID: 1, Condition: "owner_id = 1"}},
+			mockSetup: func(mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository) {
+			},
+			expectedError: errors.ErrInvalidCondition,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockPermRepo := new(mocks.MockPermissionRepository)
+			mockRoleRepo.On("GetByID", uint64(1)).Return(&model.Role{ID: 1, Code: "support"}, nil)
+			tt.mockSetup(mockRoleRepo, mockPermRepo)
+
+			roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
+			err := roleService.AssignPermission(1, tt.grants)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				mockRoleRepo.AssertNotCalled(t, "SetPermissionGrants", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试角色权限列表携带授予效果
+func TestRoleService_GetPermissionsWithEffect(t *testing.T) {
+	role := &model.Role{
+		ID:          1,
+		Code:        "support",
+		Permissions: []model.Permission{{ID: 1, Code: "user:*"}, {ID: 2, Code: "user:delete"}},
+		PermissionGrants: []model.RolePermission{
+			{RoleID: 1, PermissionID: 1, Effect: model.EffectAllow},
+			{RoleID: 1, PermissionID: 2, Effect: model.EffectDeny},
+		},
+	}
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockRoleRepo.On("GetByID", uint64(1)).Return(role, nil)
+	mockRoleRepo.On("GetRoleWithPermissions", uint64(1)).Return(role, nil)
+
+	roleService := service.NewRoleService(mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), nil)
+	permissions, err := roleService.GetPermissions(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, permissions, 2)
+	assert.Equal(t, model.EffectAllow, permissions[0].Effect)
+	assert.Equal(t, model.EffectDeny, permissions[1].Effect)
+}
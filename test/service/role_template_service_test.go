@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试克隆角色时复制授予效果并应用覆盖项
+func TestRoleService_Clone(t *testing.T) {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockRoleRepo.On("GetByID", uint64(1)).Return(&model.Role{
+		ID:          1,
+		Description: "源角色",
+		PermissionGrants: []model.RolePermission{
+			{RoleID: 1, PermissionID: 10, Effect: model.EffectAllow},
+			{RoleID: 1, PermissionID: 11, Effect: model.EffectAllow},
+			{RoleID: 1, PermissionID: 12, Effect: model.EffectDeny},
+		},
+		Parents: []model.Role{{ID: 5}},
+	}, nil)
+	mockRoleRepo.On("GetByID", uint64(5)).Return(&model.Role{ID: 5}, nil)
+	mockRoleRepo.On("GetByName", "副本", uint64(0)).Return(nil, nil)
+	mockRoleRepo.On("GetByCode", "copy", uint64(0)).Return(nil, nil)
+	mockRoleRepo.On("CreateWithGrants", mock.AnythingOfType("*model.Role"), []uint64{5}, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Role).ID = 2
+	}).Return(nil)
+	mockPermRepo.On("GetByID", uint64(11)).Return(&model.Permission{ID: 11}, nil)
+	mockPermRepo.On("GetByID", uint64(13)).Return(&model.Permission{ID: 13}, nil)
+
+	roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
+	id, err := roleService.Clone(&schema.CloneRoleRequest{
+		SourceID:    1,
+		Code:        "copy",
+		Name:        "副本",
+		CopyParents: true,
+		Grants: []schema.PermissionGrant{
+			{PermissionID: 11, Effect: model.EffectDeny},
+			{PermissionID: 13},
+		},
+		RemovePermissionIDs: []uint64{12},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), id)
+
+	mockRoleRepo.AssertCalled(t, "CreateWithGrants", mock.MatchedBy(func(role *model.Role) bool {
+		return role.Description == "源角色"
+	}), []uint64{5}, []model.RolePermission{
+		{PermissionID: 10, Effect: model.EffectAllow},
+		{PermissionID: 11, Effect: model.EffectDeny},
+		{PermissionID: 13, Effect: model.EffectAllow},
+	})
+	mockRoleRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// 测试覆盖项引用不存在的权限时不创建角色
+func TestRoleService_Clone_InvalidOverride(t *testing.T) {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockRoleRepo.On("GetByID", uint64(1)).Return(&model.Role{ID: 1}, nil)
+	mockPermRepo.On("GetByID", uint64(99)).Return(nil, nil)
+
+	roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
+	_, err := roleService.Clone(&schema.CloneRoleRequest{
+		SourceID: 1,
+		Code:     "copy",
+		Name:     "副本",
+		Grants:   []schema.PermissionGrant{{PermissionID: 99}},
+	})
+
+	assert.Equal(t, errors.ErrPermissionNotFound, err)
+	mockRoleRepo.AssertNotCalled(t, "CreateWithGrants", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// testRoleTemplates 测试用角色模板
+var testRoleTemplates = []config.RoleTemplateConfig{
+	{
+		Code:        "auditor",
+		Name:        "审计员",
+		Description: "只读",
+		Version:     2,
+		Permissions: []string{"user:list", "role:list"},
+		Deny:        []string{"user:delete"},
+	},
+}
+
+// mockTemplatePermissions 为模板引用的权限编码设置查询结果
+func mockTemplatePermissions(mockPermRepo *mocks.MockPermissionRepository) {
+	mockPermRepo.On("GetByCode", "user:list").Return(&model.Permission{ID: 1, Code: "user:list"}, nil)
+	mockPermRepo.On("GetByCode", "role:list").Return(&model.Permission{ID: 2, Code: "role:list"}, nil)
+	mockPermRepo.On("GetByCode", "user:delete").Return(&model.Permission{ID: 3, Code: "user:delete"}, nil)
+}
+
+// 测试根据模板创建角色
+func TestRoleTemplateService_Instantiate(t *testing.T) {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockTemplatePermissions(mockPermRepo)
+	mockRoleRepo.On("GetByName", "审计员", uint64(0)).Return(nil, nil)
+	mockRoleRepo.On("GetByCode", "auditor", uint64(0)).Return(nil, nil)
+	mockRoleRepo.On("CreateWithGrants", mock.AnythingOfType("*model.Role"), []uint64(nil), mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Role).ID = 7
+	}).Return(nil)
+
+	roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
+	templateService := service.NewRoleTemplateService(testRoleTemplates, roleService, mockRoleRepo, mockPermRepo, nil)
+
+	id, err := templateService.Instantiate(&schema.InstantiateRoleTemplateRequest{TemplateCode: "auditor"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), id)
+
+	mockRoleRepo.AssertCalled(t, "CreateWithGrants", mock.MatchedBy(func(role *model.Role) bool {
+		return role.Code == "auditor" && role.TemplateCode == "auditor" && role.TemplateVersion == 2
+	}), []uint64(nil), []model.RolePermission{
+		{PermissionID: 1, Effect: model.EffectAllow},
+		{PermissionID: 2, Effect: model.EffectAllow},
+		{PermissionID: 3, Effect: model.EffectDeny},
+	})
+
+	_, err = templateService.Instantiate(&schema.InstantiateRoleTemplateRequest{TemplateCode: "missing"})
+	assert.Equal(t, errors.ErrRoleTemplateNotFound, err)
+}
+
+// 测试模板同步报告每个角色的变更，并只写入发生变化的角色
+func TestRoleTemplateService_Propagate(t *testing.T) {
+	// 角色7与模板一致但版本落后，角色8缺少 role:list、拒绝变为允许并多出一个手动授予的权限
+	inSync := &model.Role{
+		ID: 7, Code: "auditor", TemplateCode: "auditor", TemplateVersion: 1,
+		Permissions: []model.Permission{{ID: 1}, {ID: 2}, {ID: 3}},
+		PermissionGrants: []model.RolePermission{
+			{PermissionID: 1, Effect: model.EffectAllow},
+			{PermissionID: 2, Effect: model.EffectAllow},
+			{PermissionID: 3, Effect: model.EffectDeny},
+		},
+	}
+	drifted := &model.Role{
+		ID: 8, Code: "auditor-eu", TemplateCode: "auditor", TemplateVersion: 1,
+		Permissions: []model.Permission{{ID: 1}, {ID: 3}, {ID: 4, Code: "user:create"}},
+		PermissionGrants: []model.RolePermission{
+			{PermissionID: 1, Effect: model.EffectAllow},
+			{PermissionID: 3, Effect: model.EffectAllow},
+			{PermissionID: 4, Effect: model.EffectAllow},
+		},
+	}
+
+	newService := func() (service.RoleTemplateService, *mocks.MockRoleRepository) {
+		mockRoleRepo := new(mocks.MockRoleRepository)
+		mockPermRepo := new(mocks.MockPermissionRepository)
+		mockTemplatePermissions(mockPermRepo)
+		mockRoleRepo.On("ListByTemplate", "auditor").Return([]*model.Role{inSync, drifted}, nil)
+		mockRoleRepo.On("SyncTemplate", mock.Anything).Return(nil)
+		roleService := service.NewRoleService(mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), nil)
+		return service.NewRoleTemplateService(testRoleTemplates, roleService, mockRoleRepo, mockPermRepo, nil), mockRoleRepo
+	}
+
+	t.Run("预览不写入", func(t *testing.T) {
+		templateService, mockRoleRepo := newService()
+		report, err := templateService.Propagate(&schema.PropagateRoleTemplateRequest{TemplateCode: "auditor", DryRun: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Version)
+		require.Len(t, report.Unchanged, 1)
+		assert.Equal(t, uint64(7), report.Unchanged[0].ID)
+		require.Len(t, report.Roles, 1)
+		change := report.Roles[0]
+		assert.Equal(t, uint64(8), change.Role.ID)
+		assert.Equal(t, 1, change.FromVersion)
+		require.Len(t, change.Added, 1)
+		assert.Equal(t, "role:list", change.Added[0].Code)
+		require.Len(t, change.Changed, 1)
+		assert.Equal(t, uint64(3), change.Changed[0].ID)
+		assert.Equal(t, model.EffectDeny, change.Changed[0].Effect)
+		require.Len(t, change.Removed, 1)
+		assert.Equal(t, "user:create", change.Removed[0].Code)
+
+		mockRoleRepo.AssertNotCalled(t, "SyncTemplate", mock.Anything)
+	})
+
+	t.Run("同步写入", func(t *testing.T) {
+		templateService, mockRoleRepo := newService()
+		_, err := templateService.Propagate(&schema.PropagateRoleTemplateRequest{TemplateCode: "auditor"})
+		require.NoError(t, err)
+
+		// 角色7只更新版本，角色8同时替换授予记录，两者在同一次调用中写入
+		mockRoleRepo.AssertNumberOfCalls(t, "SyncTemplate", 1)
+		mockRoleRepo.AssertCalled(t, "SyncTemplate", []*model.RoleTemplateSync{
+			{RoleID: 7, Version: 2},
+			{RoleID: 8, Version: 2, Grants: []model.RolePermission{
+				{RoleID: 8, PermissionID: 1, Effect: model.EffectAllow},
+				{RoleID: 8, PermissionID: 2, Effect: model.EffectAllow},
+				{RoleID: 8, PermissionID: 3, Effect: model.EffectDeny},
+			}},
+		})
+	})
+}
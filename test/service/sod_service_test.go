@@ -0,0 +1,410 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/hash"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试使用的角色：maker(10) 与 checker(20) 互斥，manager(30) 继承 checker，viewer(40) 不受约束
+var (
+	sodMaker   = model.Role{ID: 10, Code: "maker", Name: "制单"}
+	sodChecker = model.Role{ID: 20, Code: "checker", Name: "复核"}
+)
+
+// newSoDRoleRepo 创建按上述继承关系返回祖先与后代的角色仓库
+func newSoDRoleRepo() *mocks.MockRoleRepository {
+	mockRoleRepo := new(mocks.MockRoleRepository)
+	for _, id := range []uint64{10, 20, 30, 40} {
+		mockRoleRepo.On("GetByID", id).Return(&model.Role{ID: id}, nil)
+	}
+	mockRoleRepo.On("GetByID", uint64(99)).Return(nil, nil)
+	mockRoleRepo.On("GetAncestors", uint64(30)).Return([]*model.Role{{ID: 20}}, nil)
+	mockRoleRepo.On("GetAncestors", mock.Anything).Return([]*model.Role{}, nil)
+	mockRoleRepo.On("GetDescendants", uint64(20)).Return([]*model.Role{{ID: 30}}, nil)
+	mockRoleRepo.On("GetDescendants", mock.Anything).Return([]*model.Role{}, nil)
+	return mockRoleRepo
+}
+
+// sodRule 创建 maker 与 checker 互斥的规则
+func sodRule(ruleType string) *model.SoDRule {
+	return &model.SoDRule{ID: 1, Name: "付款审批", Type: ruleType, MaxRoles: 1, Roles: []model.Role{sodMaker, sodChecker}}
+}
+
+// 测试分配角色时校验静态职责分离规则，继承的角色同样计入
+func TestUserService_AssignRoleSoD(t *testing.T) {
+	tests := []struct {
+		name          string
+		tenantID      uint64
+		roleIDs       []uint64
+		existing      []*model.RoleAssignment
+		expectedError error
+	}{
+		{
+			name:          "租户内持有复核时不能再全局分配制单",
+			roleIDs:       []uint64{10},
+			existing:      []*model.RoleAssignment{{UserRole: model.UserRole{UserID: 1, RoleID: 20, TenantID: 7}}},
+			expectedError: errors.ErrSoDViolation,
+		},
+		{
+			name:          "经由继承持有复核",
+			tenantID:      7,
+			roleIDs:       []uint64{30},
+			existing:      []*model.RoleAssignment{{UserRole: model.UserRole{UserID: 1, RoleID: 10}}},
+			expectedError: errors.ErrSoDViolation,
+		},
+		{
+			name:          "经由用户组持有复核",
+			roleIDs:       []uint64{10},
+			existing:      []*model.RoleAssignment{{UserRole: model.UserRole{UserID: 1, RoleID: 20}, GroupID: 5}},
+			expectedError: errors.ErrSoDViolation,
+		},
+		{
+			name:     "替换掉同一租户内的互斥角色",
+			roleIDs:  []uint64{10, 40},
+			existing: []*model.RoleAssignment{{UserRole: model.UserRole{UserID: 1, RoleID: 20}}},
+		},
+		{
+			name:     "不同租户内的分配互不影响",
+			tenantID: 7,
+			roleIDs:  []uint64{10},
+			existing: []*model.RoleAssignment{{UserRole: model.UserRole{UserID: 1, RoleID: 20, TenantID: 8}}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			mockSoDRepo := new(mocks.MockSoDRepository)
+			mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+			mockTenantRepo.On("GetByID", tt.tenantID).Return(&model.Tenant{ID: tt.tenantID}, nil)
+			mockSoDRepo.On("ListByType", model.SoDStatic).Return([]*model.SoDRule{sodRule(model.SoDStatic)}, nil)
+			mockSoDRepo.On("GetUserAssignments", uint64(1)).Return(tt.existing, nil)
+			mockUserRepo.On("UpdateRoles", uint64(1), tt.tenantID, tt.roleIDs, model.Validity{}).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, newSoDRoleRepo(), new(mocks.MockPermissionRepository), mockTenantRepo, new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, mockSoDRepo)
+			err := userService.AssignRole(&schema.AssignRoleRequest{UserID: 1, TenantID: tt.tenantID, RoleIDs: tt.roleIDs})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				mockUserRepo.AssertNotCalled(t, "UpdateRoles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试登录时按动态职责分离规则校验会话激活的角色
+func TestUserService_LoginActiveRoles(t *testing.T) {
+	password, err := hash.GeneratePassword("password123")
+	require.NoError(t, err)
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+	tests := []struct {
+		name          string
+		activeRoleIDs []uint64
+		expected      []uint64
+		expectedError error
+	}{
+		{
+			name:          "未指定时激活全部角色，违反动态规则",
+			expectedError: errors.ErrDynamicSoDViolation,
+		},
+		{
+			name:          "只激活制单",
+			activeRoleIDs: []uint64{10, 10},
+			expected:      []uint64{10},
+		},
+		{
+			name:          "只激活经由用户组获得的复核",
+			activeRoleIDs: []uint64{20},
+			expected:      []uint64{20},
+		},
+		{
+			name:          "同时激活互斥角色",
+			activeRoleIDs: []uint64{10, 20},
+			expectedError: errors.ErrDynamicSoDViolation,
+		},
+		{
+			name:          "激活未分配的角色",
+			activeRoleIDs: []uint64{40},
+			expectedError: errors.ErrRoleNotActivatable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockSoDRepo := new(mocks.MockSoDRepository)
+			mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+			mockUserRepo.On("GetByUsername", "alice").Return(&model.User{ID: 1, Username: "alice", Password: password}, nil)
+			mockUserRepo.On("GetRoleAssignments", uint64(1), uint64(0)).Return([]*model.RoleAssignment{
+				{UserRole: model.UserRole{UserID: 1, RoleID: 10}},
+			}, nil)
+			// 复核经由用户组获得，viewer 只在其他租户内分配给用户组
+			mockUserRepo.On("GetGroupRoleAssignments", uint64(1)).Return([]*model.GroupRoleAssignment{
+				{GroupRole: model.GroupRole{GroupID: 5, RoleID: 20}},
+				{GroupRole: model.GroupRole{GroupID: 5, RoleID: 40, TenantID: 8}},
+			}, nil)
+			mockSoDRepo.On("ListByType", model.SoDDynamic).Return([]*model.SoDRule{sodRule(model.SoDDynamic)}, nil)
+			mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*model.UserRefreshToken")).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, newSoDRoleRepo(), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), mockRefreshTokenRepo, jwtConfig, nil, nil, mockSoDRepo)
+			resp, err := userService.Login(&schema.LoginRequest{Username: "alice", Password: "password123", ActiveRoleIDs: tt.activeRoleIDs})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				assert.Nil(t, resp)
+				return
+			}
+			claims, err := jwt.NewTokenService(jwtConfig).ValidateToken(resp.Token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, claims.ActiveRoles)
+		})
+	}
+}
+
+// 测试存在动态规则时，未指定激活角色的令牌也记录明确的激活角色，登录后新分配的角色不会自动激活
+func TestUserService_LoginRecordsActiveRoles(t *testing.T) {
+	password, err := hash.GeneratePassword("password123")
+	require.NoError(t, err)
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+	tests := []struct {
+		name     string
+		rules    []*model.SoDRule
+		expected []uint64
+	}{
+		{name: "存在动态规则", rules: []*model.SoDRule{sodRule(model.SoDDynamic)}, expected: []uint64{10}},
+		{name: "没有动态规则时激活全部角色", rules: []*model.SoDRule{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockSoDRepo := new(mocks.MockSoDRepository)
+			mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+			mockUserRepo.On("GetByUsername", "alice").Return(&model.User{ID: 1, Username: "alice", Password: password}, nil)
+			mockUserRepo.On("GetRoleAssignments", uint64(1), uint64(0)).Return([]*model.RoleAssignment{
+				{UserRole: model.UserRole{UserID: 1, RoleID: 10}},
+			}, nil)
+			mockUserRepo.On("GetGroupRoleAssignments", uint64(1)).Return([]*model.GroupRoleAssignment{}, nil)
+			mockSoDRepo.On("ListByType", model.SoDDynamic).Return(tt.rules, nil)
+			mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*model.UserRefreshToken")).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, newSoDRoleRepo(), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), mockRefreshTokenRepo, jwtConfig, nil, nil, mockSoDRepo)
+			resp, err := userService.Login(&schema.LoginRequest{Username: "alice", Password: "password123"})
+			require.NoError(t, err)
+
+			claims, err := jwt.NewTokenService(jwtConfig).ValidateToken(resp.Token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, claims.ActiveRoles)
+		})
+	}
+}
+
+// 测试会话权限只计入激活角色及其祖先角色的授予与直接授予
+func TestUserService_GetSessionPolicy(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{RoleID: 20, PermissionCode: "payment:create", Effect: model.EffectAllow},
+		{RoleID: 30, PermissionCode: "payment:approve", Effect: model.EffectAllow},
+		{RoleID: 0, PermissionCode: "profile:view", Effect: model.EffectAllow},
+	}, nil)
+	mockUserRepo.On("GetEffectiveRoleParents", uint64(1), uint64(0)).Return([]*model.RoleInheritance{
+		{RoleID: 10, ParentID: 20},
+	}, nil)
+
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	policy, err := userService.GetSessionPolicy(1, 0, []uint64{10})
+	require.NoError(t, err)
+	assert.True(t, policy.Allows("payment:create"))
+	assert.True(t, policy.Allows("profile:view"))
+	assert.False(t, policy.Allows("payment:approve"))
+
+	// 未指定激活角色时计入全部授予
+	policy, err = userService.GetSessionPolicy(1, 0, nil)
+	require.NoError(t, err)
+	assert.True(t, policy.Allows("payment:approve"))
+}
+
+// 测试创建职责分离规则时的参数校验
+func TestSoDService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *schema.CreateSoDRuleRequest
+		existing      *model.SoDRule
+		expectedError error
+	}{
+		{
+			name: "创建成功，重复角色去重",
+			req:  &schema.CreateSoDRuleRequest{Name: "付款审批", Type: model.SoDStatic, RoleIDs: []uint64{10, 20, 20}},
+		},
+		{
+			name:          "名称已存在",
+			req:           &schema.CreateSoDRuleRequest{Name: "付款审批", Type: model.SoDStatic, RoleIDs: []uint64{10, 20}},
+			existing:      sodRule(model.SoDStatic),
+			expectedError: errors.ErrSoDRuleExists,
+		},
+		{
+			name:          "上限不小于角色数",
+			req:           &schema.CreateSoDRuleRequest{Name: "付款审批", Type: model.SoDStatic, MaxRoles: 2, RoleIDs: []uint64{10, 20, 20}},
+			expectedError: errors.ErrInvalidSoDRule,
+		},
+		{
+			name:          "角色不存在",
+			req:           &schema.CreateSoDRuleRequest{Name: "付款审批", Type: model.SoDDynamic, RoleIDs: []uint64{10, 99}},
+			expectedError: errors.ErrRoleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockSoDRepo := new(mocks.MockSoDRepository)
+			if tt.existing != nil {
+				mockSoDRepo.On("GetByName", tt.req.Name).Return(tt.existing, nil)
+			} else {
+				mockSoDRepo.On("GetByName", tt.req.Name).Return(nil, nil)
+			}
+			mockSoDRepo.On("Create", mock.MatchedBy(func(rule *model.SoDRule) bool {
+				return rule.MaxRoles == 1
+			}), []uint64{10, 20}).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.SoDRule).ID = 5
+			}).Return(nil)
+
+			sodService := service.NewSoDService(mockSoDRepo, newSoDRoleRepo(), new(mocks.MockUserRepository))
+			id, err := sodService.Create(tt.req)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				assert.Equal(t, uint64(5), id)
+			} else {
+				mockSoDRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试列出违反规则的用户：继承的角色计入，仅由全局分配构成的违反只报告一次
+func TestSoDService_Violations(t *testing.T) {
+	mockSoDRepo := new(mocks.MockSoDRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockSoDRepo.On("GetByID", uint64(1)).Return(sodRule(model.SoDStatic), nil)
+	mockSoDRepo.On("GetByID", uint64(2)).Return(nil, nil)
+	mockSoDRepo.On("GetRoleAssignments", []uint64{10, 20, 30}).Return([]*model.RoleAssignment{
+		// alice 在租户7内经由 manager 持有复核
+		{UserRole: model.UserRole{UserID: 1, RoleID: 10}},
+		{UserRole: model.UserRole{UserID: 1, RoleID: 30, TenantID: 7}},
+		// bob 全局同时持有两者，租户内的分配不再重复报告
+		{UserRole: model.UserRole{UserID: 2, RoleID: 10}},
+		{UserRole: model.UserRole{UserID: 2, RoleID: 20}},
+		{UserRole: model.UserRole{UserID: 2, RoleID: 10, TenantID: 7}},
+		// carol 只持有制单
+		{UserRole: model.UserRole{UserID: 3, RoleID: 10}},
+	}, nil)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("GetByID", uint64(2)).Return(&model.User{ID: 2, Username: "bob"}, nil)
+
+	sodService := service.NewSoDService(mockSoDRepo, newSoDRoleRepo(), mockUserRepo)
+	report, err := sodService.Violations(&schema.SoDViolationsRequest{RuleID: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Total)
+
+	assert.Equal(t, "alice", report.Violations[0].Username)
+	assert.Equal(t, uint64(7), report.Violations[0].TenantID)
+	assert.Len(t, report.Violations[0].Roles, 2)
+	assert.Equal(t, "bob", report.Violations[1].Username)
+	assert.Equal(t, uint64(0), report.Violations[1].TenantID)
+
+	_, err = sodService.Violations(&schema.SoDViolationsRequest{RuleID: 2})
+	assert.Equal(t, errors.ErrSoDRuleNotFound, err)
+}
+
+// 测试用户组的角色分配、成员与嵌套关系变化时校验全部受影响用户的静态职责分离规则
+// alice(1) 直接持有制单，用户组 5 在全局分配了复核
+func TestGroupService_SoD(t *testing.T) {
+	direct := &model.RoleAssignment{UserRole: model.UserRole{UserID: 1, RoleID: 10}}
+	viaGroup := &model.RoleAssignment{UserRole: model.UserRole{UserID: 1, RoleID: 20}, GroupID: 5}
+
+	newGroupService := func() (service.GroupService, *mocks.MockGroupRepository, *mocks.MockSoDRepository) {
+		mockGroupRepo := new(mocks.MockGroupRepository)
+		mockGroupRepo.On("GetByID", uint64(5)).Return(&model.Group{ID: 5, Code: "payments", Name: "付款"}, nil)
+		mockGroupRepo.On("GetByID", uint64(6)).Return(&model.Group{ID: 6}, nil)
+		mockUserRepo := new(mocks.MockUserRepository)
+		mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+		mockSoDRepo := new(mocks.MockSoDRepository)
+		mockSoDRepo.On("ListByType", model.SoDStatic).Return([]*model.SoDRule{sodRule(model.SoDStatic)}, nil)
+		return service.NewGroupService(mockGroupRepo, mockUserRepo, newSoDRoleRepo(), new(mocks.MockTenantRepository), nil, mockSoDRepo), mockGroupRepo, mockSoDRepo
+	}
+
+	t.Run("分配角色", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			roleIDs       []uint64
+			expectedError error
+		}{
+			{name: "替换掉组内的复核", roleIDs: []uint64{40}},
+			{name: "分配继承复核的角色", roleIDs: []uint64{30}, expectedError: errors.ErrSoDViolation},
+		}
+
+		for _, tt := range tests {
+			tt := tt // 防止闭包问题
+			t.Run(tt.name, func(t *testing.T) {
+				groupService, mockGroupRepo, mockSoDRepo := newGroupService()
+				mockGroupRepo.On("GetAffectedUserIDs", uint64(5)).Return([]uint64{1}, nil)
+				mockGroupRepo.On("UpdateRoles", uint64(5), uint64(0), tt.roleIDs).Return(nil)
+				mockSoDRepo.On("PreviewAssignments", []uint64{1}, (*model.GroupChange)(nil)).Return([]*model.RoleAssignment{direct, viaGroup}, nil)
+
+				err := groupService.AssignRoles(&schema.AssignGroupRolesRequest{GroupID: 5, RoleIDs: tt.roleIDs})
+				assert.Equal(t, tt.expectedError, err)
+				if tt.expectedError != nil {
+					mockGroupRepo.AssertNotCalled(t, "UpdateRoles", mock.Anything, mock.Anything, mock.Anything)
+				}
+			})
+		}
+	})
+
+	t.Run("加入用户组", func(t *testing.T) {
+		groupService, mockGroupRepo, mockSoDRepo := newGroupService()
+		change := &model.GroupChange{GroupID: 5, Members: []uint64{1}}
+		mockSoDRepo.On("PreviewAssignments", []uint64{1}, change).Return([]*model.RoleAssignment{direct, viaGroup}, nil)
+
+		err := groupService.AddMembers(&schema.GroupMembersRequest{GroupID: 5, UserIDs: []uint64{1}})
+		assert.Equal(t, errors.ErrSoDViolation, err)
+		mockGroupRepo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("嵌套到上级组", func(t *testing.T) {
+		groupService, mockGroupRepo, mockSoDRepo := newGroupService()
+		mockGroupRepo.On("GetByCode", "payments").Return(&model.Group{ID: 5}, nil)
+		mockGroupRepo.On("GetByName", "付款").Return(&model.Group{ID: 5}, nil)
+		mockGroupRepo.On("GetDescendantIDs", uint64(5)).Return([]uint64{}, nil)
+		mockGroupRepo.On("GetAffectedUserIDs", uint64(5)).Return([]uint64{1}, nil)
+		change := &model.GroupChange{GroupID: 5, Parents: []uint64{6}}
+		mockSoDRepo.On("PreviewAssignments", []uint64{1}, change).Return([]*model.RoleAssignment{
+			direct,
+			{UserRole: model.UserRole{UserID: 1, RoleID: 20}, GroupID: 6},
+		}, nil)
+
+		err := groupService.Update(&schema.UpdateGroupRequest{ID: 5, Code: "payments", Name: "付款", ParentIDs: []uint64{6}})
+		assert.Equal(t, errors.ErrSoDViolation, err)
+		mockGroupRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockGroupRepo.AssertNotCalled(t, "SetParents", mock.Anything, mock.Anything)
+	})
+}
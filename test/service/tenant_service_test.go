@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/hash"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/jwt"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试租户服务创建租户
+func TestTenantService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(mockTenantRepo *mocks.MockTenantRepository)
+		expectedID    uint64
+		expectedError error
+	}{
+		{
+			name: "创建租户成功",
+			mockSetup: func(mockTenantRepo *mocks.MockTenantRepository) {
+				mockTenantRepo.On("GetByCode", "acme").Return(nil, nil)
+				mockTenantRepo.On("GetByName", "Acme").Return(nil, nil)
+				mockTenantRepo.On("Create", mock.AnythingOfType("*model.Tenant")).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.Tenant).ID = 1
+				}).Return(nil)
+			},
+			expectedID: 1,
+		},
+		{
+			name: "租户编码已存在",
+			mockSetup: func(mockTenantRepo *mocks.MockTenantRepository) {
+				mockTenantRepo.On("GetByCode", "acme").Return(&model.Tenant{ID: 2, Code: "acme"}, nil)
+			},
+			expectedError: errors.ErrTenantExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			tt.mockSetup(mockTenantRepo)
+
+			tenantService := service.NewTenantService(mockTenantRepo)
+			id, err := tenantService.Create(&schema.CreateTenantRequest{Code: "acme", Name: "Acme"})
+
+			assert.Equal(t, tt.expectedID, id)
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+// 测试租户服务删除租户
+func TestTenantService_Delete(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(mockTenantRepo *mocks.MockTenantRepository)
+		expectedError error
+	}{
+		{
+			name: "删除未使用的租户",
+			mockSetup: func(mockTenantRepo *mocks.MockTenantRepository) {
+				mockTenantRepo.On("GetByID", uint64(1)).Return(&model.Tenant{ID: 1}, nil)
+				mockTenantRepo.On("CountUsage", uint64(1)).Return(int64(0), nil)
+				mockTenantRepo.On("Delete", uint64(1)).Return(nil)
+			},
+		},
+		{
+			name: "租户仍在使用",
+			mockSetup: func(mockTenantRepo *mocks.MockTenantRepository) {
+				mockTenantRepo.On("GetByID", uint64(1)).Return(&model.Tenant{ID: 1}, nil)
+				mockTenantRepo.On("CountUsage", uint64(1)).Return(int64(3), nil)
+			},
+			expectedError: errors.ErrTenantInUse,
+		},
+		{
+			name: "租户不存在",
+			mockSetup: func(mockTenantRepo *mocks.MockTenantRepository) {
+				mockTenantRepo.On("GetByID", uint64(1)).Return(nil, nil)
+			},
+			expectedError: errors.ErrTenantNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			tt.mockSetup(mockTenantRepo)
+
+			err := service.NewTenantService(mockTenantRepo).Delete(1)
+
+			assert.Equal(t, tt.expectedError, err)
+			mockTenantRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// 测试按租户分配用户角色
+func TestUserService_AssignRoleTenant(t *testing.T) {
+	tests := []struct {
+		name          string
+		tenantID      uint64
+		role          *model.Role
+		tenant        *model.Tenant
+		expectedError error
+	}{
+		{
+			name:     "在所属租户内分配租户角色",
+			tenantID: 7,
+			role:     &model.Role{ID: 10, TenantID: 7},
+			tenant:   &model.Tenant{ID: 7},
+		},
+		{
+			name:     "在租户内分配全局角色",
+			tenantID: 7,
+			role:     &model.Role{ID: 10},
+			tenant:   &model.Tenant{ID: 7},
+		},
+		{
+			name:          "租户角色不能分配到其他租户",
+			tenantID:      8,
+			role:          &model.Role{ID: 10, TenantID: 7},
+			tenant:        &model.Tenant{ID: 8},
+			expectedError: errors.ErrTenantMismatch,
+		},
+		{
+			name:          "租户角色不能作为全局分配",
+			tenantID:      0,
+			role:          &model.Role{ID: 10, TenantID: 7},
+			expectedError: errors.ErrTenantMismatch,
+		},
+		{
+			name:          "租户不存在",
+			tenantID:      9,
+			role:          &model.Role{ID: 10},
+			expectedError: errors.ErrTenantNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+			mockRoleRepo.On("GetByID", uint64(10)).Return(tt.role, nil)
+			if tt.tenant != nil {
+				mockTenantRepo.On("GetByID", tt.tenantID).Return(tt.tenant, nil)
+			} else {
+				mockTenantRepo.On("GetByID", tt.tenantID).Return(nil, nil)
+			}
+			mockUserRepo.On("UpdateRoles", uint64(1), tt.tenantID, []uint64{10}, model.Validity{}).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, new(mocks.MockPermissionRepository), mockTenantRepo, new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+			err := userService.AssignRole(&schema.AssignRoleRequest{UserID: 1, TenantID: tt.tenantID, RoleIDs: []uint64{10}})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				mockUserRepo.AssertCalled(t, "UpdateRoles", uint64(1), tt.tenantID, []uint64{10}, model.Validity{})
+			} else {
+				mockUserRepo.AssertNotCalled(t, "UpdateRoles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试指定租户登录
+func TestUserService_LoginTenant(t *testing.T) {
+	password, err := hash.GeneratePassword("password123")
+	require.NoError(t, err)
+	jwtConfig := &config.JWTConfig{Secret: "test-secret", Expire: 3600, RefreshExpire: 7200}
+
+	tests := []struct {
+		name          string
+		tenant        *model.Tenant
+		hasAccess     bool
+		expectedError error
+	}{
+		{
+			name:      "可进入的租户写入令牌",
+			tenant:    &model.Tenant{ID: 7},
+			hasAccess: true,
+		},
+		{
+			name:          "用户不属于该租户",
+			tenant:        &model.Tenant{ID: 7},
+			expectedError: errors.ErrTenantForbidden,
+		},
+		{
+			name:          "租户不存在",
+			expectedError: errors.ErrTenantNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+			mockUserRepo.On("GetByUsername", "alice").Return(&model.User{ID: 1, Username: "alice", Password: password}, nil)
+			if tt.tenant != nil {
+				mockTenantRepo.On("GetByID", uint64(7)).Return(tt.tenant, nil)
+			} else {
+				mockTenantRepo.On("GetByID", uint64(7)).Return(nil, nil)
+			}
+			mockUserRepo.On("HasTenantAccess", uint64(1), uint64(7)).Return(tt.hasAccess, nil)
+			mockRefreshTokenRepo.On("Create", mock.AnythingOfType("*model.UserRefreshToken")).Return(nil)
+
+			userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), mockTenantRepo, mockRefreshTokenRepo, jwtConfig, nil, nil, nil)
+			resp, err := userService.Login(&schema.LoginRequest{Username: "alice", Password: "password123", TenantID: 7})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError != nil {
+				assert.Nil(t, resp)
+				return
+			}
+			claims, err := jwt.NewTokenService(jwtConfig).ValidateToken(resp.Token)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(7), claims.TenantID)
+		})
+	}
+}
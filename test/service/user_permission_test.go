@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lvyunze/fiber-rbac/config"
+	"github.com/lvyunze/fiber-rbac/internal/model"
+	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
+	"github.com/lvyunze/fiber-rbac/internal/schema"
+	"github.com/lvyunze/fiber-rbac/internal/service"
+	"github.com/lvyunze/fiber-rbac/test/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// 测试直接为用户授予权限
+func TestUserService_AssignPermissions(t *testing.T) {
+	tests := []struct {
+		name           string
+		req            *schema.AssignUserPermissionsRequest
+		expectedEffect string
+		expectedError  error
+	}{
+		{
+			name:           "默认以允许效果全局授予",
+			req:            &schema.AssignUserPermissionsRequest{UserID: 1, PermissionIDs: []uint64{100}},
+			expectedEffect: model.EffectAllow,
+		},
+		{
+			name:           "在租户内以拒绝效果授予",
+			req:            &schema.AssignUserPermissionsRequest{UserID: 1, PermissionIDs: []uint64{100}, TenantID: 7, Effect: model.EffectDeny},
+			expectedEffect: model.EffectDeny,
+		},
+		{
+			name:          "用户不存在",
+			req:           &schema.AssignUserPermissionsRequest{UserID: 2, PermissionIDs: []uint64{100}},
+			expectedError: errors.ErrUserNotFound,
+		},
+		{
+			name:          "租户不存在",
+			req:           &schema.AssignUserPermissionsRequest{UserID: 1, PermissionIDs: []uint64{100}, TenantID: 8},
+			expectedError: errors.ErrTenantNotFound,
+		},
+		{
+			name:          "权限不存在",
+			req:           &schema.AssignUserPermissionsRequest{UserID: 1, PermissionIDs: []uint64{100, 999}},
+			expectedError: errors.ErrPermissionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1}, nil)
+			mockUserRepo.On("GetByID", uint64(2)).Return(nil, nil)
+			mockUserRepo.On("AddPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockTenantRepo := new(mocks.MockTenantRepository)
+			mockTenantRepo.On("GetByID", uint64(7)).Return(&model.Tenant{ID: 7}, nil)
+			mockTenantRepo.On("GetByID", uint64(8)).Return(nil, nil)
+			mockPermRepo := new(mocks.MockPermissionRepository)
+			mockPermRepo.On("GetByID", uint64(100)).Return(&model.Permission{ID: 100}, nil)
+			mockPermRepo.On("GetByID", uint64(999)).Return(nil, nil)
+
+			userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), mockPermRepo, mockTenantRepo, new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+			err := userService.AssignPermissions(tt.req)
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				mockUserRepo.AssertCalled(t, "AddPermissions", tt.req.UserID, tt.req.TenantID, tt.req.PermissionIDs, tt.expectedEffect)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "AddPermissions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// 测试用户详情标注有效权限的来源，以及直接授予的拒绝规则参与判定
+func TestUserService_DirectGrants(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		{PermissionID: 300, PermissionCode: "report:export", Effect: model.EffectAllow},
+		{RoleID: 10, RoleCode: "editor", RoleName: "编辑", PermissionID: 100, PermissionCode: "user:*", Effect: model.EffectAllow},
+		{PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectDeny},
+	}, nil)
+	mockUserRepo.On("GetGroups", uint64(1)).Return(nil, nil)
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	user, err := userService.GetByID(1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []schema.UserPermissionGrant{
+		{PermissionID: 300, PermissionCode: "report:export", Effect: model.EffectAllow, Source: schema.PermissionSourceDirect},
+		{PermissionID: 100, PermissionCode: "user:*", Effect: model.EffectAllow, Source: schema.PermissionSourceRole, Role: &schema.RoleSimple{ID: 10, Code: "editor", Name: "编辑"}},
+		{PermissionID: 200, PermissionCode: "user:delete", Effect: model.EffectDeny, Source: schema.PermissionSourceDirect},
+	}, user.Grants)
+
+	result, err := userService.CheckPermission(1, 0, nil, "report:export")
+	require.NoError(t, err)
+	assert.True(t, result.HasPermission)
+
+	result, err = userService.CheckPermission(1, 0, nil, "user:delete")
+	require.NoError(t, err)
+	assert.False(t, result.HasPermission)
+	assert.True(t, result.DeniedDirectly)
+	assert.Nil(t, result.DeniedBy)
+	assert.Equal(t, "user:delete", result.DeniedPermission)
+}
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"fmt"
 	"github.com/lvyunze/fiber-rbac/config"
 	"github.com/lvyunze/fiber-rbac/internal/model"
 	"github.com/lvyunze/fiber-rbac/internal/pkg/errors"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // 测试用户服务创建用户功能
@@ -83,6 +85,61 @@ func TestUserService_Create(t *testing.T) {
 			expectedError:  errors.ErrEmailExists,
 			expectedCalled: false,
 		},
+		{
+			name: "初始角色不存在",
+			request: &schema.CreateUserRequest{
+				Username: "testuser",
+				Email:    "test@example.com",
+				Password: "password123",
+				RoleIDs:  []uint64{5},
+			},
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository, mockRefreshTokenRepo *mocks.MockRefreshTokenRepository) {
+				mockUserRepo.On("GetByUsername", "testuser").Return(nil, nil)
+				mockUserRepo.On("GetByEmail", "test@example.com").Return(nil, nil)
+				mockRoleRepo.On("GetByID", uint64(5)).Return(nil, nil)
+			},
+			expectedID:     0,
+			expectedError:  errors.ErrRoleNotFound,
+			expectedCalled: false,
+		},
+		{
+			name: "租户角色不能作为全局分配",
+			request: &schema.CreateUserRequest{
+				Username: "testuser",
+				Email:    "test@example.com",
+				Password: "password123",
+				RoleIDs:  []uint64{5},
+			},
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository, mockRefreshTokenRepo *mocks.MockRefreshTokenRepository) {
+				mockUserRepo.On("GetByUsername", "testuser").Return(nil, nil)
+				mockUserRepo.On("GetByEmail", "test@example.com").Return(nil, nil)
+				mockRoleRepo.On("GetByID", uint64(5)).Return(&model.Role{ID: 5, TenantID: 7}, nil)
+			},
+			expectedID:     0,
+			expectedError:  errors.ErrTenantMismatch,
+			expectedCalled: false,
+		},
+		{
+			name: "添加初始角色失败",
+			request: &schema.CreateUserRequest{
+				Username: "testuser",
+				Email:    "test@example.com",
+				Password: "password123",
+				RoleIDs:  []uint64{5},
+			},
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockRoleRepo *mocks.MockRoleRepository, mockPermRepo *mocks.MockPermissionRepository, mockRefreshTokenRepo *mocks.MockRefreshTokenRepository) {
+				mockUserRepo.On("GetByUsername", "testuser").Return(nil, nil)
+				mockUserRepo.On("GetByEmail", "test@example.com").Return(nil, nil)
+				mockRoleRepo.On("GetByID", uint64(5)).Return(&model.Role{ID: 5}, nil)
+				mockUserRepo.On("Create", mock.AnythingOfType("*model.User")).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.User).ID = 1
+				}).Return(nil)
+				mockUserRepo.On("AddRoles", uint64(1), uint64(0), []uint64{5}, model.Validity{}).Return(assert.AnError)
+			},
+			expectedID:     0,
+			expectedError:  assert.AnError,
+			expectedCalled: true,
+		},
 	}
 
 	// 运行测试用例
@@ -105,7 +162,7 @@ func TestUserService_Create(t *testing.T) {
 			}
 			
 			// 创建用户服务
-			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, mockRefreshTokenRepo, jwtConfig)
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), mockRefreshTokenRepo, jwtConfig, nil, nil, nil)
 			
 			// 调用创建用户方法
 			id, err := userService.Create(tt.request)
@@ -124,6 +181,45 @@ func TestUserService_Create(t *testing.T) {
 	}
 }
 
+// 测试更新用户时校验并写入角色分配
+func TestUserService_UpdateRoles(t *testing.T) {
+	tests := []struct {
+		name          string
+		role          *model.Role
+		updateErr     error
+		expectedError error
+	}{
+		{name: "角色不存在", expectedError: errors.ErrRoleNotFound},
+		{name: "租户角色不能作为全局分配", role: &model.Role{ID: 5, TenantID: 7}, expectedError: errors.ErrTenantMismatch},
+		{name: "更新角色失败时返回错误", role: &model.Role{ID: 5}, updateErr: assert.AnError, expectedError: assert.AnError},
+		{name: "更新成功", role: &model.Role{ID: 5}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockRoleRepo := new(mocks.MockRoleRepository)
+			mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil)
+			mockUserRepo.On("Update", mock.AnythingOfType("*model.User")).Return(nil)
+			mockUserRepo.On("UpdateRoles", uint64(1), uint64(0), []uint64{5}, model.Validity{}).Return(tt.updateErr)
+			if tt.role != nil {
+				mockRoleRepo.On("GetByID", uint64(5)).Return(tt.role, nil)
+			} else {
+				mockRoleRepo.On("GetByID", uint64(5)).Return(nil, nil)
+			}
+
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{Secret: "test-secret", Expire: 3600}, nil, nil, nil)
+			err := userService.Update(&schema.UpdateUserRequest{ID: 1, Username: "alice", Email: "alice@example.com", RoleIDs: []uint64{5}})
+
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == errors.ErrRoleNotFound || tt.expectedError == errors.ErrTenantMismatch {
+				mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+			}
+		})
+	}
+}
+
 // 测试用户服务登录功能
 func TestUserService_Login(t *testing.T) {
 	// 测试用例表
@@ -200,7 +296,7 @@ func TestUserService_Login(t *testing.T) {
 				Expire: 3600,
 			}
 
-			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, mockRefreshTokenRepo, jwtConfig)
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), mockRefreshTokenRepo, jwtConfig, nil, nil, nil)
 
 			response, err := userService.Login(tt.request)
 
@@ -238,6 +334,9 @@ func TestUserService_GetProfile(t *testing.T) {
 					Roles:     []model.Role{},
 				}
 				mockUserRepo.On("GetByID", uint64(1)).Return(existingUser, nil)
+				mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+					{RoleID: 1, RoleCode: "admin", PermissionCode: "user:list", Effect: model.EffectAllow},
+				}, nil)
 			},
 			expectedError: nil,
 			expectedUser:  true,
@@ -259,6 +358,7 @@ func TestUserService_GetProfile(t *testing.T) {
 			mockUserRepo := new(mocks.MockUserRepository)
 			mockRoleRepo := new(mocks.MockRoleRepository)
 			mockPermRepo := new(mocks.MockPermissionRepository)
+			mockPermRepo.On("ListAll").Return([]*model.Permission{}, nil)
 			mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
 
 			// 设置模拟行为
@@ -269,9 +369,9 @@ func TestUserService_GetProfile(t *testing.T) {
 				Expire: 3600,
 			}
 
-			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, mockRefreshTokenRepo, jwtConfig)
+			userService := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), mockRefreshTokenRepo, jwtConfig, nil, nil, nil)
 
-			user, err := userService.GetProfile(tt.userID)
+			user, err := userService.GetProfile(tt.userID, 0, nil)
 
 			assert.Equal(t, tt.expectedError, err)
 
@@ -291,7 +391,9 @@ func TestUserService_List(t *testing.T) {
 	mockRoleRepo := new(mocks.MockRoleRepository)
 	mockPermRepo := new(mocks.MockPermissionRepository)
 	mockRefreshTokenRepo := new(mocks.MockRefreshTokenRepository)
-	service := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, mockRefreshTokenRepo, &config.JWTConfig{})
+	service := service.NewUserService(mockUserRepo, mockRoleRepo, mockPermRepo, new(mocks.MockTenantRepository), mockRefreshTokenRepo, &config.JWTConfig{}, nil, nil, nil)
+	// 调用方可访问全部数据，数据范围原样传给仓储层
+	scope := &model.DataScope{UserID: 1, All: true}
 
 	tests := []struct {
 		name       string
@@ -309,7 +411,7 @@ func TestUserService_List(t *testing.T) {
 			keyword:  "",
 			mockSetup: func() {
 				users := []*model.User{{ID: 1, Username: "u1"}, {ID: 2, Username: "u2"}}
-				mockUserRepo.On("List", 1, 2, "").Return(users, int64(2), nil)
+				mockUserRepo.On("List", 1, 2, "", uint64(0), scope).Return(users, int64(2), nil)
 			},
 			expectResp: &schema.ListUserResponse{
 				Total:      2,
@@ -326,7 +428,7 @@ func TestUserService_List(t *testing.T) {
 			pageSize: 10,
 			keyword:  "",
 			mockSetup: func() {
-				mockUserRepo.On("List", 1, 10, "").Return([]*model.User{}, int64(0), nil)
+				mockUserRepo.On("List", 1, 10, "", uint64(0), scope).Return([]*model.User{}, int64(0), nil)
 			},
 			expectResp: &schema.ListUserResponse{
 				Total:      0,
@@ -343,7 +445,7 @@ func TestUserService_List(t *testing.T) {
 			pageSize: 10,
 			keyword:  "",
 			mockSetup: func() {
-				mockUserRepo.On("List", 1, 10, "").Return([]*model.User(nil), int64(0), errors.ErrDB)
+				mockUserRepo.On("List", 1, 10, "", uint64(0), scope).Return([]*model.User(nil), int64(0), errors.ErrDB)
 			},
 			expectResp: nil,
 			expectErr: errors.ErrDB,
@@ -353,12 +455,182 @@ func TestUserService_List(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUserRepo.ExpectedCalls = nil // 清理历史
+			mockUserRepo.On("GetDataScope", uint64(1), uint64(0)).Return(scope, nil)
 			if tt.mockSetup != nil {
 				tt.mockSetup()
 			}
-			resp, err := service.List(&schema.ListUserRequest{Page: tt.page, PageSize: tt.pageSize, Keyword: tt.keyword})
+			resp, err := service.List(&schema.ListUserRequest{Page: tt.page, PageSize: tt.pageSize, Keyword: tt.keyword}, 1, 0)
 			assert.Equal(t, tt.expectErr, err)
 			assert.Equal(t, tt.expectResp, resp)
 		})
 	}
 }
+
+// 构造测试用的权限授予记录
+func testGrant(roleID uint64, code, effect string) *model.PermissionGrant {
+	return &model.PermissionGrant{
+		RoleID:         roleID,
+		RoleCode:       fmt.Sprintf("role%d", roleID),
+		RoleName:       fmt.Sprintf("角色%d", roleID),
+		PermissionCode: code,
+		Effect:         effect,
+	}
+}
+
+// 测试用户服务权限检查功能
+func TestUserService_CheckPermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		permission    string
+		grants        []*model.PermissionGrant
+		repoErr       error
+		expected      *schema.CheckPermissionResponse
+		expectedError error
+	}{
+		{
+			name:       "具备权限",
+			permission: "user:delete",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:list", model.EffectAllow), testGrant(1, "user:delete", model.EffectAllow)},
+			expected:   &schema.CheckPermissionResponse{HasPermission: true},
+		},
+		{
+			name:       "不具备权限",
+			permission: "user:delete",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:list", model.EffectAllow)},
+			expected:   &schema.CheckPermissionResponse{},
+		},
+		{
+			name:       "通配符权限匹配",
+			permission: "user:delete",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:*", model.EffectAllow)},
+			expected:   &schema.CheckPermissionResponse{HasPermission: true},
+		},
+		{
+			name:       "超级权限匹配",
+			permission: "permission:update",
+			grants:     []*model.PermissionGrant{testGrant(1, "*:*", model.EffectAllow)},
+			expected:   &schema.CheckPermissionResponse{HasPermission: true},
+		},
+		{
+			name:       "其他角色的拒绝优先于允许",
+			permission: "user:delete",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:*", model.EffectAllow), testGrant(2, "user:delete", model.EffectDeny)},
+			expected: &schema.CheckPermissionResponse{
+				DeniedBy:         &schema.RoleSimple{ID: 2, Code: "role2", Name: "角色2"},
+				DeniedPermission: "user:delete",
+			},
+		},
+		{
+			name:       "通配符拒绝覆盖精确允许",
+			permission: "user:delete",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:delete", model.EffectAllow), testGrant(1, "user:*", model.EffectDeny)},
+			expected: &schema.CheckPermissionResponse{
+				DeniedBy:         &schema.RoleSimple{ID: 1, Code: "role1", Name: "角色1"},
+				DeniedPermission: "user:*",
+			},
+		},
+		{
+			name:       "拒绝规则不影响其他权限",
+			permission: "user:update",
+			grants:     []*model.PermissionGrant{testGrant(1, "user:*", model.EffectAllow), testGrant(2, "user:delete", model.EffectDeny)},
+			expected:   &schema.CheckPermissionResponse{HasPermission: true},
+		},
+		{
+			name:          "数据库异常",
+			permission:    "user:delete",
+			repoErr:       errors.ErrDB,
+			expectedError: errors.ErrDB,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // 防止闭包问题
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(mocks.MockUserRepository)
+			mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return(tt.grants, tt.repoErr)
+
+			userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), new(mocks.MockPermissionRepository), new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+			result, err := userService.CheckPermission(1, 0, nil, tt.permission)
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expected, result)
+			// 仅通过一次联表查询完成判定
+			mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+			mockUserRepo.AssertNumberOfCalls(t, "GetEffectivePermissionGrants", 1)
+		})
+	}
+}
+
+// 测试个人信息中的权限列表排除被拒绝的权限
+func TestUserService_GetProfilePermissions(t *testing.T) {
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "support"}, nil)
+	mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return([]*model.PermissionGrant{
+		testGrant(1, "user:delete", model.EffectAllow),
+		testGrant(1, "user:list", model.EffectAllow),
+		testGrant(2, "user:list", model.EffectAllow),
+		testGrant(2, "user:delete", model.EffectDeny),
+	}, nil)
+
+	mockPermRepo := new(mocks.MockPermissionRepository)
+	mockPermRepo.On("ListAll").Return([]*model.Permission{}, nil)
+
+	userService := service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), mockPermRepo, new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+
+	profile, err := userService.GetProfile(1, 0, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:list"}, profile.Permissions)
+	assert.Equal(t, []string{"user:delete"}, profile.DeniedPermissions)
+}
+
+// 测试个人信息返回菜单树与权限版本，版本只随有效权限与菜单变化
+func TestUserService_GetProfileVersion(t *testing.T) {
+	newService := func(grants []*model.PermissionGrant) service.UserService {
+		mockUserRepo := new(mocks.MockUserRepository)
+		mockUserRepo.On("GetByID", uint64(1)).Return(&model.User{ID: 1, Username: "support"}, nil)
+		mockUserRepo.On("GetEffectivePermissionGrants", uint64(1), uint64(0)).Return(grants, nil)
+		mockPermRepo := new(mocks.MockPermissionRepository)
+		mockPermRepo.On("ListAll").Return([]*model.Permission{
+			{ID: 1, Code: "system", Type: model.PermissionTypeModule},
+			{ID: 2, Code: "user:list", Type: model.PermissionTypeMenu, ParentID: 1},
+			{ID: 3, Code: "user:delete", Type: model.PermissionTypeButton, ParentID: 2},
+		}, nil)
+		return service.NewUserService(mockUserRepo, new(mocks.MockRoleRepository), mockPermRepo, new(mocks.MockTenantRepository), new(mocks.MockRefreshTokenRepository), &config.JWTConfig{}, nil, nil, nil)
+	}
+
+	userService := newService([]*model.PermissionGrant{
+		testGrant(1, "user:list", model.EffectAllow),
+		testGrant(1, "system", model.EffectAllow),
+	})
+	profile, err := userService.GetProfile(1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "user:list"}, profile.Permissions)
+	require.Len(t, profile.Menus, 1)
+	require.Len(t, profile.Menus[0].Children, 1)
+	assert.Equal(t, "user:list", profile.Menus[0].Children[0].Code)
+	assert.NotEmpty(t, profile.PermissionVersion)
+
+	version, err := userService.GetPermissionVersion(1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, profile.PermissionVersion, version)
+
+	// 授予顺序与重复不影响版本
+	reordered, err := newService([]*model.PermissionGrant{
+		testGrant(2, "system", model.EffectAllow),
+		testGrant(1, "user:list", model.EffectAllow),
+		testGrant(1, "system", model.EffectAllow),
+	}).GetPermissionVersion(1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, version, reordered)
+
+	// 新增授予改变版本
+	changed, err := newService([]*model.PermissionGrant{
+		testGrant(1, "user:list", model.EffectAllow),
+		testGrant(1, "system", model.EffectAllow),
+		testGrant(1, "user:delete", model.EffectAllow),
+	}).GetPermissionVersion(1, 0, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, version, changed)
+}